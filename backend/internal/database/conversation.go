@@ -0,0 +1,36 @@
+package database
+
+import (
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// migrateConversationDirectKeys 為新增 direct_key 欄位前建立的一對一對話補上 direct_key，
+// 同一對使用者有多個一對一對話時只有最早建立的對話會補上 (其餘保留為 nil)
+func migrateConversationDirectKeys(db *gorm.DB) error {
+	conversations := []models.Conversation{}
+	if err := db.Where("is_group = ? AND direct_key IS NULL", false).
+		Order("created_at ASC").Order("id ASC").
+		Preload("Participants").
+		Find(&conversations).Error; err != nil {
+		return err
+	}
+	for _, conversation := range conversations {
+		if len(conversation.Participants) != 2 {
+			continue
+		}
+		directKey := models.ConversationDirectKey(conversation.Participants[0].UserID, conversation.Participants[1].UserID)
+		existedCount := int64(0)
+		if err := db.Model(&models.Conversation{}).Where("direct_key = ?", directKey).Count(&existedCount).Error; err != nil {
+			return err
+		}
+		if existedCount > 0 {
+			continue
+		}
+		if err := db.Model(&models.Conversation{}).Where("id = ?", conversation.ID).Update("direct_key", directKey).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -21,10 +21,35 @@ func Migrate(db *gorm.DB) error {
 		&models.User{},
 		&models.Post{},
 		&models.Comment{},
+		&models.CommentEditHistory{},
 		&models.Tag{},
+		&models.TagAlias{},
+		&models.RefreshToken{},
+		&models.Follow{},
+		&models.Mention{},
+		&models.Notification{},
+		&models.Block{},
+		&models.Mute{},
+		&models.Conversation{},
+		&models.ConversationParticipant{},
+		&models.Message{},
+		&models.Report{},
+		&models.AuditLog{},
 	); err != nil {
 		return err
 	}
+	if err := migratePostSearch(db); err != nil {
+		return err
+	}
+	if err := migrateTagNames(db); err != nil {
+		return err
+	}
+	if err := migrateNotificationGroupKeys(db); err != nil {
+		return err
+	}
+	if err := migrateConversationDirectKeys(db); err != nil {
+		return err
+	}
 
 	// 創建管理員帳號
 	if adminEmail, adminPassword := os.Getenv("ADMIN_EMAIL"), os.Getenv("ADMIN_PASSWORD"); adminEmail != "" && adminPassword != "" {
@@ -68,16 +93,20 @@ func createGuestUser(db *gorm.DB, email string, password string) (*models.User,
 	}
 
 	// 建立訪客帳號
+	hashedPassword, err := cryptoUtils.GeneratePasswordHash(&pkg.CryptoUtilsPasswordHashInput{
+		Email:    email,
+		Password: password,
+	})
+	if err != nil {
+		return nil, err
+	}
 	newUser := &models.User{
 		TableModel: models.TableModel{ID: uuid.New()},
 		UserBase: models.UserBase{
-			Username: "訪客",
-			Email:    email,
-			HashedPassword: cryptoUtils.GeneratePasswordHash(&pkg.CryptoUtilsPasswordHashInput{
-				Email:    email,
-				Password: password,
-			}),
-			Role: models.RoleNormalCustomer,
+			Username:       "訪客",
+			Email:          email,
+			HashedPassword: hashedPassword,
+			Role:           models.RoleNormalCustomer,
 		},
 	}
 	if err := db.Create(newUser).Error; err != nil {
@@ -98,51 +127,40 @@ func createAdminUser(db *gorm.DB, email string, password string) (*models.User,
 	}
 	username := matches[1]
 
-	// 刪除現有的管理員帳號
-	admins := []models.User{}
-	if err := db.Where("role = ?", models.RoleAdmin).Find(&admins).Error; err != nil {
+	// 建立管理帳號的密碼雜湊
+	hashedPassword, err := cryptoUtils.GeneratePasswordHash(&pkg.CryptoUtilsPasswordHashInput{
+		Email:    email,
+		Password: password,
+	})
+	if err != nil {
 		return nil, err
 	}
-	for _, admin := range admins {
-		posts := []models.Post{}
-		if err := db.Where("author_id = ?", admin.ID).Find(&posts).Error; err != nil {
-			return nil, err
-		}
-		for _, post := range posts {
-			if err := db.Where("post_id = ?", post.ID).Delete(&models.Comment{}).Error; err != nil {
-				return nil, err
-			}
-			if err := db.Model(&post).Association("Tags").Clear(); err != nil {
-				return nil, err
-			}
-			if err := db.Model(&post).Association("Likes").Clear(); err != nil {
-				return nil, err
-			}
-			if err := db.Where("id = ?", post.ID).Delete(&models.Post{}).Error; err != nil {
-				return nil, err
-			}
-		}
-		if err := db.Where("id = ?", admin.ID).Delete(&models.User{}).Error; err != nil {
-			return nil, err
-		}
-	}
 
-	// 檢查是否已存在相同 email 的使用者
+	// 已存在相同 email 的使用者，更新為管理員並重設密碼 (保留其他透過 API 指派的管理員)
 	user := &models.User{}
 	if err := db.Where("email = ?", email).First(user).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
+	if user.ID != uuid.Nil {
+		if err := db.Model(user).Updates(map[string]any{
+			"role":             models.RoleAdmin,
+			"hashed_password":  hashedPassword,
+			"suspended_at":     nil,
+			"suspended_reason": nil,
+		}).Error; err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
 
 	// 建立管理帳號
 	newUser := &models.User{
 		TableModel: models.TableModel{ID: uuid.New()},
 		UserBase: models.UserBase{
-			Username: username,
-			Email:    email,
-			HashedPassword: cryptoUtils.GeneratePasswordHash(&pkg.CryptoUtilsPasswordHashInput{
-				Email:    email,
-				Password: password,
-			}),
+			Username:       username,
+			Email:          email,
+			HashedPassword: hashedPassword,
+			Role:           models.RoleAdmin,
 		},
 	}
 	if err := db.Create(newUser).Error; err != nil {
@@ -0,0 +1,14 @@
+package database
+
+import (
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// migrateNotificationGroupKeys 新增 group_key 欄位前建立的通知不與其他通知合併
+func migrateNotificationGroupKeys(db *gorm.DB) error {
+	return db.Model(&models.Notification{}).
+		Where("group_key = ''").
+		Update("group_key", gorm.Expr("CAST(id AS TEXT)")).Error
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// POST_SEARCH_FTS_TABLE SQLite FTS5 全文索引 (external content 指向 posts.search_text)
+const POST_SEARCH_FTS_TABLE = "posts_fts"
+
+var sqliteFTS5Once sync.Once
+var sqliteFTS5Enabled bool
+
+// IsSQLiteFTS5Enabled go-sqlite3 需以 -tags sqlite_fts5 編譯才支援 FTS5，編譯選項在執行期間不會改變，只檢查一次
+func IsSQLiteFTS5Enabled(db *gorm.DB) bool {
+	sqliteFTS5Once.Do(func() {
+		enabled := 0
+		if err := db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled).Error; err == nil {
+			sqliteFTS5Enabled = enabled == 1
+		}
+	})
+	return sqliteFTS5Enabled
+}
+
+// migratePostSearch 補齊舊貼文的索引詞並建立全文索引，可重複執行
+func migratePostSearch(db *gorm.DB) error {
+	if err := backfillPostSearchText(db); err != nil {
+		return err
+	}
+
+	switch db.Dialector.Name() {
+	case "postgres":
+		// 'simple' 設定不做語言處理，中文已由應用程式切成兩字詞
+		if err := db.Exec(`ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (to_tsvector('simple', search_text)) STORED`).Error; err != nil {
+			return err
+		}
+		return db.Exec("CREATE INDEX IF NOT EXISTS idx_posts_search_vector ON posts USING GIN (search_vector)").Error
+	case "sqlite":
+		if !IsSQLiteFTS5Enabled(db) {
+			// 未支援 FTS5 時移除觸發器，避免寫入貼文時因缺少模組而失敗 (搜尋退回 LIKE 比對)
+			for _, trigger := range []string{"posts_fts_insert", "posts_fts_delete", "posts_fts_update"} {
+				if err := db.Exec("DROP TRIGGER IF EXISTS " + trigger).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		for _, sql := range []string{
+			"CREATE VIRTUAL TABLE IF NOT EXISTS " + POST_SEARCH_FTS_TABLE + " USING fts5(search_text, content='posts', content_rowid='rowid')",
+			`CREATE TRIGGER IF NOT EXISTS posts_fts_insert AFTER INSERT ON posts BEGIN
+				INSERT INTO ` + POST_SEARCH_FTS_TABLE + `(rowid, search_text) VALUES (new.rowid, new.search_text);
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS posts_fts_delete AFTER DELETE ON posts BEGIN
+				INSERT INTO ` + POST_SEARCH_FTS_TABLE + `(` + POST_SEARCH_FTS_TABLE + `, rowid, search_text) VALUES ('delete', old.rowid, old.search_text);
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS posts_fts_update AFTER UPDATE OF search_text ON posts BEGIN
+				INSERT INTO ` + POST_SEARCH_FTS_TABLE + `(` + POST_SEARCH_FTS_TABLE + `, rowid, search_text) VALUES ('delete', old.rowid, old.search_text);
+				INSERT INTO ` + POST_SEARCH_FTS_TABLE + `(rowid, search_text) VALUES (new.rowid, new.search_text);
+			END`,
+			// 觸發器可能曾被移除，重建索引確保與 posts 一致
+			"INSERT INTO " + POST_SEARCH_FTS_TABLE + "(" + POST_SEARCH_FTS_TABLE + ") VALUES ('rebuild')",
+		} {
+			if err := db.Exec(sql).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// backfillPostSearchText 為新增 search_text 欄位前建立的貼文產生索引詞
+func backfillPostSearchText(db *gorm.DB) error {
+	searchUtils := pkg.NewSearchUtils()
+	posts := []models.Post{}
+	if err := db.Model(&models.Post{}).
+		Select("id", "content").
+		Where("search_text = '' AND content <> ''").
+		Find(&posts).Error; err != nil {
+		return err
+	}
+	for _, post := range posts {
+		if err := db.Model(&models.Post{}).
+			Where("id = ?", post.ID).
+			UpdateColumn("search_text", searchUtils.BuildSearchText(post.Content)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"backend/internal/models"
+	"backend/internal/pkg"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// mergeTagPosts 將來源標籤的貼文關聯移到目標標籤 (已關聯的貼文不重複)，並移除來源標籤的貼文關聯，
+// 遷移時使用，執行期的合併使用 TagRepository.MergePosts
+func mergeTagPosts(db *gorm.DB, sourceTagIDs []uuid.UUID, targetTagID uuid.UUID) error {
+	// 從 tags 選取目標標籤的 id，避免參數在 PostgreSQL 中被推斷為 text
+	if err := db.Exec(`INSERT INTO post_to_tag (post_id, tag_id)
+		SELECT DISTINCT post_to_tag.post_id, tags.id FROM post_to_tag, tags
+		WHERE tags.id = ? AND post_to_tag.tag_id IN ?
+		AND post_to_tag.post_id NOT IN (SELECT post_id FROM post_to_tag WHERE tag_id = ?)`,
+		targetTagID, sourceTagIDs, targetTagID,
+	).Error; err != nil {
+		return err
+	}
+	return db.Exec("DELETE FROM post_to_tag WHERE tag_id IN ?", sourceTagIDs).Error
+}
+
+// migrateTagNames 將既有標籤名稱正規化，正規化後名稱相同的標籤合併到最早建立的標籤，
+// 正規化後無效的標籤 (例如只有標點符號) 連同貼文關聯一併刪除，已正規化時不做任何事
+func migrateTagNames(db *gorm.DB) error {
+	tagUtils := pkg.NewTagUtils()
+
+	tags := []models.Tag{}
+	if err := db.Order("created_at").Order("id").Find(&tags).Error; err != nil {
+		return err
+	}
+	canonicals := map[string]*models.Tag{}
+	sourceTagIDs := map[uuid.UUID][]uuid.UUID{}
+	invalidTagIDs := []uuid.UUID{}
+	renamed := false
+	for i := range tags {
+		tag := &tags[i]
+		name, ok := tagUtils.Normalize(tag.Name)
+		if !ok {
+			invalidTagIDs = append(invalidTagIDs, tag.ID)
+			continue
+		}
+		if canonical, exists := canonicals[name]; exists {
+			sourceTagIDs[canonical.ID] = append(sourceTagIDs[canonical.ID], tag.ID)
+			continue
+		}
+		canonicals[name] = tag
+		renamed = renamed || tag.Name != name
+	}
+	if !renamed && len(sourceTagIDs) == 0 && len(invalidTagIDs) == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		deleteTagIDs := invalidTagIDs
+		for targetTagID, tagIDs := range sourceTagIDs {
+			if err := mergeTagPosts(tx, tagIDs, targetTagID); err != nil {
+				return err
+			}
+			if err := tx.Model(&models.TagAlias{}).Where("tag_id IN ?", tagIDs).Update("tag_id", targetTagID).Error; err != nil {
+				return err
+			}
+			deleteTagIDs = append(deleteTagIDs, tagIDs...)
+		}
+		if len(invalidTagIDs) > 0 {
+			if err := tx.Exec("DELETE FROM post_to_tag WHERE tag_id IN ?", invalidTagIDs).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("tag_id IN ?", invalidTagIDs).Delete(&models.TagAlias{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(deleteTagIDs) > 0 {
+			if err := tx.Where("id IN ?", deleteTagIDs).Delete(&models.Tag{}).Error; err != nil {
+				return err
+			}
+		}
+		// 重複的標籤已刪除，更名不會違反唯一限制
+		for name, tag := range canonicals {
+			if tag.Name == name {
+				continue
+			}
+			if err := tx.Model(&models.Tag{}).Where("id = ?", tag.ID).Update("name", name).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
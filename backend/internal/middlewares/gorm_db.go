@@ -8,6 +8,7 @@ import (
 )
 
 const CONTEXT_KEY_GORM_DB string = "CONTEXT_KEY:GORM_DB"
+const CONTEXT_KEY_AFTER_COMMIT string = "CONTEXT_KEY:AFTER_COMMIT"
 
 func WarpGORMDBHandler(db *gorm.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -32,14 +33,52 @@ func SetContentGORMDB(ctx *gin.Context, db *gorm.DB) {
 	ctx.Set(CONTEXT_KEY_GORM_DB, db)
 }
 
+// TransactionGORMDB 在交易中執行 fn，巢狀呼叫時使用 savepoint，
+// 最外層交易提交後依序執行 AfterCommit 註冊的函式，巢狀呼叫回滾時捨棄其中註冊的函式
 func TransactionGORMDB(ctx *gin.Context, fn func() error) error {
 	db, err := GetContentGORMDB(ctx)
 	if err != nil {
 		return err
 	}
-	return db.Transaction(func(tx *gorm.DB) error {
+	callbacks, nested := getAfterCommitCallbacks(ctx)
+	if !nested {
+		callbacks = &[]func(){}
+		ctx.Set(CONTEXT_KEY_AFTER_COMMIT, callbacks)
+		defer ctx.Set(CONTEXT_KEY_AFTER_COMMIT, (*[]func())(nil))
+	}
+	callbackCount := len(*callbacks)
+	if err := db.Transaction(func(tx *gorm.DB) error {
 		SetContentGORMDB(ctx, tx)
 		defer SetContentGORMDB(ctx, db)
 		return fn()
-	})
+	}); err != nil {
+		*callbacks = (*callbacks)[:callbackCount]
+		return err
+	}
+	if !nested {
+		ctx.Set(CONTEXT_KEY_AFTER_COMMIT, (*[]func())(nil))
+		for _, callback := range *callbacks {
+			callback()
+		}
+	}
+	return nil
+}
+
+// AfterCommit 在目前交易提交後執行 fn (交易回滾時不執行)，不在交易中時立即執行
+func AfterCommit(ctx *gin.Context, fn func()) {
+	callbacks, ok := getAfterCommitCallbacks(ctx)
+	if !ok {
+		fn()
+		return
+	}
+	*callbacks = append(*callbacks, fn)
+}
+
+func getAfterCommitCallbacks(ctx *gin.Context) (*[]func(), bool) {
+	value, exists := ctx.Get(CONTEXT_KEY_AFTER_COMMIT)
+	if !exists {
+		return nil, false
+	}
+	callbacks, ok := value.(*[]func())
+	return callbacks, ok && callbacks != nil
 }
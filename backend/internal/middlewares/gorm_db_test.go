@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestTransactionGORMDB(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := &gin.Context{}
+	SetContentGORMDB(ctx, db)
+
+	t.Run("AfterCommit - 提交後執行", func(t *testing.T) {
+		calls := []string{}
+		err := TransactionGORMDB(ctx, func() error {
+			AfterCommit(ctx, func() { calls = append(calls, "outer") })
+			if err := TransactionGORMDB(ctx, func() error {
+				AfterCommit(ctx, func() { calls = append(calls, "nested") })
+				return nil
+			}); err != nil {
+				return err
+			}
+			assert.Empty(t, calls, "提交前不應執行")
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"outer", "nested"}, calls, "最外層交易提交後依序執行")
+	})
+
+	t.Run("AfterCommit - 回滾時不執行", func(t *testing.T) {
+		called := false
+		err := TransactionGORMDB(ctx, func() error {
+			AfterCommit(ctx, func() { called = true })
+			return errors.New("rollback")
+		})
+		assert.Error(t, err)
+		assert.False(t, called)
+	})
+
+	t.Run("AfterCommit - 巢狀交易回滾時不執行", func(t *testing.T) {
+		calls := []string{}
+		err := TransactionGORMDB(ctx, func() error {
+			AfterCommit(ctx, func() { calls = append(calls, "outer") })
+			nestedErr := TransactionGORMDB(ctx, func() error {
+				AfterCommit(ctx, func() { calls = append(calls, "nested") })
+				return errors.New("rollback")
+			})
+			assert.Error(t, nestedErr)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"outer"}, calls, "外層交易提交時不應執行已回滾的巢狀交易的函式")
+	})
+
+	t.Run("AfterCommit - 不在交易中立即執行", func(t *testing.T) {
+		called := false
+		AfterCommit(ctx, func() { called = true })
+		assert.True(t, called)
+	})
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"backend/internal/models"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole 限制只有指定角色可存取，需在 VerifyAccessToken 之後使用
+func RequireRole(roles ...models.Role) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tokenData, err := GetContentAccessTokenData(ctx)
+		if err != nil {
+			ctx.JSON(401, models.ErrorResponse{Error: "invalid token"})
+			ctx.Abort()
+			return
+		}
+		if !slices.Contains(roles, tokenData.Role) {
+			ctx.JSON(403, models.ErrorResponse{Error: "permission denied"})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// RequirePermission 限制角色需具備指定權限 (任何範圍)，需在 VerifyAccessToken 之後使用
+// 資源擁有者的檢查由 handler 透過 JWTClaimsData.Can 進行
+func RequirePermission(permissions ...models.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tokenData, err := GetContentAccessTokenData(ctx)
+		if err != nil {
+			ctx.JSON(401, models.ErrorResponse{Error: "invalid token"})
+			ctx.Abort()
+			return
+		}
+		for _, permission := range permissions {
+			if tokenData.Role.PermissionScope(permission) == models.PermissionScopeNone {
+				ctx.JSON(403, models.ErrorResponse{Error: "permission denied"})
+				ctx.Abort()
+				return
+			}
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"backend/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// 模擬 VerifyAccessToken 寫入的 Token Data
+	withTokenData := func(userID uuid.UUID, role models.Role) gin.HandlerFunc {
+		return func(ctx *gin.Context) {
+			ctx.Set(CONTEXT_KEY_ACCESS_TOKEN_DATA, jwt.MapClaims{
+				"data": map[string]any{
+					"UserID":       userID.String(),
+					"SessionID":    uuid.New().String(),
+					"Role":         float64(role),
+					"TokenVersion": float64(0),
+				},
+			})
+			ctx.Next()
+		}
+	}
+	serve := func(handlers ...gin.HandlerFunc) int {
+		server := gin.New()
+		handlers = append(handlers, func(ctx *gin.Context) {
+			ctx.JSON(200, models.SuccessResponse{Success: true})
+		})
+		server.GET("/", handlers...)
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		server.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	t.Run("RequireRole", func(t *testing.T) {
+		assert.Equal(t, 200, serve(withTokenData(uuid.New(), models.RoleAdmin), RequireRole(models.RoleAdmin)))
+		assert.Equal(t, 403, serve(withTokenData(uuid.New(), models.RoleNormalCustomer), RequireRole(models.RoleAdmin)))
+		assert.Equal(t, 401, serve(RequireRole(models.RoleAdmin)), "缺少 Token Data 應該回傳 401")
+	})
+
+	t.Run("RequirePermission", func(t *testing.T) {
+		assert.Equal(t, 200, serve(withTokenData(uuid.New(), models.RoleNormalCustomer), RequirePermission(models.PermissionPostEdit)))
+		assert.Equal(t, 403, serve(withTokenData(uuid.New(), models.RoleNormalCustomer), RequirePermission(models.PermissionTagDelete)))
+		assert.Equal(t, 200, serve(withTokenData(uuid.New(), models.RoleAdmin), RequirePermission(models.PermissionTagDelete, models.PermissionUserManage)))
+	})
+
+	t.Run("Permission Matrix", func(t *testing.T) {
+		owner := uuid.New()
+		other := uuid.New()
+		assert.True(t, models.RoleNormalCustomer.Can(models.PermissionPostEdit, owner, owner), "作者可以編輯自己的貼文")
+		assert.False(t, models.RoleNormalCustomer.Can(models.PermissionPostEdit, other, owner), "不可編輯他人的貼文")
+		assert.True(t, models.RoleNormalCustomer.Can(models.PermissionCommentDelete, other, owner, other), "任一擁有者皆可刪除")
+		assert.False(t, models.RoleNormalCustomer.Can(models.PermissionTagEdit, owner, owner), "一般用戶不可編輯標籤")
+		assert.True(t, models.RoleAdmin.Can(models.PermissionPostDelete, other, owner), "管理員可以刪除任何貼文")
+	})
+}
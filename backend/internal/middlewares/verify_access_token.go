@@ -10,6 +10,7 @@ import (
 )
 
 const CONTEXT_KEY_ACCESS_TOKEN_DATA string = "CONTEXT_KEY:ACCESS_TOKEN_DATA"
+const CONTEXT_KEY_ALLOW_PASSWORD_RESET_REQUIRED string = "CONTEXT_KEY:ALLOW_PASSWORD_RESET_REQUIRED"
 
 func VerifyAccessToken(validateToken func(authHeader string) (jwt.MapClaims, bool)) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -25,6 +26,57 @@ func VerifyAccessToken(validateToken func(authHeader string) (jwt.MapClaims, boo
 			ctx.Abort()
 			return
 		}
+
+		// 檢查 Token 所屬 session 與使用者狀態
+		claimsData, err := parseAccessTokenData(tokenData)
+		if err != nil {
+			ctx.JSON(401, models.ErrorResponse{Error: "invalid token"})
+			ctx.Abort()
+			return
+		}
+		if rejectStatus, rejectReason, err := checkAccessTokenState(ctx, claimsData); err != nil {
+			ctx.JSON(500, models.ErrorResponse{Error: pkg.NewErrorUtils().ServerInternalError(err.Error()).Error()})
+			ctx.Abort()
+			return
+		} else if rejectReason != "" {
+			ctx.JSON(rejectStatus, models.ErrorResponse{Error: rejectReason})
+			ctx.Abort()
+			return
+		}
+		ctx.Set(CONTEXT_KEY_ACCESS_TOKEN_DATA, tokenData)
+
+		ctx.Next()
+	}
+}
+
+// AllowPasswordResetRequired 放在 VerifyAccessToken 之前，需要變更密碼的使用者仍可使用該路由 (變更密碼與登出)
+func AllowPasswordResetRequired(ctx *gin.Context) {
+	ctx.Set(CONTEXT_KEY_ALLOW_PASSWORD_RESET_REQUIRED, true)
+	ctx.Next()
+}
+
+// OptionalAccessToken 有提供有效的 Token 時寫入 Token Data，否則以訪客身分繼續
+func OptionalAccessToken(validateToken func(authHeader string) (jwt.MapClaims, bool)) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authHeader := ctx.GetHeader("Authorization")
+		if authHeader == "" {
+			ctx.Next()
+			return
+		}
+		tokenData, valid := validateToken(authHeader)
+		if !valid {
+			ctx.Next()
+			return
+		}
+		claimsData, err := parseAccessTokenData(tokenData)
+		if err != nil {
+			ctx.Next()
+			return
+		}
+		if _, rejectReason, err := checkAccessTokenState(ctx, claimsData); err != nil || rejectReason != "" {
+			ctx.Next()
+			return
+		}
 		ctx.Set(CONTEXT_KEY_ACCESS_TOKEN_DATA, tokenData)
 
 		ctx.Next()
@@ -51,14 +103,111 @@ func GetContentAccessTokenData(ctx *gin.Context) (*models.JWTClaimsData, error)
 		return nil, errorUtils.ServerInternalError("AccessToken Data not found in context, type assertion failed")
 	}
 
-	// 解析 Token 中的數據
-	userID, err := uuid.Parse(tokenData["data"].(map[string]any)["UserID"].(string))
+	result, err := parseAccessTokenData(tokenData)
+	if err != nil {
+		return nil, errorUtils.ServerInternalError(err.Error())
+	}
+	return result, nil
+}
+
+// GetOptionalContentAccessTokenData 搭配 OptionalAccessToken 使用，未登入時回傳 nil
+func GetOptionalContentAccessTokenData(ctx *gin.Context) *models.JWTClaimsData {
+	if _, exists := ctx.Get(CONTEXT_KEY_ACCESS_TOKEN_DATA); !exists {
+		return nil
+	}
+	tokenData, err := GetContentAccessTokenData(ctx)
+	if err != nil {
+		return nil
+	}
+	return tokenData
+}
+
+// CheckContentAccessTokenState 重新檢查 VerifyAccessToken 驗證過的 Token 所屬 session 與使用者狀態 (供長時間的連線定期檢查)，
+// 回傳拒絕原因，空字串表示 Token 仍有效
+func CheckContentAccessTokenState(ctx *gin.Context) (string, error) {
+	claimsData, err := GetContentAccessTokenData(ctx)
+	if err != nil {
+		return "", err
+	}
+	_, rejectReason, err := checkAccessTokenState(ctx, claimsData)
+	return rejectReason, err
+}
+
+// 解析 Token 中的數據
+func parseAccessTokenData(tokenData jwt.MapClaims) (*models.JWTClaimsData, error) {
+	errorUtils := pkg.NewErrorUtils()
+
+	data, ok := tokenData["data"].(map[string]any)
+	if !ok {
+		return nil, errorUtils.ServerInternalError("failed to parse data from token")
+	}
+	rawUserID, _ := data["UserID"].(string)
+	userID, err := uuid.Parse(rawUserID)
 	if err != nil {
 		return nil, errorUtils.ServerInternalError("failed to parse user ID from token")
 	}
+	rawSessionID, _ := data["SessionID"].(string)
+	sessionID, err := uuid.Parse(rawSessionID)
+	if err != nil {
+		return nil, errorUtils.ServerInternalError("failed to parse session ID from token")
+	}
+	// JSON 數字解析後為 float64
+	role, ok := data["Role"].(float64)
+	if !ok {
+		return nil, errorUtils.ServerInternalError("failed to parse role from token")
+	}
+	tokenVersion, ok := data["TokenVersion"].(float64)
+	if !ok {
+		return nil, errorUtils.ServerInternalError("failed to parse token version from token")
+	}
 
-	result := &models.JWTClaimsData{
-		UserID: userID,
+	return &models.JWTClaimsData{
+		UserID:       userID,
+		SessionID:    sessionID,
+		Role:         models.Role(role),
+		TokenVersion: int64(tokenVersion),
+	}, nil
+}
+
+// checkAccessTokenState 回傳拒絕的狀態碼與原因，空字串表示 Token 仍有效
+func checkAccessTokenState(ctx *gin.Context, claimsData *models.JWTClaimsData) (int, string, error) {
+	db, err := GetContentGORMDB(ctx)
+	if err != nil {
+		return 0, "", err
 	}
-	return result, nil
+
+	// session 仍有未撤銷的 refresh token 即視為有效
+	count := int64(0)
+	if err := db.Model(&models.RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", claimsData.SessionID).
+		Count(&count).Error; err != nil {
+		return 0, "", err
+	}
+	if count == 0 {
+		return 401, "session has been revoked", nil
+	}
+
+	// 角色或權限變更後，舊版本的 Token 失效；停權帳號一律拒絕
+	users := []models.User{}
+	if err := db.Model(&models.User{}).
+		Select("id", "token_version", "suspended_at", "password_reset_required").
+		Where("id = ?", claimsData.UserID).
+		Limit(1).
+		Find(&users).Error; err != nil {
+		return 0, "", err
+	}
+	if len(users) == 0 {
+		return 401, "user not found", nil
+	}
+	if users[0].SuspendedAt != nil {
+		return 401, "account suspended", nil
+	}
+	if users[0].TokenVersion != claimsData.TokenVersion {
+		return 401, "token has been invalidated", nil
+	}
+	// 管理員強制重設密碼後，變更密碼前只能使用 AllowPasswordResetRequired 的路由
+	if users[0].PasswordResetRequired && !ctx.GetBool(CONTEXT_KEY_ALLOW_PASSWORD_RESET_REQUIRED) {
+		return 403, "password reset required", nil
+	}
+	return 0, "", nil
 }
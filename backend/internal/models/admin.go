@@ -0,0 +1,31 @@
+package models
+
+import "github.com/google/uuid"
+
+// Admin GetUsers structs
+type AdminGetUsersResponseItem struct {
+	ID                    uuid.UUID `json:"id"`
+	Username              string    `json:"username"`
+	Email                 string    `json:"email"`
+	Role                  string    `json:"role"`
+	SuspendedAt           *string   `json:"suspendedAt"`
+	SuspendedReason       *string   `json:"suspendedReason"`
+	PasswordResetRequired bool      `json:"passwordResetRequired"`
+	CreatedAt             string    `json:"createdAt"`
+	UpdatedAt             string    `json:"updatedAt"`
+}
+
+// Admin UpdateUserRole structs
+type AdminUpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// Admin SuspendUser structs
+type AdminSuspendUserRequest struct {
+	Reason *string `json:"reason"`
+}
+
+// Admin ResetUserPassword structs
+type AdminResetUserPasswordResponse struct {
+	TemporaryPassword string `json:"temporaryPassword"`
+}
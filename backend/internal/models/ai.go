@@ -4,7 +4,10 @@ type AIModelConfigs struct {
 	ChatModel AIModelConfig
 }
 
+// AIModelConfig Provider 為空字串時停用 AI
 type AIModelConfig struct {
+	// Provider openai, ollama, fake
+	Provider  string
 	APIKey    string
 	BaseURL   string
 	ModelName string
@@ -40,3 +43,24 @@ type AIGenerateTextCreatePostContentRequest struct {
 type AIGenerateTextCreatePostContentResponse struct {
 	Content string `json:"content"`
 }
+
+// AI_MODERATION_CATEGORIES AI 審核分類的類別，與檢舉原因代碼相同
+var AI_MODERATION_CATEGORIES = []ReportReason{
+	ReportReasonSpam,
+	ReportReasonHarassment,
+	ReportReasonHateSpeech,
+	ReportReasonViolence,
+	ReportReasonSexualContent,
+	ReportReasonMisinformation,
+}
+
+// AIModerationVerdict 模型回傳的審核結果
+type AIModerationVerdict struct {
+	Categories []AIModerationCategory `json:"categories"`
+}
+
+// AIModerationCategory Confidence 為 0 到 1 之間內容屬於此類別的信心程度
+type AIModerationCategory struct {
+	Category   ReportReason `json:"category"`
+	Confidence float64      `json:"confidence"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "github.com/google/uuid"
+
+// Block BlockerID 封鎖了 BlockedID，雙方互相看不到對方的貼文與評論，也無法評論、提及、喜歡或私訊對方
+type Block struct {
+	TableModel
+	BlockBase
+}
+
+type BlockBase struct {
+	BlockerID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_blocks_blocker_blocked"`
+	Blocker   *User     `gorm:"foreignKey:BlockerID"`
+	BlockedID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_blocks_blocker_blocked;index"`
+	Blocked   *User     `gorm:"foreignKey:BlockedID"`
+}
+
+// Block structs
+type BlockResponse struct {
+	UserID  uuid.UUID `json:"userID"`
+	Blocked bool      `json:"blocked"`
+}
+
+// Block GetBlockedUsers structs
+type BlockGetUsersResponseItem struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
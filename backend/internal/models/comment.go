@@ -14,6 +14,32 @@ type CommentBase struct {
 	User     *User      `gorm:"foreignKey:UserID"`
 	Content  string     `gorm:"type:text;not null"`
 	ParentID *uuid.UUID `gorm:"type:uuid"`
+	EditedAt *int64
+	// Mentions 依 StartOffset 排序的 @提及，墓碑評論沒有提及
+	Mentions []Mention `gorm:"foreignKey:CommentID"`
+	// DeletedAt 不為 nil 時為已刪除的墓碑評論 (保留以維持回覆串結構)
+	DeletedAt *int64
+	// HiddenAt 不為 nil 時為被管理員隱藏或等待審核的評論，連同其回覆不會出現在任何查詢中
+	HiddenAt *int64
+}
+
+// COMMENT_DELETED_CONTENT 墓碑評論顯示的內容
+const COMMENT_DELETED_CONTENT = "[deleted]"
+
+func (c *Comment) IsDeleted() bool {
+	return c.DeletedAt != nil
+}
+
+// CommentEditHistory 評論每次編輯或刪除前的內容
+type CommentEditHistory struct {
+	TableModel
+	CommentEditHistoryBase
+}
+
+type CommentEditHistoryBase struct {
+	CommentID uuid.UUID `gorm:"type:uuid;not null;index"`
+	EditorID  uuid.UUID `gorm:"type:uuid;not null"`
+	Content   string    `gorm:"type:text;not null"`
 }
 
 // Create Comment structs
@@ -24,11 +50,54 @@ type CommentCreateRequest struct {
 }
 
 type CommentCreateResponse struct {
-	ID       uuid.UUID  `json:"id"`
-	PostID   uuid.UUID  `json:"postID"`
-	Content  string     `json:"content"`
-	ParentID *uuid.UUID `json:"parentID"`
-	UserID   uuid.UUID  `json:"userID"`
+	ID       uuid.UUID       `json:"id"`
+	PostID   uuid.UUID       `json:"postID"`
+	Content  string          `json:"content"`
+	Mentions []MentionEntity `json:"mentions"`
+	ParentID *uuid.UUID      `json:"parentID"`
+	UserID   uuid.UUID       `json:"userID"`
+	// PendingReview 違反內容政策，審核通過前不會公開
+	PendingReview bool `json:"pendingReview"`
+}
+
+// Update Comment structs
+type CommentUpdateRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+type CommentUpdateResponse struct {
+	ID       uuid.UUID       `json:"id"`
+	PostID   uuid.UUID       `json:"postID"`
+	Content  string          `json:"content"`
+	Mentions []MentionEntity `json:"mentions"`
+	ParentID *uuid.UUID      `json:"parentID"`
+	UserID   uuid.UUID       `json:"userID"`
+	EditedAt string          `json:"editedAt"`
+
+	// PendingReview 違反內容政策，審核通過前不會公開
+	PendingReview bool `json:"pendingReview"`
+}
+
+// Delete Comment structs
+type CommentDeleteResponse struct {
+	// Tombstoned 評論有回覆時僅標記為已刪除，保留回覆串結構
+	Tombstoned bool `json:"tombstoned"`
+}
+
+// Get Comment Edit Histories structs
+type CommentGetEditHistoriesResponseItem struct {
+	ID        uuid.UUID `json:"id"`
+	EditorID  uuid.UUID `json:"editorID"`
+	Content   string    `json:"content"`
+	CreatedAt string    `json:"createdAt"`
+}
+
+// CommentTreeNode 評論樹節點，Depth 從 0 (根評論) 開始，Path 為根評論到自己的評論 ID
+type CommentTreeNode struct {
+	Comment  Comment
+	Depth    uint
+	Path     []uuid.UUID
+	Children []*CommentTreeNode
 }
 
 // Get Comments By PostID
@@ -36,10 +105,30 @@ type CommentGetListByPostIDResponseItem struct {
 	ID          uuid.UUID                            `json:"id"`
 	PostID      uuid.UUID                            `json:"postID"`
 	Content     string                               `json:"content"`
+	Mentions    []MentionEntity                      `json:"mentions"`
 	ParentID    *uuid.UUID                           `json:"parentID"`
 	UserID      uuid.UUID                            `json:"userID"`
 	UserName    string                               `json:"userName"`
 	CreatedAt   string                               `json:"createdAt"`
 	UpdatedAt   string                               `json:"updatedAt"`
+	EditedAt    *string                              `json:"editedAt"`
+	Deleted     bool                                 `json:"deleted"`
 	SubComments []CommentGetListByPostIDResponseItem `json:"subComments"`
 }
+
+// Get Comments By PostID (flat mode)
+type CommentGetFlatListByPostIDResponseItem struct {
+	ID        uuid.UUID       `json:"id"`
+	PostID    uuid.UUID       `json:"postID"`
+	Content   string          `json:"content"`
+	Mentions  []MentionEntity `json:"mentions"`
+	ParentID  *uuid.UUID      `json:"parentID"`
+	UserID    uuid.UUID       `json:"userID"`
+	UserName  string          `json:"userName"`
+	CreatedAt string          `json:"createdAt"`
+	UpdatedAt string          `json:"updatedAt"`
+	EditedAt  *string         `json:"editedAt"`
+	Deleted   bool            `json:"deleted"`
+	Depth     uint            `json:"depth"`
+	Path      []uuid.UUID     `json:"path"`
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// CONVERSATION_MAX_PARTICIPANTS 群組對話最多的參與者數 (包含建立者)
+const CONVERSATION_MAX_PARTICIPANTS = 10
+
+// MESSAGE_MAX_LENGTH 私訊內容的最大字元數
+const MESSAGE_MAX_LENGTH = 2000
+
+// Conversation 私訊對話，IsGroup 為 false 時為兩人之間的一對一對話
+type Conversation struct {
+	TableModel
+	ConversationBase
+}
+
+type ConversationBase struct {
+	IsGroup bool   `gorm:"not null;default:false"`
+	Title   string `gorm:"not null;default:''"`
+	// DirectKey 一對一對話的參與者組合 (見 ConversationDirectKey)，確保兩人之間只有一個一對一對話，群組對話為 nil
+	DirectKey *string `gorm:"uniqueIndex"`
+	// LastMessageAt 最後一則訊息的時間，沒有訊息時為建立時間，對話列表依此由新到舊排序
+	LastMessageAt int64                     `gorm:"not null;index"`
+	Participants  []ConversationParticipant `gorm:"foreignKey:ConversationID"`
+}
+
+// ConversationDirectKey 兩位使用者的 ID 排序後以 ":" 串接，與參數順序無關
+func ConversationDirectKey(userID uuid.UUID, otherUserID uuid.UUID) string {
+	userIDs := []string{userID.String(), otherUserID.String()}
+	if userIDs[0] > userIDs[1] {
+		userIDs[0], userIDs[1] = userIDs[1], userIDs[0]
+	}
+	return strings.Join(userIDs, ":")
+}
+
+// ConversationParticipant 對話的參與者與其已讀位置
+type ConversationParticipant struct {
+	TableModel
+	ConversationParticipantBase
+}
+
+type ConversationParticipantBase struct {
+	ConversationID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_conversation_participants_conversation_user"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_conversation_participants_conversation_user;index"`
+	User           *User     `gorm:"foreignKey:UserID"`
+	// LastReadMessageID 為已讀的最後一則訊息，nil 表示尚未讀過任何訊息，LastReadAt 為該訊息的建立時間
+	LastReadMessageID *uuid.UUID `gorm:"type:uuid"`
+	LastReadAt        int64      `gorm:"not null;default:0"`
+}
+
+// Message 對話中的訊息
+type Message struct {
+	TableModel
+	MessageBase
+}
+
+type MessageBase struct {
+	ConversationID uuid.UUID `gorm:"type:uuid;not null;index"`
+	SenderID       uuid.UUID `gorm:"type:uuid;not null;index"`
+	Sender         *User     `gorm:"foreignKey:SenderID"`
+	Content        string    `gorm:"type:text;not null"`
+}
+
+// ConversationSummary 對話列表的項目，LastMessage 在沒有訊息時為 nil
+type ConversationSummary struct {
+	Conversation Conversation
+	LastMessage  *Message
+	UnreadCount  uint
+}
+
+// Create Conversation structs
+type ConversationCreateRequest struct {
+	// ParticipantIDs 不包含自己，只有一位時為一對一對話 (已存在時回傳既有的對話)
+	ParticipantIDs []uuid.UUID `json:"participantIDs" binding:"required"`
+	Title          string      `json:"title"`
+}
+
+type ConversationParticipantResponseItem struct {
+	UserID            uuid.UUID  `json:"userID"`
+	Username          string     `json:"username"`
+	LastReadMessageID *uuid.UUID `json:"lastReadMessageID"`
+}
+
+type ConversationMessageResponseItem struct {
+	ID             uuid.UUID `json:"id"`
+	ConversationID uuid.UUID `json:"conversationID"`
+	SenderID       uuid.UUID `json:"senderID"`
+	Content        string    `json:"content"`
+	CreatedAt      string    `json:"createdAt"`
+}
+
+// Get Conversations / Get Conversation / Create Conversation structs
+type ConversationResponse struct {
+	ID            uuid.UUID                             `json:"id"`
+	IsGroup       bool                                  `json:"isGroup"`
+	Title         string                                `json:"title"`
+	Participants  []ConversationParticipantResponseItem `json:"participants"`
+	LastMessage   *ConversationMessageResponseItem      `json:"lastMessage"`
+	UnreadCount   uint                                  `json:"unreadCount"`
+	LastMessageAt string                                `json:"lastMessageAt"`
+	CreatedAt     string                                `json:"createdAt"`
+}
+
+// Send Message structs
+type ConversationSendMessageRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// Mark Conversation Read structs
+type ConversationMarkReadResponse struct {
+	ConversationID    uuid.UUID  `json:"conversationID"`
+	LastReadMessageID *uuid.UUID `json:"lastReadMessageID"`
+}
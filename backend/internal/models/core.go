@@ -1,6 +1,13 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
 
 type TableModel struct {
 	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
@@ -8,8 +15,16 @@ type TableModel struct {
 	UpdatedAt int64     `gorm:"autoUpdateTime" json:"updatedAt"`
 }
 
+// GetCursor 回傳此筆資料在 (created_at, id) keyset 分頁中的位置
+func (m TableModel) GetCursor() Cursor {
+	return Cursor{CreatedAt: m.CreatedAt, ID: m.ID}
+}
+
 type JWTClaimsData struct {
-	UserID uuid.UUID
+	UserID       uuid.UUID
+	SessionID    uuid.UUID
+	Role         Role
+	TokenVersion int64
 }
 
 type Pagination struct {
@@ -23,6 +38,52 @@ type PaginationResponse[T any] struct {
 	Pagination *Pagination `json:"pagination"`
 }
 
+// Cursor keyset 分頁的位置，列表依 (created_at, id) 排序，下一頁從此位置之後開始
+type Cursor struct {
+	CreatedAt int64
+	ID        uuid.UUID
+}
+
+// String 將分頁位置編碼為不透明字串
+func (c Cursor) String() string {
+	raw := fmt.Sprintf("%d:%s", c.CreatedAt, c.ID.String())
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func ParseCursor(encoded string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	createdAt, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid cursor format")
+	}
+	cursor := &Cursor{}
+	if cursor.CreatedAt, err = strconv.ParseInt(createdAt, 10, 64); err != nil {
+		return nil, err
+	}
+	if cursor.ID, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+// CursorPagination Cursor 為 nil 時為第一頁，WithTotalCount 為 false 時不計算總筆數 (省略 COUNT 查詢)
+type CursorPagination struct {
+	Cursor         *Cursor
+	Limit          uint
+	WithTotalCount bool
+}
+
+type CursorPaginationResponse[T any] struct {
+	Data []T `json:"data"`
+	// NextCursor 為 nil 表示沒有下一頁
+	NextCursor *string `json:"nextCursor"`
+	// TotalCount 只在 withTotalCount=true 時回傳
+	TotalCount *uint `json:"totalCount,omitempty"`
+}
+
 type MessageResponse struct {
 	Message string `json:"message"`
 }
@@ -0,0 +1,63 @@
+package models
+
+import "github.com/google/uuid"
+
+// EventType 即時事件的類型
+type EventType string
+
+const (
+	// EventTypeNotification 我收到新的通知
+	EventTypeNotification EventType = "notification"
+	// EventTypeComment 訂閱的貼文有新的評論
+	EventTypeComment EventType = "comment"
+	// EventTypeLikeCount 訂閱的貼文喜歡數改變
+	EventTypeLikeCount EventType = "likeCount"
+	// EventTypeMessage 我參與的對話有新的訊息
+	EventTypeMessage EventType = "message"
+	// EventTypeMessageRead 我參與的對話中有人讀了訊息
+	EventTypeMessageRead EventType = "messageRead"
+)
+
+// Event 即時事件，以 JSON 傳送，Data 依 Type 為 EventNotificationData、EventCommentData、EventLikeCountData、
+// EventMessageData 或 EventMessageReadData
+type Event struct {
+	Type EventType `json:"type"`
+	Data any       `json:"data"`
+}
+
+type EventNotificationData struct {
+	ID        uuid.UUID        `json:"id"`
+	Type      NotificationType `json:"type"`
+	ActorID   uuid.UUID        `json:"actorID"`
+	PostID    *uuid.UUID       `json:"postID"`
+	CommentID *uuid.UUID       `json:"commentID"`
+	CreatedAt string           `json:"createdAt"`
+}
+
+type EventCommentData struct {
+	ID        uuid.UUID  `json:"id"`
+	PostID    uuid.UUID  `json:"postID"`
+	ParentID  *uuid.UUID `json:"parentID"`
+	UserID    uuid.UUID  `json:"userID"`
+	Content   string     `json:"content"`
+	CreatedAt string     `json:"createdAt"`
+}
+
+type EventLikeCountData struct {
+	PostID    uuid.UUID `json:"postID"`
+	LikeCount uint      `json:"likeCount"`
+}
+
+type EventMessageData struct {
+	ID             uuid.UUID `json:"id"`
+	ConversationID uuid.UUID `json:"conversationID"`
+	SenderID       uuid.UUID `json:"senderID"`
+	Content        string    `json:"content"`
+	CreatedAt      string    `json:"createdAt"`
+}
+
+type EventMessageReadData struct {
+	ConversationID    uuid.UUID `json:"conversationID"`
+	UserID            uuid.UUID `json:"userID"`
+	LastReadMessageID uuid.UUID `json:"lastReadMessageID"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "github.com/google/uuid"
+
+type Follow struct {
+	TableModel
+	FollowBase
+}
+
+type FollowBase struct {
+	FollowerID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_follows_follower_followee"`
+	Follower   *User     `gorm:"foreignKey:FollowerID"`
+	FolloweeID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_follows_follower_followee;index"`
+	Followee   *User     `gorm:"foreignKey:FolloweeID"`
+}
+
+// Follow structs
+type FollowResponse struct {
+	UserID    uuid.UUID `json:"userID"`
+	Following bool      `json:"following"`
+}
+
+// Follow GetFollowers / GetFollowing structs
+type FollowGetUsersResponseItem struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
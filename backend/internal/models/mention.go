@@ -0,0 +1,30 @@
+package models
+
+import "github.com/google/uuid"
+
+// MENTION_MAX_PER_CONTENT 每則貼文或評論最多解析的提及數，超過的提及視為一般文字
+const MENTION_MAX_PER_CONTENT = 20
+
+// Mention 貼文或評論中的 @提及，PostID 與 CommentID 只會有一個不為 nil
+type Mention struct {
+	TableModel
+	MentionBase
+}
+
+type MentionBase struct {
+	PostID    *uuid.UUID `gorm:"type:uuid;index"`
+	CommentID *uuid.UUID `gorm:"type:uuid;index"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	User      *User      `gorm:"foreignKey:UserID"`
+	// StartOffset 與 EndOffset 為內容中包含 @ 的字元 (rune) 位置，EndOffset 不包含
+	StartOffset int `gorm:"not null"`
+	EndOffset   int `gorm:"not null"`
+}
+
+// MentionEntity 回應中的提及，start 與 end 為內容中包含 @ 的字元 (Unicode code point) 位置，end 不包含
+type MentionEntity struct {
+	UserID   uuid.UUID `json:"userID"`
+	Username string    `json:"username"`
+	Start    int       `json:"start"`
+	End      int       `json:"end"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "github.com/google/uuid"
+
+// Mute MuterID 靜音了 MutedID，只在 MuterID 的動態中隱藏 MutedID 的貼文，對方不會察覺
+type Mute struct {
+	TableModel
+	MuteBase
+}
+
+type MuteBase struct {
+	MuterID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_mutes_muter_muted"`
+	Muter   *User     `gorm:"foreignKey:MuterID"`
+	MutedID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_mutes_muter_muted;index"`
+	Muted   *User     `gorm:"foreignKey:MutedID"`
+}
+
+// Mute structs
+type MuteResponse struct {
+	UserID uuid.UUID `json:"userID"`
+	Muted  bool      `json:"muted"`
+}
+
+// Mute GetMutedUsers structs
+type MuteGetUsersResponseItem struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// NotificationType 通知的事件類型
+type NotificationType string
+
+const (
+	// NotificationTypeComment 有人評論了我的貼文
+	NotificationTypeComment NotificationType = "comment"
+	// NotificationTypeReply 有人回覆了我的評論
+	NotificationTypeReply NotificationType = "reply"
+	// NotificationTypeLike 有人喜歡了我的貼文
+	NotificationTypeLike NotificationType = "like"
+	// NotificationTypeFollow 有人追蹤了我
+	NotificationTypeFollow NotificationType = "follow"
+	// NotificationTypeMention 有人在貼文或評論中提及我
+	NotificationTypeMention NotificationType = "mention"
+)
+
+// Notification 發送給 UserID 的通知，ActorID 為觸發事件的使用者
+type Notification struct {
+	TableModel
+	NotificationBase
+}
+
+type NotificationBase struct {
+	UserID  uuid.UUID        `gorm:"type:uuid;not null;index"`
+	ActorID uuid.UUID        `gorm:"type:uuid;not null"`
+	Actor   *User            `gorm:"foreignKey:ActorID"`
+	Type    NotificationType `gorm:"not null"`
+	// PostID 為事件所屬的貼文，CommentID 為觸發事件的評論 (評論、回覆與評論中的提及)
+	PostID    *uuid.UUID `gorm:"type:uuid;index"`
+	CommentID *uuid.UUID `gorm:"type:uuid;index"`
+	// GroupKey 相同的通知在列表中合併為一則，為空時由 NotificationRepository.Create 設為通知 ID (不合併)，
+	// 否則由 NotificationRepository.Create 加上群組的世代 (群組已全部讀取後的通知開啟新的群組)
+	GroupKey string `gorm:"not null;default:'';index"`
+	// ReadAt 為 nil 時為未讀
+	ReadAt *int64
+}
+
+// NotificationGroup 列表中合併後的通知，Latest 為群組中最新的通知
+type NotificationGroup struct {
+	Latest Notification
+	// ActorCount 群組中不重複的使用者數
+	ActorCount uint
+	// Read 群組中所有通知皆已讀
+	Read bool
+}
+
+// Message 通知的文字，例如 "alice and 5 others liked your post"
+func (g *NotificationGroup) Message() string {
+	actor := "someone"
+	if g.Latest.Actor != nil {
+		actor = g.Latest.Actor.Username
+	}
+	switch others := g.ActorCount - 1; others {
+	case 0:
+	case 1:
+		actor += " and 1 other"
+	default:
+		actor += fmt.Sprintf(" and %d others", others)
+	}
+
+	switch g.Latest.Type {
+	case NotificationTypeComment:
+		return actor + " commented on your post"
+	case NotificationTypeReply:
+		return actor + " replied to your comment"
+	case NotificationTypeLike:
+		return actor + " liked your post"
+	case NotificationTypeFollow:
+		return actor + " followed you"
+	case NotificationTypeMention:
+		if g.Latest.CommentID != nil {
+			return actor + " mentioned you in a comment"
+		}
+		return actor + " mentioned you in a post"
+	}
+	return actor + " sent you a notification"
+}
+
+// Notification GetList structs
+type NotificationGetListResponseItem struct {
+	// ID 群組中最新的通知，標記已讀時會一併標記整個群組
+	ID        uuid.UUID                            `json:"id"`
+	Type      NotificationType                     `json:"type"`
+	Actor     NotificationGetListResponseItemActor `json:"actor"`
+	PostID    *uuid.UUID                           `json:"postID"`
+	CommentID *uuid.UUID                           `json:"commentID"`
+	// ActorCount 合併的通知中不重複的使用者數 (包含 actor)
+	ActorCount uint   `json:"actorCount"`
+	Message    string `json:"message"`
+	Read       bool   `json:"read"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type NotificationGetListResponseItemActor struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+// Notification GetUnreadCount structs
+type NotificationGetUnreadCountResponse struct {
+	Count uint `json:"count"`
+}
+
+// Notification MarkRead / MarkAllRead structs
+type NotificationMarkReadResponse struct {
+	// Count 標記為已讀的通知數
+	Count uint `json:"count"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import "github.com/google/uuid"
+
+type Permission string
+
+const (
+	PermissionPostEdit      Permission = "post:edit"
+	PermissionPostDelete    Permission = "post:delete"
+	PermissionCommentEdit   Permission = "comment:edit"
+	PermissionCommentDelete Permission = "comment:delete"
+	PermissionTagEdit       Permission = "tag:edit"
+	PermissionTagDelete     Permission = "tag:delete"
+	PermissionUserManage    Permission = "user:manage"
+)
+
+// PermissionScope 權限作用範圍
+type PermissionScope int64
+
+const (
+	// PermissionScopeNone 無權限
+	PermissionScopeNone PermissionScope = iota
+	// PermissionScopeOwn 僅限自己擁有的資源
+	PermissionScopeOwn
+	// PermissionScopeAny 任何資源
+	PermissionScopeAny
+)
+
+// ROLE_PERMISSIONS 角色權限表，未列出的權限視為 PermissionScopeNone
+var ROLE_PERMISSIONS = map[Role]map[Permission]PermissionScope{
+	RoleAdmin: {
+		PermissionPostEdit:      PermissionScopeAny,
+		PermissionPostDelete:    PermissionScopeAny,
+		PermissionCommentEdit:   PermissionScopeAny,
+		PermissionCommentDelete: PermissionScopeAny,
+		PermissionTagEdit:       PermissionScopeAny,
+		PermissionTagDelete:     PermissionScopeAny,
+		PermissionUserManage:    PermissionScopeAny,
+	},
+	RoleNormalCustomer: {
+		PermissionPostEdit:      PermissionScopeOwn,
+		PermissionPostDelete:    PermissionScopeOwn,
+		PermissionCommentEdit:   PermissionScopeOwn,
+		PermissionCommentDelete: PermissionScopeOwn,
+	},
+}
+
+func (r Role) PermissionScope(permission Permission) PermissionScope {
+	return ROLE_PERMISSIONS[r][permission]
+}
+
+// Can 判斷角色是否可對資源執行操作，ownerIDs 為資源擁有者 (任一符合即視為自己的資源)
+func (r Role) Can(permission Permission, actorID uuid.UUID, ownerIDs ...uuid.UUID) bool {
+	switch r.PermissionScope(permission) {
+	case PermissionScopeAny:
+		return true
+	case PermissionScopeOwn:
+		for _, ownerID := range ownerIDs {
+			if ownerID == actorID {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func (c *JWTClaimsData) Can(permission Permission, ownerIDs ...uuid.UUID) bool {
+	return c.Role.Can(permission, c.UserID, ownerIDs...)
+}
@@ -14,6 +14,15 @@ type PostBase struct {
 	Content  string  `gorm:"not null"`
 	Tags     []*Tag  `gorm:"many2many:post_to_tag;"`
 	Likes    []*User `gorm:"many2many:post_to_user;"`
+	// Mentions 依 StartOffset 排序的 @提及
+	Mentions []Mention `gorm:"foreignKey:PostID"`
+	// 反正規化計數，喜歡與評論異動時於同一交易中更新
+	LikeCount    uint `gorm:"not null;default:0"`
+	CommentCount uint `gorm:"not null;default:0"`
+	// 全文搜尋的索引詞 (由 SearchUtils 斷詞後以空白串接)，內容異動時由 PostRepository 更新
+	SearchText string `gorm:"not null;default:''"`
+	// HiddenAt 不為 nil 時為被管理員隱藏或等待審核的貼文，不會出現在任何查詢中
+	HiddenAt *int64
 }
 
 // Post Create structs
@@ -24,13 +33,16 @@ type PostCreateRequest struct {
 }
 
 type PostCreateResponse struct {
-	ID        uuid.UUID   `json:"id"`
-	AuthorID  uuid.UUID   `json:"authorID"`
-	ImageURL  *string     `json:"imageURL"`
-	Content   string      `json:"content"`
-	TagIDs    []uuid.UUID `json:"tagIDs"`
-	CreatedAt string      `json:"createdAt"`
-	UpdatedAt string      `json:"updatedAt"`
+	ID        uuid.UUID       `json:"id"`
+	AuthorID  uuid.UUID       `json:"authorID"`
+	ImageURL  *string         `json:"imageURL"`
+	Content   string          `json:"content"`
+	Mentions  []MentionEntity `json:"mentions"`
+	TagIDs    []uuid.UUID     `json:"tagIDs"`
+	CreatedAt string          `json:"createdAt"`
+	UpdatedAt string          `json:"updatedAt"`
+	// PendingReview 違反內容政策，審核通過前不會公開
+	PendingReview bool `json:"pendingReview"`
 }
 
 type PostCreateResponseTag struct {
@@ -38,16 +50,39 @@ type PostCreateResponseTag struct {
 	Name string    `json:"name"`
 }
 
+// Post Update structs
+type PostUpdateRequest struct {
+	ImageURL *string `json:"imageURL"`
+	Content  string  `json:"content" binding:"required"`
+}
+
+type PostUpdateResponse struct {
+	ID        uuid.UUID       `json:"id"`
+	AuthorID  uuid.UUID       `json:"authorID"`
+	ImageURL  *string         `json:"imageURL"`
+	Content   string          `json:"content"`
+	Mentions  []MentionEntity `json:"mentions"`
+	TagIDs    []uuid.UUID     `json:"tagIDs"`
+	CreatedAt string          `json:"createdAt"`
+	UpdatedAt string          `json:"updatedAt"`
+
+	// PendingReview 違反內容政策，審核通過前不會公開
+	PendingReview bool `json:"pendingReview"`
+}
+
 // Post GetPostsByAuthorID structs
 type PostGetPostsByAuthorIDResponseItem struct {
-	ID         uuid.UUID                                `json:"id"`
-	Author     PostGetPostsByAuthorIDResponseItemAuthor `json:"author"`
-	ImageURL   *string                                  `json:"imageURL"`
-	Content    string                                   `json:"content"`
-	CreatedAt  string                                   `json:"createdAt"`
-	UpdatedAt  string                                   `json:"updatedAt"`
-	Tags       []PostGetPostsByAuthorIDResponseItemTag  `json:"tags"`
-	LikedCount uint                                     `json:"likedCount"`
+	ID           uuid.UUID                                `json:"id"`
+	Author       PostGetPostsByAuthorIDResponseItemAuthor `json:"author"`
+	ImageURL     *string                                  `json:"imageURL"`
+	Content      string                                   `json:"content"`
+	Mentions     []MentionEntity                          `json:"mentions"`
+	CreatedAt    string                                   `json:"createdAt"`
+	UpdatedAt    string                                   `json:"updatedAt"`
+	Tags         []PostGetPostsByAuthorIDResponseItemTag  `json:"tags"`
+	LikedCount   uint                                     `json:"likedCount"`
+	CommentCount uint                                     `json:"commentCount"`
+	LikedByMe    bool                                     `json:"likedByMe"`
 }
 
 type PostGetPostsByAuthorIDResponseItemAuthor struct {
@@ -60,16 +95,48 @@ type PostGetPostsByAuthorIDResponseItemTag struct {
 	Name string    `json:"name"`
 }
 
-// Post GetHotPosts structs
+// Post GetPostsByTag structs
+type PostGetPostsByTagResponseItem struct {
+	ID           uuid.UUID                           `json:"id"`
+	Author       PostGetPostsByTagResponseItemAuthor `json:"author"`
+	ImageURL     *string                             `json:"imageURL"`
+	Content      string                              `json:"content"`
+	Mentions     []MentionEntity                     `json:"mentions"`
+	CreatedAt    string                              `json:"createdAt"`
+	UpdatedAt    string                              `json:"updatedAt"`
+	Tags         []PostGetPostsByTagResponseItemTag  `json:"tags"`
+	LikedCount   uint                                `json:"likedCount"`
+	CommentCount uint                                `json:"commentCount"`
+	LikedByMe    bool                                `json:"likedByMe"`
+}
+
+type PostGetPostsByTagResponseItemAuthor struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+type PostGetPostsByTagResponseItemTag struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// Post GetPostsByKeyword structs
 type PostGetPostsByKeywordResponseItem struct {
-	ID         uuid.UUID                               `json:"id"`
-	Author     PostGetPostsByKeywordResponseItemAuthor `json:"author"`
-	ImageURL   *string                                 `json:"imageURL"`
-	Content    string                                  `json:"content"`
-	CreatedAt  string                                  `json:"createdAt"`
-	UpdatedAt  string                                  `json:"updatedAt"`
-	Tags       []PostGetPostsByKeywordResponseItemTag  `json:"tags"`
-	LikedCount uint                                    `json:"likedCount"`
+	ID           uuid.UUID                               `json:"id"`
+	Author       PostGetPostsByKeywordResponseItemAuthor `json:"author"`
+	ImageURL     *string                                 `json:"imageURL"`
+	Content      string                                  `json:"content"`
+	Mentions     []MentionEntity                         `json:"mentions"`
+	CreatedAt    string                                  `json:"createdAt"`
+	UpdatedAt    string                                  `json:"updatedAt"`
+	Tags         []PostGetPostsByKeywordResponseItemTag  `json:"tags"`
+	LikedCount   uint                                    `json:"likedCount"`
+	CommentCount uint                                    `json:"commentCount"`
+	LikedByMe    bool                                    `json:"likedByMe"`
+	// Rank 搜尋相關度，越大越相關
+	Rank float64 `json:"rank"`
+	// Snippet 內容摘要 (已 HTML 跳脫)，符合的片段以 <mark></mark> 標示
+	Snippet string `json:"snippet"`
 }
 
 type PostGetPostsByKeywordResponseItemAuthor struct {
@@ -81,3 +148,67 @@ type PostGetPostsByKeywordResponseItemTag struct {
 	ID   uuid.UUID `json:"id"`
 	Name string    `json:"name"`
 }
+
+// Post GetPostByID structs
+type PostGetPostByIDResponse struct {
+	ID           uuid.UUID                                                     `json:"id"`
+	Author       PostGetPostByIDResponseAuthor                                 `json:"author"`
+	ImageURL     *string                                                       `json:"imageURL"`
+	Content      string                                                        `json:"content"`
+	Mentions     []MentionEntity                                               `json:"mentions"`
+	CreatedAt    string                                                        `json:"createdAt"`
+	UpdatedAt    string                                                        `json:"updatedAt"`
+	Tags         []PostGetPostByIDResponseTag                                  `json:"tags"`
+	LikedCount   uint                                                          `json:"likedCount"`
+	CommentCount uint                                                          `json:"commentCount"`
+	LikedByMe    bool                                                          `json:"likedByMe"`
+	Comments     *CursorPaginationResponse[CommentGetListByPostIDResponseItem] `json:"comments,omitempty"`
+}
+
+type PostGetPostByIDResponseAuthor struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+type PostGetPostByIDResponseTag struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// Post GetTimeline structs
+type PostGetTimelineResponseItem struct {
+	ID           uuid.UUID                         `json:"id"`
+	Author       PostGetTimelineResponseItemAuthor `json:"author"`
+	ImageURL     *string                           `json:"imageURL"`
+	Content      string                            `json:"content"`
+	Mentions     []MentionEntity                   `json:"mentions"`
+	CreatedAt    string                            `json:"createdAt"`
+	UpdatedAt    string                            `json:"updatedAt"`
+	Tags         []PostGetTimelineResponseItemTag  `json:"tags"`
+	LikedCount   uint                              `json:"likedCount"`
+	CommentCount uint                              `json:"commentCount"`
+	LikedByMe    bool                              `json:"likedByMe"`
+}
+
+type PostGetTimelineResponseItemAuthor struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+type PostGetTimelineResponseItemTag struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// Post Like structs
+type PostLikeResponse struct {
+	PostID     uuid.UUID `json:"postID"`
+	Liked      bool      `json:"liked"`
+	LikedCount uint      `json:"likedCount"`
+}
+
+// Post GetLikedUsers structs
+type PostGetLikedUsersResponseItem struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
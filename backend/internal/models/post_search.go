@@ -0,0 +1,232 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// POST_SEARCH_QUERY_MAX_CONDITIONS 搜尋語法最多的條件數，避免組出過大的查詢
+const POST_SEARCH_QUERY_MAX_CONDITIONS = 20
+
+// POST_SEARCH_DATE_LAYOUT from: 與 to: 的日期格式 (UTC)
+const POST_SEARCH_DATE_LAYOUT = "2006-01-02"
+
+// PostSearchSort 搜尋結果的排序方式
+type PostSearchSort string
+
+const (
+	PostSearchSortRelevance PostSearchSort = "relevance"
+	PostSearchSortRecent    PostSearchSort = "recent"
+)
+
+func ParsePostSearchSort(name string) (PostSearchSort, bool) {
+	switch sort := PostSearchSort(name); sort {
+	case PostSearchSortRelevance, PostSearchSortRecent:
+		return sort, true
+	}
+	return "", false
+}
+
+// PostSearchResult Rank 越大越相關，Snippet 為標示符合片段的 HTML 摘要
+type PostSearchResult struct {
+	Post    Post
+	Rank    float64
+	Snippet string
+}
+
+// PostSearchFilter 搜尋語法解析後的條件，所有條件皆需符合，沒有任何條件時不篩選
+type PostSearchFilter struct {
+	// Terms 與 Phrases 以全文搜尋比對，片語中的字詞需依序相鄰出現
+	Terms   []string
+	Phrases []string
+	// ExcludedTerms 排除包含這些字詞或片語的貼文
+	ExcludedTerms []string
+	Tags          []string
+	ExcludedTags  []string
+	// AuthorUsername 由 author: 指定，AuthorID 由 userID 查詢參數指定
+	AuthorUsername *string
+	AuthorID       *uuid.UUID
+	// From 與 To 為 UTC 日期，皆包含當天
+	From     *time.Time
+	To       *time.Time
+	HasImage *bool
+	// ViewerID 為登入的使用者，排除與其有封鎖關係的作者的貼文
+	ViewerID *uuid.UUID
+}
+
+// HighlightTexts 摘要中需要標示的文字
+func (f *PostSearchFilter) HighlightTexts() []string {
+	texts := make([]string, 0, len(f.Terms)+len(f.Phrases)+len(f.Tags))
+	texts = append(texts, f.Terms...)
+	texts = append(texts, f.Phrases...)
+	return append(texts, f.Tags...)
+}
+
+// PostSearchQueryError 搜尋語法錯誤，Position 為從 1 開始的字元位置 (0 表示整個查詢)
+type PostSearchQueryError struct {
+	Position int
+	Message  string
+}
+
+func (e *PostSearchQueryError) Error() string {
+	if e.Position == 0 {
+		return "invalid search query: " + e.Message
+	}
+	return fmt.Sprintf("invalid search query at position %d: %s", e.Position, e.Message)
+}
+
+// ParsePostSearchQuery 解析搜尋語法，條件以空白分隔：
+//
+//	word            全文搜尋字詞
+//	"exact phrase"  字詞需依序相鄰出現
+//	#tag            包含標籤
+//	author:name     作者的使用者名稱
+//	from:2026-01-01 建立日期起日 (含)
+//	to:2026-02-01   建立日期迄日 (含)
+//	has:image       包含圖片
+//
+// 在條件前加上 - 表示排除 (-word、-"phrase"、-#tag、-has:image)
+func ParsePostSearchQuery(query string) (*PostSearchFilter, error) {
+	filter := &PostSearchFilter{}
+	runes := []rune(query)
+	conditions := 0
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		conditions++
+		if conditions > POST_SEARCH_QUERY_MAX_CONDITIONS {
+			return nil, &PostSearchQueryError{
+				Position: i + 1,
+				Message:  fmt.Sprintf("too many conditions (max %d)", POST_SEARCH_QUERY_MAX_CONDITIONS),
+			}
+		}
+
+		start := i
+		excluded := false
+		if runes[i] == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			excluded = true
+			i++
+		}
+
+		if runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, &PostSearchQueryError{Position: i + 1, Message: "unterminated quote"}
+			}
+			if end+1 < len(runes) && !unicode.IsSpace(runes[end+1]) {
+				return nil, &PostSearchQueryError{Position: end + 2, Message: "expected whitespace after closing quote"}
+			}
+			phrase := strings.TrimSpace(string(runes[i+1 : end]))
+			if phrase == "" {
+				return nil, &PostSearchQueryError{Position: i + 1, Message: "empty phrase"}
+			}
+			if excluded {
+				filter.ExcludedTerms = append(filter.ExcludedTerms, phrase)
+			} else {
+				filter.Phrases = append(filter.Phrases, phrase)
+			}
+			i = end + 1
+			continue
+		}
+
+		end := i
+		for end < len(runes) && !unicode.IsSpace(runes[end]) {
+			if runes[end] == '"' {
+				return nil, &PostSearchQueryError{Position: end + 1, Message: "unexpected quote"}
+			}
+			end++
+		}
+		if err := filter.addWord(string(runes[i:end]), excluded, start+1); err != nil {
+			return nil, err
+		}
+		i = end
+	}
+
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return nil, &PostSearchQueryError{Message: "from: must not be after to:"}
+	}
+	return filter, nil
+}
+
+func (f *PostSearchFilter) addWord(word string, excluded bool, position int) error {
+	if tag, ok := strings.CutPrefix(word, "#"); ok {
+		if tag == "" || strings.Contains(tag, "#") {
+			return &PostSearchQueryError{Position: position, Message: fmt.Sprintf("invalid tag %q", word)}
+		}
+		if excluded {
+			f.ExcludedTags = append(f.ExcludedTags, tag)
+		} else {
+			f.Tags = append(f.Tags, tag)
+		}
+		return nil
+	}
+
+	operator, value, ok := strings.Cut(word, ":")
+	operator = strings.ToLower(operator)
+	if !ok || !isPostSearchOperator(operator) {
+		if excluded {
+			f.ExcludedTerms = append(f.ExcludedTerms, word)
+		} else {
+			f.Terms = append(f.Terms, word)
+		}
+		return nil
+	}
+
+	newError := func(format string, args ...any) error {
+		return &PostSearchQueryError{Position: position, Message: fmt.Sprintf(format, args...)}
+	}
+	if value == "" {
+		return newError("%s: requires a value", operator)
+	}
+	if excluded && operator != "has" {
+		return newError("%s: cannot be excluded", operator)
+	}
+	switch operator {
+	case "author":
+		if f.AuthorUsername != nil {
+			return newError("duplicate author:")
+		}
+		f.AuthorUsername = &value
+	case "from", "to":
+		date, err := time.ParseInLocation(POST_SEARCH_DATE_LAYOUT, value, time.UTC)
+		if err != nil {
+			return newError("invalid date %q for %s:, expected YYYY-MM-DD", value, operator)
+		}
+		target := &f.From
+		if operator == "to" {
+			target = &f.To
+		}
+		if *target != nil {
+			return newError("duplicate %s:", operator)
+		}
+		*target = &date
+	case "has":
+		if strings.ToLower(value) != "image" {
+			return newError("unknown has: value %q, expected image", value)
+		}
+		if f.HasImage != nil {
+			return newError("duplicate has:image")
+		}
+		hasImage := !excluded
+		f.HasImage = &hasImage
+	}
+	return nil
+}
+
+// isPostSearchOperator 其他含有冒號的字詞 (例如網址、時間) 視為一般字詞
+func isPostSearchOperator(operator string) bool {
+	switch operator {
+	case "author", "from", "to", "has":
+		return true
+	}
+	return false
+}
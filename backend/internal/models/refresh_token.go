@@ -0,0 +1,34 @@
+package models
+
+import "github.com/google/uuid"
+
+type RefreshToken struct {
+	TableModel
+	RefreshTokenBase
+}
+
+type RefreshTokenBase struct {
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	User      *User     `gorm:"foreignKey:UserID"`
+	SessionID uuid.UUID `gorm:"type:uuid;not null;index"`
+	TokenHash string    `gorm:"uniqueIndex;not null"`
+	ExpiresAt int64     `gorm:"not null"`
+	UsedAt    *int64
+	RevokedAt *int64
+}
+
+type AuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+	SessionID    uuid.UUID
+}
+
+// User TokenRefresh structs
+type UserTokenRefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type UserTokenRefreshResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+}
@@ -0,0 +1,203 @@
+package models
+
+import "github.com/google/uuid"
+
+// REPORT_DETAIL_MAX_LENGTH 檢舉說明的最大字數
+const REPORT_DETAIL_MAX_LENGTH = 500
+
+// ReportTargetType 被檢舉的內容類型
+type ReportTargetType string
+
+const (
+	ReportTargetTypePost    ReportTargetType = "post"
+	ReportTargetTypeComment ReportTargetType = "comment"
+)
+
+func ParseReportTargetType(name string) (ReportTargetType, bool) {
+	switch targetType := ReportTargetType(name); targetType {
+	case ReportTargetTypePost, ReportTargetTypeComment:
+		return targetType, true
+	default:
+		return "", false
+	}
+}
+
+// ReportReason 檢舉原因代碼
+type ReportReason string
+
+const (
+	ReportReasonSpam           ReportReason = "spam"
+	ReportReasonHarassment     ReportReason = "harassment"
+	ReportReasonHateSpeech     ReportReason = "hate_speech"
+	ReportReasonViolence       ReportReason = "violence"
+	ReportReasonSexualContent  ReportReason = "sexual_content"
+	ReportReasonMisinformation ReportReason = "misinformation"
+	ReportReasonOther          ReportReason = "other"
+	// ReportReasonContentPolicy 內容違反內容政策，由系統送交審核，使用者不可選擇
+	ReportReasonContentPolicy ReportReason = "content_policy"
+)
+
+var REPORT_REASONS = []ReportReason{
+	ReportReasonSpam,
+	ReportReasonHarassment,
+	ReportReasonHateSpeech,
+	ReportReasonViolence,
+	ReportReasonSexualContent,
+	ReportReasonMisinformation,
+	ReportReasonOther,
+}
+
+func ParseReportReason(name string) (ReportReason, bool) {
+	for _, reason := range REPORT_REASONS {
+		if string(reason) == name {
+			return reason, true
+		}
+	}
+	return "", false
+}
+
+// ReportStatus 檢舉的處理狀態
+type ReportStatus string
+
+const (
+	// ReportStatusPending 等待管理員處理
+	ReportStatusPending ReportStatus = "pending"
+	// ReportStatusDismissed 管理員駁回檢舉，內容不做處置
+	ReportStatusDismissed ReportStatus = "dismissed"
+	// ReportStatusActioned 管理員已對內容或作者做出處置
+	ReportStatusActioned ReportStatus = "actioned"
+)
+
+func ParseReportStatus(name string) (ReportStatus, bool) {
+	switch status := ReportStatus(name); status {
+	case ReportStatusPending, ReportStatusDismissed, ReportStatusActioned:
+		return status, true
+	default:
+		return "", false
+	}
+}
+
+// Report ReporterID 檢舉了 TargetType 與 TargetID 指定的貼文或評論，同一使用者對同一內容只會有一筆檢舉，
+// ReporterID 為 nil 時為系統送交審核的內容
+type Report struct {
+	TableModel
+	ReportBase
+}
+
+type ReportBase struct {
+	ReporterID *uuid.UUID       `gorm:"type:uuid;uniqueIndex:idx_reports_reporter_target"`
+	Reporter   *User            `gorm:"foreignKey:ReporterID"`
+	TargetType ReportTargetType `gorm:"not null;uniqueIndex:idx_reports_reporter_target;index:idx_reports_target"`
+	TargetID   uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_reports_reporter_target;index:idx_reports_target"`
+	// TargetAuthorID 與 TargetContent 為檢舉當下的作者與內容，內容之後被編輯或刪除時仍可供管理員檢視
+	TargetAuthorID uuid.UUID    `gorm:"type:uuid;not null;index"`
+	TargetAuthor   *User        `gorm:"foreignKey:TargetAuthorID"`
+	TargetContent  string       `gorm:"type:text;not null"`
+	Reason         ReportReason `gorm:"not null"`
+	Detail         string       `gorm:"type:text;not null;default:''"`
+	Status         ReportStatus `gorm:"not null;index"`
+	// ResolvedByID 與 ResolvedAt 為處理檢舉的管理員與時間，待處理時為 nil
+	ResolvedByID *uuid.UUID `gorm:"type:uuid"`
+	ResolvedAt   *int64
+}
+
+// ModerationAction 管理員對檢舉的處置
+type ModerationAction string
+
+const (
+	// ModerationActionDismiss 駁回檢舉，等待審核的內容視為審核通過並發布
+	ModerationActionDismiss ModerationAction = "dismiss"
+	// ModerationActionHide 隱藏內容，隱藏的內容不會出現在任何查詢中
+	ModerationActionHide ModerationAction = "hide"
+	// ModerationActionDelete 刪除內容 (有回覆的評論保留為墓碑)
+	ModerationActionDelete ModerationAction = "delete"
+	// ModerationActionSuspend 停權內容的作者
+	ModerationActionSuspend ModerationAction = "suspend"
+)
+
+func ParseModerationAction(name string) (ModerationAction, bool) {
+	switch action := ModerationAction(name); action {
+	case ModerationActionDismiss, ModerationActionHide, ModerationActionDelete, ModerationActionSuspend:
+		return action, true
+	default:
+		return "", false
+	}
+}
+
+// AuditLog 管理員處置的紀錄，不隨使用者或內容刪除
+type AuditLog struct {
+	TableModel
+	AuditLogBase
+}
+
+type AuditLogBase struct {
+	ActorID        uuid.UUID        `gorm:"type:uuid;not null;index"`
+	Action         ModerationAction `gorm:"not null"`
+	TargetType     ReportTargetType `gorm:"not null"`
+	TargetID       uuid.UUID        `gorm:"type:uuid;not null;index"`
+	TargetAuthorID uuid.UUID        `gorm:"type:uuid;not null"`
+	ReportID       *uuid.UUID       `gorm:"type:uuid"`
+	Note           string           `gorm:"type:text;not null;default:''"`
+}
+
+// Report Create structs
+type ReportCreateRequest struct {
+	TargetType string    `json:"targetType" binding:"required"`
+	TargetID   uuid.UUID `json:"targetID" binding:"required"`
+	// Reason spam, harassment, hate_speech, violence, sexual_content, misinformation, other
+	Reason string `json:"reason" binding:"required"`
+	Detail string `json:"detail"`
+}
+
+type ReportResponse struct {
+	ID         uuid.UUID `json:"id"`
+	TargetType string    `json:"targetType"`
+	TargetID   uuid.UUID `json:"targetID"`
+	Reason     string    `json:"reason"`
+	Detail     string    `json:"detail"`
+	Status     string    `json:"status"`
+	CreatedAt  string    `json:"createdAt"`
+}
+
+// Admin GetReports structs
+type AdminGetReportsResponseItem struct {
+	ID uuid.UUID `json:"id"`
+	// ReporterID 為 null 時為系統送交審核的內容
+	ReporterID     *uuid.UUID `json:"reporterID"`
+	TargetType     string     `json:"targetType"`
+	TargetID       uuid.UUID  `json:"targetID"`
+	TargetAuthorID uuid.UUID  `json:"targetAuthorID"`
+	TargetContent  string     `json:"targetContent"`
+	Reason         string     `json:"reason"`
+	Detail         string     `json:"detail"`
+	Status         string     `json:"status"`
+	ResolvedByID   *uuid.UUID `json:"resolvedByID"`
+	ResolvedAt     *string    `json:"resolvedAt"`
+	CreatedAt      string     `json:"createdAt"`
+}
+
+// Admin ResolveReport structs
+type AdminResolveReportRequest struct {
+	// Action dismiss, hide, delete, suspend
+	Action string `json:"action" binding:"required"`
+	Note   string `json:"note"`
+}
+
+type AdminResolveReportResponse struct {
+	Action string `json:"action"`
+	// ResolvedCount 一併處理的同一內容的待處理檢舉數 (包含此檢舉)
+	ResolvedCount uint `json:"resolvedCount"`
+}
+
+// Admin GetAuditLogs structs
+type AdminGetAuditLogsResponseItem struct {
+	ID             uuid.UUID  `json:"id"`
+	ActorID        uuid.UUID  `json:"actorID"`
+	Action         string     `json:"action"`
+	TargetType     string     `json:"targetType"`
+	TargetID       uuid.UUID  `json:"targetID"`
+	TargetAuthorID uuid.UUID  `json:"targetAuthorID"`
+	ReportID       *uuid.UUID `json:"reportID"`
+	Note           string     `json:"note"`
+	CreatedAt      string     `json:"createdAt"`
+}
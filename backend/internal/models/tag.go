@@ -1,11 +1,113 @@
 package models
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type Tag struct {
 	TableModel
 	TagBase
 }
 
 type TagBase struct {
-	Name  string  `gorm:"not null;unique"`
-	Posts []*Post `gorm:"many2many:post_to_tag;"`
+	// Name 正規化後的名稱 (TagUtils.Normalize)，因此不分大小寫唯一
+	Name    string     `gorm:"not null;unique"`
+	Posts   []*Post    `gorm:"many2many:post_to_tag;"`
+	Aliases []TagAlias `gorm:"foreignKey:TagID"`
+}
+
+// TagAlias 合併後的標籤名稱，使用別名時視為 Tag 所指的標籤
+type TagAlias struct {
+	TableModel
+	TagAliasBase
+}
+
+type TagAliasBase struct {
+	// Name 正規化後的名稱，不會與任何 Tag.Name 重複
+	Name  string    `gorm:"not null;unique"`
+	TagID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Tag   *Tag      `gorm:"foreignKey:TagID"`
+}
+
+// TagSummary 標籤與其貼文數，趨勢標籤中 PostCount 為時間區間內的貼文數
+type TagSummary struct {
+	ID        uuid.UUID
+	Name      string
+	PostCount uint
+}
+
+// TagSort 標籤列表的排序方式
+type TagSort string
+
+const (
+	TagSortName    TagSort = "name"
+	TagSortPopular TagSort = "popular"
+)
+
+func ParseTagSort(name string) (TagSort, bool) {
+	switch sort := TagSort(name); sort {
+	case TagSortName, TagSortPopular:
+		return sort, true
+	}
+	return "", false
+}
+
+// TrendingTags 趨勢標籤的快取，統計 RefreshedAt 前 Window 內建立的貼文
+type TrendingTags struct {
+	Tags        []TagSummary
+	Window      time.Duration
+	RefreshedAt time.Time
+}
+
+// Tag GetList structs
+type TagGetListResponseItem struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	PostCount uint      `json:"postCount"`
+}
+
+// Tag Autocomplete structs
+type TagAutocompleteResponseItem struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	PostCount uint      `json:"postCount"`
+}
+
+// Tag GetTrending structs
+type TagGetTrendingResponse struct {
+	Data []TagGetTrendingResponseItem `json:"data"`
+	// WindowSeconds 統計的時間區間 (秒)
+	WindowSeconds int64  `json:"windowSeconds"`
+	RefreshedAt   string `json:"refreshedAt"`
+}
+
+type TagGetTrendingResponseItem struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	PostCount uint      `json:"postCount"`
+}
+
+// Tag GetByName structs
+type TagGetByNameResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Aliases   []string  `json:"aliases"`
+	PostCount uint      `json:"postCount"`
+	CreatedAt string    `json:"createdAt"`
+}
+
+// Admin Merge Tags structs
+type AdminMergeTagsRequest struct {
+	// SourceNames 合併到 TargetName 的標籤，合併後成為 TargetName 的別名
+	SourceNames []string `json:"sourceNames" binding:"required,min=1"`
+	TargetName  string   `json:"targetName" binding:"required"`
+}
+
+type AdminMergeTagsResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Aliases   []string  `json:"aliases"`
+	PostCount uint      `json:"postCount"`
 }
@@ -11,6 +11,27 @@ const (
 	RoleNormalCustomer
 )
 
+var ROLE_NAMES = map[Role]string{
+	RoleAdmin:          "admin",
+	RoleNormalCustomer: "normal_customer",
+}
+
+func (r Role) String() string {
+	if name, ok := ROLE_NAMES[r]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func ParseRole(name string) (Role, bool) {
+	for role, roleName := range ROLE_NAMES {
+		if roleName == name {
+			return role, true
+		}
+	}
+	return 0, false
+}
+
 type User struct {
 	TableModel
 	UserBase
@@ -25,6 +46,19 @@ type UserBase struct {
 	Address        *Address `gorm:"foreignKey:AddressID"`
 	Likes          []*Post  `gorm:"many2many:post_to_user;"`
 	Role           Role     `gorm:"not null"`
+	// TokenVersion 角色或權限變更時遞增，使舊的 access token 失效
+	TokenVersion int64 `gorm:"not null;default:0"`
+	// SuspendedAt 不為 nil 表示帳號已停權
+	SuspendedAt     *int64
+	SuspendedReason *string
+	// PasswordResetRequired 管理員強制重設密碼後，使用者需變更密碼
+	PasswordResetRequired bool `gorm:"not null;default:false"`
+}
+
+type UserListFilter struct {
+	Keyword   string
+	Role      *Role
+	Suspended *bool
 }
 
 // User Register structs
@@ -61,8 +95,16 @@ type UserLoginRequest struct {
 }
 
 type UserLoginResponse struct {
-	ID          uuid.UUID `json:"id"`
-	Username    string    `json:"username"`
-	Email       string    `json:"email"`
-	AccessToken string    `json:"accessToken"`
+	ID                    uuid.UUID `json:"id"`
+	Username              string    `json:"username"`
+	Email                 string    `json:"email"`
+	AccessToken           string    `json:"accessToken"`
+	RefreshToken          string    `json:"refreshToken"`
+	PasswordResetRequired bool      `json:"passwordResetRequired"`
+}
+
+// User ChangePassword structs
+type UserChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
 }
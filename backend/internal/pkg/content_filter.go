@@ -0,0 +1,447 @@
+package pkg
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/unicode/norm"
+)
+
+// CONTENT_FILTER_MASK_RUNE 遮蔽違規內容使用的字元
+const CONTENT_FILTER_MASK_RUNE = '*'
+
+// CONTENT_FILTER_URL_REGEX 內容中的連結，包含 http(s):// 開頭與 www. 開頭的網址
+var CONTENT_FILTER_URL_REGEX = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"'，。！？、）」』]+`)
+
+// ContentFilterAction 違反規則時的處置
+type ContentFilterAction string
+
+const (
+	// ContentFilterActionMask 遮蔽違規的部分後照常發布
+	ContentFilterActionMask ContentFilterAction = "mask"
+	// ContentFilterActionReview 照常保存但隱藏，等待管理員審核
+	ContentFilterActionReview ContentFilterAction = "review"
+	// ContentFilterActionReject 拒絕發布
+	ContentFilterActionReject ContentFilterAction = "reject"
+)
+
+// severity 處置的嚴重程度，多條規則違規時採用最嚴重的處置
+func (a ContentFilterAction) severity() int {
+	switch a {
+	case ContentFilterActionMask:
+		return 1
+	case ContentFilterActionReview:
+		return 2
+	case ContentFilterActionReject:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func ParseContentFilterAction(name string) (ContentFilterAction, bool) {
+	switch action := ContentFilterAction(strings.ToLower(name)); action {
+	case ContentFilterActionMask, ContentFilterActionReview, ContentFilterActionReject:
+		return action, true
+	default:
+		return "", false
+	}
+}
+
+// ContentFilterRule 內容政策的規則，實作此介面即可加入 ContentFilter
+type ContentFilterRule interface {
+	// Name 規則名稱，記錄於 ContentFilterResult.Violations
+	Name() string
+	// Apply 回傳內容是否違反規則與遮蔽違規部分後的內容
+	Apply(content string) (masked string, violated bool)
+}
+
+// ContentFilterPolicy 規則與違反時的處置
+type ContentFilterPolicy struct {
+	Rule   ContentFilterRule
+	Action ContentFilterAction
+}
+
+type ContentFilterResult struct {
+	// Content 依處置為 mask 的規則遮蔽後的內容
+	Content string
+	// Action 違反的規則中最嚴重的處置，沒有違規時為空字串
+	Action ContentFilterAction
+	// Violations 依規則順序排列的違反的規則名稱
+	Violations []string
+}
+
+// ContentFilter 依序套用每條規則，前一條規則遮蔽後的內容會交給下一條規則，違反處置為 reject 的規則時立即停止
+type ContentFilter struct {
+	Policies []ContentFilterPolicy
+}
+
+func (f *ContentFilter) Apply(content string) *ContentFilterResult {
+	result := &ContentFilterResult{Content: content, Violations: []string{}}
+	for _, policy := range f.Policies {
+		masked, violated := policy.Rule.Apply(result.Content)
+		if !violated {
+			continue
+		}
+		result.Violations = append(result.Violations, policy.Rule.Name())
+		if policy.Action.severity() > result.Action.severity() {
+			result.Action = policy.Action
+		}
+		switch policy.Action {
+		case ContentFilterActionMask:
+			result.Content = masked
+		case ContentFilterActionReject:
+			return result
+		}
+	}
+	return result
+}
+
+type ContentFilterConfig struct {
+	// BannedWordsFile 每行一個禁用詞，空白行與 # 開頭的行會被忽略，空字串表示不檢查禁用詞
+	BannedWordsFile   string
+	BannedWordsAction ContentFilterAction
+
+	// URLAllowlist 不為空時只允許這些網域 (含子網域) 的連結，URLDenylist 的網域 (含子網域) 一律不允許
+	URLAllowlist []string
+	URLDenylist  []string
+	URLAction    ContentFilterAction
+
+	// MaxLinks 連結數量上限，0 表示不限制
+	MaxLinks       int
+	MaxLinksAction ContentFilterAction
+
+	// MaxRepeatedChars 同一字元連續出現的次數上限，0 表示不限制
+	MaxRepeatedChars       int
+	MaxRepeatedCharsAction ContentFilterAction
+}
+
+// DefaultContentFilterConfig 預設不啟用任何規則
+func DefaultContentFilterConfig() *ContentFilterConfig {
+	return &ContentFilterConfig{
+		BannedWordsAction:      ContentFilterActionMask,
+		URLAction:              ContentFilterActionReject,
+		MaxLinksAction:         ContentFilterActionReview,
+		MaxRepeatedCharsAction: ContentFilterActionMask,
+	}
+}
+
+// ContentFilterConfigFromEnv 從環境變數讀取內容政策，未設定則使用預設值
+func ContentFilterConfigFromEnv() (*ContentFilterConfig, error) {
+	cfg := DefaultContentFilterConfig()
+	cfg.BannedWordsFile = os.Getenv("CONTENT_FILTER_BANNED_WORDS_FILE")
+	splitList := func(key string) []string {
+		list := []string{}
+		for _, item := range strings.Split(os.Getenv(key), ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list
+	}
+	cfg.URLAllowlist = splitList("CONTENT_FILTER_URL_ALLOWLIST")
+	cfg.URLDenylist = splitList("CONTENT_FILTER_URL_DENYLIST")
+	parseInt := func(key string, target *int) error {
+		value := os.Getenv(key)
+		if value == "" {
+			return nil
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return errors.Errorf("invalid %s", key)
+		}
+		*target = parsed
+		return nil
+	}
+	if err := parseInt("CONTENT_FILTER_MAX_LINKS", &cfg.MaxLinks); err != nil {
+		return nil, err
+	}
+	if err := parseInt("CONTENT_FILTER_MAX_REPEATED_CHARS", &cfg.MaxRepeatedChars); err != nil {
+		return nil, err
+	}
+	parseAction := func(key string, target *ContentFilterAction) error {
+		value := os.Getenv(key)
+		if value == "" {
+			return nil
+		}
+		action, ok := ParseContentFilterAction(value)
+		if !ok {
+			return errors.Errorf("invalid %s: %s", key, value)
+		}
+		*target = action
+		return nil
+	}
+	for key, target := range map[string]*ContentFilterAction{
+		"CONTENT_FILTER_BANNED_WORDS_ACTION":       &cfg.BannedWordsAction,
+		"CONTENT_FILTER_URL_ACTION":                &cfg.URLAction,
+		"CONTENT_FILTER_MAX_LINKS_ACTION":          &cfg.MaxLinksAction,
+		"CONTENT_FILTER_MAX_REPEATED_CHARS_ACTION": &cfg.MaxRepeatedCharsAction,
+	} {
+		if err := parseAction(key, target); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// NewContentFilter 依設定建立 ContentFilter，規則依禁用詞、連結網域、連結數量、重複字元的順序套用
+func NewContentFilter(cfg *ContentFilterConfig) (*ContentFilter, error) {
+	filter := &ContentFilter{Policies: []ContentFilterPolicy{}}
+	if cfg.BannedWordsFile != "" {
+		words, err := LoadBannedWords(cfg.BannedWordsFile)
+		if err != nil {
+			return nil, err
+		}
+		filter.Policies = append(filter.Policies, ContentFilterPolicy{Rule: NewBannedWordsRule(words), Action: cfg.BannedWordsAction})
+	}
+	if len(cfg.URLAllowlist) > 0 || len(cfg.URLDenylist) > 0 {
+		filter.Policies = append(filter.Policies, ContentFilterPolicy{Rule: NewURLRule(cfg.URLAllowlist, cfg.URLDenylist), Action: cfg.URLAction})
+	}
+	if cfg.MaxLinks > 0 {
+		filter.Policies = append(filter.Policies, ContentFilterPolicy{Rule: &MaxLinksRule{Max: cfg.MaxLinks}, Action: cfg.MaxLinksAction})
+	}
+	if cfg.MaxRepeatedChars > 0 {
+		filter.Policies = append(filter.Policies, ContentFilterPolicy{Rule: &RepeatedCharsRule{Max: cfg.MaxRepeatedChars}, Action: cfg.MaxRepeatedCharsAction})
+	}
+	return filter, nil
+}
+
+// LoadBannedWords 讀取禁用詞檔案 (UTF-8，每行一個詞)
+func LoadBannedWords(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open banned words file")
+	}
+	defer file.Close()
+
+	words := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words = append(words, word)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read banned words file")
+	}
+	return words, nil
+}
+
+// maskRunes 將 runes[start:end] 替換為 CONTENT_FILTER_MASK_RUNE
+func maskRunes(runes []rune, start int, end int) {
+	for i := start; i < end; i++ {
+		runes[i] = CONTENT_FILTER_MASK_RUNE
+	}
+}
+
+// normalizeRune 全形轉半形 (NFKC) 並轉為小寫，無法對應為單一字元時保留原字元，讓比對結果的位置與原內容一致
+func normalizeRune(r rune) rune {
+	if normalized := norm.NFKC.String(string(r)); utf8.RuneCountInString(normalized) == 1 {
+		r, _ = utf8.DecodeRuneInString(normalized)
+	}
+	return unicode.ToLower(r)
+}
+
+// isWordRune 英文與數字，英文禁用詞的前後不可緊接這些字元 (避免 class 比對到 ass)
+func isWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+// BannedWordsRule 禁用詞，不分大小寫與全形半形；
+// 英文等以空白分詞的詞需以完整的詞出現，中文等不分詞的詞只要出現在內容中即違規
+type BannedWordsRule struct {
+	words [][]rune
+}
+
+func NewBannedWordsRule(words []string) *BannedWordsRule {
+	rule := &BannedWordsRule{words: [][]rune{}}
+	for _, word := range words {
+		normalized := []rune(word)
+		for i, r := range normalized {
+			normalized[i] = normalizeRune(r)
+		}
+		if len(normalized) > 0 {
+			rule.words = append(rule.words, normalized)
+		}
+	}
+	return rule
+}
+
+func (r *BannedWordsRule) Name() string {
+	return "banned_words"
+}
+
+func (r *BannedWordsRule) Apply(content string) (string, bool) {
+	runes := []rune(content)
+	normalized := make([]rune, len(runes))
+	for i, c := range runes {
+		normalized[i] = normalizeRune(c)
+	}
+
+	violated := false
+	for _, word := range r.words {
+		for start := 0; start+len(word) <= len(normalized); start++ {
+			end := start + len(word)
+			if !slices.Equal(normalized[start:end], word) {
+				continue
+			}
+			if isWordRune(word[0]) && start > 0 && isWordRune(normalized[start-1]) {
+				continue
+			}
+			if isWordRune(word[len(word)-1]) && end < len(normalized) && isWordRune(normalized[end]) {
+				continue
+			}
+			violated = true
+			maskRunes(runes, start, end)
+		}
+	}
+	return string(runes), violated
+}
+
+// contentLink 內容中的連結，Start 與 End 為字元 (rune) 位置，End 不包含
+type contentLink struct {
+	Host  string
+	Start int
+	End   int
+}
+
+// findLinks 依出現順序回傳內容中的連結
+func findLinks(content string) []contentLink {
+	links := []contentLink{}
+	for _, indexes := range CONTENT_FILTER_URL_REGEX.FindAllStringIndex(content, -1) {
+		// 句尾的標點不屬於連結
+		link := strings.TrimRight(content[indexes[0]:indexes[1]], ".,;:!?)]")
+		rawURL := link
+		if !strings.Contains(strings.ToLower(rawURL), "://") {
+			rawURL = "http://" + rawURL
+		}
+		host := ""
+		if parsed, err := url.Parse(rawURL); err == nil {
+			host = strings.ToLower(parsed.Hostname())
+		}
+		start := utf8.RuneCountInString(content[:indexes[0]])
+		links = append(links, contentLink{
+			Host:  host,
+			Start: start,
+			End:   start + utf8.RuneCountInString(link),
+		})
+	}
+	return links
+}
+
+// matchDomain host 為 domain 或其子網域
+func matchDomain(host string, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// URLRule 連結網域的允許與禁止清單，違規的連結整段遮蔽
+type URLRule struct {
+	Allowlist []string
+	Denylist  []string
+}
+
+func NewURLRule(allowlist []string, denylist []string) *URLRule {
+	normalize := func(domains []string) []string {
+		normalized := make([]string, len(domains))
+		for i, domain := range domains {
+			normalized[i] = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), ".")
+		}
+		return normalized
+	}
+	return &URLRule{Allowlist: normalize(allowlist), Denylist: normalize(denylist)}
+}
+
+func (r *URLRule) Name() string {
+	return "url"
+}
+
+func (r *URLRule) allowed(host string) bool {
+	for _, domain := range r.Denylist {
+		if matchDomain(host, domain) {
+			return false
+		}
+	}
+	if len(r.Allowlist) == 0 {
+		return true
+	}
+	for _, domain := range r.Allowlist {
+		if matchDomain(host, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *URLRule) Apply(content string) (string, bool) {
+	runes := []rune(content)
+	violated := false
+	for _, link := range findLinks(content) {
+		if r.allowed(link.Host) {
+			continue
+		}
+		violated = true
+		maskRunes(runes, link.Start, link.End)
+	}
+	return string(runes), violated
+}
+
+// MaxLinksRule 連結數量上限，超過上限的連結整段遮蔽
+type MaxLinksRule struct {
+	Max int
+}
+
+func (r *MaxLinksRule) Name() string {
+	return "max_links"
+}
+
+func (r *MaxLinksRule) Apply(content string) (string, bool) {
+	links := findLinks(content)
+	if len(links) <= r.Max {
+		return content, false
+	}
+	runes := []rune(content)
+	for _, link := range links[r.Max:] {
+		maskRunes(runes, link.Start, link.End)
+	}
+	return string(runes), true
+}
+
+// RepeatedCharsRule 同一字元 (不分大小寫) 連續出現的次數上限，遮蔽時將過長的連續字元縮短為上限的長度，空白不列入檢查
+type RepeatedCharsRule struct {
+	Max int
+}
+
+func (r *RepeatedCharsRule) Name() string {
+	return "repeated_chars"
+}
+
+func (r *RepeatedCharsRule) Apply(content string) (string, bool) {
+	builder := strings.Builder{}
+	violated := false
+	var previous rune
+	count := 0
+	for _, c := range content {
+		if count > 0 && unicode.ToLower(c) == unicode.ToLower(previous) && !unicode.IsSpace(c) {
+			count++
+		} else {
+			previous = c
+			count = 1
+		}
+		if count > r.Max {
+			violated = true
+			continue
+		}
+		builder.WriteRune(c)
+	}
+	return builder.String(), violated
+}
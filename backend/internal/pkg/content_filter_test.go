@@ -0,0 +1,134 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentFilter(t *testing.T) {
+	t.Run("BannedWordsRule", func(t *testing.T) {
+		rule := NewBannedWordsRule([]string{"垃圾", "Spam", "ass"})
+
+		masked, violated := rule.Apply("這是垃圾訊息")
+		assert.True(t, violated)
+		assert.Equal(t, "這是**訊息", masked)
+
+		masked, violated = rule.Apply("Buy SPAM now, ｓｐａｍ!")
+		assert.True(t, violated, "不分大小寫與全形半形")
+		assert.Equal(t, "Buy **** now, ****!", masked)
+
+		_, violated = rule.Apply("first class spammer")
+		assert.False(t, violated, "英文禁用詞需以完整的詞出現")
+
+		masked, violated = rule.Apply("你是ass嗎")
+		assert.True(t, violated, "前後為中文時視為完整的詞")
+		assert.Equal(t, "你是***嗎", masked)
+	})
+
+	t.Run("URLRule", func(t *testing.T) {
+		rule := NewURLRule(nil, []string{"Bad.com"})
+		masked, violated := rule.Apply("看 https://www.bad.com/x 和 https://good.com。")
+		assert.True(t, violated, "子網域也禁止")
+		assert.Equal(t, "看 ********************* 和 https://good.com。", masked)
+		_, violated = rule.Apply("notbad.com 和 https://notbad.com")
+		assert.False(t, violated, "只比對完整的網域")
+
+		rule = NewURLRule([]string{"example.com"}, nil)
+		_, violated = rule.Apply("https://docs.example.com/a, www.example.com.")
+		assert.False(t, violated)
+		masked, violated = rule.Apply("www.other.org.")
+		assert.True(t, violated, "不在允許清單的網域")
+		assert.Equal(t, "*************.", masked, "句尾的標點不屬於連結")
+	})
+
+	t.Run("MaxLinksRule", func(t *testing.T) {
+		rule := &MaxLinksRule{Max: 1}
+		_, violated := rule.Apply("https://a.com")
+		assert.False(t, violated)
+		masked, violated := rule.Apply("https://a.com https://b.com")
+		assert.True(t, violated)
+		assert.Equal(t, "https://a.com *************", masked, "遮蔽超過上限的連結")
+	})
+
+	t.Run("RepeatedCharsRule", func(t *testing.T) {
+		rule := &RepeatedCharsRule{Max: 3}
+		_, violated := rule.Apply("好好好 !!!")
+		assert.False(t, violated)
+		masked, violated := rule.Apply("好好好好好好 wowWWW")
+		assert.True(t, violated)
+		assert.Equal(t, "好好好 wowWW", masked, "不分大小寫，縮短為上限的長度")
+		_, violated = rule.Apply("a          b")
+		assert.False(t, violated, "空白不列入檢查")
+	})
+
+	t.Run("ContentFilter", func(t *testing.T) {
+		filter := &ContentFilter{Policies: []ContentFilterPolicy{
+			{Rule: NewBannedWordsRule([]string{"垃圾"}), Action: ContentFilterActionMask},
+			{Rule: &MaxLinksRule{Max: 0}, Action: ContentFilterActionReview},
+			{Rule: &RepeatedCharsRule{Max: 3}, Action: ContentFilterActionReject},
+			{Rule: NewURLRule(nil, []string{"bad.com"}), Action: ContentFilterActionMask},
+		}}
+
+		result := filter.Apply("正常的內容")
+		assert.Equal(t, ContentFilterAction(""), result.Action)
+		assert.Empty(t, result.Violations)
+		assert.Equal(t, "正常的內容", result.Content)
+
+		result = filter.Apply("垃圾 https://bad.com")
+		assert.Equal(t, ContentFilterActionReview, result.Action, "採用最嚴重的處置")
+		assert.Equal(t, []string{"banned_words", "max_links", "url"}, result.Violations)
+		assert.Equal(t, "** ***************", result.Content, "處置為 mask 的規則才會遮蔽內容")
+
+		result = filter.Apply("垃圾!!!!")
+		assert.Equal(t, ContentFilterActionReject, result.Action)
+		assert.Equal(t, []string{"banned_words", "repeated_chars"}, result.Violations)
+	})
+
+	t.Run("NewContentFilter", func(t *testing.T) {
+		filter, err := NewContentFilter(DefaultContentFilterConfig())
+		require.NoError(t, err)
+		assert.Empty(t, filter.Policies, "預設不啟用任何規則")
+
+		path := filepath.Join(t.TempDir(), "banned_words.txt")
+		require.NoError(t, os.WriteFile(path, []byte("# 註解\n垃圾\n\n  spam  \n"), 0o644))
+		words, err := LoadBannedWords(path)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"垃圾", "spam"}, words)
+
+		cfg := DefaultContentFilterConfig()
+		cfg.BannedWordsFile = path
+		cfg.MaxLinks = 2
+		filter, err = NewContentFilter(cfg)
+		require.NoError(t, err)
+		if assert.Len(t, filter.Policies, 2) {
+			assert.Equal(t, "banned_words", filter.Policies[0].Rule.Name())
+			assert.Equal(t, ContentFilterActionMask, filter.Policies[0].Action)
+			assert.Equal(t, "max_links", filter.Policies[1].Rule.Name())
+			assert.Equal(t, ContentFilterActionReview, filter.Policies[1].Action)
+		}
+
+		cfg.BannedWordsFile = filepath.Join(t.TempDir(), "missing.txt")
+		_, err = NewContentFilter(cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("ContentFilterConfigFromEnv", func(t *testing.T) {
+		t.Setenv("CONTENT_FILTER_URL_DENYLIST", "bad.com, evil.org ,")
+		t.Setenv("CONTENT_FILTER_MAX_REPEATED_CHARS", "5")
+		t.Setenv("CONTENT_FILTER_MAX_REPEATED_CHARS_ACTION", "Reject")
+		cfg, err := ContentFilterConfigFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bad.com", "evil.org"}, cfg.URLDenylist)
+		assert.Empty(t, cfg.URLAllowlist)
+		assert.Equal(t, 5, cfg.MaxRepeatedChars)
+		assert.Equal(t, ContentFilterActionReject, cfg.MaxRepeatedCharsAction)
+
+		t.Setenv("CONTENT_FILTER_URL_ACTION", "block")
+		_, err = ContentFilterConfigFromEnv()
+		assert.Error(t, err)
+	})
+}
@@ -1,36 +1,116 @@
 package pkg
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"log"
+	"regexp"
 	"sync"
 )
 
-type CryptoUtils struct{}
+type CryptoUtils struct {
+	// PasswordHasher 目前用於產生新雜湊的演算法
+	PasswordHasher PasswordHasher
+	// verifiers 用於驗證既有雜湊的所有演算法 (依雜湊字串前綴選擇)
+	verifiers []PasswordHasher
+}
+
+// 舊版 SHA-256(email|password) 雜湊格式
+var LEGACY_PASSWORD_HASH_REGEX = regexp.MustCompile(`^[0-9a-f]{64}$`)
 
 var cryptoUtilsOnce sync.Once
 var cryptoUtils *CryptoUtils
 
 func NewCryptoUtils() *CryptoUtils {
 	cryptoUtilsOnce.Do(func() {
+		cfg, err := PasswordHasherConfigFromEnv()
+		if err != nil {
+			log.Printf("Invalid password hasher config, fallback to default: %v\n", err)
+			cfg = DefaultPasswordHasherConfig()
+		}
+		hasher, err := NewPasswordHasher(cfg)
+		if err != nil {
+			log.Printf("Invalid password hasher config, fallback to default: %v\n", err)
+			hasher, _ = NewPasswordHasher(DefaultPasswordHasherConfig())
+		}
 		cryptoUtils = &CryptoUtils{}
+		cryptoUtils.SetPasswordHasher(hasher)
 	})
 	return cryptoUtils
 }
 
+// SetPasswordHasher 更換產生新雜湊的演算法，既有雜湊仍可被驗證
+func (c *CryptoUtils) SetPasswordHasher(hasher PasswordHasher) {
+	c.PasswordHasher = hasher
+	c.verifiers = []PasswordHasher{hasher}
+	for _, algorithm := range []string{PASSWORD_HASH_ALGORITHM_ARGON2ID, PASSWORD_HASH_ALGORITHM_BCRYPT} {
+		if algorithm == hasher.Algorithm() {
+			continue
+		}
+		cfg := DefaultPasswordHasherConfig()
+		cfg.Algorithm = algorithm
+		verifier, _ := NewPasswordHasher(cfg)
+		c.verifiers = append(c.verifiers, verifier)
+	}
+}
+
 type CryptoUtilsPasswordHashInput struct {
 	Email    string
 	Password string
 }
 
-func (c *CryptoUtils) GeneratePasswordHash(input *CryptoUtilsPasswordHashInput) string {
+func (c *CryptoUtils) GeneratePasswordHash(input *CryptoUtilsPasswordHashInput) (string, error) {
+	return c.PasswordHasher.Hash(input.Password)
+}
+
+func (c *CryptoUtils) VerifyPasswordHash(hashedPassword string, input *CryptoUtilsPasswordHashInput) bool {
+	if c.IsLegacyPasswordHash(hashedPassword) {
+		expectedHash := c.generateLegacyPasswordHash(input)
+		return subtle.ConstantTimeCompare([]byte(hashedPassword), []byte(expectedHash)) == 1
+	}
+	for _, verifier := range c.verifiers {
+		if !verifier.Match(hashedPassword) {
+			continue
+		}
+		ok, err := verifier.Verify(hashedPassword, input.Password)
+		return err == nil && ok
+	}
+	return false
+}
+
+// NeedsRehash 舊版雜湊、其他演算法或參數過時的雜湊，需要在登入成功後重新雜湊
+func (c *CryptoUtils) NeedsRehash(hashedPassword string) bool {
+	if !c.PasswordHasher.Match(hashedPassword) {
+		return true
+	}
+	return c.PasswordHasher.NeedsRehash(hashedPassword)
+}
+
+func (c *CryptoUtils) IsLegacyPasswordHash(hashedPassword string) bool {
+	return LEGACY_PASSWORD_HASH_REGEX.MatchString(hashedPassword)
+}
+
+func (c *CryptoUtils) generateLegacyPasswordHash(input *CryptoUtilsPasswordHashInput) string {
 	combined := fmt.Sprintf("%s|%s", input.Email, input.Password)
 	hash := sha256.Sum256([]byte(combined))
 	return hex.EncodeToString(hash[:])
 }
 
-func (c *CryptoUtils) VerifyPasswordHash(hashedPassword string, input *CryptoUtilsPasswordHashInput) bool {
-	expectedHash := c.GeneratePasswordHash(input)
-	return hashedPassword == expectedHash
+// GenerateRandomToken 產生 URL-safe 的隨機字串，byteLength 為隨機位元組數
+func (c *CryptoUtils) GenerateRandomToken(byteLength int) (string, error) {
+	b := make([]byte, byteLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken 高熵隨機 token 的雜湊 (用於伺服器端儲存，不適用於密碼)
+func (c *CryptoUtils) HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
@@ -24,7 +24,7 @@ func TestHash256Password(t *testing.T) {
 		username := GetRandomString(5)
 		email := username + "@example.com"
 		password := "password123"
-		hashedPassword := cryptoUtils.GeneratePasswordHash(&CryptoUtilsPasswordHashInput{
+		hashedPassword, _ := cryptoUtils.GeneratePasswordHash(&CryptoUtilsPasswordHashInput{
 			Email:    email,
 			Password: password,
 		})
@@ -36,7 +36,7 @@ func TestHash256Password(t *testing.T) {
 		username := GetRandomString(5)
 		email := username + "@example.com"
 		password := "password123"
-		hashedPassword := cryptoUtils.GeneratePasswordHash(&CryptoUtilsPasswordHashInput{
+		hashedPassword, _ := cryptoUtils.GeneratePasswordHash(&CryptoUtilsPasswordHashInput{
 			Email:    email,
 			Password: password,
 		})
@@ -51,7 +51,7 @@ func TestHash256Password(t *testing.T) {
 		username := GetRandomString(5)
 		email := username + "@example.com"
 		password := "password123"
-		hashedPassword := cryptoUtils.GeneratePasswordHash(&CryptoUtilsPasswordHashInput{
+		hashedPassword, _ := cryptoUtils.GeneratePasswordHash(&CryptoUtilsPasswordHashInput{
 			Email:    email,
 			Password: password,
 		})
@@ -61,4 +61,35 @@ func TestHash256Password(t *testing.T) {
 			Password: "wrongpassword",
 		}), "Expected password verification to fail")
 	})
+
+	t.Run("Salted Hash", func(t *testing.T) {
+		input := &CryptoUtilsPasswordHashInput{Email: "salt@example.com", Password: "password123"}
+		hash1, _ := cryptoUtils.GeneratePasswordHash(input)
+		hash2, _ := cryptoUtils.GeneratePasswordHash(input)
+		assert.NotEqual(t, hash1, hash2, "Expected different hashes for the same password")
+		assert.False(t, cryptoUtils.NeedsRehash(hash1), "Expected fresh hash not to need rehash")
+	})
+
+	t.Run("Verifying Password After Email Changed", func(t *testing.T) {
+		hashedPassword, _ := cryptoUtils.GeneratePasswordHash(&CryptoUtilsPasswordHashInput{
+			Email:    "old@example.com",
+			Password: "password123",
+		})
+		assert.True(t, cryptoUtils.VerifyPasswordHash(hashedPassword, &CryptoUtilsPasswordHashInput{
+			Email:    "new@example.com",
+			Password: "password123",
+		}), "Expected password to be verified regardless of email")
+	})
+
+	t.Run("Verifying Legacy Password", func(t *testing.T) {
+		input := &CryptoUtilsPasswordHashInput{Email: "legacy@example.com", Password: "password123"}
+		legacyHash := cryptoUtils.generateLegacyPasswordHash(input)
+		assert.True(t, cryptoUtils.IsLegacyPasswordHash(legacyHash))
+		assert.True(t, cryptoUtils.VerifyPasswordHash(legacyHash, input), "Expected legacy hash to be verified")
+		assert.False(t, cryptoUtils.VerifyPasswordHash(legacyHash, &CryptoUtilsPasswordHashInput{
+			Email:    input.Email,
+			Password: "wrongpassword",
+		}), "Expected legacy verification to fail")
+		assert.True(t, cryptoUtils.NeedsRehash(legacyHash), "Expected legacy hash to need rehash")
+	})
 }
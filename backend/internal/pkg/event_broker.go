@@ -0,0 +1,81 @@
+package pkg
+
+import (
+	"context"
+	"sync"
+)
+
+// EVENT_SUBSCRIBER_BUFFER_SIZE 每個訂閱者的緩衝訊息數，緩衝已滿時 (訂閱者處理過慢) 捨棄新訊息
+const EVENT_SUBSCRIBER_BUFFER_SIZE = 64
+
+// EventMessage 發布到主題的訊息，Payload 為序列化後的資料
+type EventMessage struct {
+	Topic   string
+	Payload []byte
+}
+
+// EventBroker 發布/訂閱的後端，MemoryEventBroker 只在單一實例內傳遞訊息，
+// 多個實例時可替換為跨實例的實作 (例如 PostgreSQL LISTEN/NOTIFY)
+type EventBroker interface {
+	Publish(topic string, payload []byte) error
+	// Subscribe 訂閱多個主題，回傳的 channel 在 ctx 結束後關閉
+	Subscribe(ctx context.Context, topics []string) (<-chan EventMessage, error)
+}
+
+// MemoryEventBroker 行程內的 EventBroker
+type MemoryEventBroker struct {
+	mutex       sync.RWMutex
+	subscribers map[string]map[chan EventMessage]bool
+}
+
+func NewMemoryEventBroker() *MemoryEventBroker {
+	return &MemoryEventBroker{
+		subscribers: map[string]map[chan EventMessage]bool{},
+	}
+}
+
+// Publish 不會等待訂閱者接收
+func (b *MemoryEventBroker) Publish(topic string, payload []byte) error {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	for subscriber := range b.subscribers[topic] {
+		select {
+		case subscriber <- EventMessage{Topic: topic, Payload: payload}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *MemoryEventBroker) Subscribe(ctx context.Context, topics []string) (<-chan EventMessage, error) {
+	subscriber := make(chan EventMessage, EVENT_SUBSCRIBER_BUFFER_SIZE)
+	b.mutex.Lock()
+	for _, topic := range topics {
+		if b.subscribers[topic] == nil {
+			b.subscribers[topic] = map[chan EventMessage]bool{}
+		}
+		b.subscribers[topic][subscriber] = true
+	}
+	b.mutex.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		for _, topic := range topics {
+			delete(b.subscribers[topic], subscriber)
+			if len(b.subscribers[topic]) == 0 {
+				delete(b.subscribers, topic)
+			}
+		}
+		close(subscriber)
+	}()
+	return subscriber, nil
+}
+
+// CountSubscribers 回傳主題目前的訂閱者數
+func (b *MemoryEventBroker) CountSubscribers(topic string) int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return len(b.subscribers[topic])
+}
@@ -0,0 +1,60 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryEventBroker(t *testing.T) {
+	broker := NewMemoryEventBroker()
+
+	receive := func(messages <-chan EventMessage) (EventMessage, bool) {
+		select {
+		case message, ok := <-messages:
+			return message, ok
+		case <-time.After(time.Second):
+			return EventMessage{}, false
+		}
+	}
+
+	t.Run("發布與訂閱", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		messages, err := broker.Subscribe(ctx, []string{"a", "b"})
+		require.NoError(t, err)
+
+		assert.NoError(t, broker.Publish("c", []byte("ignored")))
+		assert.NoError(t, broker.Publish("b", []byte("hello")))
+		message, ok := receive(messages)
+		assert.True(t, ok)
+		assert.Equal(t, EventMessage{Topic: "b", Payload: []byte("hello")}, message, "只收到訂閱的主題")
+	})
+
+	t.Run("取消訂閱後關閉 channel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		messages, err := broker.Subscribe(ctx, []string{"topic"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, broker.CountSubscribers("topic"))
+
+		cancel()
+		_, ok := receive(messages)
+		assert.False(t, ok, "channel 應該被關閉")
+		assert.Equal(t, 0, broker.CountSubscribers("topic"))
+		assert.NoError(t, broker.Publish("topic", []byte("after")), "沒有訂閱者時發布不應出錯")
+	})
+
+	t.Run("緩衝已滿時不阻塞", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		messages, err := broker.Subscribe(ctx, []string{"slow"})
+		require.NoError(t, err)
+		for range EVENT_SUBSCRIBER_BUFFER_SIZE + 10 {
+			assert.NoError(t, broker.Publish("slow", []byte("x")))
+		}
+		assert.Len(t, messages, EVENT_SUBSCRIBER_BUFFER_SIZE)
+	})
+}
@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"log"
 	"os"
 	"sync"
 	"time"
@@ -10,7 +11,9 @@ import (
 )
 
 type JWTUtils struct {
-	DefaultEnvKey string
+	DefaultEnvKey   string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
 }
 
 var jwtUtilsOnce sync.Once
@@ -19,12 +22,27 @@ var jwtUtils *JWTUtils
 func NewJWTUtils() *JWTUtils {
 	jwtUtilsOnce.Do(func() {
 		jwtUtils = &JWTUtils{
-			DefaultEnvKey: "JWT_SECRET",
+			DefaultEnvKey:   "JWT_SECRET",
+			AccessTokenTTL:  durationFromEnv("JWT_ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL: durationFromEnv("JWT_REFRESH_TOKEN_TTL", 30*24*time.Hour),
 		}
 	})
 	return jwtUtils
 }
 
+func durationFromEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Invalid %s: %q, fallback to %s\n", key, value, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
+
 func (u *JWTUtils) GenerateToken(data any, secret []byte) (string, error) {
 	if secret == nil {
 		secret = []byte(os.Getenv(u.DefaultEnvKey))
@@ -32,7 +50,7 @@ func (u *JWTUtils) GenerateToken(data any, secret []byte) (string, error) {
 	claims := jwt.MapClaims{
 		"data": data,
 		"iat":  jwt.NewNumericDate(time.Now()),
-		"exp":  jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // Token valid for 24 hours
+		"exp":  jwt.NewNumericDate(time.Now().Add(u.AccessTokenTTL)),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(secret)
@@ -0,0 +1,45 @@
+package pkg
+
+import (
+	"regexp"
+	"sync"
+	"unicode/utf8"
+)
+
+// MENTION_REGEX @ 前不可為英文字母或數字 (避免比對到 email，中文等文字後仍可提及)，使用者名稱可包含 . 但不以 . 結尾
+var MENTION_REGEX = regexp.MustCompile(`(?:^|[^A-Za-z0-9_.@])@([\p{L}\p{N}_](?:[\p{L}\p{N}_.]*[\p{L}\p{N}_])?)`)
+
+// MentionMatch 內容中的一個提及，Start 與 End 為包含 @ 的字元 (rune) 位置，End 不包含
+type MentionMatch struct {
+	Username string
+	Start    int
+	End      int
+}
+
+type MentionUtils struct{}
+
+var mentionUtilsOnce sync.Once
+var mentionUtils *MentionUtils
+
+func NewMentionUtils() *MentionUtils {
+	mentionUtilsOnce.Do(func() {
+		mentionUtils = &MentionUtils{}
+	})
+	return mentionUtils
+}
+
+// Parse 依出現順序回傳內容中的 @提及
+func (u *MentionUtils) Parse(content string) []MentionMatch {
+	matches := []MentionMatch{}
+	for _, indexes := range MENTION_REGEX.FindAllStringSubmatchIndex(content, -1) {
+		// indexes[2:4] 為使用者名稱的位元組位置，前一個位元組為 @
+		start := utf8.RuneCountInString(content[:indexes[2]-1])
+		username := content[indexes[2]:indexes[3]]
+		matches = append(matches, MentionMatch{
+			Username: username,
+			Start:    start,
+			End:      start + 1 + utf8.RuneCountInString(username),
+		})
+	}
+	return matches
+}
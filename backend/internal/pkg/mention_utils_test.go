@@ -0,0 +1,38 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMentionUtils(t *testing.T) {
+	mentionUtils := NewMentionUtils()
+
+	t.Run("單例模式測試", func(t *testing.T) {
+		assert.Same(t, mentionUtils, NewMentionUtils(), "應該返回相同的實例")
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		assert.Equal(t, []MentionMatch{
+			{Username: "alice", Start: 0, End: 6},
+			{Username: "john.doe", Start: 9, End: 18},
+		}, mentionUtils.Parse("@alice 和 @john.doe."), "結尾的 . 不屬於使用者名稱")
+
+		assert.Equal(t, []MentionMatch{
+			{Username: "小明", Start: 3, End: 6},
+		}, mentionUtils.Parse("哈囉，@小明！"), "位置以字元計算")
+
+		assert.Equal(t, []MentionMatch{
+			{Username: "bob", Start: 1, End: 5},
+		}, mentionUtils.Parse("(@bob)"))
+
+		assert.Equal(t, []MentionMatch{
+			{Username: "alice", Start: 2, End: 8},
+			{Username: "bob", Start: 11, End: 15},
+		}, mentionUtils.Parse("謝謝@alice，你好@bob"), "中文後直接接 @ 仍是提及")
+
+		assert.Empty(t, mentionUtils.Parse("寄信到 alice@example.com"), "email 不是提及")
+		assert.Empty(t, mentionUtils.Parse("@ @@alice #tag"))
+	})
+}
@@ -0,0 +1,256 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	PASSWORD_HASH_ALGORITHM_ARGON2ID = "argon2id"
+	PASSWORD_HASH_ALGORITHM_BCRYPT   = "bcrypt"
+)
+
+// PasswordHasher 密碼雜湊演算法介面，輸出為 PHC 格式字串 (含演算法與參數)
+type PasswordHasher interface {
+	// Algorithm 演算法名稱
+	Algorithm() string
+	// Hash 產生包含鹽值與參數的雜湊字串
+	Hash(password string) (string, error)
+	// Verify 驗證密碼是否符合雜湊字串
+	Verify(encodedHash string, password string) (bool, error)
+	// Match 判斷雜湊字串是否由此演算法產生
+	Match(encodedHash string) bool
+	// NeedsRehash 判斷雜湊字串的參數是否與目前設定不同
+	NeedsRehash(encodedHash string) bool
+}
+
+type PasswordHasherConfig struct {
+	Algorithm string
+
+	// argon2id 參數
+	Argon2Memory      uint32 // KiB
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	Argon2SaltLength  uint32
+	Argon2KeyLength   uint32
+
+	// bcrypt 參數
+	BcryptCost int
+}
+
+func DefaultPasswordHasherConfig() *PasswordHasherConfig {
+	return &PasswordHasherConfig{
+		Algorithm:         PASSWORD_HASH_ALGORITHM_ARGON2ID,
+		Argon2Memory:      64 * 1024,
+		Argon2Iterations:  3,
+		Argon2Parallelism: 2,
+		Argon2SaltLength:  16,
+		Argon2KeyLength:   32,
+		BcryptCost:        bcrypt.DefaultCost,
+	}
+}
+
+// PasswordHasherConfigFromEnv 從環境變數讀取雜湊參數，未設定則使用預設值
+func PasswordHasherConfigFromEnv() (*PasswordHasherConfig, error) {
+	cfg := DefaultPasswordHasherConfig()
+	if algorithm := os.Getenv("PASSWORD_HASH_ALGORITHM"); algorithm != "" {
+		cfg.Algorithm = strings.ToLower(algorithm)
+	}
+	parseUint := func(key string, bitSize int) (uint64, bool, error) {
+		value := os.Getenv(key)
+		if value == "" {
+			return 0, false, nil
+		}
+		parsed, err := strconv.ParseUint(value, 10, bitSize)
+		if err != nil {
+			return 0, false, errors.Wrapf(err, "invalid %s", key)
+		}
+		return parsed, true, nil
+	}
+	if v, ok, err := parseUint("PASSWORD_HASH_ARGON2_MEMORY", 32); err != nil {
+		return nil, err
+	} else if ok {
+		cfg.Argon2Memory = uint32(v)
+	}
+	if v, ok, err := parseUint("PASSWORD_HASH_ARGON2_ITERATIONS", 32); err != nil {
+		return nil, err
+	} else if ok {
+		cfg.Argon2Iterations = uint32(v)
+	}
+	if v, ok, err := parseUint("PASSWORD_HASH_ARGON2_PARALLELISM", 8); err != nil {
+		return nil, err
+	} else if ok {
+		cfg.Argon2Parallelism = uint8(v)
+	}
+	if v, ok, err := parseUint("PASSWORD_HASH_BCRYPT_COST", 32); err != nil {
+		return nil, err
+	} else if ok {
+		cfg.BcryptCost = int(v)
+	}
+	return cfg, nil
+}
+
+// NewPasswordHasher 依設定建立對應演算法的 PasswordHasher
+func NewPasswordHasher(cfg *PasswordHasherConfig) (PasswordHasher, error) {
+	switch cfg.Algorithm {
+	case PASSWORD_HASH_ALGORITHM_ARGON2ID:
+		if cfg.Argon2Memory == 0 || cfg.Argon2Iterations == 0 || cfg.Argon2Parallelism == 0 {
+			return nil, errors.New("argon2id parameters must be greater than 0")
+		}
+		return &Argon2idPasswordHasher{
+			Memory:      cfg.Argon2Memory,
+			Iterations:  cfg.Argon2Iterations,
+			Parallelism: cfg.Argon2Parallelism,
+			SaltLength:  cfg.Argon2SaltLength,
+			KeyLength:   cfg.Argon2KeyLength,
+		}, nil
+	case PASSWORD_HASH_ALGORITHM_BCRYPT:
+		if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+			return nil, errors.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+		return &BcryptPasswordHasher{Cost: cfg.BcryptCost}, nil
+	default:
+		return nil, errors.Errorf("unsupported password hash algorithm: %s", cfg.Algorithm)
+	}
+}
+
+// Argon2idPasswordHasher
+// 格式: $argon2id$v=19$m=65536,t=3,p=2$<base64 salt>$<base64 hash>
+type Argon2idPasswordHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+type argon2idParams struct {
+	version     int
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+func (h *Argon2idPasswordHasher) Algorithm() string {
+	return PASSWORD_HASH_ALGORITHM_ARGON2ID
+}
+
+func (h *Argon2idPasswordHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.Wrap(err, "failed to generate salt")
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Argon2idPasswordHasher) Verify(encodedHash string, password string) (bool, error) {
+	params, err := h.decode(encodedHash)
+	if err != nil {
+		return false, err
+	}
+	key := argon2.IDKey([]byte(password), params.salt, params.iterations, params.memory, params.parallelism, uint32(len(params.key)))
+	return subtle.ConstantTimeCompare(key, params.key) == 1, nil
+}
+
+func (h *Argon2idPasswordHasher) Match(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$argon2id$")
+}
+
+func (h *Argon2idPasswordHasher) NeedsRehash(encodedHash string) bool {
+	params, err := h.decode(encodedHash)
+	if err != nil {
+		return true
+	}
+	return params.version != argon2.Version ||
+		params.memory != h.Memory ||
+		params.iterations != h.Iterations ||
+		params.parallelism != h.Parallelism ||
+		uint32(len(params.salt)) != h.SaltLength ||
+		uint32(len(params.key)) != h.KeyLength
+}
+
+func (h *Argon2idPasswordHasher) decode(encodedHash string) (*argon2idParams, error) {
+	// ["", "argon2id", "v=19", "m=65536,t=3,p=2", "<salt>", "<hash>"]
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != PASSWORD_HASH_ALGORITHM_ARGON2ID {
+		return nil, errors.New("invalid argon2id hash format")
+	}
+	params := &argon2idParams{}
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &params.version); err != nil {
+		return nil, errors.Wrap(err, "invalid argon2id version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return nil, errors.Wrap(err, "invalid argon2id parameters")
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid argon2id salt")
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid argon2id hash")
+	}
+	params.salt = salt
+	params.key = key
+	return params, nil
+}
+
+// BcryptPasswordHasher
+// 格式: $2a$<cost>$<22 chars salt><31 chars hash>
+type BcryptPasswordHasher struct {
+	Cost int
+}
+
+func (h *BcryptPasswordHasher) Algorithm() string {
+	return PASSWORD_HASH_ALGORITHM_BCRYPT
+}
+
+func (h *BcryptPasswordHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate bcrypt hash")
+	}
+	return string(hash), nil
+}
+
+func (h *BcryptPasswordHasher) Verify(encodedHash string, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (h *BcryptPasswordHasher) Match(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$2a$") ||
+		strings.HasPrefix(encodedHash, "$2b$") ||
+		strings.HasPrefix(encodedHash, "$2y$")
+}
+
+func (h *BcryptPasswordHasher) NeedsRehash(encodedHash string) bool {
+	cost, err := bcrypt.Cost([]byte(encodedHash))
+	if err != nil {
+		return true
+	}
+	return cost != h.Cost
+}
@@ -0,0 +1,77 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordHasher(t *testing.T) {
+	t.Run("Argon2id", func(t *testing.T) {
+		cfg := DefaultPasswordHasherConfig()
+		cfg.Argon2Memory = 8 * 1024
+		cfg.Argon2Iterations = 1
+		hasher, err := NewPasswordHasher(cfg)
+		assert.NoError(t, err)
+
+		hash, err := hasher.Hash("password123")
+		assert.NoError(t, err)
+		assert.Regexp(t, `^\$argon2id\$v=19\$m=8192,t=1,p=2\$[^$]+\$[^$]+$`, hash, "Expected PHC formatted hash")
+		assert.True(t, hasher.Match(hash))
+
+		ok, err := hasher.Verify(hash, "password123")
+		assert.NoError(t, err)
+		assert.True(t, ok, "Expected password to be verified")
+		ok, err = hasher.Verify(hash, "wrongpassword")
+		assert.NoError(t, err)
+		assert.False(t, ok, "Expected wrong password to fail")
+		assert.False(t, hasher.NeedsRehash(hash))
+
+		// 提高成本後，舊雜湊仍可驗證但需要重新雜湊
+		cfg.Argon2Iterations = 2
+		stronger, _ := NewPasswordHasher(cfg)
+		ok, _ = stronger.Verify(hash, "password123")
+		assert.True(t, ok, "Expected old parameters to be verified")
+		assert.True(t, stronger.NeedsRehash(hash), "Expected old parameters to need rehash")
+	})
+
+	t.Run("Bcrypt", func(t *testing.T) {
+		cfg := DefaultPasswordHasherConfig()
+		cfg.Algorithm = PASSWORD_HASH_ALGORITHM_BCRYPT
+		cfg.BcryptCost = 4
+		hasher, err := NewPasswordHasher(cfg)
+		assert.NoError(t, err)
+
+		hash, err := hasher.Hash("password123")
+		assert.NoError(t, err)
+		assert.True(t, hasher.Match(hash))
+		ok, _ := hasher.Verify(hash, "password123")
+		assert.True(t, ok, "Expected password to be verified")
+		ok, _ = hasher.Verify(hash, "wrongpassword")
+		assert.False(t, ok, "Expected wrong password to fail")
+
+		cfg.BcryptCost = 5
+		stronger, _ := NewPasswordHasher(cfg)
+		assert.True(t, stronger.NeedsRehash(hash), "Expected lower cost to need rehash")
+	})
+
+	t.Run("Unsupported Algorithm", func(t *testing.T) {
+		cfg := DefaultPasswordHasherConfig()
+		cfg.Algorithm = "md5"
+		_, err := NewPasswordHasher(cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("Config From Env", func(t *testing.T) {
+		t.Setenv("PASSWORD_HASH_ALGORITHM", "BCRYPT")
+		t.Setenv("PASSWORD_HASH_BCRYPT_COST", "11")
+		cfg, err := PasswordHasherConfigFromEnv()
+		assert.NoError(t, err)
+		assert.Equal(t, PASSWORD_HASH_ALGORITHM_BCRYPT, cfg.Algorithm)
+		assert.Equal(t, 11, cfg.BcryptCost)
+
+		t.Setenv("PASSWORD_HASH_ARGON2_ITERATIONS", "abc")
+		_, err = PasswordHasherConfigFromEnv()
+		assert.Error(t, err)
+	})
+}
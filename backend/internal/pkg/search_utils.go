@@ -0,0 +1,168 @@
+package pkg
+
+import (
+	"html"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const SEARCH_HIGHLIGHT_OPEN_TAG = "<mark>"
+const SEARCH_HIGHLIGHT_CLOSE_TAG = "</mark>"
+
+// SearchUtils 全文搜尋的斷詞與摘要
+// 資料庫內建的斷詞器無法切分中文，因此由應用程式斷詞：
+// 英文與數字以單字為單位，連續的中日韓文字切成重疊的兩字詞 (bigram)，例如 "台北市" -> "台北" "北市"
+type SearchUtils struct{}
+
+var searchUtilsOnce sync.Once
+var searchUtils *SearchUtils
+
+func NewSearchUtils() *SearchUtils {
+	searchUtilsOnce.Do(func() {
+		searchUtils = &SearchUtils{}
+	})
+	return searchUtils
+}
+
+// Segments 將文字切成連續的英數字或中日韓文字片段 (轉為小寫)，標點與空白視為分隔
+func (u *SearchUtils) Segments(text string) []string {
+	segments := []string{}
+	segment := []rune{}
+	segmentIsCJK := false
+	flush := func() {
+		if len(segment) > 0 {
+			segments = append(segments, string(segment))
+			segment = segment[:0]
+		}
+	}
+	for _, r := range text {
+		r = unicode.ToLower(r)
+		switch {
+		case u.isCJK(r):
+			if !segmentIsCJK {
+				flush()
+			}
+			segmentIsCJK = true
+			segment = append(segment, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if segmentIsCJK {
+				flush()
+			}
+			segmentIsCJK = false
+			segment = append(segment, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return segments
+}
+
+// Tokenize 回傳文字依序的索引詞，單一中日韓文字的片段保留為單字
+func (u *SearchUtils) Tokenize(text string) []string {
+	tokens := []string{}
+	for _, segment := range u.Segments(text) {
+		tokens = append(tokens, u.segmentTokens(segment)...)
+	}
+	return tokens
+}
+
+// BuildSearchText 將索引詞以空白串接，存入資料庫供全文索引使用
+func (u *SearchUtils) BuildSearchText(text string) string {
+	return strings.Join(u.Tokenize(text), " ")
+}
+
+// QueryTerms 回傳每個查詢字詞依序的索引詞 (需相鄰出現)，不含索引詞的字詞會被忽略
+func (u *SearchUtils) QueryTerms(texts []string) [][]string {
+	terms := [][]string{}
+	for _, text := range texts {
+		if tokens := u.Tokenize(text); len(tokens) > 0 {
+			terms = append(terms, tokens)
+		}
+	}
+	return terms
+}
+
+// IsCJKPrefixTerm 單一中日韓文字無法與兩字詞完全比對，查詢時以前綴比對
+func (u *SearchUtils) IsCJKPrefixTerm(tokens []string) bool {
+	if len(tokens) != 1 {
+		return false
+	}
+	runes := []rune(tokens[0])
+	return len(runes) == 1 && u.isCJK(runes[0])
+}
+
+// Highlight 擷取 content 中第一個符合片段附近最多 maxRunes 字的摘要，符合的片段以 <mark> 標示
+// 其餘文字會經過 HTML 跳脫，沒有符合的片段時回傳開頭的摘要
+func (u *SearchUtils) Highlight(content string, segments []string, maxRunes int) string {
+	runes := []rune(content)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	marked := make([]bool, len(runes))
+	first := -1
+	for _, segment := range segments {
+		segmentRunes := []rune(segment)
+		if len(segmentRunes) == 0 {
+			continue
+		}
+		for i := 0; i+len(segmentRunes) <= len(lower); i++ {
+			if string(lower[i:i+len(segmentRunes)]) != segment {
+				continue
+			}
+			for j := i; j < i+len(segmentRunes); j++ {
+				marked[j] = true
+			}
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+
+	// 讓第一個符合的片段前保留部分上下文
+	start := 0
+	if first > maxRunes/4 {
+		start = first - maxRunes/4
+	}
+	end := min(len(runes), start+maxRunes)
+	if end-start < maxRunes {
+		start = max(0, end-maxRunes)
+	}
+
+	builder := strings.Builder{}
+	if start > 0 {
+		builder.WriteString("…")
+	}
+	for i := start; i < end; i++ {
+		if marked[i] && (i == start || !marked[i-1]) {
+			builder.WriteString(SEARCH_HIGHLIGHT_OPEN_TAG)
+		}
+		builder.WriteString(html.EscapeString(string(runes[i])))
+		if marked[i] && (i+1 == end || !marked[i+1]) {
+			builder.WriteString(SEARCH_HIGHLIGHT_CLOSE_TAG)
+		}
+	}
+	if end < len(runes) {
+		builder.WriteString("…")
+	}
+	return builder.String()
+}
+
+func (u *SearchUtils) segmentTokens(segment string) []string {
+	runes := []rune(segment)
+	if len(runes) < 2 || !u.isCJK(runes[0]) {
+		return []string{segment}
+	}
+	tokens := make([]string, 0, len(runes)-1)
+	for i := 0; i+1 < len(runes); i++ {
+		tokens = append(tokens, string(runes[i:i+2]))
+	}
+	return tokens
+}
+
+func (u *SearchUtils) isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
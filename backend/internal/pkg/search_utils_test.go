@@ -0,0 +1,38 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchUtils(t *testing.T) {
+	searchUtils := NewSearchUtils()
+
+	t.Run("Tokenize", func(t *testing.T) {
+		assert.Equal(t, []string{"台北", "北市", "iphone", "15", "好"}, searchUtils.Tokenize("台北市 iPhone-15，好！"))
+		assert.Equal(t, []string{"測試", "post"}, searchUtils.Tokenize("#測試 #Post"))
+		assert.Equal(t, []string{"中文", "abc", "文字"}, searchUtils.Tokenize("中文abc文字"), "中英文交界應該切開")
+		assert.Empty(t, searchUtils.Tokenize("!!! ..."))
+	})
+
+	t.Run("QueryTerms", func(t *testing.T) {
+		assert.Equal(t, [][]string{{"台北", "北市"}, {"go"}}, searchUtils.QueryTerms([]string{"台北市", "Go", "..."}))
+		assert.Equal(t, [][]string{{"hello", "world"}}, searchUtils.QueryTerms([]string{"Hello, world!"}), "片語的索引詞需相鄰出現")
+		assert.True(t, searchUtils.IsCJKPrefixTerm([]string{"台"}))
+		assert.False(t, searchUtils.IsCJKPrefixTerm([]string{"a"}))
+		assert.False(t, searchUtils.IsCJKPrefixTerm([]string{"台北"}))
+	})
+
+	t.Run("Highlight", func(t *testing.T) {
+		assert.Equal(t, "我住在<mark>台北市</mark>的 <mark>Go</mark> 社群",
+			searchUtils.Highlight("我住在台北市的 Go 社群", []string{"台北市", "go"}, 100))
+		assert.Equal(t, "&lt;b&gt;<mark>台北</mark>",
+			searchUtils.Highlight("<b>台北", []string{"台北"}, 100), "內容應該經過 HTML 跳脫")
+		assert.Equal(t, "…5<mark>6</mark>789a…",
+			searchUtils.Highlight("0123456789abcdef", []string{"6"}, 6), "摘要應該包含符合片段附近的文字")
+		assert.Equal(t, "…456<mark>7</mark>89",
+			searchUtils.Highlight("0123456789", []string{"7"}, 6), "接近結尾時摘要往前延伸")
+		assert.Equal(t, "abc…", searchUtils.Highlight("abcdef", []string{"x"}, 3), "沒有符合時回傳開頭")
+	})
+}
@@ -0,0 +1,53 @@
+package pkg
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TAG_NAME_MAX_LENGTH 標籤名稱最多的字元數 (正規化後)
+const TAG_NAME_MAX_LENGTH = 50
+
+// TagUtils 標籤名稱正規化，讓 #Go、#go 與 #go, 視為同一個標籤
+type TagUtils struct {
+	caser cases.Caser
+}
+
+var tagUtilsOnce sync.Once
+var tagUtils *TagUtils
+
+func NewTagUtils() *TagUtils {
+	tagUtilsOnce.Do(func() {
+		tagUtils = &TagUtils{
+			caser: cases.Fold(),
+		}
+	})
+	return tagUtils
+}
+
+// Normalize 回傳正規化的標籤名稱：Unicode NFKC、case folding，
+// 只保留文字、數字、組合符號、底線與連字號 (開頭與結尾的連字號會被移除)
+// 正規化後為空或超過 TAG_NAME_MAX_LENGTH 字元時回傳 false
+func (u *TagUtils) Normalize(name string) (string, bool) {
+	// cases.Caser 不可同時使用，每次複製一份
+	caser := u.caser
+	folded := caser.String(norm.NFKC.String(name))
+
+	builder := strings.Builder{}
+	for _, r := range folded {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_' || r == '-' {
+			builder.WriteRune(r)
+		}
+	}
+	// 折疊後可能產生非 NFKC 的組合，再正規化一次
+	normalized := norm.NFKC.String(strings.Trim(builder.String(), "-"))
+	if normalized == "" || utf8.RuneCountInString(normalized) > TAG_NAME_MAX_LENGTH {
+		return "", false
+	}
+	return normalized, true
+}
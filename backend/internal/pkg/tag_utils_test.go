@@ -0,0 +1,46 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagUtils(t *testing.T) {
+	tagUtils := NewTagUtils()
+
+	t.Run("單例模式測試", func(t *testing.T) {
+		assert.Same(t, tagUtils, NewTagUtils(), "應該返回相同的實例")
+	})
+
+	t.Run("Normalize", func(t *testing.T) {
+		for name, expected := range map[string]string{
+			"Go":           "go",
+			"go,":          "go",
+			"「台北」":         "台北",
+			"ＧＯ１２３":        "go123",
+			"Straße":       "strasse",
+			"go_lang":      "go_lang",
+			"-covid-19-":   "covid-19",
+			"don't":        "dont",
+			"Café":         "café",
+			"ｶﾀｶﾅ":         "カタカナ",
+			"C++/C#":       "cc",
+			"e\u0301clair": "\u00e9clair", // 組合字元
+		} {
+			normalized, ok := tagUtils.Normalize(name)
+			assert.True(t, ok, name)
+			assert.Equal(t, expected, normalized, name)
+		}
+	})
+
+	t.Run("Normalize - 無效的名稱", func(t *testing.T) {
+		for _, name := range []string{"", "!!!", "---", "🎉", strings.Repeat("a", TAG_NAME_MAX_LENGTH+1)} {
+			_, ok := tagUtils.Normalize(name)
+			assert.False(t, ok, name)
+		}
+		_, ok := tagUtils.Normalize(strings.Repeat("台", TAG_NAME_MAX_LENGTH))
+		assert.True(t, ok, "長度以字元計算")
+	})
+}
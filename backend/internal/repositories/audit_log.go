@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+type AuditLogRepository struct {
+	ErrorUtils *pkg.ErrorUtils
+}
+
+var auditLogRepositoryOnce sync.Once
+var auditLogRepository *AuditLogRepository
+
+func NewAuditLogRepository() *AuditLogRepository {
+	auditLogRepositoryOnce.Do(func() {
+		auditLogRepository = &AuditLogRepository{
+			ErrorUtils: pkg.NewErrorUtils(),
+		}
+	})
+	return auditLogRepository
+}
+
+func (r *AuditLogRepository) Create(ctx *gin.Context, auditLogBase models.AuditLogBase) (*models.AuditLog, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 使用 UUIDv7 (依時間遞增)，讓同一秒的紀錄依建立順序排列
+	auditLogID, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+	auditLog := &models.AuditLog{
+		TableModel:   models.TableModel{ID: auditLogID},
+		AuditLogBase: auditLogBase,
+	}
+	if err := db.Create(auditLog).Error; err != nil {
+		return nil, err
+	}
+	return auditLog, nil
+}
+
+// GetList 依建立時間由新到舊排序，targetID 不為 nil 時只回傳對該內容的處置
+func (r *AuditLogRepository) GetList(ctx *gin.Context, targetID *uuid.UUID, pagination *models.Pagination) ([]models.AuditLog, uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	db = db.Model(&models.AuditLog{})
+	if targetID != nil {
+		db = db.Where("target_id = ?", *targetID)
+	}
+	db = db.Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Name: "created_at"}, Desc: true},
+		{Column: clause.Column{Name: "id"}, Desc: true},
+	}})
+
+	totalCount := int64(0)
+	if err := db.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+	if pagination != nil {
+		if pagination.Limit <= 0 {
+			return nil, 0, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+		}
+		db = db.Offset(int(pagination.Offset)).Limit(int(pagination.Limit))
+	}
+	auditLogs := []models.AuditLog{}
+	if err := db.Find(&auditLogs).Error; err != nil {
+		return nil, 0, err
+	}
+	return auditLogs, uint(totalCount), nil
+}
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BlockRepository struct {
+	ErrorUtils *pkg.ErrorUtils
+}
+
+var blockRepositoryOnce sync.Once
+var blockRepository *BlockRepository
+
+func NewBlockRepository() *BlockRepository {
+	blockRepositoryOnce.Do(func() {
+		blockRepository = &BlockRepository{
+			ErrorUtils: pkg.NewErrorUtils(),
+		}
+	})
+	return blockRepository
+}
+
+// excludeBlockedUsers 排除 column (使用者 ID 欄位) 與 viewerID 之間有封鎖關係 (不論是誰封鎖誰) 的資料，viewerID 為 nil 時不過濾
+func excludeBlockedUsers(db *gorm.DB, column string, viewerID *uuid.UUID) *gorm.DB {
+	if viewerID == nil {
+		return db
+	}
+	return db.Where(
+		column+" NOT IN (SELECT blocks.blocked_id FROM blocks WHERE blocks.blocker_id = ?) AND "+
+			column+" NOT IN (SELECT blocks.blocker_id FROM blocks WHERE blocks.blocked_id = ?)",
+		*viewerID, *viewerID,
+	)
+}
+
+// Block 重複封鎖不會產生錯誤，回傳是否為新增的封鎖
+func (r *BlockRepository) Block(ctx *gin.Context, blockerID uuid.UUID, blockedID uuid.UUID) (bool, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return false, err
+	}
+	block := &models.Block{
+		TableModel: models.TableModel{ID: uuid.New()},
+		BlockBase: models.BlockBase{
+			BlockerID: blockerID,
+			BlockedID: blockedID,
+		},
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Omit("Blocker", "Blocked").Create(block)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Unblock 未封鎖時不會產生錯誤，回傳是否有解除封鎖
+func (r *BlockRepository) Unblock(ctx *gin.Context, blockerID uuid.UUID, blockedID uuid.UUID) (bool, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return false, err
+	}
+	result := db.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&models.Block{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ExistsBetween userID 與 otherUserIDs 中任一使用者之間有封鎖關係 (不論是誰封鎖誰)
+func (r *BlockRepository) ExistsBetween(ctx *gin.Context, userID uuid.UUID, otherUserIDs []uuid.UUID) (bool, error) {
+	if len(otherUserIDs) == 0 {
+		return false, nil
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return false, err
+	}
+	count := int64(0)
+	if err := db.Model(&models.Block{}).
+		Where("(blocker_id = ? AND blocked_id IN ?) OR (blocked_id = ? AND blocker_id IN ?)", userID, otherUserIDs, userID, otherUserIDs).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetBlockedUsers 回傳 userID 封鎖的使用者，依封鎖時間由新到舊排序
+func (r *BlockRepository) GetBlockedUsers(ctx *gin.Context, userID uuid.UUID, pagination *models.Pagination) ([]models.User, uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	db = db.Model(&models.User{}).
+		Joins("JOIN blocks ON blocks.blocked_id = users.id").
+		Where("blocks.blocker_id = ?", userID).
+		Order(clause.OrderByColumn{
+			Column: clause.Column{Table: "blocks", Name: "created_at"},
+			Desc:   true,
+		})
+
+	totalCount := int64(0)
+	if err := db.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+	if pagination != nil {
+		if pagination.Limit <= 0 {
+			return nil, 0, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+		}
+		db = db.Offset(int(pagination.Offset)).Limit(int(pagination.Limit))
+	}
+	users := []models.User{}
+	if err := db.Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, uint(totalCount), nil
+}
+
+// DeleteByUserID 刪除使用者所有的封鎖與被封鎖關係
+func (r *BlockRepository) DeleteByUserID(ctx *gin.Context, userID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Where("blocker_id = ? OR blocked_id = ?", userID, userID).Delete(&models.Block{}).Error
+}
@@ -3,13 +3,16 @@ package repositories
 import (
 	"backend/internal/middlewares"
 	"backend/internal/models"
+	"backend/internal/pkg"
 	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type CommentRepository struct {
+	ErrorUtils *pkg.ErrorUtils
 }
 
 var commentRepositoryOnce sync.Once
@@ -17,7 +20,9 @@ var commentRepository *CommentRepository
 
 func NewCommentRepository() *CommentRepository {
 	commentRepositoryOnce.Do(func() {
-		commentRepository = &CommentRepository{}
+		commentRepository = &CommentRepository{
+			ErrorUtils: pkg.NewErrorUtils(),
+		}
 	})
 	return commentRepository
 }
@@ -44,33 +49,199 @@ func (r *CommentRepository) Create(ctx *gin.Context, commentBase []models.Commen
 	return comment, nil
 }
 
+// excludeHiddenComments 排除被管理員隱藏或等待審核的評論
+func excludeHiddenComments(db *gorm.DB) *gorm.DB {
+	return db.Where("comments.hidden_at IS NULL")
+}
+
+// GetByID 被隱藏的評論視為不存在
 func (r *CommentRepository) GetByID(ctx *gin.Context, commentID uuid.UUID) (*models.Comment, error) {
+	return r.getByID(ctx, commentID, excludeHiddenComments)
+}
+
+// GetHiddenByID 只回傳被隱藏 (被管理員隱藏或等待審核) 的評論
+func (r *CommentRepository) GetHiddenByID(ctx *gin.Context, commentID uuid.UUID) (*models.Comment, error) {
+	return r.getByID(ctx, commentID, func(db *gorm.DB) *gorm.DB {
+		return db.Where("comments.hidden_at IS NOT NULL")
+	})
+}
+
+func (r *CommentRepository) getByID(ctx *gin.Context, commentID uuid.UUID, scope func(db *gorm.DB) *gorm.DB) (*models.Comment, error) {
 	db, err := middlewares.GetContentGORMDB(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	var comment models.Comment
-	if err := db.First(&comment, "id = ?", commentID).Error; err != nil {
+	if err := scope(db.Model(&models.Comment{})).Preload("Mentions", preloadMentions).First(&comment, "id = ?", commentID).Error; err != nil {
 		return nil, err
 	}
 
 	return &comment, nil
 }
 
-func (r *CommentRepository) GetListByPostID(ctx *gin.Context, postID uuid.UUID) ([]models.Comment, error) {
+// GetListByPostID 回傳貼文所有評論 (不含被隱藏的評論)，依 created_at 由舊到新排序，viewerID 不為 nil 時排除與其有封鎖關係的使用者的評論
+func (r *CommentRepository) GetListByPostID(ctx *gin.Context, postID uuid.UUID, viewerID *uuid.UUID) ([]models.Comment, error) {
 	db, err := middlewares.GetContentGORMDB(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	comments := []models.Comment{}
-	if err := db.Where("post_id = ?", postID).
+	if err := excludeBlockedUsers(excludeHiddenComments(db.Model(&models.Comment{})), "comments.user_id", viewerID).
+		Where("post_id = ?", postID).
 		Order("created_at ASC").
 		Preload("User").
+		Preload("Mentions", preloadMentions).
 		Find(&comments).Error; err != nil {
 		return nil, err
 	}
 
 	return comments, nil
 }
+
+// GetRootListByPostIDByCursor 依 (created_at, id) 由舊到新分頁貼文的根評論，回傳下一頁的位置與總筆數 (未要求時為 nil)，
+// 過濾方式同 GetListByPostID
+func (r *CommentRepository) GetRootListByPostIDByCursor(ctx *gin.Context, postID uuid.UUID, viewerID *uuid.UUID, pagination *models.CursorPagination) ([]models.Comment, *models.Cursor, *uint, error) {
+	if pagination == nil || pagination.Limit <= 0 {
+		return nil, nil, nil, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	db = excludeBlockedUsers(excludeHiddenComments(db.Model(&models.Comment{})), "comments.user_id", viewerID).
+		Where("post_id = ? AND parent_id IS NULL", postID).
+		Preload("User").
+		Preload("Mentions", preloadMentions)
+	totalCount, err := countByCursorPagination(db, pagination)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	comments := []models.Comment{}
+	if err := paginateByCursor(db, "comments", pagination, false).Find(&comments).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	comments, nextCursor := takeCursorPage(comments, pagination.Limit)
+	return comments, nextCursor, totalCount, nil
+}
+
+// GetDescendantsByIDs 逐層查詢評論的所有回覆 (不含 commentIDs 本身)，同層依 created_at 由舊到新排序，
+// 被隱藏或與 viewerID 有封鎖關係的使用者的評論及其回覆不會被查詢
+func (r *CommentRepository) GetDescendantsByIDs(ctx *gin.Context, commentIDs []uuid.UUID, viewerID *uuid.UUID) ([]models.Comment, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	descendants := []models.Comment{}
+	visited := make(map[uuid.UUID]bool, len(commentIDs))
+	for _, commentID := range commentIDs {
+		visited[commentID] = true
+	}
+	parentIDs := commentIDs
+	for len(parentIDs) > 0 {
+		children := []models.Comment{}
+		if err := excludeBlockedUsers(excludeHiddenComments(db.Model(&models.Comment{})), "comments.user_id", viewerID).
+			Where("parent_id IN ?", parentIDs).
+			Order("created_at ASC").
+			Preload("User").
+			Preload("Mentions", preloadMentions).
+			Find(&children).Error; err != nil {
+			return nil, err
+		}
+		parentIDs = make([]uuid.UUID, 0, len(children))
+		for _, child := range children {
+			// 避免資料中的循環造成無限查詢
+			if visited[child.ID] {
+				continue
+			}
+			visited[child.ID] = true
+			descendants = append(descendants, child)
+			parentIDs = append(parentIDs, child.ID)
+		}
+	}
+	return descendants, nil
+}
+
+func (r *CommentRepository) UpdateByID(ctx *gin.Context, commentID uuid.UUID, updates map[string]any) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Model(&models.Comment{TableModel: models.TableModel{ID: commentID}}).Updates(updates).Error
+}
+
+// CountReplies 回傳評論的直接回覆數 (包含墓碑評論)
+func (r *CommentRepository) CountReplies(ctx *gin.Context, commentID uuid.UUID) (uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := int64(0)
+	if err := db.Model(&models.Comment{}).Where("parent_id = ?", commentID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return uint(count), nil
+}
+
+// DeleteByIDs 永久刪除評論以及其編輯紀錄、提及與通知
+func (r *CommentRepository) DeleteByIDs(ctx *gin.Context, commentIDs []uuid.UUID) error {
+	if len(commentIDs) == 0 {
+		return nil
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	if err := db.Where("comment_id IN ?", commentIDs).Delete(&models.CommentEditHistory{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("comment_id IN ?", commentIDs).Delete(&models.Mention{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("comment_id IN ?", commentIDs).Delete(&models.Notification{}).Error; err != nil {
+		return err
+	}
+	return db.Where("id IN ?", commentIDs).Delete(&models.Comment{}).Error
+}
+
+// GetPostIDsByUserID 回傳使用者評論過的所有貼文
+func (r *CommentRepository) GetPostIDsByUserID(ctx *gin.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	postIDs := []uuid.UUID{}
+	if err := db.Model(&models.Comment{}).
+		Where("user_id = ?", userID).
+		Distinct().
+		Pluck("post_id", &postIDs).Error; err != nil {
+		return nil, err
+	}
+	return postIDs, nil
+}
+
+// DeleteByUserID 刪除使用者所有評論，回覆這些評論的子評論改為根評論
+func (r *CommentRepository) DeleteByUserID(ctx *gin.Context, userID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	commentIDs := []uuid.UUID{}
+	if err := db.Model(&models.Comment{}).
+		Where("user_id = ?", userID).
+		Pluck("id", &commentIDs).Error; err != nil {
+		return err
+	}
+	if len(commentIDs) == 0 {
+		return nil
+	}
+	if err := db.Model(&models.Comment{}).
+		Where("parent_id IN ? AND user_id <> ?", commentIDs, userID).
+		Update("parent_id", nil).Error; err != nil {
+		return err
+	}
+	return r.DeleteByIDs(ctx, commentIDs)
+}
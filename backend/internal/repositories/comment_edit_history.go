@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CommentEditHistoryRepository struct{}
+
+var commentEditHistoryRepositoryOnce sync.Once
+var commentEditHistoryRepository *CommentEditHistoryRepository
+
+func NewCommentEditHistoryRepository() *CommentEditHistoryRepository {
+	commentEditHistoryRepositoryOnce.Do(func() {
+		commentEditHistoryRepository = &CommentEditHistoryRepository{}
+	})
+	return commentEditHistoryRepository
+}
+
+func (r *CommentEditHistoryRepository) Create(ctx *gin.Context, historyBases []models.CommentEditHistoryBase) ([]models.CommentEditHistory, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	histories := make([]models.CommentEditHistory, len(historyBases))
+	for i, base := range historyBases {
+		histories[i] = models.CommentEditHistory{
+			TableModel:             models.TableModel{ID: uuid.New()},
+			CommentEditHistoryBase: base,
+		}
+	}
+	if err := db.Create(&histories).Error; err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
+
+// GetListByCommentID 依時間由舊到新排序
+func (r *CommentEditHistoryRepository) GetListByCommentID(ctx *gin.Context, commentID uuid.UUID) ([]models.CommentEditHistory, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	histories := []models.CommentEditHistory{}
+	if err := db.Where("comment_id = ?", commentID).
+		Order("created_at ASC").
+		Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ConversationRepository struct {
+	ErrorUtils *pkg.ErrorUtils
+}
+
+var conversationRepositoryOnce sync.Once
+var conversationRepository *ConversationRepository
+
+func NewConversationRepository() *ConversationRepository {
+	conversationRepositoryOnce.Do(func() {
+		conversationRepository = &ConversationRepository{
+			ErrorUtils: pkg.NewErrorUtils(),
+		}
+	})
+	return conversationRepository
+}
+
+func preloadParticipants(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at ASC").Order("id ASC").Preload("User")
+}
+
+// Create 建立對話與其參與者，LastMessageAt 設為建立時間，
+// 相同 DirectKey 的一對一對話已存在時不建立，回傳既有的對話 (created 為 false，不包含參與者)
+func (r *ConversationRepository) Create(ctx *gin.Context, conversationBase models.ConversationBase, userIDs []uuid.UUID) (conversation *models.Conversation, created bool, err error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	conversationBase.LastMessageAt = time.Now().Unix()
+	conversationBase.Participants = nil
+	conversation = &models.Conversation{
+		TableModel:       models.TableModel{ID: uuid.New()},
+		ConversationBase: conversationBase,
+	}
+	// 同時建立相同的一對一對話時，由 direct_key 的唯一索引決定只有一個成功
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(conversation)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		conversation = &models.Conversation{}
+		if err := db.Where("direct_key = ?", conversationBase.DirectKey).First(conversation).Error; err != nil {
+			return nil, false, err
+		}
+		return conversation, false, nil
+	}
+	participants := make([]models.ConversationParticipant, len(userIDs))
+	for i, userID := range userIDs {
+		participants[i] = models.ConversationParticipant{
+			TableModel: models.TableModel{ID: uuid.New()},
+			ConversationParticipantBase: models.ConversationParticipantBase{
+				ConversationID: conversation.ID,
+				UserID:         userID,
+			},
+		}
+	}
+	if err := db.Omit("User").Create(participants).Error; err != nil {
+		return nil, false, err
+	}
+	conversation.Participants = participants
+	return conversation, true, nil
+}
+
+// GetByID 包含參與者 (依加入順序)
+func (r *ConversationRepository) GetByID(ctx *gin.Context, conversationID uuid.UUID) (*models.Conversation, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conversation := &models.Conversation{}
+	if err := db.Preload("Participants", preloadParticipants).
+		First(conversation, "id = ?", conversationID).Error; err != nil {
+		return nil, err
+	}
+	return conversation, nil
+}
+
+// GetListByUserID 回傳使用者參與的對話 (包含參與者)，依最後活動時間由新到舊排序
+func (r *ConversationRepository) GetListByUserID(ctx *gin.Context, userID uuid.UUID, pagination *models.Pagination) ([]models.Conversation, uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	db = db.Model(&models.Conversation{}).
+		Where("id IN (?)", db.Model(&models.ConversationParticipant{}).Select("conversation_id").Where("user_id = ?", userID))
+
+	totalCount := int64(0)
+	if err := db.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+	if pagination != nil {
+		if pagination.Limit <= 0 {
+			return nil, 0, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+		}
+		db = db.Offset(int(pagination.Offset)).Limit(int(pagination.Limit))
+	}
+	conversations := []models.Conversation{}
+	if err := db.Order("last_message_at DESC").Order("id DESC").
+		Preload("Participants", preloadParticipants).
+		Find(&conversations).Error; err != nil {
+		return nil, 0, err
+	}
+	return conversations, uint(totalCount), nil
+}
+
+func (r *ConversationRepository) UpdateLastMessageAt(ctx *gin.Context, conversationID uuid.UUID, lastMessageAt int64) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Model(&models.Conversation{}).
+		Where("id = ?", conversationID).
+		Update("last_message_at", lastMessageAt).Error
+}
+
+// UpdateLastRead 將參與者的已讀位置移到 message，已讀位置只會往後移動，回傳是否有更新
+func (r *ConversationRepository) UpdateLastRead(ctx *gin.Context, conversationID uuid.UUID, userID uuid.UUID, message *models.Message) (bool, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return false, err
+	}
+	result := db.Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Where("last_read_message_id IS NULL OR last_read_at < ? OR (last_read_at = ? AND last_read_message_id < ?)", message.CreatedAt, message.CreatedAt, message.ID).
+		Updates(map[string]any{
+			"last_read_message_id": message.ID,
+			"last_read_at":         message.CreatedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteByUserID 移除使用者參與的對話，沒有參與者的對話與其訊息一併刪除
+func (r *ConversationRepository) DeleteByUserID(ctx *gin.Context, userID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	if err := db.Where("user_id = ?", userID).Delete(&models.ConversationParticipant{}).Error; err != nil {
+		return err
+	}
+	emptyConversationIDs := []uuid.UUID{}
+	if err := db.Model(&models.Conversation{}).
+		Where("id NOT IN (?)", db.Model(&models.ConversationParticipant{}).Select("conversation_id")).
+		Pluck("id", &emptyConversationIDs).Error; err != nil {
+		return err
+	}
+	if len(emptyConversationIDs) == 0 {
+		return nil
+	}
+	if err := db.Where("conversation_id IN ?", emptyConversationIDs).Delete(&models.Message{}).Error; err != nil {
+		return err
+	}
+	return db.Where("id IN ?", emptyConversationIDs).Delete(&models.Conversation{}).Error
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"backend/internal/models"
+	"backend/internal/pkg"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// countByCursorPagination 只在 WithTotalCount 時執行 COUNT，需在套用 cursor 條件前呼叫
+func countByCursorPagination(db *gorm.DB, pagination *models.CursorPagination) (*uint, error) {
+	if !pagination.WithTotalCount {
+		return nil, nil
+	}
+	totalCount := int64(0)
+	if err := db.Count(&totalCount).Error; err != nil {
+		return nil, err
+	}
+	return pkg.GetPointer(uint(totalCount)), nil
+}
+
+// paginateByCursor 依 (created_at, id) 排序並從 Cursor 之後開始，desc 為 true 時由新到舊
+// 多取一筆以判斷是否有下一頁，查詢結果需交由 takeCursorPage 處理
+func paginateByCursor(db *gorm.DB, table string, pagination *models.CursorPagination, desc bool) *gorm.DB {
+	if pagination.Cursor != nil {
+		operator := ">"
+		if desc {
+			operator = "<"
+		}
+		db = db.Where(
+			"("+table+".created_at "+operator+" ? OR ("+table+".created_at = ? AND "+table+".id "+operator+" ?))",
+			pagination.Cursor.CreatedAt, pagination.Cursor.CreatedAt, pagination.Cursor.ID,
+		)
+	}
+	return db.
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Table: table, Name: "created_at"}, Desc: desc},
+			{Column: clause.Column{Table: table, Name: "id"}, Desc: desc},
+		}}).
+		Limit(int(pagination.Limit) + 1)
+}
+
+// takeCursorPage 截取一頁資料，回傳下一頁的位置 (沒有下一頁時為 nil)
+func takeCursorPage[T interface{ GetCursor() models.Cursor }](items []T, limit uint) ([]T, *models.Cursor) {
+	if uint(len(items)) <= limit {
+		return items, nil
+	}
+	items = items[:limit]
+	nextCursor := items[len(items)-1].GetCursor()
+	return items, &nextCursor
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+type FollowRepository struct {
+	ErrorUtils *pkg.ErrorUtils
+}
+
+var followRepositoryOnce sync.Once
+var followRepository *FollowRepository
+
+func NewFollowRepository() *FollowRepository {
+	followRepositoryOnce.Do(func() {
+		followRepository = &FollowRepository{
+			ErrorUtils: pkg.NewErrorUtils(),
+		}
+	})
+	return followRepository
+}
+
+// Follow 重複追蹤不會產生錯誤，回傳是否為新增的追蹤
+func (r *FollowRepository) Follow(ctx *gin.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return false, err
+	}
+	follow := &models.Follow{
+		TableModel: models.TableModel{ID: uuid.New()},
+		FollowBase: models.FollowBase{
+			FollowerID: followerID,
+			FolloweeID: followeeID,
+		},
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(follow)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Unfollow 未追蹤時不會產生錯誤，回傳是否有移除追蹤
+func (r *FollowRepository) Unfollow(ctx *gin.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return false, err
+	}
+	result := db.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&models.Follow{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *FollowRepository) IsFollowing(ctx *gin.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return false, err
+	}
+	count := int64(0)
+	if err := db.Model(&models.Follow{}).
+		Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetFollowers 回傳追蹤 userID 的使用者，依追蹤時間由新到舊排序
+func (r *FollowRepository) GetFollowers(ctx *gin.Context, userID uuid.UUID, pagination *models.Pagination) ([]models.User, uint, error) {
+	return r.getUsers(ctx, "follows.follower_id", "follows.followee_id", userID, pagination)
+}
+
+// GetFollowing 回傳 userID 追蹤的使用者，依追蹤時間由新到舊排序
+func (r *FollowRepository) GetFollowing(ctx *gin.Context, userID uuid.UUID, pagination *models.Pagination) ([]models.User, uint, error) {
+	return r.getUsers(ctx, "follows.followee_id", "follows.follower_id", userID, pagination)
+}
+
+func (r *FollowRepository) getUsers(ctx *gin.Context, joinColumn string, whereColumn string, userID uuid.UUID, pagination *models.Pagination) ([]models.User, uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	db = db.Model(&models.User{}).
+		Joins("JOIN follows ON "+joinColumn+" = users.id").
+		Where(whereColumn+" = ?", userID).
+		Order(clause.OrderByColumn{
+			Column: clause.Column{Table: "follows", Name: "created_at"},
+			Desc:   true,
+		})
+
+	totalCount := int64(0)
+	if err := db.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+	if pagination != nil {
+		if pagination.Limit <= 0 {
+			return nil, 0, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+		}
+		db = db.Offset(int(pagination.Offset)).Limit(int(pagination.Limit))
+	}
+	users := []models.User{}
+	if err := db.Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, uint(totalCount), nil
+}
+
+// DeleteByUserID 刪除使用者所有的追蹤與被追蹤關係
+func (r *FollowRepository) DeleteByUserID(ctx *gin.Context, userID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Where("follower_id = ? OR followee_id = ?", userID, userID).Delete(&models.Follow{}).Error
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MentionRepository struct{}
+
+var mentionRepositoryOnce sync.Once
+var mentionRepository *MentionRepository
+
+func NewMentionRepository() *MentionRepository {
+	mentionRepositoryOnce.Do(func() {
+		mentionRepository = &MentionRepository{}
+	})
+	return mentionRepository
+}
+
+// preloadMentions 提及依在內容中的位置排序並帶入被提及的使用者
+func preloadMentions(db *gorm.DB) *gorm.DB {
+	return db.Order("start_offset ASC").Preload("User")
+}
+
+func (r *MentionRepository) Create(ctx *gin.Context, mentionBases []models.MentionBase) ([]models.Mention, error) {
+	if len(mentionBases) == 0 {
+		return []models.Mention{}, nil
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mentions := make([]models.Mention, len(mentionBases))
+	for i, base := range mentionBases {
+		mentions[i] = models.Mention{
+			TableModel:  models.TableModel{ID: uuid.New()},
+			MentionBase: base,
+		}
+	}
+	if err := db.Omit("User").Create(mentions).Error; err != nil {
+		return nil, err
+	}
+	return mentions, nil
+}
+
+// GetUserIDsByPostID 回傳貼文中被提及的使用者 (不重複)
+func (r *MentionRepository) GetUserIDsByPostID(ctx *gin.Context, postID uuid.UUID) ([]uuid.UUID, error) {
+	return r.getUserIDs(ctx, "post_id = ?", postID)
+}
+
+// GetUserIDsByCommentID 回傳評論中被提及的使用者 (不重複)
+func (r *MentionRepository) GetUserIDsByCommentID(ctx *gin.Context, commentID uuid.UUID) ([]uuid.UUID, error) {
+	return r.getUserIDs(ctx, "comment_id = ?", commentID)
+}
+
+func (r *MentionRepository) getUserIDs(ctx *gin.Context, query string, args ...any) ([]uuid.UUID, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := []uuid.UUID{}
+	if err := db.Model(&models.Mention{}).
+		Where(query, args...).
+		Distinct().
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+func (r *MentionRepository) DeleteByPostID(ctx *gin.Context, postID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Where("post_id = ?", postID).Delete(&models.Mention{}).Error
+}
+
+func (r *MentionRepository) DeleteByCommentID(ctx *gin.Context, commentID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Where("comment_id = ?", commentID).Delete(&models.Mention{}).Error
+}
+
+// DeleteByUserID 刪除提及使用者的紀錄 (使用者自己內容中的提及隨內容刪除)
+func (r *MentionRepository) DeleteByUserID(ctx *gin.Context, userID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Where("user_id = ?", userID).Delete(&models.Mention{}).Error
+}
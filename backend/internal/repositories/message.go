@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MessageRepository struct {
+	ErrorUtils *pkg.ErrorUtils
+}
+
+var messageRepositoryOnce sync.Once
+var messageRepository *MessageRepository
+
+func NewMessageRepository() *MessageRepository {
+	messageRepositoryOnce.Do(func() {
+		messageRepository = &MessageRepository{
+			ErrorUtils: pkg.NewErrorUtils(),
+		}
+	})
+	return messageRepository
+}
+
+func (r *MessageRepository) Create(ctx *gin.Context, messageBase models.MessageBase) (*models.Message, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 使用 UUIDv7 (依時間遞增)，讓同一秒傳送的訊息依傳送順序排列
+	messageID, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+	message := &models.Message{
+		TableModel:  models.TableModel{ID: messageID},
+		MessageBase: messageBase,
+	}
+	if err := db.Omit("Sender").Create(message).Error; err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// GetListByConversationIDByCursor 依 (created_at, id) 由新到舊分頁對話的訊息，回傳下一頁的位置與總筆數 (未要求時為 nil)
+func (r *MessageRepository) GetListByConversationIDByCursor(ctx *gin.Context, conversationID uuid.UUID, pagination *models.CursorPagination) ([]models.Message, *models.Cursor, *uint, error) {
+	if pagination == nil || pagination.Limit <= 0 {
+		return nil, nil, nil, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	db = db.Model(&models.Message{}).Where("conversation_id = ?", conversationID)
+	totalCount, err := countByCursorPagination(db, pagination)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	messages := []models.Message{}
+	if err := paginateByCursor(db, "messages", pagination, true).Find(&messages).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	messages, nextCursor := takeCursorPage(messages, pagination.Limit)
+	return messages, nextCursor, totalCount, nil
+}
+
+// GetLatestByConversationIDs 回傳各對話最新的一則訊息，沒有訊息的對話不在結果中
+func (r *MessageRepository) GetLatestByConversationIDs(ctx *gin.Context, conversationIDs []uuid.UUID) (map[uuid.UUID]models.Message, error) {
+	latestMessages := make(map[uuid.UUID]models.Message, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return latestMessages, nil
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	messages := []models.Message{}
+	if err := db.Where("conversation_id IN ?", conversationIDs).
+		Where("id = (?)", db.Table("messages AS latest").
+			Select("latest.id").
+			Where("latest.conversation_id = messages.conversation_id").
+			Order("latest.created_at DESC").
+			Order("latest.id DESC").
+			Limit(1)).
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	for _, message := range messages {
+		latestMessages[message.ConversationID] = message
+	}
+	return latestMessages, nil
+}
+
+// GetLatestByConversationID 回傳對話最新的一則訊息，沒有訊息時回傳 gorm.ErrRecordNotFound
+func (r *MessageRepository) GetLatestByConversationID(ctx *gin.Context, conversationID uuid.UUID) (*models.Message, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	message := &models.Message{}
+	if err := db.Where("conversation_id = ?", conversationID).
+		Order("created_at DESC").
+		Order("id DESC").
+		First(message).Error; err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+type messageUnreadCountRow struct {
+	ConversationID uuid.UUID
+	Count          uint
+}
+
+// CountUnreadByConversationIDs 回傳使用者在各對話中已讀位置之後、由其他人傳送的訊息數
+func (r *MessageRepository) CountUnreadByConversationIDs(ctx *gin.Context, userID uuid.UUID, conversationIDs []uuid.UUID) (map[uuid.UUID]uint, error) {
+	unreadCounts := make(map[uuid.UUID]uint, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return unreadCounts, nil
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows := []messageUnreadCountRow{}
+	if err := db.Model(&models.Message{}).
+		Select("messages.conversation_id AS conversation_id, COUNT(*) AS count").
+		Joins("JOIN conversation_participants ON conversation_participants.conversation_id = messages.conversation_id AND conversation_participants.user_id = ?", userID).
+		Where("messages.conversation_id IN ? AND messages.sender_id <> ?", conversationIDs, userID).
+		Where("conversation_participants.last_read_message_id IS NULL OR messages.created_at > conversation_participants.last_read_at OR " +
+			"(messages.created_at = conversation_participants.last_read_at AND messages.id > conversation_participants.last_read_message_id)").
+		Group("messages.conversation_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		unreadCounts[row.ConversationID] = row.Count
+	}
+	return unreadCounts, nil
+}
+
+// DeleteBySenderID 刪除使用者傳送的所有訊息
+func (r *MessageRepository) DeleteBySenderID(ctx *gin.Context, senderID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Where("sender_id = ?", senderID).Delete(&models.Message{}).Error
+}
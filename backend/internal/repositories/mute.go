@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MuteRepository struct {
+	ErrorUtils *pkg.ErrorUtils
+}
+
+var muteRepositoryOnce sync.Once
+var muteRepository *MuteRepository
+
+func NewMuteRepository() *MuteRepository {
+	muteRepositoryOnce.Do(func() {
+		muteRepository = &MuteRepository{
+			ErrorUtils: pkg.NewErrorUtils(),
+		}
+	})
+	return muteRepository
+}
+
+// excludeMutedUsers 排除 column (使用者 ID 欄位) 為 viewerID 靜音的使用者的資料，viewerID 為 nil 時不過濾
+func excludeMutedUsers(db *gorm.DB, column string, viewerID *uuid.UUID) *gorm.DB {
+	if viewerID == nil {
+		return db
+	}
+	return db.Where(column+" NOT IN (SELECT mutes.muted_id FROM mutes WHERE mutes.muter_id = ?)", *viewerID)
+}
+
+// Mute 重複靜音不會產生錯誤，回傳是否為新增的靜音
+func (r *MuteRepository) Mute(ctx *gin.Context, muterID uuid.UUID, mutedID uuid.UUID) (bool, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return false, err
+	}
+	mute := &models.Mute{
+		TableModel: models.TableModel{ID: uuid.New()},
+		MuteBase: models.MuteBase{
+			MuterID: muterID,
+			MutedID: mutedID,
+		},
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Omit("Muter", "Muted").Create(mute)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Unmute 未靜音時不會產生錯誤，回傳是否有解除靜音
+func (r *MuteRepository) Unmute(ctx *gin.Context, muterID uuid.UUID, mutedID uuid.UUID) (bool, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return false, err
+	}
+	result := db.Where("muter_id = ? AND muted_id = ?", muterID, mutedID).Delete(&models.Mute{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetMutedUsers 回傳 userID 靜音的使用者，依靜音時間由新到舊排序
+func (r *MuteRepository) GetMutedUsers(ctx *gin.Context, userID uuid.UUID, pagination *models.Pagination) ([]models.User, uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	db = db.Model(&models.User{}).
+		Joins("JOIN mutes ON mutes.muted_id = users.id").
+		Where("mutes.muter_id = ?", userID).
+		Order(clause.OrderByColumn{
+			Column: clause.Column{Table: "mutes", Name: "created_at"},
+			Desc:   true,
+		})
+
+	totalCount := int64(0)
+	if err := db.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+	if pagination != nil {
+		if pagination.Limit <= 0 {
+			return nil, 0, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+		}
+		db = db.Offset(int(pagination.Offset)).Limit(int(pagination.Limit))
+	}
+	users := []models.User{}
+	if err := db.Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, uint(totalCount), nil
+}
+
+// DeleteByUserID 刪除使用者所有的靜音與被靜音關係
+func (r *MuteRepository) DeleteByUserID(ctx *gin.Context, userID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Where("muter_id = ? OR muted_id = ?", userID, userID).Delete(&models.Mute{}).Error
+}
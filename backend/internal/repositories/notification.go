@@ -0,0 +1,259 @@
+package repositories
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	ErrorUtils *pkg.ErrorUtils
+}
+
+var notificationRepositoryOnce sync.Once
+var notificationRepository *NotificationRepository
+
+func NewNotificationRepository() *NotificationRepository {
+	notificationRepositoryOnce.Do(func() {
+		notificationRepository = &NotificationRepository{
+			ErrorUtils: pkg.NewErrorUtils(),
+		}
+	})
+	return notificationRepository
+}
+
+// NOTIFICATION_GROUP_GENERATION_SEPARATOR 分隔群組的 GroupKey 與其世代 (開啟群組的通知 ID)
+const NOTIFICATION_GROUP_GENERATION_SEPARATOR = "#"
+
+// Create GroupKey 為空的通知不與其他通知合併，否則加入同一 GroupKey 尚有未讀通知的群組，
+// 群組已全部讀取時開啟新的群組 (GroupKey 加上開啟群組的通知 ID)，已讀的通知不再與新的通知合併
+func (r *NotificationRepository) Create(ctx *gin.Context, notificationBases []models.NotificationBase) ([]models.Notification, error) {
+	if len(notificationBases) == 0 {
+		return []models.Notification{}, nil
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 使用 UUIDv7 (依時間遞增)，讓同一秒建立的通知依建立順序排列
+	notifications := make([]models.Notification, len(notificationBases))
+	openGroupKeys := map[string]string{}
+	for i, base := range notificationBases {
+		notificationID, err := uuid.NewV7()
+		if err != nil {
+			return nil, err
+		}
+		notifications[i] = models.Notification{
+			TableModel:       models.TableModel{ID: notificationID},
+			NotificationBase: base,
+		}
+		if base.GroupKey == "" {
+			notifications[i].GroupKey = notifications[i].ID.String()
+			continue
+		}
+		cacheKey := base.UserID.String() + NOTIFICATION_GROUP_GENERATION_SEPARATOR + base.GroupKey
+		groupKey, ok := openGroupKeys[cacheKey]
+		if !ok {
+			groupKey, err = r.getOpenGroupKey(db, base.UserID, base.GroupKey)
+			if err != nil {
+				return nil, err
+			}
+			if groupKey == "" {
+				groupKey = base.GroupKey + NOTIFICATION_GROUP_GENERATION_SEPARATOR + notificationID.String()
+			}
+			openGroupKeys[cacheKey] = groupKey
+		}
+		notifications[i].GroupKey = groupKey
+	}
+	if err := db.Omit("Actor").Create(notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// getOpenGroupKey 回傳 groupKey 尚有未讀通知的群組 (包含加上世代前建立的群組)，沒有時回傳空字串
+func (r *NotificationRepository) getOpenGroupKey(db *gorm.DB, userID uuid.UUID, groupKey string) (string, error) {
+	groupKeys := []string{}
+	if err := db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Where("group_key = ? OR group_key LIKE ?", groupKey, groupKey+NOTIFICATION_GROUP_GENERATION_SEPARATOR+"%").
+		Order("created_at DESC, id DESC").
+		Limit(1).
+		Pluck("group_key", &groupKeys).Error; err != nil {
+		return "", err
+	}
+	if len(groupKeys) == 0 {
+		return "", nil
+	}
+	return groupKeys[0], nil
+}
+
+func (r *NotificationRepository) GetByID(ctx *gin.Context, notificationID uuid.UUID) (*models.Notification, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	notification := &models.Notification{}
+	if err := db.First(notification, "id = ?", notificationID).Error; err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
+
+type notificationGroupRow struct {
+	GroupKey    string
+	LatestAt    int64
+	LatestID    string
+	ActorCount  uint
+	UnreadCount uint
+}
+
+// GetGroupsByUserID 依 GroupKey 合併使用者的通知，依群組中最新的通知由新到舊排序，回傳群組總數
+func (r *NotificationRepository) GetGroupsByUserID(ctx *gin.Context, userID uuid.UUID, pagination *models.Pagination) ([]models.NotificationGroup, uint, error) {
+	if pagination == nil || pagination.Limit <= 0 {
+		return nil, 0, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	totalCount := int64(0)
+	if err := db.Model(&models.Notification{}).
+		Where("user_id = ?", userID).
+		Distinct("group_key").
+		Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	rows := []notificationGroupRow{}
+	if err := db.Model(&models.Notification{}).
+		Select("group_key, MAX(created_at) AS latest_at, MAX(CAST(id AS TEXT)) AS latest_id, "+
+			"COUNT(DISTINCT actor_id) AS actor_count, SUM(CASE WHEN read_at IS NULL THEN 1 ELSE 0 END) AS unread_count").
+		Where("user_id = ?", userID).
+		Group("group_key").
+		Order("latest_at DESC, latest_id DESC").
+		Offset(int(pagination.Offset)).
+		Limit(int(pagination.Limit)).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	if len(rows) == 0 {
+		return []models.NotificationGroup{}, uint(totalCount), nil
+	}
+
+	// 每個群組最新的通知皆不早於最舊群組的 latest_at
+	groupKeys := make([]string, len(rows))
+	for i, row := range rows {
+		groupKeys[i] = row.GroupKey
+	}
+	notifications := []models.Notification{}
+	if err := db.Where("user_id = ? AND group_key IN ? AND created_at >= ?", userID, groupKeys, rows[len(rows)-1].LatestAt).
+		Preload("Actor").
+		Order("created_at DESC, id DESC").
+		Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+	latest := make(map[string]models.Notification, len(rows))
+	for _, notification := range notifications {
+		if _, ok := latest[notification.GroupKey]; !ok {
+			latest[notification.GroupKey] = notification
+		}
+	}
+
+	groups := make([]models.NotificationGroup, len(rows))
+	for i, row := range rows {
+		groups[i] = models.NotificationGroup{
+			Latest:     latest[row.GroupKey],
+			ActorCount: row.ActorCount,
+			Read:       row.UnreadCount == 0,
+		}
+	}
+	return groups, uint(totalCount), nil
+}
+
+// CountUnreadGroupsByUserID 回傳有未讀通知的群組數
+func (r *NotificationRepository) CountUnreadGroupsByUserID(ctx *gin.Context, userID uuid.UUID) (uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := int64(0)
+	if err := db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Distinct("group_key").
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return uint(count), nil
+}
+
+// MarkReadByGroupKey 將群組中未讀的通知標記為已讀，回傳標記的通知數
+func (r *NotificationRepository) MarkReadByGroupKey(ctx *gin.Context, userID uuid.UUID, groupKey string) (uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return 0, err
+	}
+	result := db.Model(&models.Notification{}).
+		Where("user_id = ? AND group_key = ? AND read_at IS NULL", userID, groupKey).
+		Update("read_at", time.Now().Unix())
+	return uint(result.RowsAffected), result.Error
+}
+
+// MarkAllReadByUserID 將使用者所有未讀的通知標記為已讀，回傳標記的通知數
+func (r *NotificationRepository) MarkAllReadByUserID(ctx *gin.Context, userID uuid.UUID) (uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return 0, err
+	}
+	result := db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now().Unix())
+	return uint(result.RowsAffected), result.Error
+}
+
+// DeleteByActor 刪除 actorID 觸發的通知 (取消喜歡、取消追蹤時)，postID 為 nil 時不限貼文
+func (r *NotificationRepository) DeleteByActor(ctx *gin.Context, userID uuid.UUID, actorID uuid.UUID, notificationType models.NotificationType, postID *uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	db = db.Where("user_id = ? AND actor_id = ? AND type = ?", userID, actorID, notificationType)
+	if postID != nil {
+		db = db.Where("post_id = ?", *postID)
+	}
+	return db.Delete(&models.Notification{}).Error
+}
+
+// DeleteMentions 刪除 userIDs 在貼文 (commentID 為 nil) 或評論中被提及的通知
+func (r *NotificationRepository) DeleteMentions(ctx *gin.Context, postID uuid.UUID, commentID *uuid.UUID, userIDs []uuid.UUID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	db = db.Where("type = ? AND post_id = ? AND user_id IN ?", models.NotificationTypeMention, postID, userIDs)
+	if commentID == nil {
+		db = db.Where("comment_id IS NULL")
+	} else {
+		db = db.Where("comment_id = ?", *commentID)
+	}
+	return db.Delete(&models.Notification{}).Error
+}
+
+// DeleteByUserID 刪除使用者收到與觸發的通知
+func (r *NotificationRepository) DeleteByUserID(ctx *gin.Context, userID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Where("user_id = ? OR actor_id = ?", userID, userID).Delete(&models.Notification{}).Error
+}
@@ -4,15 +4,19 @@ import (
 	"backend/internal/middlewares"
 	"backend/internal/models"
 	"backend/internal/pkg"
+	"database/sql"
 	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 type PostRepository struct {
-	ErrorUtils *pkg.ErrorUtils
+	ErrorUtils  *pkg.ErrorUtils
+	SearchUtils *pkg.SearchUtils
+	TagUtils    *pkg.TagUtils
 
 	UserRepository *UserRepository
 }
@@ -23,7 +27,9 @@ var postRepository *PostRepository
 func NewPostRepository() *PostRepository {
 	postRepositoryOnce.Do(func() {
 		postRepository = &PostRepository{
-			ErrorUtils: pkg.NewErrorUtils(),
+			ErrorUtils:  pkg.NewErrorUtils(),
+			SearchUtils: pkg.NewSearchUtils(),
+			TagUtils:    pkg.NewTagUtils(),
 
 			UserRepository: NewUserRepository(),
 		}
@@ -31,17 +37,34 @@ func NewPostRepository() *PostRepository {
 	return postRepository
 }
 
+// excludeHiddenPosts 排除被管理員隱藏或等待審核的貼文
+func excludeHiddenPosts(db *gorm.DB) *gorm.DB {
+	return db.Where("posts.hidden_at IS NULL")
+}
+
+// GetByID 被隱藏的貼文視為不存在
 func (r *PostRepository) GetByID(ctx *gin.Context, postID uuid.UUID) (*models.Post, error) {
+	return r.getByID(ctx, postID, excludeHiddenPosts)
+}
+
+// GetHiddenByID 只回傳被隱藏 (被管理員隱藏或等待審核) 的貼文
+func (r *PostRepository) GetHiddenByID(ctx *gin.Context, postID uuid.UUID) (*models.Post, error) {
+	return r.getByID(ctx, postID, func(db *gorm.DB) *gorm.DB {
+		return db.Where("posts.hidden_at IS NOT NULL")
+	})
+}
+
+func (r *PostRepository) getByID(ctx *gin.Context, postID uuid.UUID, scope func(db *gorm.DB) *gorm.DB) (*models.Post, error) {
 	db, err := middlewares.GetContentGORMDB(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	post := &models.Post{}
-	if err := db.Model(post).
+	if err := scope(db.Model(post)).
 		Preload("Author").
 		Preload("Tags").
-		Preload("Likes").
+		Preload("Mentions", preloadMentions).
 		Where("id = ?", postID).
 		First(post).Error; err != nil {
 		return nil, err
@@ -49,68 +72,177 @@ func (r *PostRepository) GetByID(ctx *gin.Context, postID uuid.UUID) (*models.Po
 	return post, nil
 }
 
-func (r *PostRepository) GetListByKeywords(ctx *gin.Context, keywords []string, pagination *models.Pagination) ([]models.Post, uint, error) {
+// postSearchHit 搜尋結果的貼文與相關度，貼文內容另外載入
+type postSearchHit struct {
+	ID         uuid.UUID
+	CreatedAt  int64
+	SearchRank float64
+}
+
+func (h postSearchHit) GetCursor() models.Cursor {
+	return models.Cursor{CreatedAt: h.CreatedAt, ID: h.ID}
+}
+
+// searchQuery 將搜尋條件編譯為查詢，回傳選取貼文與相關度的查詢及總筆數的查詢
+func (r *PostRepository) searchQuery(db *gorm.DB, filter *models.PostSearchFilter) (*gorm.DB, *gorm.DB) {
+	db = excludeHiddenPosts(db.Model(&models.Post{}))
+	engine := getPostSearchEngine(db)
+	rank := clause.Expr{SQL: "0"}
+	if terms := r.SearchUtils.QueryTerms(append(append([]string{}, filter.Terms...), filter.Phrases...)); len(terms) > 0 {
+		db = engine.match(db, terms)
+		rank = engine.rank(terms)
+	}
+	if terms := r.SearchUtils.QueryTerms(filter.ExcludedTerms); len(terms) > 0 {
+		db = engine.exclude(db, terms)
+	}
+
+	// 標籤名稱正規化後比對，也可使用別名
+	tagPostIDs := "SELECT post_to_tag.post_id FROM post_to_tag JOIN tags ON tags.id = post_to_tag.tag_id " +
+		"WHERE tags.name = @name OR tags.id IN (SELECT tag_aliases.tag_id FROM tag_aliases WHERE tag_aliases.name = @name)"
+	for _, tag := range filter.Tags {
+		name, _ := r.TagUtils.Normalize(tag)
+		db = db.Where("posts.id IN ("+tagPostIDs+")", sql.Named("name", name))
+	}
+	for _, tag := range filter.ExcludedTags {
+		name, _ := r.TagUtils.Normalize(tag)
+		db = db.Where("posts.id NOT IN ("+tagPostIDs+")", sql.Named("name", name))
+	}
+	if filter.AuthorUsername != nil {
+		db = db.Where("posts.author_id IN (SELECT users.id FROM users WHERE users.username = ?)", *filter.AuthorUsername)
+	}
+	if filter.AuthorID != nil {
+		db = db.Where("posts.author_id = ?", *filter.AuthorID)
+	}
+	// 日期皆包含當天，迄日以隔天零時為上限
+	if filter.From != nil {
+		db = db.Where("posts.created_at >= ?", filter.From.Unix())
+	}
+	if filter.To != nil {
+		db = db.Where("posts.created_at < ?", filter.To.AddDate(0, 0, 1).Unix())
+	}
+	db = excludeBlockedUsers(db, "posts.author_id", filter.ViewerID)
+	if filter.HasImage != nil {
+		if *filter.HasImage {
+			db = db.Where("posts.image_url IS NOT NULL AND posts.image_url <> ''")
+		} else {
+			db = db.Where("(posts.image_url IS NULL OR posts.image_url = '')")
+		}
+	}
+
+	// 兩個查詢各自使用 Session，避免 Select 影響 COUNT
+	hitsQuery := db.Session(&gorm.Session{}).Select("posts.id, posts.created_at, ? AS search_rank", rank)
+	return hitsQuery, db.Session(&gorm.Session{})
+}
+
+// Search 依搜尋條件搜尋貼文，sort 為 relevance 時依相關度排序 (相同時較新的在前)
+func (r *PostRepository) Search(ctx *gin.Context, filter *models.PostSearchFilter, sort models.PostSearchSort, pagination *models.Pagination) ([]models.PostSearchResult, uint, error) {
+	if pagination == nil || pagination.Limit <= 0 {
+		return nil, 0, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+	}
 	db, err := middlewares.GetContentGORMDB(ctx)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	db = db.Model(&models.Post{}).
-		Joins("LEFT JOIN users AS author ON author.id = posts.author_id").
-		Joins("LEFT JOIN post_to_tag ON post_to_tag.post_id = posts.id").
-		Joins("LEFT JOIN tags ON tags.id = post_to_tag.tag_id").
-		Preload("Author").
-		Preload("Tags").
-		Preload("Likes").
-		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
-			{Column: clause.Column{Table: "posts", Name: "created_at"}, Desc: true},
-		}}).
-		Group("posts.id")
-
-	if len(keywords) > 0 {
-		first := "%" + keywords[0] + "%"
-		db = db.Where(
-			"author.username ILIKE ? OR content ILIKE ? OR tags.name ILIKE ?",
-			first, first, first,
-		)
-		for _, keyword := range keywords[1:] {
-			k := "%" + keyword + "%"
-			db = db.Or(
-				"author.username ILIKE ? OR content ILIKE ? OR tags.name ILIKE ?",
-				k, k, k,
-			)
-		}
+	hitsQuery, countQuery := r.searchQuery(db, filter)
+	totalCount := int64(0)
+	if err := countQuery.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
 	}
 
-	totalCount := int64(0)
-	if err := db.Count(&totalCount).Error; err != nil {
+	orderColumns := []clause.OrderByColumn{
+		{Column: clause.Column{Table: "posts", Name: "created_at"}, Desc: true},
+		{Column: clause.Column{Table: "posts", Name: "id"}, Desc: true},
+	}
+	if sort == models.PostSearchSortRelevance {
+		orderColumns = append([]clause.OrderByColumn{
+			{Column: clause.Column{Name: "search_rank", Raw: true}, Desc: true},
+		}, orderColumns...)
+	}
+	hits := []postSearchHit{}
+	if err := hitsQuery.
+		Order(clause.OrderBy{Columns: orderColumns}).
+		Offset(int(pagination.Offset)).
+		Limit(int(pagination.Limit)).
+		Scan(&hits).Error; err != nil {
 		return nil, 0, err
 	}
+	results, err := r.getSearchResults(db, hits)
+	if err != nil {
+		return nil, 0, err
+	}
+	return results, uint(totalCount), nil
+}
 
-	if pagination != nil {
-		if pagination.Limit <= 0 {
-			return nil, 0, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
-		}
-		db = db.Offset(int(pagination.Offset)).Limit(int(pagination.Limit))
+// SearchByCursor 依搜尋條件搜尋貼文，依 (created_at, id) 由新到舊分頁，回傳下一頁的位置與總筆數 (未要求時為 nil)
+func (r *PostRepository) SearchByCursor(ctx *gin.Context, filter *models.PostSearchFilter, pagination *models.CursorPagination) ([]models.PostSearchResult, *models.Cursor, *uint, error) {
+	if pagination == nil || pagination.Limit <= 0 {
+		return nil, nil, nil, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
+	hitsQuery, countQuery := r.searchQuery(db, filter)
+	totalCount, err := countByCursorPagination(countQuery, pagination)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	hits := []postSearchHit{}
+	if err := paginateByCursor(hitsQuery, "posts", pagination, true).Scan(&hits).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	hits, nextCursor := takeCursorPage(hits, pagination.Limit)
+	results, err := r.getSearchResults(db, hits)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return results, nextCursor, totalCount, nil
+}
+
+// getSearchResults 依 hits 的順序載入貼文 (含作者與標籤)
+func (r *PostRepository) getSearchResults(db *gorm.DB, hits []postSearchHit) ([]models.PostSearchResult, error) {
+	results := make([]models.PostSearchResult, 0, len(hits))
+	if len(hits) == 0 {
+		return results, nil
+	}
+	postIDs := make([]uuid.UUID, len(hits))
+	for i, hit := range hits {
+		postIDs[i] = hit.ID
+	}
 	posts := []models.Post{}
-	if err := db.Find(&posts).Error; err != nil {
-		return nil, 0, err
+	if err := db.Model(&models.Post{}).
+		Preload("Author").
+		Preload("Tags").
+		Preload("Mentions", preloadMentions).
+		Where("id IN ?", postIDs).
+		Find(&posts).Error; err != nil {
+		return nil, err
 	}
-	return posts, uint(totalCount), nil
+	postsByID := make(map[uuid.UUID]models.Post, len(posts))
+	for _, post := range posts {
+		postsByID[post.ID] = post
+	}
+	for _, hit := range hits {
+		if post, ok := postsByID[hit.ID]; ok {
+			results = append(results, models.PostSearchResult{Post: post, Rank: hit.SearchRank})
+		}
+	}
+	return results, nil
 }
 
-func (r *PostRepository) GetList(ctx *gin.Context, pagination *models.Pagination) ([]models.Post, uint, error) {
+// GetList 回傳所有貼文 (不含被隱藏的貼文)，依建立時間由新到舊排序，viewerID 不為 nil 時排除與其有封鎖關係或被其靜音的作者的貼文
+func (r *PostRepository) GetList(ctx *gin.Context, viewerID *uuid.UUID, pagination *models.Pagination) ([]models.Post, uint, error) {
 	db, err := middlewares.GetContentGORMDB(ctx)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	db = db.Model(&models.Post{}).
+	db = excludeMutedUsers(excludeBlockedUsers(excludeHiddenPosts(db.Model(&models.Post{})), "posts.author_id", viewerID), "posts.author_id", viewerID).
 		Preload("Author").
 		Preload("Tags").
-		Preload("Likes").
+		Preload("Mentions", preloadMentions).
 		Order(clause.OrderBy{Columns: []clause.OrderByColumn{
 			{Column: clause.Column{Name: "created_at"}, Desc: true},
 		}})
@@ -134,6 +266,55 @@ func (r *PostRepository) GetList(ctx *gin.Context, pagination *models.Pagination
 	return posts, uint(totalCount), nil
 }
 
+// GetListByCursor 依 (created_at, id) 由新到舊分頁，回傳下一頁的位置與總筆數 (未要求時為 nil)，過濾方式同 GetList
+func (r *PostRepository) GetListByCursor(ctx *gin.Context, viewerID *uuid.UUID, pagination *models.CursorPagination) ([]models.Post, *models.Cursor, *uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	db = excludeMutedUsers(excludeBlockedUsers(excludeHiddenPosts(db.Model(&models.Post{})), "posts.author_id", viewerID), "posts.author_id", viewerID).
+		Preload("Author").
+		Preload("Tags").
+		Preload("Mentions", preloadMentions)
+	return r.findByCursor(db, pagination)
+}
+
+// GetTimeline 回傳使用者與其追蹤對象的貼文 (排除有封鎖關係或被靜音的作者)，依 (created_at, id) 由新到舊分頁
+func (r *PostRepository) GetTimeline(ctx *gin.Context, userID uuid.UUID, pagination *models.CursorPagination) ([]models.Post, *models.Cursor, *uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	db = excludeHiddenPosts(db.Model(&models.Post{})).
+		Where("posts.author_id = ? OR posts.author_id IN (?)",
+			userID,
+			db.Model(&models.Follow{}).Select("followee_id").Where("follower_id = ?", userID),
+		)
+	db = excludeMutedUsers(excludeBlockedUsers(db, "posts.author_id", &userID), "posts.author_id", &userID).
+		Preload("Author").
+		Preload("Tags").
+		Preload("Mentions", preloadMentions)
+	return r.findByCursor(db, pagination)
+}
+
+// findByCursor 在 db 的查詢條件上套用 keyset 分頁 (由新到舊)
+func (r *PostRepository) findByCursor(db *gorm.DB, pagination *models.CursorPagination) ([]models.Post, *models.Cursor, *uint, error) {
+	if pagination == nil || pagination.Limit <= 0 {
+		return nil, nil, nil, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+	}
+	totalCount, err := countByCursorPagination(db, pagination)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	posts := []models.Post{}
+	if err := paginateByCursor(db, "posts", pagination, true).Find(&posts).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	posts, nextCursor := takeCursorPage(posts, pagination.Limit)
+	return posts, nextCursor, totalCount, nil
+}
+
 func (r *PostRepository) Create(ctx *gin.Context, postBases []models.PostBase, tags [][]models.Tag) ([]models.Post, error) {
 	if len(postBases) != len(tags) {
 		return nil, r.ErrorUtils.ServerInternalError("postBases and tags length mismatch")
@@ -145,10 +326,16 @@ func (r *PostRepository) Create(ctx *gin.Context, postBases []models.PostBase, t
 	}
 
 	// Create posts
+	// 使用 UUIDv7 (依時間遞增)，讓同一秒建立的貼文在 (created_at, id) keyset 分頁中依建立順序排列
 	posts := make([]models.Post, len(postBases))
 	for i, postBase := range postBases {
+		postID, err := uuid.NewV7()
+		if err != nil {
+			return nil, err
+		}
+		postBase.SearchText = r.SearchUtils.BuildSearchText(postBase.Content)
 		posts[i] = models.Post{
-			TableModel: models.TableModel{ID: uuid.New()},
+			TableModel: models.TableModel{ID: postID},
 			PostBase:   postBase,
 		}
 	}
@@ -163,45 +350,226 @@ func (r *PostRepository) Create(ctx *gin.Context, postBases []models.PostBase, t
 		}
 	}
 
-	if err := db.Model(&models.Post{}).Preload("Tags").Find(&posts).Error; err != nil {
+	postIDs := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+	if err := db.Model(&models.Post{}).Preload("Tags").Where("id IN ?", postIDs).Find(&posts).Error; err != nil {
 		return nil, err
 	}
 	return posts, nil
 }
 
-func (r *PostRepository) LikedByUser(ctx *gin.Context, postID uuid.UUID, userID uuid.UUID) error {
+// UpdateByID 更新 content 時一併更新全文搜尋的索引詞
+func (r *PostRepository) UpdateByID(ctx *gin.Context, postID uuid.UUID, updates map[string]any) error {
 	db, err := middlewares.GetContentGORMDB(ctx)
 	if err != nil {
 		return err
 	}
+	if content, ok := updates["content"].(string); ok {
+		updates["search_text"] = r.SearchUtils.BuildSearchText(content)
+	}
+	return db.Model(&models.Post{TableModel: models.TableModel{ID: postID}}).Updates(updates).Error
+}
 
-	post, err := r.GetByID(ctx, postID)
+// ReplaceTags 以 tags 取代貼文目前的標籤關聯
+func (r *PostRepository) ReplaceTags(ctx *gin.Context, postID uuid.UUID, tags []models.Tag) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
 	if err != nil {
 		return err
 	}
+	post := &models.Post{TableModel: models.TableModel{ID: postID}}
+	if len(tags) == 0 {
+		return db.Model(post).Association("Tags").Clear()
+	}
+	return db.Model(post).Association("Tags").Replace(tags)
+}
+
+// GetTagIDsByPostIDs 回傳貼文目前關聯的所有標籤
+func (r *PostRepository) GetTagIDsByPostIDs(ctx *gin.Context, postIDs []uuid.UUID) ([]uuid.UUID, error) {
+	tagIDs := []uuid.UUID{}
+	if len(postIDs) == 0 {
+		return tagIDs, nil
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Table("post_to_tag").
+		Where("post_id IN ?", postIDs).
+		Distinct().
+		Pluck("tag_id", &tagIDs).Error; err != nil {
+		return nil, err
+	}
+	return tagIDs, nil
+}
+
+// LikedByUser 重複喜歡不會產生錯誤，回傳是否為新增的喜歡
+func (r *PostRepository) LikedByUser(ctx *gin.Context, postID uuid.UUID, userID uuid.UUID) (bool, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return false, err
+	}
+	result := db.Exec(
+		"INSERT INTO post_to_user (post_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+		postID, userID,
+	)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UnlikedByUser 未喜歡時不會產生錯誤，回傳是否有移除喜歡
+func (r *PostRepository) UnlikedByUser(ctx *gin.Context, postID uuid.UUID, userID uuid.UUID) (bool, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return false, err
+	}
+	result := db.Exec("DELETE FROM post_to_user WHERE post_id = ? AND user_id = ?", postID, userID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
 
-	user, err := r.UserRepository.GetByID(ctx, userID)
+// IncrementLikeCount delta 可為負數，不更新 updated_at
+func (r *PostRepository) IncrementLikeCount(ctx *gin.Context, postID uuid.UUID, delta int) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
 	if err != nil {
 		return err
 	}
+	return db.Model(&models.Post{}).
+		Where("id = ?", postID).
+		UpdateColumn("like_count", gorm.Expr("like_count + ?", delta)).Error
+}
+
+// GetLikeCountByID 回傳貼文目前的喜歡數
+func (r *PostRepository) GetLikeCountByID(ctx *gin.Context, postID uuid.UUID) (uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return 0, err
+	}
+	likeCount := uint(0)
+	if err := db.Model(&models.Post{}).
+		Select("like_count").
+		Where("id = ?", postID).
+		Scan(&likeCount).Error; err != nil {
+		return 0, err
+	}
+	return likeCount, nil
+}
 
-	if err := db.Model(post).Association("Likes").Append(user); err != nil {
+// IncrementCommentCount delta 可為負數，不更新 updated_at
+func (r *PostRepository) IncrementCommentCount(ctx *gin.Context, postID uuid.UUID, delta int) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
 		return err
 	}
-	return nil
+	return db.Model(&models.Post{}).
+		Where("id = ?", postID).
+		UpdateColumn("comment_count", gorm.Expr("comment_count + ?", delta)).Error
+}
+
+// ReconcileCounters 依喜歡與評論 (不含墓碑、被隱藏的評論及其回覆) 重新計算貼文計數，postIDs 為空時重算所有貼文，回傳更新筆數
+func (r *PostRepository) ReconcileCounters(ctx *gin.Context, postIDs []uuid.UUID) (uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return 0, err
+	}
+	db = db.Model(&models.Post{})
+	if len(postIDs) > 0 {
+		db = db.Where("id IN ?", postIDs)
+	} else {
+		db = db.Where("1 = 1")
+	}
+	result := db.UpdateColumns(map[string]any{
+		"like_count": gorm.Expr("(SELECT COUNT(*) FROM post_to_user WHERE post_to_user.post_id = posts.id)"),
+		// 從根評論往下走訪未被隱藏的評論，與評論樹顯示的評論一致
+		"comment_count": gorm.Expr("(WITH RECURSIVE visible_comments(id, deleted_at) AS (" +
+			"SELECT id, deleted_at FROM comments WHERE comments.post_id = posts.id AND comments.parent_id IS NULL AND comments.hidden_at IS NULL " +
+			"UNION SELECT comments.id, comments.deleted_at FROM comments JOIN visible_comments ON comments.parent_id = visible_comments.id WHERE comments.hidden_at IS NULL" +
+			") SELECT COUNT(*) FROM visible_comments WHERE deleted_at IS NULL)"),
+	})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return uint(result.RowsAffected), nil
+}
+
+// GetIDsLikedByUserID 回傳使用者喜歡的所有貼文
+func (r *PostRepository) GetIDsLikedByUserID(ctx *gin.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	postIDs := []uuid.UUID{}
+	if err := db.Table("post_to_user").
+		Where("user_id = ?", userID).
+		Pluck("post_id", &postIDs).Error; err != nil {
+		return nil, err
+	}
+	return postIDs, nil
+}
+
+// GetLikedPostIDs 回傳 postIDs 中被使用者喜歡的貼文
+func (r *PostRepository) GetLikedPostIDs(ctx *gin.Context, userID uuid.UUID, postIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	result := make(map[uuid.UUID]bool)
+	if len(postIDs) == 0 {
+		return result, nil
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	likedPostIDs := []uuid.UUID{}
+	if err := db.Table("post_to_user").
+		Where("user_id = ? AND post_id IN ?", userID, postIDs).
+		Pluck("post_id", &likedPostIDs).Error; err != nil {
+		return nil, err
+	}
+	for _, postID := range likedPostIDs {
+		result[postID] = true
+	}
+	return result, nil
+}
+
+func (r *PostRepository) GetLikedUsers(ctx *gin.Context, postID uuid.UUID, pagination *models.Pagination) ([]models.User, uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	db = db.Model(&models.User{}).
+		Joins("JOIN post_to_user ON post_to_user.user_id = users.id").
+		Where("post_to_user.post_id = ?", postID).
+		Order(clause.OrderByColumn{
+			Column: clause.Column{Table: "users", Name: "username"},
+		})
+	totalCount := int64(0)
+	if err := db.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+	if pagination != nil {
+		if pagination.Limit <= 0 {
+			return nil, 0, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+		}
+		db = db.Offset(int(pagination.Offset)).Limit(int(pagination.Limit))
+	}
+	users := []models.User{}
+	if err := db.Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, uint(totalCount), nil
 }
 
-func (r *PostRepository) GetPostsByAuthorID(ctx *gin.Context, AuthorID uuid.UUID, pagination *models.Pagination) ([]models.Post, uint, error) {
+// GetPostsByAuthorID 作者與 viewerID 之間有封鎖關係時回傳空列表
+func (r *PostRepository) GetPostsByAuthorID(ctx *gin.Context, AuthorID uuid.UUID, viewerID *uuid.UUID, pagination *models.Pagination) ([]models.Post, uint, error) {
 	db, err := middlewares.GetContentGORMDB(ctx)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	db = db.Model(&models.Post{}).
-		Where(&models.Post{PostBase: models.PostBase{AuthorID: AuthorID}}).
-		Preload("Author").
-		Preload("Tags").
-		Preload("Likes").
+	db = r.listByAuthorIDQuery(db, AuthorID, viewerID).
 		Order(clause.OrderByColumn{
 			Column: clause.Column{Name: "created_at"},
 			Desc:   true,
@@ -225,3 +593,81 @@ func (r *PostRepository) GetPostsByAuthorID(ctx *gin.Context, AuthorID uuid.UUID
 	}
 	return posts, uint(totalCount), nil
 }
+
+// GetPostsByAuthorIDByCursor 依 (created_at, id) 由新到舊分頁，回傳下一頁的位置與總筆數 (未要求時為 nil)，
+// 作者與 viewerID 之間有封鎖關係時回傳空列表
+func (r *PostRepository) GetPostsByAuthorIDByCursor(ctx *gin.Context, authorID uuid.UUID, viewerID *uuid.UUID, pagination *models.CursorPagination) ([]models.Post, *models.Cursor, *uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return r.findByCursor(r.listByAuthorIDQuery(db, authorID, viewerID), pagination)
+}
+
+// GetPostsByTagIDByCursor 回傳使用標籤的貼文 (排除與 viewerID 有封鎖關係的作者)，依 (created_at, id) 由新到舊分頁
+func (r *PostRepository) GetPostsByTagIDByCursor(ctx *gin.Context, tagID uuid.UUID, viewerID *uuid.UUID, pagination *models.CursorPagination) ([]models.Post, *models.Cursor, *uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	db = excludeBlockedUsers(excludeHiddenPosts(db.Model(&models.Post{})), "posts.author_id", viewerID).
+		Where("posts.id IN (?)", db.Table("post_to_tag").Select("post_id").Where("tag_id = ?", tagID)).
+		Preload("Author").
+		Preload("Tags").
+		Preload("Mentions", preloadMentions)
+	return r.findByCursor(db, pagination)
+}
+
+func (r *PostRepository) listByAuthorIDQuery(db *gorm.DB, authorID uuid.UUID, viewerID *uuid.UUID) *gorm.DB {
+	return excludeBlockedUsers(excludeHiddenPosts(db.Model(&models.Post{})), "posts.author_id", viewerID).
+		Where(&models.Post{PostBase: models.PostBase{AuthorID: authorID}}).
+		Preload("Author").
+		Preload("Tags").
+		Preload("Mentions", preloadMentions)
+}
+
+// DeleteByIDs 刪除貼文以及其評論 (含編輯紀錄)、提及、通知、喜歡與標籤關聯
+func (r *PostRepository) DeleteByIDs(ctx *gin.Context, postIDs []uuid.UUID) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	commentIDs := db.Model(&models.Comment{}).Select("id").Where("post_id IN ?", postIDs)
+	if err := db.Where("comment_id IN (?)", commentIDs).Delete(&models.CommentEditHistory{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("post_id IN ? OR comment_id IN (?)", postIDs, commentIDs).Delete(&models.Mention{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("post_id IN ?", postIDs).Delete(&models.Notification{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("post_id IN ?", postIDs).Delete(&models.Comment{}).Error; err != nil {
+		return err
+	}
+	if err := db.Exec("DELETE FROM post_to_tag WHERE post_id IN ?", postIDs).Error; err != nil {
+		return err
+	}
+	if err := db.Exec("DELETE FROM post_to_user WHERE post_id IN ?", postIDs).Error; err != nil {
+		return err
+	}
+	return db.Where("id IN ?", postIDs).Delete(&models.Post{}).Error
+}
+
+func (r *PostRepository) GetIDsByAuthorID(ctx *gin.Context, authorID uuid.UUID) ([]uuid.UUID, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	postIDs := []uuid.UUID{}
+	if err := db.Model(&models.Post{}).
+		Where("author_id = ?", authorID).
+		Pluck("id", &postIDs).Error; err != nil {
+		return nil, err
+	}
+	return postIDs, nil
+}
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"backend/internal/database"
+	"backend/internal/pkg"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// postSearchEngine 全文搜尋在各資料庫的實作，terms 為 SearchUtils.QueryTerms 的結果
+type postSearchEngine interface {
+	// match 篩選符合所有查詢詞的貼文，查詢詞中的索引詞需相鄰出現
+	match(db *gorm.DB, terms [][]string) *gorm.DB
+	// exclude 排除符合任一查詢詞的貼文
+	exclude(db *gorm.DB, terms [][]string) *gorm.DB
+	// rank 相關度的 SQL 運算式，數值越大越相關，需搭配 match 使用
+	rank(terms [][]string) clause.Expr
+}
+
+// getPostSearchEngine PostgreSQL 使用 tsvector，SQLite 在編譯時啟用 FTS5 (-tags sqlite_fts5) 時使用 FTS5，否則退回 LIKE 比對
+func getPostSearchEngine(db *gorm.DB) postSearchEngine {
+	searchUtils := pkg.NewSearchUtils()
+	switch db.Dialector.Name() {
+	case "postgres":
+		return &postgresPostSearchEngine{SearchUtils: searchUtils}
+	case "sqlite":
+		if database.IsSQLiteFTS5Enabled(db) {
+			return &sqliteFTS5PostSearchEngine{SearchUtils: searchUtils}
+		}
+	}
+	return &likePostSearchEngine{SearchUtils: searchUtils}
+}
+
+// postgresPostSearchEngine 比對 posts.search_vector (to_tsvector('simple', search_text) 的產生欄位，GIN 索引)
+type postgresPostSearchEngine struct {
+	SearchUtils *pkg.SearchUtils
+}
+
+func (e *postgresPostSearchEngine) tsquery(terms [][]string, operator string) string {
+	parts := make([]string, len(terms))
+	for i, tokens := range terms {
+		if e.SearchUtils.IsCJKPrefixTerm(tokens) {
+			parts[i] = tokens[0] + ":*"
+			continue
+		}
+		parts[i] = "(" + strings.Join(tokens, " <-> ") + ")"
+	}
+	return strings.Join(parts, " "+operator+" ")
+}
+
+func (e *postgresPostSearchEngine) match(db *gorm.DB, terms [][]string) *gorm.DB {
+	return db.Where("posts.search_vector @@ to_tsquery('simple', ?)", e.tsquery(terms, "&"))
+}
+
+func (e *postgresPostSearchEngine) exclude(db *gorm.DB, terms [][]string) *gorm.DB {
+	return db.Where("NOT (posts.search_vector @@ to_tsquery('simple', ?))", e.tsquery(terms, "|"))
+}
+
+func (e *postgresPostSearchEngine) rank(terms [][]string) clause.Expr {
+	return clause.Expr{
+		SQL:  "ts_rank(posts.search_vector, to_tsquery('simple', ?))",
+		Vars: []any{e.tsquery(terms, "&")},
+	}
+}
+
+// sqliteFTS5PostSearchEngine 比對 posts_fts，以 bm25 計算相關度
+type sqliteFTS5PostSearchEngine struct {
+	SearchUtils *pkg.SearchUtils
+}
+
+func (e *sqliteFTS5PostSearchEngine) matchExpression(terms [][]string, operator string) string {
+	parts := make([]string, len(terms))
+	for i, tokens := range terms {
+		if e.SearchUtils.IsCJKPrefixTerm(tokens) {
+			parts[i] = `"` + tokens[0] + `"*`
+			continue
+		}
+		parts[i] = `"` + strings.Join(tokens, " ") + `"`
+	}
+	return strings.Join(parts, " "+operator+" ")
+}
+
+func (e *sqliteFTS5PostSearchEngine) match(db *gorm.DB, terms [][]string) *gorm.DB {
+	return db.
+		Joins("JOIN "+database.POST_SEARCH_FTS_TABLE+" ON "+database.POST_SEARCH_FTS_TABLE+".rowid = posts.rowid").
+		Where(database.POST_SEARCH_FTS_TABLE+" MATCH ?", e.matchExpression(terms, "AND"))
+}
+
+func (e *sqliteFTS5PostSearchEngine) exclude(db *gorm.DB, terms [][]string) *gorm.DB {
+	return db.Where(
+		"posts.rowid NOT IN (SELECT rowid FROM "+database.POST_SEARCH_FTS_TABLE+" WHERE "+database.POST_SEARCH_FTS_TABLE+" MATCH ?)",
+		e.matchExpression(terms, "OR"),
+	)
+}
+
+func (e *sqliteFTS5PostSearchEngine) rank(terms [][]string) clause.Expr {
+	// bm25 越小越相關
+	return clause.Expr{SQL: "-bm25(" + database.POST_SEARCH_FTS_TABLE + ")"}
+}
+
+// likePostSearchEngine 沒有全文索引時以 LIKE 比對 search_text，不計算相關度
+type likePostSearchEngine struct {
+	SearchUtils *pkg.SearchUtils
+}
+
+// pattern 前後補空白，確保以完整索引詞比對
+func (e *likePostSearchEngine) pattern(tokens []string) string {
+	if e.SearchUtils.IsCJKPrefixTerm(tokens) {
+		return "% " + tokens[0] + "%"
+	}
+	return "% " + strings.Join(tokens, " ") + " %"
+}
+
+func (e *likePostSearchEngine) match(db *gorm.DB, terms [][]string) *gorm.DB {
+	for _, tokens := range terms {
+		db = db.Where("(' ' || posts.search_text || ' ') LIKE ?", e.pattern(tokens))
+	}
+	return db
+}
+
+func (e *likePostSearchEngine) exclude(db *gorm.DB, terms [][]string) *gorm.DB {
+	for _, tokens := range terms {
+		db = db.Where("(' ' || posts.search_text || ' ') NOT LIKE ?", e.pattern(tokens))
+	}
+	return db
+}
+
+func (e *likePostSearchEngine) rank(terms [][]string) clause.Expr {
+	return clause.Expr{SQL: "0"}
+}
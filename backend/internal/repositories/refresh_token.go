@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository struct{}
+
+var refreshTokenRepositoryOnce sync.Once
+var refreshTokenRepository *RefreshTokenRepository
+
+func NewRefreshTokenRepository() *RefreshTokenRepository {
+	refreshTokenRepositoryOnce.Do(func() {
+		refreshTokenRepository = &RefreshTokenRepository{}
+	})
+	return refreshTokenRepository
+}
+
+func (r *RefreshTokenRepository) Create(ctx *gin.Context, refreshTokenBases []models.RefreshTokenBase) ([]models.RefreshToken, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	refreshTokens := make([]models.RefreshToken, len(refreshTokenBases))
+	for i, base := range refreshTokenBases {
+		refreshTokens[i] = models.RefreshToken{
+			TableModel:       models.TableModel{ID: uuid.New()},
+			RefreshTokenBase: base,
+		}
+	}
+	if err := db.Create(&refreshTokens).Error; err != nil {
+		return nil, err
+	}
+	return refreshTokens, nil
+}
+
+// GetByTokenHash 找不到時回傳 nil, nil
+func (r *RefreshTokenRepository) GetByTokenHash(ctx *gin.Context, tokenHash string) (*models.RefreshToken, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken := &models.RefreshToken{}
+	if err := db.Where("token_hash = ?", tokenHash).First(refreshToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return refreshToken, nil
+}
+
+// MarkUsed 標記 token 已被輪替，回傳是否由本次呼叫成功標記 (避免併發重複使用)
+func (r *RefreshTokenRepository) MarkUsed(ctx *gin.Context, refreshTokenID uuid.UUID) (bool, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return false, err
+	}
+	result := db.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", refreshTokenID).
+		Update("used_at", time.Now().Unix())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *RefreshTokenRepository) RevokeBySessionID(ctx *gin.Context, sessionID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Model(&models.RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now().Unix()).Error
+}
+
+func (r *RefreshTokenRepository) RevokeByUserID(ctx *gin.Context, userID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now().Unix()).Error
+}
+
+func (r *RefreshTokenRepository) DeleteByUserID(ctx *gin.Context, userID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Where("user_id = ?", userID).Delete(&models.RefreshToken{}).Error
+}
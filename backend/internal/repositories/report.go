@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+type ReportRepository struct {
+	ErrorUtils *pkg.ErrorUtils
+}
+
+var reportRepositoryOnce sync.Once
+var reportRepository *ReportRepository
+
+func NewReportRepository() *ReportRepository {
+	reportRepositoryOnce.Do(func() {
+		reportRepository = &ReportRepository{
+			ErrorUtils: pkg.NewErrorUtils(),
+		}
+	})
+	return reportRepository
+}
+
+// Create 使用者已檢舉過同一內容時回傳既有的檢舉 (created 為 false)
+func (r *ReportRepository) Create(ctx *gin.Context, reportBase models.ReportBase) (report *models.Report, created bool, err error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	// 使用 UUIDv7 (依時間遞增)，讓同一秒建立的檢舉依建立順序排列
+	reportID, err := uuid.NewV7()
+	if err != nil {
+		return nil, false, err
+	}
+	report = &models.Report{
+		TableModel: models.TableModel{ID: reportID},
+		ReportBase: reportBase,
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Omit("Reporter", "TargetAuthor").Create(report)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return report, true, nil
+	}
+
+	report = &models.Report{}
+	if err := db.Where("reporter_id = ? AND target_type = ? AND target_id = ?", reportBase.ReporterID, reportBase.TargetType, reportBase.TargetID).
+		First(report).Error; err != nil {
+		return nil, false, err
+	}
+	return report, false, nil
+}
+
+func (r *ReportRepository) GetByID(ctx *gin.Context, reportID uuid.UUID) (*models.Report, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &models.Report{}
+	if err := db.Where("id = ?", reportID).First(report).Error; err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetList 回傳檢舉，依建立時間由舊到新排序 (先檢舉的先處理)，status 為 nil 時不過濾狀態
+func (r *ReportRepository) GetList(ctx *gin.Context, status *models.ReportStatus, pagination *models.Pagination) ([]models.Report, uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	db = db.Model(&models.Report{})
+	if status != nil {
+		db = db.Where("status = ?", *status)
+	}
+	db = db.Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Name: "created_at"}},
+		{Column: clause.Column{Name: "id"}},
+	}})
+
+	totalCount := int64(0)
+	if err := db.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+	if pagination != nil {
+		if pagination.Limit <= 0 {
+			return nil, 0, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+		}
+		db = db.Offset(int(pagination.Offset)).Limit(int(pagination.Limit))
+	}
+	reports := []models.Report{}
+	if err := db.Find(&reports).Error; err != nil {
+		return nil, 0, err
+	}
+	return reports, uint(totalCount), nil
+}
+
+// ResolvePendingByTarget 將同一內容所有待處理的檢舉設為 status，回傳處理的筆數
+func (r *ReportRepository) ResolvePendingByTarget(ctx *gin.Context, targetType models.ReportTargetType, targetID uuid.UUID, status models.ReportStatus, resolvedByID uuid.UUID) (uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return 0, err
+	}
+	result := db.Model(&models.Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, models.ReportStatusPending).
+		Updates(map[string]any{
+			"status":         status,
+			"resolved_by_id": resolvedByID,
+			"resolved_at":    time.Now().Unix(),
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return uint(result.RowsAffected), nil
+}
+
+// HasPendingByTarget 回傳內容是否有 reason 的待處理檢舉
+func (r *ReportRepository) HasPendingByTarget(ctx *gin.Context, targetType models.ReportTargetType, targetID uuid.UUID, reason models.ReportReason) (bool, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return false, err
+	}
+	count := int64(0)
+	if err := db.Model(&models.Report{}).
+		Where("target_type = ? AND target_id = ? AND reason = ? AND status = ?", targetType, targetID, reason, models.ReportStatusPending).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// DeleteByUserID 刪除使用者提出的檢舉與檢舉其內容的檢舉
+func (r *ReportRepository) DeleteByUserID(ctx *gin.Context, userID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.Where("reporter_id = ? OR target_author_id = ?", userID, userID).Delete(&models.Report{}).Error
+}
@@ -3,22 +3,29 @@ package repositories
 import (
 	"backend/internal/middlewares"
 	"backend/internal/models"
+	"backend/internal/pkg"
 	"errors"
+	"strings"
 	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type TagRepository struct{}
+type TagRepository struct {
+	ErrorUtils *pkg.ErrorUtils
+}
 
 var tagRepositoryOnce sync.Once
 var tagRepository *TagRepository
 
 func NewTagRepository() *TagRepository {
 	tagRepositoryOnce.Do(func() {
-		tagRepository = &TagRepository{}
+		tagRepository = &TagRepository{
+			ErrorUtils: pkg.NewErrorUtils(),
+		}
 	})
 	return tagRepository
 }
@@ -75,3 +82,213 @@ func (r *TagRepository) GetByIDs(ctx *gin.Context, ids []uuid.UUID) ([]models.Ta
 
 	return tags, nil
 }
+
+// DeleteOrphansByIDs 刪除 tagIDs 中已無任何貼文關聯的標籤，有別名的標籤由管理員合併而來，予以保留
+func (r *TagRepository) DeleteOrphansByIDs(ctx *gin.Context, tagIDs []uuid.UUID) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	return db.
+		Where("id IN ?", tagIDs).
+		Where("NOT EXISTS (SELECT 1 FROM post_to_tag WHERE post_to_tag.tag_id = tags.id)").
+		Where("NOT EXISTS (SELECT 1 FROM tag_aliases WHERE tag_aliases.tag_id = tags.id)").
+		Delete(&models.Tag{}).Error
+}
+
+// GetByAlias 回傳別名所指的標籤，不存在時回傳 nil
+func (r *TagRepository) GetByAlias(ctx *gin.Context, name string) (*models.Tag, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := &models.Tag{}
+	if err := db.
+		Where("id IN (?)", db.Model(&models.TagAlias{}).Select("tag_id").Where("name = ?", name)).
+		First(tag).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// GetAliasNamesByID 回傳標籤的別名，依名稱排序
+func (r *TagRepository) GetAliasNamesByID(ctx *gin.Context, tagID uuid.UUID) ([]string, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	if err := db.Model(&models.TagAlias{}).
+		Where("tag_id = ?", tagID).
+		Order("name").
+		Pluck("name", &names).Error; err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// Merge 將來源標籤的貼文與別名移到目標標籤，來源標籤的名稱成為目標標籤的別名後刪除來源標籤
+func (r *TagRepository) Merge(ctx *gin.Context, sources []models.Tag, targetID uuid.UUID) error {
+	if len(sources) == 0 {
+		return nil
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+
+	sourceIDs := make([]uuid.UUID, len(sources))
+	aliases := make([]models.TagAlias, len(sources))
+	for i, source := range sources {
+		sourceIDs[i] = source.ID
+		aliases[i] = models.TagAlias{
+			TableModel: models.TableModel{
+				ID: uuid.New(),
+			},
+			TagAliasBase: models.TagAliasBase{
+				Name:  source.Name,
+				TagID: targetID,
+			},
+		}
+	}
+	if err := r.MergePosts(ctx, sourceIDs, targetID); err != nil {
+		return err
+	}
+	if err := db.Model(&models.TagAlias{}).Where("tag_id IN ?", sourceIDs).Update("tag_id", targetID).Error; err != nil {
+		return err
+	}
+	// 先刪除來源標籤，別名才不會與標籤名稱同時存在
+	if err := db.Where("id IN ?", sourceIDs).Delete(&models.Tag{}).Error; err != nil {
+		return err
+	}
+	return db.Create(&aliases).Error
+}
+
+// MergePosts 將來源標籤的貼文關聯移到目標標籤 (已關聯的貼文不重複)，並移除來源標籤的貼文關聯
+func (r *TagRepository) MergePosts(ctx *gin.Context, sourceTagIDs []uuid.UUID, targetTagID uuid.UUID) error {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return err
+	}
+	// 從 tags 選取目標標籤的 id，避免參數在 PostgreSQL 中被推斷為 text
+	if err := db.Exec(`INSERT INTO post_to_tag (post_id, tag_id)
+		SELECT DISTINCT post_to_tag.post_id, tags.id FROM post_to_tag, tags
+		WHERE tags.id = ? AND post_to_tag.tag_id IN ?
+		AND post_to_tag.post_id NOT IN (SELECT post_id FROM post_to_tag WHERE tag_id = ?)`,
+		targetTagID, sourceTagIDs, targetTagID,
+	).Error; err != nil {
+		return err
+	}
+	return db.Exec("DELETE FROM post_to_tag WHERE tag_id IN ?", sourceTagIDs).Error
+}
+
+// CountPostsByID 回傳標籤的貼文數 (不含被隱藏的貼文)
+func (r *TagRepository) CountPostsByID(ctx *gin.Context, tagID uuid.UUID) (uint, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	postCount := int64(0)
+	if err := db.Table("post_to_tag").
+		Joins("JOIN posts ON posts.id = post_to_tag.post_id").
+		Where("post_to_tag.tag_id = ? AND posts.hidden_at IS NULL", tagID).
+		Count(&postCount).Error; err != nil {
+		return 0, err
+	}
+	return uint(postCount), nil
+}
+
+// GetSummaries 回傳標籤與其貼文數，sort 為 popular 時貼文數多的在前 (相同時依名稱排序)
+func (r *TagRepository) GetSummaries(ctx *gin.Context, sort models.TagSort, pagination *models.Pagination) ([]models.TagSummary, uint, error) {
+	if pagination == nil || pagination.Limit <= 0 {
+		return nil, 0, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+	}
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	totalCount := int64(0)
+	if err := db.Model(&models.Tag{}).Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	orderColumns := []clause.OrderByColumn{
+		{Column: clause.Column{Table: "tags", Name: "name"}},
+	}
+	if sort == models.TagSortPopular {
+		orderColumns = append([]clause.OrderByColumn{
+			{Column: clause.Column{Name: "post_count", Raw: true}, Desc: true},
+		}, orderColumns...)
+	}
+	summaries := []models.TagSummary{}
+	if err := r.summaryQuery(db).
+		Order(clause.OrderBy{Columns: orderColumns}).
+		Offset(int(pagination.Offset)).
+		Limit(int(pagination.Limit)).
+		Scan(&summaries).Error; err != nil {
+		return nil, 0, err
+	}
+	return summaries, uint(totalCount), nil
+}
+
+// GetSummariesByPrefix 回傳名稱以 prefix 開頭 (不分大小寫) 的標籤，貼文數多的在前
+func (r *TagRepository) GetSummariesByPrefix(ctx *gin.Context, prefix string, limit uint) ([]models.TagSummary, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 跳脫 LIKE 的萬用字元，讓 prefix 以字面比對
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(prefix)) + "%"
+	summaries := []models.TagSummary{}
+	if err := r.summaryQuery(db).
+		Where(`LOWER(tags.name) LIKE ? ESCAPE '\'`, pattern).
+		Order("post_count DESC, tags.name").
+		Limit(int(limit)).
+		Scan(&summaries).Error; err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// GetTrendingSummaries 統計 since (Unix 秒) 之後建立的貼文所使用的標籤，貼文數多的在前 (相同時最近使用的在前)
+func (r *TagRepository) GetTrendingSummaries(ctx *gin.Context, since int64, limit uint) ([]models.TagSummary, error) {
+	db, err := middlewares.GetContentGORMDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := []models.TagSummary{}
+	if err := db.Table("post_to_tag").
+		Select("tags.id, tags.name, COUNT(*) AS post_count").
+		Joins("JOIN posts ON posts.id = post_to_tag.post_id").
+		Joins("JOIN tags ON tags.id = post_to_tag.tag_id").
+		Where("posts.created_at >= ? AND posts.hidden_at IS NULL", since).
+		Group("tags.id, tags.name").
+		Order("post_count DESC, MAX(posts.created_at) DESC, tags.name").
+		Limit(int(limit)).
+		Scan(&summaries).Error; err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// summaryQuery 標籤與其貼文數 (不含被隱藏的貼文)，沒有貼文的標籤貼文數為 0
+func (r *TagRepository) summaryQuery(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Tag{}).
+		Select("tags.id, tags.name, COUNT(posts.id) AS post_count").
+		Joins("LEFT JOIN post_to_tag ON post_to_tag.tag_id = tags.id").
+		Joins("LEFT JOIN posts ON posts.id = post_to_tag.post_id AND posts.hidden_at IS NULL").
+		Group("tags.id, tags.name")
+}
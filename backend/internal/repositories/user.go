@@ -3,21 +3,28 @@ package repositories
 import (
 	"backend/internal/middlewares"
 	"backend/internal/models"
+	"backend/internal/pkg"
+	"strings"
 	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type UserRepository struct{}
+type UserRepository struct {
+	ErrorUtils *pkg.ErrorUtils
+}
 
 var userOnce sync.Once
 var userRepository *UserRepository
 
 func NewUserRepository() *UserRepository {
 	userOnce.Do(func() {
-		userRepository = &UserRepository{}
+		userRepository = &UserRepository{
+			ErrorUtils: pkg.NewErrorUtils(),
+		}
 	})
 	return userRepository
 }
@@ -56,6 +63,72 @@ func (r *UserRepository) GetByEmail(ctx *gin.Context, email string) (*models.Use
 	return user, nil
 }
 
+func (r *UserRepository) GetList(ctx *gin.Context, filter *models.UserListFilter, pagination *models.Pagination) ([]models.User, uint, error) {
+	db := ctx.MustGet(middlewares.CONTEXT_KEY_GORM_DB).(*gorm.DB)
+	db = r.listQuery(db, filter).
+		Order(clause.OrderByColumn{
+			Column: clause.Column{Name: "created_at"},
+			Desc:   true,
+		})
+
+	totalCount := int64(0)
+	if err := db.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+	if pagination != nil {
+		if pagination.Limit <= 0 {
+			return nil, 0, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+		}
+		db = db.Offset(int(pagination.Offset)).Limit(int(pagination.Limit))
+	}
+	users := []models.User{}
+	if err := db.Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, uint(totalCount), nil
+}
+
+// GetListByCursor 依 (created_at, id) 由新到舊分頁，回傳下一頁的位置與總筆數 (未要求時為 nil)
+func (r *UserRepository) GetListByCursor(ctx *gin.Context, filter *models.UserListFilter, pagination *models.CursorPagination) ([]models.User, *models.Cursor, *uint, error) {
+	if pagination == nil || pagination.Limit <= 0 {
+		return nil, nil, nil, r.ErrorUtils.ServerInternalError("invalid pagination parameters")
+	}
+	db := ctx.MustGet(middlewares.CONTEXT_KEY_GORM_DB).(*gorm.DB)
+	db = r.listQuery(db, filter)
+
+	totalCount, err := countByCursorPagination(db, pagination)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	users := []models.User{}
+	if err := paginateByCursor(db, "users", pagination, true).Find(&users).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	users, nextCursor := takeCursorPage(users, pagination.Limit)
+	return users, nextCursor, totalCount, nil
+}
+
+func (r *UserRepository) listQuery(db *gorm.DB, filter *models.UserListFilter) *gorm.DB {
+	db = db.Model(&models.User{})
+	if filter != nil {
+		if filter.Keyword != "" {
+			keyword := "%" + strings.ToLower(filter.Keyword) + "%"
+			db = db.Where("LOWER(username) LIKE ? OR LOWER(email) LIKE ?", keyword, keyword)
+		}
+		if filter.Role != nil {
+			db = db.Where("role = ?", *filter.Role)
+		}
+		if filter.Suspended != nil {
+			if *filter.Suspended {
+				db = db.Where("suspended_at IS NOT NULL")
+			} else {
+				db = db.Where("suspended_at IS NULL")
+			}
+		}
+	}
+	return db
+}
+
 func (r *UserRepository) Create(ctx *gin.Context, userBases []models.UserBase) ([]models.User, error) {
 	db := ctx.MustGet(middlewares.CONTEXT_KEY_GORM_DB).(*gorm.DB)
 
@@ -72,6 +145,30 @@ func (r *UserRepository) Create(ctx *gin.Context, userBases []models.UserBase) (
 	return userSlice, nil
 }
 
+func (r *UserRepository) UpdateByID(ctx *gin.Context, userID uuid.UUID, values map[string]any) error {
+	db := ctx.MustGet(middlewares.CONTEXT_KEY_GORM_DB).(*gorm.DB)
+	if err := db.Model(&models.User{}).
+		Where(&models.User{TableModel: models.TableModel{ID: userID}}).
+		Updates(values).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// IncrementTokenVersion 使該使用者所有已簽發的 access token 失效
+func (r *UserRepository) IncrementTokenVersion(ctx *gin.Context, userID uuid.UUID) error {
+	db := ctx.MustGet(middlewares.CONTEXT_KEY_GORM_DB).(*gorm.DB)
+	return db.Model(&models.User{}).
+		Where(&models.User{TableModel: models.TableModel{ID: userID}}).
+		Update("token_version", gorm.Expr("token_version + 1")).Error
+}
+
+// ClearLikes 移除使用者對所有貼文的喜歡
+func (r *UserRepository) ClearLikes(ctx *gin.Context, userID uuid.UUID) error {
+	db := ctx.MustGet(middlewares.CONTEXT_KEY_GORM_DB).(*gorm.DB)
+	return db.Model(&models.User{TableModel: models.TableModel{ID: userID}}).Association("Likes").Clear()
+}
+
 func (r *UserRepository) DeleteByID(ctx *gin.Context, userID uuid.UUID) error {
 	db := ctx.MustGet(middlewares.CONTEXT_KEY_GORM_DB).(*gorm.DB)
 
@@ -0,0 +1,584 @@
+package routers
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/services"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AdminRouter struct {
+	ErrorUtils *pkg.ErrorUtils
+
+	UserService   *services.UserService
+	ReportService *services.ReportService
+	TagService    *services.TagService
+}
+
+var adminRouterOnce sync.Once
+var adminRouter *AdminRouter
+
+func NewAdminRouter() *AdminRouter {
+	adminRouterOnce.Do(func() {
+		adminRouter = &AdminRouter{
+			ErrorUtils: pkg.NewErrorUtils(),
+
+			UserService:   services.NewUserService(),
+			ReportService: services.NewReportService(),
+			TagService:    services.NewTagService(),
+		}
+	})
+	return adminRouter
+}
+
+func (r *AdminRouter) Bind(_router *gin.RouterGroup) {
+	router := _router.Group("/admin",
+		middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+		middlewares.RequireRole(models.RoleAdmin),
+	)
+	// GET
+	{
+		router.GET("/user/list", r.GetUsers)
+		router.GET("/report/list", r.GetReports)
+		router.GET("/audit-log/list", r.GetAuditLogs)
+	}
+	// POST
+	{
+		router.POST("/user/:userID/password/reset", r.ResetUserPassword)
+		router.POST("/tag/merge", r.MergeTags)
+	}
+	// PUT
+	{
+		router.PUT("/user/:userID/role", r.UpdateUserRole)
+		router.PUT("/user/:userID/suspend", r.SuspendUser)
+		router.PUT("/user/:userID/unsuspend", r.UnsuspendUser)
+		router.PUT("/report/:reportID/resolve", r.ResolveReport)
+	}
+	// DELETE
+	{
+		router.DELETE("/user/:userID", r.DeleteUser)
+	}
+}
+
+// @title Admin API
+// @Summary List and search users
+// @Tags Admin
+// @Security AccessToken
+// @Accept text/plain
+// @Produce application/json
+// @Param keyword query string false "Search username or email"
+// @Param role query string false "Role (admin, normal_customer)"
+// @Param suspended query bool false "Suspended"
+// @Param offset query string false "Offset"
+// @Param limit query string false "Limit"
+// @Param cursor query string false "Cursor of the next page, passing cursor (empty for the first page) switches to cursor pagination"
+// @Param withTotalCount query bool false "Return totalCount in cursor pagination"
+// @Success 200 {object} models.PaginationResponse[models.AdminGetUsersResponseItem]
+// @Success 200 {object} models.CursorPaginationResponse[models.AdminGetUsersResponseItem]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/user/list [get]
+func (r *AdminRouter) GetUsers(ctx *gin.Context) {
+	filter := &models.UserListFilter{Keyword: ctx.Query("keyword")}
+	if queryRole := ctx.Query("role"); queryRole != "" {
+		role, ok := models.ParseRole(queryRole)
+		if !ok {
+			ctx.JSON(400, models.ErrorResponse{Error: "invalid role"})
+			return
+		}
+		filter.Role = &role
+	}
+	if querySuspended := ctx.Query("suspended"); querySuspended != "" {
+		suspended, err := strconv.ParseBool(querySuspended)
+		if err != nil {
+			ctx.JSON(400, models.ErrorResponse{Error: "invalid suspended"})
+			return
+		}
+		filter.Suspended = &suspended
+	}
+
+	if isCursorPagination(ctx) {
+		pagination, ok := parseCursorPagination(ctx)
+		if !ok {
+			return
+		}
+		users, nextCursor, totalCount, err := r.UserService.GetListByCursor(ctx, filter, pagination)
+		if err != nil {
+			ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(200, newCursorPaginationResponse(toAdminGetUsersResponseItems(users), nextCursor, totalCount))
+		return
+	}
+
+	offset, err := strconv.ParseUint(ctx.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid offset"})
+		return
+	}
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
+	if err != nil || limit == 0 {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		return
+	}
+	pagination := &models.Pagination{
+		Offset: uint(offset),
+		Limit:  uint(limit),
+	}
+	users, totalCount, err := r.UserService.GetList(ctx, filter, pagination)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.PaginationResponse[models.AdminGetUsersResponseItem]{
+		Data:       toAdminGetUsersResponseItems(users),
+		TotalCount: totalCount,
+		Pagination: pagination,
+	})
+}
+
+func toAdminGetUsersResponseItems(users []models.User) []models.AdminGetUsersResponseItem {
+	items := make([]models.AdminGetUsersResponseItem, len(users))
+	for i, user := range users {
+		var suspendedAt *string
+		if user.SuspendedAt != nil {
+			suspendedAt = pkg.GetPointer(time.Unix(*user.SuspendedAt, 0).Format(time.RFC3339))
+		}
+		items[i] = models.AdminGetUsersResponseItem{
+			ID:                    user.ID,
+			Username:              user.Username,
+			Email:                 user.Email,
+			Role:                  user.Role.String(),
+			SuspendedAt:           suspendedAt,
+			SuspendedReason:       user.SuspendedReason,
+			PasswordResetRequired: user.PasswordResetRequired,
+			CreatedAt:             time.Unix(user.CreatedAt, 0).Format(time.RFC3339),
+			UpdatedAt:             time.Unix(user.UpdatedAt, 0).Format(time.RFC3339),
+		}
+	}
+	return items
+}
+
+// @title Admin API
+// @Summary Change role of a user
+// @Tags Admin
+// @Security AccessToken
+// @Accept application/json
+// @Produce application/json
+// @Param userID path string true "User ID"
+// @Param request body models.AdminUpdateUserRoleRequest true "Role (admin, normal_customer)"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/user/{userID}/role [put]
+func (r *AdminRouter) UpdateUserRole(ctx *gin.Context) {
+	reqBody := &models.AdminUpdateUserRoleRequest{}
+	if err := ctx.ShouldBindJSON(reqBody); err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	role, ok := models.ParseRole(reqBody.Role)
+	if !ok {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid role"})
+		return
+	}
+	user, ok := r.getTargetUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := r.UserService.UpdateRole(ctx, user.ID, role); err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.SuccessResponse{Success: true})
+}
+
+// @title Admin API
+// @Summary Suspend a user
+// @Tags Admin
+// @Security AccessToken
+// @Accept application/json
+// @Produce application/json
+// @Param userID path string true "User ID"
+// @Param request body models.AdminSuspendUserRequest false "Suspend reason"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/user/{userID}/suspend [put]
+func (r *AdminRouter) SuspendUser(ctx *gin.Context) {
+	reqBody := &models.AdminSuspendUserRequest{}
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(reqBody); err != nil {
+			ctx.JSON(400, models.ErrorResponse{Error: "invalid request body"})
+			return
+		}
+	}
+	user, ok := r.getTargetUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := r.UserService.Suspend(ctx, user.ID, reqBody.Reason); err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.SuccessResponse{Success: true})
+}
+
+// @title Admin API
+// @Summary Unsuspend a user
+// @Tags Admin
+// @Security AccessToken
+// @Produce application/json
+// @Param userID path string true "User ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/user/{userID}/unsuspend [put]
+func (r *AdminRouter) UnsuspendUser(ctx *gin.Context) {
+	user, ok := r.getTargetUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := r.UserService.Unsuspend(ctx, user.ID); err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.SuccessResponse{Success: true})
+}
+
+// @title Admin API
+// @Summary Force password reset of a user
+// @Description Returns a one-time temporary password, all sessions of the user are revoked
+// @Tags Admin
+// @Security AccessToken
+// @Produce application/json
+// @Param userID path string true "User ID"
+// @Success 200 {object} models.AdminResetUserPasswordResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/user/{userID}/password/reset [post]
+func (r *AdminRouter) ResetUserPassword(ctx *gin.Context) {
+	user, ok := r.getTargetUser(ctx)
+	if !ok {
+		return
+	}
+
+	temporaryPassword, err := r.UserService.ForcePasswordReset(ctx, user)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.AdminResetUserPasswordResponse{TemporaryPassword: temporaryPassword})
+}
+
+// @title Admin API
+// @Summary Permanently delete a user with posts, comments and likes
+// @Tags Admin
+// @Security AccessToken
+// @Produce application/json
+// @Param userID path string true "User ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/user/{userID} [delete]
+func (r *AdminRouter) DeleteUser(ctx *gin.Context) {
+	user, ok := r.getTargetUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := r.UserService.DeleteWithContent(ctx, user); err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.SuccessResponse{Success: true})
+}
+
+// getTargetUser 解析路由中的 userID，管理員不可對自己操作
+func (r *AdminRouter) getTargetUser(ctx *gin.Context) (*models.User, bool) {
+	userID, err := uuid.Parse(ctx.Param("userID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid user ID"})
+		return nil, false
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+	if tokenData.UserID == userID {
+		ctx.JSON(400, models.ErrorResponse{Error: "cannot modify your own account"})
+		return nil, false
+	}
+	user, err := r.UserService.GetByID(ctx, userID)
+	if err != nil {
+		ctx.JSON(404, models.ErrorResponse{Error: "user not found"})
+		return nil, false
+	}
+	return user, true
+}
+
+// @title Admin API
+// @Summary Moderation queue of reports
+// @Description Oldest first. status is pending (default), dismissed, actioned or all
+// @Tags Admin
+// @Security AccessToken
+// @Produce application/json
+// @Param status query string false "Status (pending, dismissed, actioned, all)"
+// @Param offset query string false "Offset"
+// @Param limit query string false "Limit"
+// @Success 200 {object} models.PaginationResponse[models.AdminGetReportsResponseItem]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/report/list [get]
+func (r *AdminRouter) GetReports(ctx *gin.Context) {
+	var status *models.ReportStatus
+	if queryStatus := ctx.DefaultQuery("status", string(models.ReportStatusPending)); queryStatus != "all" {
+		parsedStatus, ok := models.ParseReportStatus(queryStatus)
+		if !ok {
+			ctx.JSON(400, models.ErrorResponse{Error: "invalid status"})
+			return
+		}
+		status = &parsedStatus
+	}
+	offset, err := strconv.ParseUint(ctx.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid offset"})
+		return
+	}
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
+	if err != nil || limit == 0 {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		return
+	}
+	pagination := &models.Pagination{
+		Offset: uint(offset),
+		Limit:  uint(limit),
+	}
+
+	reports, totalCount, err := r.ReportService.GetList(ctx, status, pagination)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	responseData := make([]models.AdminGetReportsResponseItem, len(reports))
+	for i, report := range reports {
+		var resolvedAt *string
+		if report.ResolvedAt != nil {
+			resolvedAt = pkg.GetPointer(time.Unix(*report.ResolvedAt, 0).Format(time.RFC3339))
+		}
+		responseData[i] = models.AdminGetReportsResponseItem{
+			ID:             report.ID,
+			ReporterID:     report.ReporterID,
+			TargetType:     string(report.TargetType),
+			TargetID:       report.TargetID,
+			TargetAuthorID: report.TargetAuthorID,
+			TargetContent:  report.TargetContent,
+			Reason:         string(report.Reason),
+			Detail:         report.Detail,
+			Status:         string(report.Status),
+			ResolvedByID:   report.ResolvedByID,
+			ResolvedAt:     resolvedAt,
+			CreatedAt:      time.Unix(report.CreatedAt, 0).Format(time.RFC3339),
+		}
+	}
+	ctx.JSON(200, models.PaginationResponse[models.AdminGetReportsResponseItem]{
+		Data:       responseData,
+		TotalCount: totalCount,
+		Pagination: pagination,
+	})
+}
+
+// @title Admin API
+// @Summary Resolve a report
+// @Description action is dismiss, hide (content is excluded from every query), delete (a comment with replies is kept as a tombstone) or suspend (the author).
+// @Description All pending reports of the same content are resolved together and the action is recorded in the audit log
+// @Tags Admin
+// @Security AccessToken
+// @Accept application/json
+// @Produce application/json
+// @Param reportID path string true "Report ID"
+// @Param request body models.AdminResolveReportRequest true "Action and note"
+// @Success 200 {object} models.AdminResolveReportResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse "Report or content not found"
+// @Failure 409 {object} models.ErrorResponse "Report already resolved"
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/report/{reportID}/resolve [put]
+func (r *AdminRouter) ResolveReport(ctx *gin.Context) {
+	reportID, err := uuid.Parse(ctx.Param("reportID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid report ID"})
+		return
+	}
+	reqBody := &models.AdminResolveReportRequest{}
+	if err := ctx.ShouldBindJSON(reqBody); err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	action, ok := models.ParseModerationAction(reqBody.Action)
+	if !ok {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid action"})
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	resolvedCount, err := r.ReportService.Resolve(ctx, tokenData.UserID, reportID, action, strings.TrimSpace(reqBody.Note))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrReportNotFound), errors.Is(err, services.ErrReportTargetNotFound):
+			ctx.JSON(404, models.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, services.ErrReportResolved):
+			ctx.JSON(409, models.ErrorResponse{Error: err.Error()})
+		case r.ErrorUtils.IsServerInternalError(err.Error()):
+			ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		default:
+			ctx.JSON(400, models.ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+	ctx.JSON(200, models.AdminResolveReportResponse{
+		Action:        string(action),
+		ResolvedCount: resolvedCount,
+	})
+}
+
+// @title Admin API
+// @Summary Audit log of moderation actions
+// @Description Newest first
+// @Tags Admin
+// @Security AccessToken
+// @Produce application/json
+// @Param targetID query string false "Only actions on this post or comment"
+// @Param offset query string false "Offset"
+// @Param limit query string false "Limit"
+// @Success 200 {object} models.PaginationResponse[models.AdminGetAuditLogsResponseItem]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/audit-log/list [get]
+func (r *AdminRouter) GetAuditLogs(ctx *gin.Context) {
+	var targetID *uuid.UUID
+	if queryTargetID := ctx.Query("targetID"); queryTargetID != "" {
+		parsedTargetID, err := uuid.Parse(queryTargetID)
+		if err != nil {
+			ctx.JSON(400, models.ErrorResponse{Error: "invalid target ID"})
+			return
+		}
+		targetID = &parsedTargetID
+	}
+	offset, err := strconv.ParseUint(ctx.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid offset"})
+		return
+	}
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
+	if err != nil || limit == 0 {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		return
+	}
+	pagination := &models.Pagination{
+		Offset: uint(offset),
+		Limit:  uint(limit),
+	}
+
+	auditLogs, totalCount, err := r.ReportService.GetAuditLogs(ctx, targetID, pagination)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	responseData := make([]models.AdminGetAuditLogsResponseItem, len(auditLogs))
+	for i, auditLog := range auditLogs {
+		responseData[i] = models.AdminGetAuditLogsResponseItem{
+			ID:             auditLog.ID,
+			ActorID:        auditLog.ActorID,
+			Action:         string(auditLog.Action),
+			TargetType:     string(auditLog.TargetType),
+			TargetID:       auditLog.TargetID,
+			TargetAuthorID: auditLog.TargetAuthorID,
+			ReportID:       auditLog.ReportID,
+			Note:           auditLog.Note,
+			CreatedAt:      time.Unix(auditLog.CreatedAt, 0).Format(time.RFC3339),
+		}
+	}
+	ctx.JSON(200, models.PaginationResponse[models.AdminGetAuditLogsResponseItem]{
+		Data:       responseData,
+		TotalCount: totalCount,
+		Pagination: pagination,
+	})
+}
+
+// @title Admin API
+// @Summary Merge tags
+// @Description Move the posts of the source tags to the target tag and delete the source tags. The names of the source tags become aliases of the target tag
+// @Tags Admin
+// @Security AccessToken
+// @Accept application/json
+// @Produce application/json
+// @Param request body models.AdminMergeTagsRequest true "Merge request"
+// @Success 200 {object} models.AdminMergeTagsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/admin/tag/merge [post]
+func (r *AdminRouter) MergeTags(ctx *gin.Context) {
+	reqBody := &models.AdminMergeTagsRequest{}
+	if err := ctx.ShouldBindJSON(reqBody); err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	tag, err := r.TagService.Merge(ctx, reqBody.SourceNames, reqBody.TargetName)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTagNotFound):
+			ctx.JSON(404, models.ErrorResponse{Error: err.Error()})
+		case r.TagService.ErrorUtils.IsServerInternalError(err.Error()):
+			ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		default:
+			ctx.JSON(400, models.ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+	aliases, postCount, ok := getTagAliasesAndPostCount(ctx, r.TagService, tag)
+	if !ok {
+		return
+	}
+	ctx.JSON(200, models.AdminMergeTagsResponse{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		Aliases:   aliases,
+		PostCount: postCount,
+	})
+}
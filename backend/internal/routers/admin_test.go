@@ -0,0 +1,191 @@
+package routers
+
+import (
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/tests"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminRouter(t *testing.T) {
+	server, apiRouter, _, db, cleanup := tests.SetupTestServer("test_admin_router.db")
+	defer cleanup()
+
+	NewUserRouter().Bind(apiRouter)
+	NewPostRouter().Bind(apiRouter)
+	NewCommentRouter().Bind(apiRouter)
+	NewAdminRouter().Bind(apiRouter)
+
+	_, adminLoginData, err := tests.SetupTestAdminUser(server, db)
+	require.NoError(t, err)
+	require.NotEmpty(t, adminLoginData.AccessToken)
+
+	login := func(email string, password string) *httptest.ResponseRecorder {
+		return tests.SendTestRequest(server, "POST", "/api/user/login", "", models.UserLoginRequest{Email: email, Password: password})
+	}
+
+	t.Run("權限檢查", func(t *testing.T) {
+		_, userLoginData, err := tests.SetupTestUser(server)
+		require.NoError(t, err)
+
+		recorder := tests.SendTestRequest(server, "GET", "/api/admin/user/list", "", nil)
+		assert.Equal(t, 401, recorder.Code, "未登入應該回傳 401")
+		recorder = tests.SendTestRequest(server, "GET", "/api/admin/user/list", userLoginData.AccessToken, nil)
+		assert.Equal(t, 403, recorder.Code, "一般用戶應該回傳 403")
+	})
+
+	t.Run("GetUsers", func(t *testing.T) {
+		userData, _, err := tests.SetupTestUser(server)
+		require.NoError(t, err)
+
+		recorder := tests.SendTestRequest(server, "GET", "/api/admin/user/list?keyword="+userData.Username+"&limit=5", adminLoginData.AccessToken, nil)
+		assert.Equal(t, 200, recorder.Code)
+		response := &models.PaginationResponse[models.AdminGetUsersResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, userData.ID, response.Data[0].ID)
+		assert.Equal(t, "normal_customer", response.Data[0].Role)
+		assert.Equal(t, uint(1), response.TotalCount)
+
+		recorder = tests.SendTestRequest(server, "GET", "/api/admin/user/list?role=unknown", adminLoginData.AccessToken, nil)
+		assert.Equal(t, 400, recorder.Code, "無效的角色應該回傳 400")
+	})
+
+	t.Run("GetUsers - cursor 分頁", func(t *testing.T) {
+		for range 2 {
+			_, _, err := tests.SetupTestUser(server)
+			require.NoError(t, err)
+		}
+		userCount := int64(0)
+		require.NoError(t, db.Model(&models.User{}).Count(&userCount).Error)
+
+		seen := map[uuid.UUID]bool{}
+		query := "?cursor=&limit=2&withTotalCount=true"
+		for page := 0; ; page++ {
+			require.Less(t, page, int(userCount), "分頁應該結束")
+			recorder := tests.SendTestRequest(server, "GET", "/api/admin/user/list"+query, adminLoginData.AccessToken, nil)
+			require.Equal(t, 200, recorder.Code)
+			response := &models.CursorPaginationResponse[models.AdminGetUsersResponseItem]{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+			if page == 0 {
+				require.NotNil(t, response.TotalCount)
+				assert.Equal(t, uint(userCount), *response.TotalCount)
+			} else {
+				assert.Nil(t, response.TotalCount, "未要求時不回傳總筆數")
+			}
+			for _, user := range response.Data {
+				assert.False(t, seen[user.ID], "分頁之間不應重複")
+				seen[user.ID] = true
+			}
+			if response.NextCursor == nil {
+				break
+			}
+			query = "?limit=2&cursor=" + *response.NextCursor
+		}
+		assert.Len(t, seen, int(userCount), "應該走訪所有用戶")
+
+		recorder := tests.SendTestRequest(server, "GET", "/api/admin/user/list?cursor=invalid", adminLoginData.AccessToken, nil)
+		assert.Equal(t, 400, recorder.Code, "無效的 cursor 應該回傳 400")
+	})
+
+	t.Run("UpdateUserRole", func(t *testing.T) {
+		userData, userLoginData, err := tests.SetupTestUser(server)
+		require.NoError(t, err)
+
+		recorder := tests.SendTestRequest(server, "PUT", "/api/admin/user/"+userData.ID.String()+"/role", adminLoginData.AccessToken, models.AdminUpdateUserRoleRequest{Role: "admin"})
+		assert.Equal(t, 200, recorder.Code)
+
+		// 舊 Token 失效，重新登入後取得管理員權限
+		recorder = tests.SendTestRequest(server, "GET", "/api/admin/user/list", userLoginData.AccessToken, nil)
+		assert.Equal(t, 401, recorder.Code, "角色變更後舊 Token 應該失效")
+		recorder = login(userData.Email, "password123")
+		require.Equal(t, 200, recorder.Code)
+		newLoginData := &models.UserLoginResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), newLoginData))
+		recorder = tests.SendTestRequest(server, "GET", "/api/admin/user/list", newLoginData.AccessToken, nil)
+		assert.Equal(t, 200, recorder.Code, "應該具備管理員權限")
+
+		recorder = tests.SendTestRequest(server, "PUT", "/api/admin/user/"+adminLoginData.ID.String()+"/role", adminLoginData.AccessToken, models.AdminUpdateUserRoleRequest{Role: "normal_customer"})
+		assert.Equal(t, 400, recorder.Code, "不可變更自己的角色")
+	})
+
+	t.Run("SuspendUser", func(t *testing.T) {
+		userData, userLoginData, err := tests.SetupTestUser(server)
+		require.NoError(t, err)
+
+		recorder := tests.SendTestRequest(server, "PUT", "/api/admin/user/"+userData.ID.String()+"/suspend", adminLoginData.AccessToken, models.AdminSuspendUserRequest{Reason: pkg.GetPointer("spam")})
+		assert.Equal(t, 200, recorder.Code)
+
+		recorder = tests.SendTestRequest(server, "POST", "/api/user/logout", userLoginData.AccessToken, nil)
+		assert.Equal(t, 401, recorder.Code, "停權後 Token 應該失效")
+		recorder = login(userData.Email, "password123")
+		assert.Equal(t, 403, recorder.Code, "停權帳號不可登入")
+
+		recorder = tests.SendTestRequest(server, "PUT", "/api/admin/user/"+userData.ID.String()+"/unsuspend", adminLoginData.AccessToken, nil)
+		assert.Equal(t, 200, recorder.Code)
+		recorder = login(userData.Email, "password123")
+		assert.Equal(t, 200, recorder.Code, "解除停權後可登入")
+	})
+
+	t.Run("ResetUserPassword", func(t *testing.T) {
+		userData, _, err := tests.SetupTestUser(server)
+		require.NoError(t, err)
+
+		recorder := tests.SendTestRequest(server, "POST", "/api/admin/user/"+userData.ID.String()+"/password/reset", adminLoginData.AccessToken, nil)
+		assert.Equal(t, 200, recorder.Code)
+		response := &models.AdminResetUserPasswordResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+		assert.NotEmpty(t, response.TemporaryPassword)
+
+		recorder = login(userData.Email, "password123")
+		assert.Equal(t, 400, recorder.Code, "舊密碼應該失效")
+		recorder = login(userData.Email, response.TemporaryPassword)
+		require.Equal(t, 200, recorder.Code, "臨時密碼可登入")
+		loginData := &models.UserLoginResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), loginData))
+		assert.True(t, loginData.PasswordResetRequired, "需要變更密碼")
+		recorder = tests.SendTestRequest(server, "POST", "/api/post", loginData.AccessToken, models.PostCreateRequest{Content: "post"})
+		assert.Equal(t, 403, recorder.Code, "變更密碼前不可使用其他 API")
+
+		recorder = tests.SendTestRequest(server, "PUT", "/api/user/password", loginData.AccessToken, models.UserChangePasswordRequest{
+			OldPassword: response.TemporaryPassword,
+			NewPassword: "newpass123",
+		})
+		assert.Equal(t, 200, recorder.Code)
+		recorder = tests.SendTestRequest(server, "POST", "/api/user/logout", loginData.AccessToken, nil)
+		assert.Equal(t, 401, recorder.Code, "變更密碼後所有 session 應該失效")
+		recorder = login(userData.Email, "newpass123")
+		require.Equal(t, 200, recorder.Code)
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), loginData))
+		assert.False(t, loginData.PasswordResetRequired, "變更密碼後不需要重設")
+	})
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		userData, userLoginData, err := tests.SetupTestUser(server)
+		require.NoError(t, err)
+		postData, err := tests.SetupTestPost(server, userLoginData.AccessToken)
+		require.NoError(t, err)
+		recorder := tests.SendTestRequest(server, "POST", "/api/comment", userLoginData.AccessToken, models.CommentCreateRequest{PostID: postData.ID, Content: "comment"})
+		require.Equal(t, 200, recorder.Code)
+
+		recorder = tests.SendTestRequest(server, "DELETE", "/api/admin/user/"+userData.ID.String(), adminLoginData.AccessToken, nil)
+		assert.Equal(t, 200, recorder.Code)
+
+		count := int64(0)
+		db.Model(&models.User{}).Where("id = ?", userData.ID).Count(&count)
+		assert.Equal(t, int64(0), count, "使用者應該已刪除")
+		db.Model(&models.Post{}).Where("author_id = ?", userData.ID).Count(&count)
+		assert.Equal(t, int64(0), count, "貼文應該已刪除")
+		db.Model(&models.Comment{}).Where("post_id = ?", postData.ID).Count(&count)
+		assert.Equal(t, int64(0), count, "評論應該已刪除")
+
+		recorder = tests.SendTestRequest(server, "DELETE", "/api/admin/user/"+userData.ID.String(), adminLoginData.AccessToken, nil)
+		assert.Equal(t, 404, recorder.Code, "使用者不存在")
+	})
+}
@@ -10,28 +10,29 @@ import (
 	"sync"
 
 	"github.com/gin-gonic/gin"
-	"github.com/tmc/langchaingo/llms/openai"
+	"github.com/tmc/langchaingo/llms"
 )
 
 type AIRouter struct {
 	ErrorUtils *pkg.ErrorUtils
 
-	ChatModel *openai.LLM
+	// ChatModel 為 nil 時停用 AI，所有 AI API 回傳 503
+	ChatModel llms.Model
 	AIService *services.AIService
 }
 
 var aiRouterOnce sync.Once
 var aiRouter *AIRouter
 
+// NewAIRouter 未設定模型供應者或無法建立模型時停用 AI (只記錄錯誤，不影響伺服器啟動)
 func NewAIRouter(modelsConfigs *models.AIModelConfigs) *AIRouter {
 	aiRouterOnce.Do(func() {
-		chatModel, err := openai.New(
-			openai.WithToken(modelsConfigs.ChatModel.APIKey),
-			openai.WithBaseURL(modelsConfigs.ChatModel.BaseURL),
-			openai.WithModel(modelsConfigs.ChatModel.ModelName),
-		)
+		chatModel, err := services.NewAIModel(&modelsConfigs.ChatModel)
 		if err != nil {
-			log.Fatal(err)
+			log.Printf("Failed to create chat model, AI is disabled: %v\n", err)
+			chatModel = nil
+		} else if chatModel == nil {
+			log.Println("No AI provider configured, AI is disabled")
 		}
 
 		aiRouter = &AIRouter{
@@ -48,13 +49,23 @@ func (r *AIRouter) Bind(_router *gin.RouterGroup) {
 	router := _router.Group("/ai")
 	// POST
 	{
-		router.POST("/generate/text/create-post-content", middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken), r.CreatePostContent)
-		router.POST("/generate/text/create-post-content/stream", middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken), r.CreatePostContentStream)
-		router.POST("/generate/text/content-optimize", middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken), r.ContentOptimization)
-		router.POST("/generate/text/content-optimize/stream", middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken), r.ContentOptimizationStream)
+		router.POST("/generate/text/create-post-content", middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken), r.RequireChatModel, r.CreatePostContent)
+		router.POST("/generate/text/create-post-content/stream", middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken), r.RequireChatModel, r.CreatePostContentStream)
+		router.POST("/generate/text/content-optimize", middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken), r.RequireChatModel, r.ContentOptimization)
+		router.POST("/generate/text/content-optimize/stream", middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken), r.RequireChatModel, r.ContentOptimizationStream)
 	}
 }
 
+// RequireChatModel 停用 AI 時回傳 503
+func (r *AIRouter) RequireChatModel(ctx *gin.Context) {
+	if r.ChatModel == nil {
+		ctx.JSON(503, models.ErrorResponse{Error: "AI is not available"})
+		ctx.Abort()
+		return
+	}
+	ctx.Next()
+}
+
 // @title AI API
 // @Summary Create post content using AI
 // @Tags AI
@@ -65,6 +76,7 @@ func (r *AIRouter) Bind(_router *gin.RouterGroup) {
 // @Success 200 {object} models.AIGenerateTextCreatePostContentResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse "AI is disabled"
 // @Router /api/ai/generate/text/create-post-content [post]
 func (r *AIRouter) CreatePostContent(ctx *gin.Context) {
 	reqBody := &models.AIGenerateTextCreatePostContentRequest{}
@@ -95,6 +107,7 @@ func (r *AIRouter) CreatePostContent(ctx *gin.Context) {
 // @Success 200 {string} string "Streaming response"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse "AI is disabled"
 // @Router /api/ai/generate/text/create-post-content/stream [post]
 func (r *AIRouter) CreatePostContentStream(ctx *gin.Context) {
 	reqBody := &models.AIGenerateTextCreatePostContentRequest{}
@@ -139,6 +152,7 @@ func (r *AIRouter) CreatePostContentStream(ctx *gin.Context) {
 // @Success 200 {object} models.AIGenerateTextContentOptimizationResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse "AI is disabled"
 // @Router /api/ai/generate/text/content-optimize [post]
 func (r *AIRouter) ContentOptimization(ctx *gin.Context) {
 	reqBody := &models.AIGenerateTextContentOptimizationRequest{}
@@ -169,6 +183,7 @@ func (r *AIRouter) ContentOptimization(ctx *gin.Context) {
 // @Success 200 {string} string "Streaming response"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse "AI is disabled"
 // @Router /api/ai/generate/text/content-optimize/stream [post]
 func (r *AIRouter) ContentOptimizationStream(ctx *gin.Context) {
 	reqBody := &models.AIGenerateTextContentOptimizationRequest{}
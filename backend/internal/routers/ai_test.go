@@ -0,0 +1,62 @@
+package routers
+
+import (
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/internal/tests"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAIRouter(t *testing.T) {
+	server, apiRouter, _, _, cleanup := tests.SetupTestServer("test_ai_router.db")
+	defer cleanup()
+
+	NewUserRouter().Bind(apiRouter)
+	// 未設定模型供應者，停用 AI
+	aiRouter := NewAIRouter(&models.AIModelConfigs{})
+	aiRouter.Bind(apiRouter)
+	defer func() { aiRouter.ChatModel = nil }()
+
+	_, loginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+
+	createPostContentRequest := &models.AIGenerateTextCreatePostContentRequest{Topic: "咖啡", Style: "輕鬆"}
+	contentOptimizationRequest := &models.AIGenerateTextContentOptimizationRequest{Context: "今天喝咖啡", Style: "輕鬆"}
+
+	t.Run("停用 AI 時回傳 503", func(t *testing.T) {
+		assert.Nil(t, aiRouter.ChatModel)
+		assert.Equal(t, 401, tests.SendTestRequest(server, "POST", "/api/ai/generate/text/create-post-content", "", createPostContentRequest).Code, "先驗證身分")
+		assert.Equal(t, 503, tests.SendTestRequest(server, "POST", "/api/ai/generate/text/create-post-content", loginData.AccessToken, createPostContentRequest).Code)
+		assert.Equal(t, 503, tests.SendTestRequest(server, "POST", "/api/ai/generate/text/create-post-content/stream", loginData.AccessToken, createPostContentRequest).Code)
+		assert.Equal(t, 503, tests.SendTestRequest(server, "POST", "/api/ai/generate/text/content-optimize", loginData.AccessToken, contentOptimizationRequest).Code)
+		assert.Equal(t, 503, tests.SendTestRequest(server, "POST", "/api/ai/generate/text/content-optimize/stream", loginData.AccessToken, contentOptimizationRequest).Code)
+	})
+
+	t.Run("使用固定輸出的模型", func(t *testing.T) {
+		aiRouter.ChatModel = &services.FakeAIModel{Response: "來杯咖啡 #咖啡"}
+
+		assert.Equal(t, 401, tests.SendTestRequest(server, "POST", "/api/ai/generate/text/create-post-content", "", createPostContentRequest).Code)
+		assert.Equal(t, 400, tests.SendTestRequest(server, "POST", "/api/ai/generate/text/create-post-content", loginData.AccessToken, map[string]string{}).Code)
+
+		recorder := tests.SendTestRequest(server, "POST", "/api/ai/generate/text/create-post-content", loginData.AccessToken, createPostContentRequest)
+		require.Equal(t, 200, recorder.Code)
+		createPostContentResponse := &models.AIGenerateTextCreatePostContentResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), createPostContentResponse))
+		assert.Equal(t, "來杯咖啡 #咖啡", createPostContentResponse.Content)
+
+		recorder = tests.SendTestRequest(server, "POST", "/api/ai/generate/text/content-optimize", loginData.AccessToken, contentOptimizationRequest)
+		require.Equal(t, 200, recorder.Code)
+		contentOptimizationResponse := &models.AIGenerateTextContentOptimizationResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), contentOptimizationResponse))
+		assert.Equal(t, "來杯咖啡 #咖啡", contentOptimizationResponse.Content)
+
+		recorder = tests.SendTestRequest(server, "POST", "/api/ai/generate/text/content-optimize/stream", loginData.AccessToken, contentOptimizationRequest)
+		require.Equal(t, 200, recorder.Code)
+		assert.Equal(t, "text/event-stream", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, "data: 來杯咖啡 #咖啡\n\ndata: [DONE]\n\n", recorder.Body.String())
+	})
+}
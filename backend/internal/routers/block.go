@@ -0,0 +1,177 @@
+package routers
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/services"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BlockRouter struct {
+	BlockService *services.BlockService
+	UserService  *services.UserService
+}
+
+var blockRouterOnce sync.Once
+var blockRouter *BlockRouter
+
+func NewBlockRouter() *BlockRouter {
+	blockRouterOnce.Do(func() {
+		blockRouter = &BlockRouter{
+			BlockService: services.NewBlockService(),
+			UserService:  services.NewUserService(),
+		}
+	})
+	return blockRouter
+}
+
+func (r *BlockRouter) Bind(_router *gin.RouterGroup) {
+	router := _router.Group("/block")
+	// GET
+	{
+		router.GET("/list",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.GetBlockedUsers,
+		)
+	}
+	// PUT
+	{
+		router.PUT("/:userID",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.Block,
+		)
+	}
+	// DELETE
+	{
+		router.DELETE("/:userID",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.Unblock,
+		)
+	}
+}
+
+// @title Block API
+// @Summary Block a user
+// @Description Blocked users and the blocker cannot see each other's posts and comments, and cannot comment on, mention, like, follow or message each other. Blocking removes follows in both directions. Blocking a user more than once has no effect
+// @Tags Block
+// @Security AccessToken
+// @Produce application/json
+// @Param userID path string true "User ID"
+// @Success 200 {object} models.BlockResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/block/{userID} [put]
+func (r *BlockRouter) Block(ctx *gin.Context) {
+	r.setBlocked(ctx, true)
+}
+
+// @title Block API
+// @Summary Unblock a user
+// @Description Unblocking a user that is not blocked has no effect. Follows removed by blocking are not restored
+// @Tags Block
+// @Security AccessToken
+// @Produce application/json
+// @Param userID path string true "User ID"
+// @Success 200 {object} models.BlockResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/block/{userID} [delete]
+func (r *BlockRouter) Unblock(ctx *gin.Context) {
+	r.setBlocked(ctx, false)
+}
+
+func (r *BlockRouter) setBlocked(ctx *gin.Context, blocked bool) {
+	userID, err := uuid.Parse(ctx.Param("userID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid user ID"})
+		return
+	}
+	user, err := r.UserService.GetByID(ctx, userID)
+	if err != nil {
+		ctx.JSON(404, models.ErrorResponse{Error: "user not found"})
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if blocked {
+		err = r.BlockService.Block(ctx, tokenData.UserID, user.ID)
+	} else {
+		err = r.BlockService.Unblock(ctx, tokenData.UserID, user.ID)
+	}
+	if err != nil {
+		if r.BlockService.ErrorUtils.IsServerInternalError(err.Error()) {
+			ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(400, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.BlockResponse{
+		UserID:  user.ID,
+		Blocked: blocked,
+	})
+}
+
+// @title Block API
+// @Summary Get users blocked by the current user
+// @Description Ordered by block time, newest first
+// @Tags Block
+// @Security AccessToken
+// @Produce application/json
+// @Param offset query string false "Offset"
+// @Param limit query string false "Limit"
+// @Success 200 {object} models.PaginationResponse[models.BlockGetUsersResponseItem]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/block/list [get]
+func (r *BlockRouter) GetBlockedUsers(ctx *gin.Context) {
+	offset, err := strconv.ParseUint(ctx.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid offset"})
+		return
+	}
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
+	if err != nil || limit == 0 {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	pagination := &models.Pagination{
+		Offset: uint(offset),
+		Limit:  uint(limit),
+	}
+	users, totalCount, err := r.BlockService.GetBlockedUsers(ctx, tokenData.UserID, pagination)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	responseData := make([]models.BlockGetUsersResponseItem, len(users))
+	for i, user := range users {
+		responseData[i] = models.BlockGetUsersResponseItem{
+			ID:       user.ID,
+			Username: user.Username,
+		}
+	}
+	ctx.JSON(200, models.PaginationResponse[models.BlockGetUsersResponseItem]{
+		Data:       responseData,
+		TotalCount: totalCount,
+		Pagination: pagination,
+	})
+}
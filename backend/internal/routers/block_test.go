@@ -0,0 +1,157 @@
+package routers
+
+import (
+	"backend/internal/models"
+	"backend/internal/tests"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockRouter(t *testing.T) {
+	server, apiRouter, _, _, cleanup := tests.SetupTestServer("test_block_router.db")
+	defer cleanup()
+
+	NewUserRouter().Bind(apiRouter)
+	NewPostRouter().Bind(apiRouter)
+	NewCommentRouter().Bind(apiRouter)
+	NewFollowRouter().Bind(apiRouter)
+	NewBlockRouter().Bind(apiRouter)
+
+	aliceData, aliceLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+	bobData, bobLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+	_, carolLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+
+	createComment := func(accessToken string, postID uuid.UUID) *httptest.ResponseRecorder {
+		return tests.SendTestRequest(server, "POST", "/api/comment", accessToken, &models.CommentCreateRequest{PostID: postID, Content: "評論"})
+	}
+	getAuthorPostIDs := func(accessToken string, authorID uuid.UUID) []uuid.UUID {
+		recorder := tests.SendTestRequest(server, "GET", "/api/post/list/author/"+authorID.String()+"?limit=10", accessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		respBody := &models.CursorPaginationResponse[models.PostGetPostsByAuthorIDResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		postIDs := make([]uuid.UUID, len(respBody.Data))
+		for i, post := range respBody.Data {
+			postIDs[i] = post.ID
+		}
+		return postIDs
+	}
+	getCommentUserIDs := func(accessToken string, postID uuid.UUID) []uuid.UUID {
+		recorder := tests.SendTestRequest(server, "GET", "/api/comment/list/post/"+postID.String()+"?mode=flat", accessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		respBody := []models.CommentGetFlatListByPostIDResponseItem{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &respBody))
+		userIDs := make([]uuid.UUID, len(respBody))
+		for i, comment := range respBody {
+			userIDs[i] = comment.UserID
+		}
+		return userIDs
+	}
+	getBlockedUsers := func(accessToken string) *models.PaginationResponse[models.BlockGetUsersResponseItem] {
+		recorder := tests.SendTestRequest(server, "GET", "/api/block/list?limit=10", accessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		respBody := &models.PaginationResponse[models.BlockGetUsersResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		return respBody
+	}
+
+	bobPostData, err := tests.SetupTestPost(server, bobLoginData.AccessToken)
+	require.NoError(t, err)
+	alicePostData, err := tests.SetupTestPost(server, aliceLoginData.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, 200, createComment(aliceLoginData.AccessToken, bobPostData.ID).Code)
+	require.Equal(t, 200, createComment(carolLoginData.AccessToken, bobPostData.ID).Code)
+	require.Equal(t, 200, tests.SendTestRequest(server, "PUT", "/api/follow/"+bobData.ID.String(), aliceLoginData.AccessToken, nil).Code)
+	require.Equal(t, 200, tests.SendTestRequest(server, "PUT", "/api/follow/"+aliceData.ID.String(), bobLoginData.AccessToken, nil).Code)
+
+	t.Run("封鎖失敗", func(t *testing.T) {
+		assert.Equal(t, 401, tests.SendTestRequest(server, "PUT", "/api/block/"+bobData.ID.String(), "", nil).Code)
+		assert.Equal(t, 401, tests.SendTestRequest(server, "GET", "/api/block/list", "", nil).Code)
+		assert.Equal(t, 400, tests.SendTestRequest(server, "PUT", "/api/block/invalid", aliceLoginData.AccessToken, nil).Code)
+		assert.Equal(t, 404, tests.SendTestRequest(server, "PUT", "/api/block/"+uuid.New().String(), aliceLoginData.AccessToken, nil).Code)
+		assert.Equal(t, 400, tests.SendTestRequest(server, "PUT", "/api/block/"+aliceData.ID.String(), aliceLoginData.AccessToken, nil).Code, "不能封鎖自己")
+	})
+
+	t.Run("成功封鎖 - 重複封鎖不會產生錯誤", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/block/"+bobData.ID.String(), aliceLoginData.AccessToken, nil)
+			require.Equal(t, 200, recorder.Code)
+			respBody := &models.BlockResponse{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			assert.True(t, respBody.Blocked)
+		}
+
+		blockedUsers := getBlockedUsers(aliceLoginData.AccessToken)
+		assert.Equal(t, uint(1), blockedUsers.TotalCount)
+		if assert.Len(t, blockedUsers.Data, 1) {
+			assert.Equal(t, bobData.ID, blockedUsers.Data[0].ID)
+		}
+		assert.Zero(t, getBlockedUsers(bobLoginData.AccessToken).TotalCount, "被封鎖者的列表不受影響")
+	})
+
+	t.Run("封鎖後移除雙方的追蹤且無法再追蹤", func(t *testing.T) {
+		for _, path := range []string{"/api/follow/" + aliceData.ID.String() + "/followers", "/api/follow/" + aliceData.ID.String() + "/following"} {
+			recorder := tests.SendTestRequest(server, "GET", path, "", nil)
+			require.Equal(t, 200, recorder.Code)
+			respBody := &models.PaginationResponse[models.FollowGetUsersResponseItem]{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			assert.Zero(t, respBody.TotalCount, path)
+		}
+		assert.Equal(t, 403, tests.SendTestRequest(server, "PUT", "/api/follow/"+aliceData.ID.String(), bobLoginData.AccessToken, nil).Code)
+	})
+
+	t.Run("雙方互相看不到貼文與評論", func(t *testing.T) {
+		assert.Empty(t, getAuthorPostIDs(aliceLoginData.AccessToken, bobData.ID))
+		assert.Empty(t, getAuthorPostIDs(bobLoginData.AccessToken, aliceData.ID), "不論誰封鎖誰")
+		assert.Equal(t, []uuid.UUID{bobPostData.ID}, getAuthorPostIDs("", bobData.ID), "未登入時不過濾")
+		assert.Equal(t, []uuid.UUID{bobPostData.ID}, getAuthorPostIDs(carolLoginData.AccessToken, bobData.ID))
+
+		recorder := tests.SendTestRequest(server, "GET", "/api/post/list/search?sort=recent&keyword="+url.QueryEscape("#測試"), aliceLoginData.AccessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		searchResp := &models.PaginationResponse[models.PostGetPostsByKeywordResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), searchResp))
+		if assert.Len(t, searchResp.Data, 1) {
+			assert.Equal(t, alicePostData.ID, searchResp.Data[0].ID)
+		}
+
+		assert.NotContains(t, getCommentUserIDs(bobLoginData.AccessToken, bobPostData.ID), aliceData.ID)
+		assert.Len(t, getCommentUserIDs(bobLoginData.AccessToken, bobPostData.ID), 1)
+		assert.Len(t, getCommentUserIDs("", bobPostData.ID), 2)
+	})
+
+	t.Run("無法評論、喜歡或提及對方", func(t *testing.T) {
+		assert.Equal(t, 403, createComment(aliceLoginData.AccessToken, bobPostData.ID).Code)
+		assert.Equal(t, 403, createComment(bobLoginData.AccessToken, alicePostData.ID).Code)
+		assert.Equal(t, 403, tests.SendTestRequest(server, "PUT", "/api/post/like/"+bobPostData.ID.String(), aliceLoginData.AccessToken, nil).Code)
+		assert.Equal(t, 403, tests.SendTestRequest(server, "PUT", "/api/post/like/"+alicePostData.ID.String(), bobLoginData.AccessToken, nil).Code)
+
+		recorder := tests.SendTestRequest(server, "POST", "/api/post", bobLoginData.AccessToken, &models.PostCreateRequest{Content: "嗨 @" + aliceData.Username})
+		require.Equal(t, 200, recorder.Code)
+		respBody := &models.PostCreateResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		assert.Empty(t, respBody.Mentions, "被封鎖的使用者視為一般文字")
+	})
+
+	t.Run("解除封鎖", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			recorder := tests.SendTestRequest(server, "DELETE", "/api/block/"+bobData.ID.String(), aliceLoginData.AccessToken, nil)
+			require.Equal(t, 200, recorder.Code)
+			respBody := &models.BlockResponse{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			assert.False(t, respBody.Blocked)
+		}
+
+		assert.Zero(t, getBlockedUsers(aliceLoginData.AccessToken).TotalCount)
+		assert.Contains(t, getAuthorPostIDs(aliceLoginData.AccessToken, bobData.ID), bobPostData.ID)
+		assert.Len(t, getCommentUserIDs(bobLoginData.AccessToken, bobPostData.ID), 2)
+		assert.Equal(t, 200, createComment(aliceLoginData.AccessToken, bobPostData.ID).Code)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"backend/internal/models"
 	"backend/internal/pkg"
 	"backend/internal/services"
+	"errors"
 	"sync"
 	"time"
 
@@ -46,16 +47,49 @@ func (r *CommentRouter) Bind(_router *gin.RouterGroup) {
 	// GET
 	{
 		// @Summary Get comments by post ID
-		router.GET("/list/post/:postID", r.GetCommentsByPostID)
+		router.GET("/list/post/:postID",
+			middlewares.OptionalAccessToken(middlewares.ParseJWTAccessToken),
+			r.GetCommentsByPostID,
+		)
+		router.GET("/:commentID/history",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			middlewares.RequirePermission(models.PermissionCommentEdit),
+			r.GetCommentEditHistories,
+		)
+	}
+	// PUT
+	{
+		router.PUT("/:commentID",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			middlewares.RequirePermission(models.PermissionCommentEdit),
+			r.UpdateComment,
+		)
+	}
+	// DELETE
+	{
+		router.DELETE("/:commentID",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			middlewares.RequirePermission(models.PermissionCommentDelete),
+			r.DeleteComment,
+		)
 	}
 }
 
 // @Tags Comment
 // @Summary Get comments by post ID
+// @Description Returns a nested comment tree by default, mode=flat returns a depth-first list with depth and path.
+// @Description Passing cursor (empty for the first page) paginates by root comments (oldest first), each root comment includes all of its replies, the response is then models.CursorPaginationResponse
+// @Description Comments of users with a block between them and the logged-in user are hidden together with their replies
 // @Accept application/json
 // @Produce application/json
 // @Param postID path string true "Post ID"
+// @Param mode query string false "Response mode (tree, flat)"
+// @Param cursor query string false "Cursor of the next page"
+// @Param limit query string false "Root comments per page (default 10, max 100)"
+// @Param withTotalCount query bool false "Return totalCount (number of root comments)"
 // @Success 200 {array} models.CommentGetListByPostIDResponseItem
+// @Success 200 {array} models.CommentGetFlatListByPostIDResponseItem
+// @Success 200 {object} models.CursorPaginationResponse[models.CommentGetListByPostIDResponseItem]
 // @Failure 400 {object} models.ErrorResponse "Invalid post ID format"
 // @Failure 404 {object} models.ErrorResponse "Post not found"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
@@ -67,6 +101,19 @@ func (r *CommentRouter) GetCommentsByPostID(ctx *gin.Context) {
 		ctx.JSON(400, models.ErrorResponse{Error: "Invalid post ID format"})
 		return
 	}
+	mode := ctx.DefaultQuery("mode", "tree")
+	if mode != "tree" && mode != "flat" {
+		ctx.JSON(400, models.ErrorResponse{Error: "Invalid mode"})
+		return
+	}
+
+	var pagination *models.CursorPagination
+	if isCursorPagination(ctx) {
+		var ok bool
+		if pagination, ok = parseCursorPagination(ctx); !ok {
+			return
+		}
+	}
 
 	// 檢查 Post 是否存在
 	if _, err := r.PostService.GetByID(ctx, postID); err != nil {
@@ -74,51 +121,113 @@ func (r *CommentRouter) GetCommentsByPostID(ctx *gin.Context) {
 		return
 	}
 
+	// 依根評論分頁
+	if pagination != nil {
+		roots, nextCursor, totalCount, err := r.CommentService.GetTreePageByPostID(ctx, postID, getViewerID(ctx), pagination)
+		if err != nil {
+			ctx.JSON(500, models.ErrorResponse{Error: "Failed to retrieve comments"})
+			return
+		}
+		if mode == "flat" {
+			responseData := toCommentFlatResponseItems(r.CommentService.Flatten(roots))
+			ctx.JSON(200, newCursorPaginationResponse(responseData, nextCursor, totalCount))
+			return
+		}
+		ctx.JSON(200, newCursorPaginationResponse(toCommentTreeResponseItems(roots), nextCursor, totalCount))
+		return
+	}
+
 	// 獲取評論
-	comments, err := r.CommentService.GetListByPostID(ctx, postID)
+	if mode == "flat" {
+		nodes, err := r.CommentService.GetFlatListByPostID(ctx, postID, getViewerID(ctx))
+		if err != nil {
+			ctx.JSON(500, models.ErrorResponse{Error: "Failed to retrieve comments"})
+			return
+		}
+		ctx.JSON(200, toCommentFlatResponseItems(nodes))
+		return
+	}
+	roots, err := r.CommentService.GetTreeByPostID(ctx, postID, getViewerID(ctx))
 	if err != nil {
 		ctx.JSON(500, models.ErrorResponse{Error: "Failed to retrieve comments"})
 		return
 	}
+	ctx.JSON(200, toCommentTreeResponseItems(roots))
+}
+
+func toCommentFlatResponseItems(nodes []*models.CommentTreeNode) []models.CommentGetFlatListByPostIDResponseItem {
+	items := make([]models.CommentGetFlatListByPostIDResponseItem, len(nodes))
+	for i, node := range nodes {
+		comment := node.Comment
+		userID, userName := commentAuthor(&comment)
+		items[i] = models.CommentGetFlatListByPostIDResponseItem{
+			ID:        comment.ID,
+			PostID:    comment.PostID,
+			Content:   comment.Content,
+			Mentions:  toMentionEntities(comment.Mentions),
+			ParentID:  comment.ParentID,
+			UserID:    userID,
+			UserName:  userName,
+			CreatedAt: time.Unix(comment.CreatedAt, 0).Format(time.RFC3339),
+			UpdatedAt: time.Unix(comment.UpdatedAt, 0).Format(time.RFC3339),
+			EditedAt:  formatCommentEditedAt(&comment),
+			Deleted:   comment.IsDeleted(),
+			Depth:     node.Depth,
+			Path:      node.Path,
+		}
+	}
+	return items
+}
 
-	// 組織評論資料
-	rootComments := make([]models.CommentGetListByPostIDResponseItem, 0)
-	subComments := make([]models.CommentGetListByPostIDResponseItem, 0)
-	parentCommentsMap := make(map[uuid.UUID][]models.CommentGetListByPostIDResponseItem)
-	for _, comment := range comments {
-		item := models.CommentGetListByPostIDResponseItem{
+// toCommentTreeResponseItems 遞迴轉換評論樹
+func toCommentTreeResponseItems(nodes []*models.CommentTreeNode) []models.CommentGetListByPostIDResponseItem {
+	items := make([]models.CommentGetListByPostIDResponseItem, len(nodes))
+	for i, node := range nodes {
+		comment := node.Comment
+		userID, userName := commentAuthor(&comment)
+		items[i] = models.CommentGetListByPostIDResponseItem{
 			ID:          comment.ID,
 			PostID:      comment.PostID,
 			Content:     comment.Content,
+			Mentions:    toMentionEntities(comment.Mentions),
 			ParentID:    comment.ParentID,
-			UserID:      comment.UserID,
-			UserName:    (*comment.User).Username,
+			UserID:      userID,
+			UserName:    userName,
 			CreatedAt:   time.Unix(comment.CreatedAt, 0).Format(time.RFC3339),
 			UpdatedAt:   time.Unix(comment.UpdatedAt, 0).Format(time.RFC3339),
-			SubComments: []models.CommentGetListByPostIDResponseItem{},
-		}
-		if item.ParentID == nil {
-			rootComments = append(rootComments, item)
-			parentCommentsMap[item.ID] = item.SubComments
-			continue
-		} else {
-			subComments = append(subComments, item)
+			EditedAt:    formatCommentEditedAt(&comment),
+			Deleted:     comment.IsDeleted(),
+			SubComments: toCommentTreeResponseItems(node.Children),
 		}
 	}
-	for _, subComment := range subComments {
-		parentCommentsMap[*subComment.ParentID] = append(parentCommentsMap[*subComment.ParentID], subComment)
+	return items
+}
+
+// commentAuthor 墓碑評論不顯示作者
+func commentAuthor(comment *models.Comment) (uuid.UUID, string) {
+	if comment.IsDeleted() || comment.User == nil {
+		return uuid.Nil, models.COMMENT_DELETED_CONTENT
 	}
-	ctx.JSON(200, rootComments)
+	return comment.UserID, comment.User.Username
+}
+
+func formatCommentEditedAt(comment *models.Comment) *string {
+	if comment.EditedAt == nil {
+		return nil
+	}
+	return pkg.GetPointer(time.Unix(*comment.EditedAt, 0).Format(time.RFC3339))
 }
 
 // @Tags Comment
 // @Summary Create a new comment
+// @Description The content policy may mask parts of the content, hold the comment for review (pendingReview) or reject it
 // @Security AccessToken
 // @Accept application/json
 // @Produce application/json
 // @Param comment body models.CommentCreateRequest true "Comment data"
 // @Success 200 {object} models.CommentCreateResponse
-// @Failure 400 {object} models.ErrorResponse "Invalid request body"
+// @Failure 400 {object} models.ErrorResponse "Invalid request body, parent comment of another post or content rejected by the content policy"
+// @Failure 403 {object} models.ErrorResponse "Block between the user and the post or parent comment author"
 // @Failure 404 {object} models.ErrorResponse "Post or parent comment not found"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
 // @Router /api/comment [post]
@@ -136,12 +245,17 @@ func (r *CommentRouter) CreateComment(ctx *gin.Context) {
 		return
 	}
 
-	// 如果 ParentID 不為空，檢查父評論是否存在
+	// 如果 ParentID 不為空，檢查父評論是否存在且屬於同一篇貼文
 	if commentCreateRequest.ParentID != nil {
-		if _, err := r.CommentService.GetByID(ctx, *commentCreateRequest.ParentID); err != nil {
+		parentComment, err := r.CommentService.GetByID(ctx, *commentCreateRequest.ParentID)
+		if err != nil || parentComment.IsDeleted() {
 			ctx.JSON(404, models.ErrorResponse{Error: "Parent comment not found"})
 			return
 		}
+		if parentComment.PostID != commentCreateRequest.PostID {
+			ctx.JSON(400, models.ErrorResponse{Error: "Parent comment does not belong to the post"})
+			return
+		}
 	}
 
 	// 獲取登入使用者資料
@@ -161,6 +275,14 @@ func (r *CommentRouter) CreateComment(ctx *gin.Context) {
 	}
 	comments, err := r.CommentService.Create(ctx, []models.CommentBase{commentBase})
 	if err != nil {
+		if errors.Is(err, services.ErrCommentBlocked) {
+			ctx.JSON(403, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrContentRejected) {
+			ctx.JSON(400, models.ErrorResponse{Error: err.Error()})
+			return
+		}
 		err = r.ErrorUtils.ServerInternalError(err.Error())
 		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
 		return
@@ -176,8 +298,153 @@ func (r *CommentRouter) CreateComment(ctx *gin.Context) {
 		ID:       comment.ID,
 		PostID:   comment.PostID,
 		Content:  comment.Content,
+		Mentions: toMentionEntities(comment.Mentions),
 		ParentID: comment.ParentID,
 		UserID:   tokenData.UserID,
+
+		PendingReview: comment.HiddenAt != nil,
 	}
 	ctx.JSON(200, respData)
 }
+
+// @Tags Comment
+// @Summary Update a comment
+// @Description Allowed for the comment author, the post author and admins, the previous content is kept in the edit history, the content policy may mask parts of the content, hold the comment for review (pendingReview) or reject it
+// @Security AccessToken
+// @Accept application/json
+// @Produce application/json
+// @Param commentID path string true "Comment ID"
+// @Param comment body models.CommentUpdateRequest true "Comment data"
+// @Success 200 {object} models.CommentUpdateResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid request body or content rejected by the content policy"
+// @Failure 403 {object} models.ErrorResponse "Permission denied"
+// @Failure 404 {object} models.ErrorResponse "Comment not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/comment/{commentID} [put]
+func (r *CommentRouter) UpdateComment(ctx *gin.Context) {
+	// 解析請求體
+	commentUpdateRequest := &models.CommentUpdateRequest{}
+	if err := ctx.ShouldBindJSON(commentUpdateRequest); err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+	comment, tokenData, ok := r.getOwnedComment(ctx, models.PermissionCommentEdit)
+	if !ok {
+		return
+	}
+
+	// 編輯評論
+	comment, err := r.CommentService.Update(ctx, comment, tokenData.UserID, commentUpdateRequest.Content)
+	if err != nil {
+		if errors.Is(err, services.ErrContentRejected) {
+			ctx.JSON(400, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應資料
+	ctx.JSON(200, models.CommentUpdateResponse{
+		ID:       comment.ID,
+		PostID:   comment.PostID,
+		Content:  comment.Content,
+		Mentions: toMentionEntities(comment.Mentions),
+		ParentID: comment.ParentID,
+		UserID:   comment.UserID,
+		EditedAt: *formatCommentEditedAt(comment),
+
+		PendingReview: comment.HiddenAt != nil,
+	})
+}
+
+// @Tags Comment
+// @Summary Delete a comment
+// @Description Allowed for the comment author, the post author and admins, a comment with replies is kept as a "[deleted]" tombstone
+// @Security AccessToken
+// @Produce application/json
+// @Param commentID path string true "Comment ID"
+// @Success 200 {object} models.CommentDeleteResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid comment ID format"
+// @Failure 403 {object} models.ErrorResponse "Permission denied"
+// @Failure 404 {object} models.ErrorResponse "Comment not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/comment/{commentID} [delete]
+func (r *CommentRouter) DeleteComment(ctx *gin.Context) {
+	comment, tokenData, ok := r.getOwnedComment(ctx, models.PermissionCommentDelete)
+	if !ok {
+		return
+	}
+
+	tombstoned, err := r.CommentService.Delete(ctx, comment, tokenData.UserID)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.CommentDeleteResponse{Tombstoned: tombstoned})
+}
+
+// @Tags Comment
+// @Summary Get edit histories of a comment
+// @Description Allowed for the comment author, the post author and admins, ordered from oldest to newest
+// @Security AccessToken
+// @Produce application/json
+// @Param commentID path string true "Comment ID"
+// @Success 200 {array} models.CommentGetEditHistoriesResponseItem
+// @Failure 400 {object} models.ErrorResponse "Invalid comment ID format"
+// @Failure 403 {object} models.ErrorResponse "Permission denied"
+// @Failure 404 {object} models.ErrorResponse "Comment not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/comment/{commentID}/history [get]
+func (r *CommentRouter) GetCommentEditHistories(ctx *gin.Context) {
+	comment, _, ok := r.getOwnedComment(ctx, models.PermissionCommentEdit)
+	if !ok {
+		return
+	}
+
+	histories, err := r.CommentService.GetEditHistories(ctx, comment.ID)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: "Failed to retrieve edit histories"})
+		return
+	}
+	responseData := make([]models.CommentGetEditHistoriesResponseItem, len(histories))
+	for i, history := range histories {
+		responseData[i] = models.CommentGetEditHistoriesResponseItem{
+			ID:        history.ID,
+			EditorID:  history.EditorID,
+			Content:   history.Content,
+			CreatedAt: time.Unix(history.CreatedAt, 0).Format(time.RFC3339),
+		}
+	}
+	ctx.JSON(200, responseData)
+}
+
+// getOwnedComment 解析路由中的 commentID，評論作者與貼文作者皆視為擁有者
+func (r *CommentRouter) getOwnedComment(ctx *gin.Context, permission models.Permission) (*models.Comment, *models.JWTClaimsData, bool) {
+	commentID, err := uuid.Parse(ctx.Param("commentID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "Invalid comment ID format"})
+		return nil, nil, false
+	}
+	comment, err := r.CommentService.GetByID(ctx, commentID)
+	if err != nil || comment.IsDeleted() {
+		ctx.JSON(404, models.ErrorResponse{Error: "Comment not found"})
+		return nil, nil, false
+	}
+	post, err := r.PostService.GetByID(ctx, comment.PostID)
+	if err != nil {
+		ctx.JSON(404, models.ErrorResponse{Error: "Post not found"})
+		return nil, nil, false
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		err = r.ErrorUtils.ServerInternalError(err.Error())
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return nil, nil, false
+	}
+	if !tokenData.Can(permission, comment.UserID, post.AuthorID) {
+		ctx.JSON(403, models.ErrorResponse{Error: "Permission denied"})
+		return nil, nil, false
+	}
+	return comment, tokenData, true
+}
@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -89,6 +90,247 @@ func TestCommentRouter(t *testing.T) {
 			assert.Equal(t, postData.ID, responseBody.PostID, "Post ID should match the request")
 			assert.Equal(t, loginData.ID, responseBody.UserID, "User ID should match the creator's ID")
 			assert.Nil(t, responseBody.ParentID, "Parent ID should be nil for top-level comments")
+			assert.Empty(t, responseBody.Mentions, "Comment without mentions")
+		})
+
+		t.Run("成功創建評論 - 包含提及", func(t *testing.T) {
+			commentCreateRequest := &models.CommentCreateRequest{
+				PostID:  postData.ID,
+				Content: "請看 @" + userData.Username,
+			}
+			bufCommentCreateRequest, _ := httpUtils.ToJSONBuffer(commentCreateRequest)
+			reqCreateComment, _ := http.NewRequest("POST", "/api/comment", bufCommentCreateRequest)
+			reqCreateComment.Header.Set("Content-Type", "application/json")
+			reqCreateComment.Header.Set("Authorization", loginData.AccessToken)
+
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, reqCreateComment)
+			assert.Equal(t, 200, recorder.Code, "應該回傳 200 表示評論創建成功")
+			responseBody := &models.CommentCreateResponse{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), responseBody))
+			assert.Equal(t, []models.MentionEntity{{
+				UserID:   userData.ID,
+				Username: userData.Username,
+				Start:    3,
+				End:      4 + len([]rune(userData.Username)),
+			}}, responseBody.Mentions)
+		})
+	})
+
+	t.Run("巢狀評論", func(t *testing.T) {
+		nestedPostData, err := tests.SetupTestPost(server, loginData.AccessToken)
+		assert.NoError(t, err)
+		createComment := func(postID uuid.UUID, content string, parentID *uuid.UUID) *httptest.ResponseRecorder {
+			buf, _ := httpUtils.ToJSONBuffer(&models.CommentCreateRequest{PostID: postID, Content: content, ParentID: parentID})
+			req, _ := http.NewRequest("POST", "/api/comment", buf)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", loginData.AccessToken)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			return recorder
+		}
+		createCommentID := func(content string, parentID *uuid.UUID) uuid.UUID {
+			recorder := createComment(nestedPostData.ID, content, parentID)
+			assert.Equal(t, 200, recorder.Code)
+			responseBody := &models.CommentCreateResponse{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), responseBody))
+			return responseBody.ID
+		}
+
+		// root -> reply -> replyOfReply, root2
+		rootID := createCommentID("root", nil)
+		replyID := createCommentID("reply", &rootID)
+		replyOfReplyID := createCommentID("reply of reply", &replyID)
+		root2ID := createCommentID("root2", nil)
+
+		t.Run("樹狀模式包含所有層級", func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/comment/list/post/"+nestedPostData.ID.String(), nil)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			assert.Equal(t, 200, recorder.Code)
+			responseBody := make([]models.CommentGetListByPostIDResponseItem, 0)
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+			// created_at 以秒為單位，同一秒建立的同層評論不保證順序
+			rootsByID := make(map[uuid.UUID]models.CommentGetListByPostIDResponseItem)
+			for _, item := range responseBody {
+				rootsByID[item.ID] = item
+			}
+			assert.Len(t, responseBody, 2, "應該只有兩個根評論")
+			if root, ok := rootsByID[rootID]; assert.True(t, ok) && assert.Len(t, root.SubComments, 1) {
+				assert.Equal(t, replyID, root.SubComments[0].ID)
+				if assert.Len(t, root.SubComments[0].SubComments, 1, "回覆的回覆不應遺失") {
+					assert.Equal(t, replyOfReplyID, root.SubComments[0].SubComments[0].ID)
+				}
+			}
+			if root2, ok := rootsByID[root2ID]; assert.True(t, ok) {
+				assert.Empty(t, root2.SubComments)
+			}
+		})
+
+		t.Run("平面模式包含 depth 與 path", func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/comment/list/post/"+nestedPostData.ID.String()+"?mode=flat", nil)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			assert.Equal(t, 200, recorder.Code)
+			responseBody := make([]models.CommentGetFlatListByPostIDResponseItem, 0)
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+			assert.Len(t, responseBody, 4)
+			itemsByID := make(map[uuid.UUID]models.CommentGetFlatListByPostIDResponseItem)
+			indexByID := make(map[uuid.UUID]int)
+			for i, item := range responseBody {
+				itemsByID[item.ID] = item
+				indexByID[item.ID] = i
+			}
+			assert.Equal(t, []uuid.UUID{rootID}, itemsByID[rootID].Path)
+			assert.Equal(t, uint(0), itemsByID[rootID].Depth)
+			assert.Equal(t, []uuid.UUID{rootID, replyID}, itemsByID[replyID].Path)
+			assert.Equal(t, uint(1), itemsByID[replyID].Depth)
+			assert.Equal(t, []uuid.UUID{rootID, replyID, replyOfReplyID}, itemsByID[replyOfReplyID].Path)
+			assert.Equal(t, uint(2), itemsByID[replyOfReplyID].Depth)
+			assert.Equal(t, uint(0), itemsByID[root2ID].Depth)
+			// 深度優先：子評論緊接在父評論之後
+			assert.Equal(t, indexByID[rootID]+1, indexByID[replyID])
+			assert.Equal(t, indexByID[replyID]+1, indexByID[replyOfReplyID])
+
+			req, _ = http.NewRequest("GET", "/api/comment/list/post/"+nestedPostData.ID.String()+"?mode=unknown", nil)
+			recorder = httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			assert.Equal(t, 400, recorder.Code, "無效的 mode 應該回傳 400")
+		})
+
+		t.Run("依根評論 cursor 分頁", func(t *testing.T) {
+			getPage := func(query string) *models.CursorPaginationResponse[models.CommentGetListByPostIDResponseItem] {
+				req, _ := http.NewRequest("GET", "/api/comment/list/post/"+nestedPostData.ID.String()+query, nil)
+				recorder := httptest.NewRecorder()
+				server.ServeHTTP(recorder, req)
+				assert.Equal(t, 200, recorder.Code)
+				responseBody := &models.CursorPaginationResponse[models.CommentGetListByPostIDResponseItem]{}
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), responseBody))
+				return responseBody
+			}
+
+			firstPage := getPage("?cursor=&limit=1&withTotalCount=true")
+			if !assert.Len(t, firstPage.Data, 1) || !assert.NotNil(t, firstPage.NextCursor) {
+				return
+			}
+			if assert.NotNil(t, firstPage.TotalCount) {
+				assert.Equal(t, uint(2), *firstPage.TotalCount, "總筆數為根評論數")
+			}
+			secondPage := getPage("?limit=1&cursor=" + *firstPage.NextCursor)
+			if !assert.Len(t, secondPage.Data, 1) {
+				return
+			}
+			assert.Nil(t, secondPage.NextCursor, "沒有下一頁")
+			assert.Nil(t, secondPage.TotalCount, "未要求時不回傳總筆數")
+
+			rootsByID := map[uuid.UUID]models.CommentGetListByPostIDResponseItem{
+				firstPage.Data[0].ID:  firstPage.Data[0],
+				secondPage.Data[0].ID: secondPage.Data[0],
+			}
+			if root, ok := rootsByID[rootID]; assert.True(t, ok) && assert.Len(t, root.SubComments, 1) {
+				assert.Len(t, root.SubComments[0].SubComments, 1, "每個根評論包含所有層級的回覆")
+			}
+			assert.Contains(t, rootsByID, root2ID)
+
+			req, _ := http.NewRequest("GET", "/api/comment/list/post/"+nestedPostData.ID.String()+"?cursor=invalid", nil)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			assert.Equal(t, 400, recorder.Code, "無效的 cursor 應該回傳 400")
+		})
+
+		t.Run("創建失敗 - 父評論屬於其他貼文", func(t *testing.T) {
+			recorder := createComment(postData.ID, "wrong parent", &rootID)
+			assert.Equal(t, 400, recorder.Code, "應該回傳 400 表示父評論不屬於此貼文")
+		})
+	})
+
+	t.Run("編輯與刪除評論", func(t *testing.T) {
+		_, commenterLoginData, err := tests.SetupTestUser(server)
+		assert.NoError(t, err)
+		_, otherLoginData, err := tests.SetupTestUser(server)
+		assert.NoError(t, err)
+		// 貼文作者為 loginData，評論作者為 commenterLoginData
+		editPostData, err := tests.SetupTestPost(server, loginData.AccessToken)
+		assert.NoError(t, err)
+		createComment := func(content string, parentID *uuid.UUID) uuid.UUID {
+			recorder := tests.SendTestRequest(server, "POST", "/api/comment", commenterLoginData.AccessToken, models.CommentCreateRequest{PostID: editPostData.ID, Content: content, ParentID: parentID})
+			assert.Equal(t, 200, recorder.Code)
+			responseBody := &models.CommentCreateResponse{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), responseBody))
+			return responseBody.ID
+		}
+		getFlatComments := func() map[uuid.UUID]models.CommentGetFlatListByPostIDResponseItem {
+			recorder := tests.SendTestRequest(server, "GET", "/api/comment/list/post/"+editPostData.ID.String()+"?mode=flat", "", nil)
+			assert.Equal(t, 200, recorder.Code)
+			responseBody := make([]models.CommentGetFlatListByPostIDResponseItem, 0)
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responseBody))
+			result := make(map[uuid.UUID]models.CommentGetFlatListByPostIDResponseItem)
+			for _, item := range responseBody {
+				result[item.ID] = item
+			}
+			return result
+		}
+
+		parentID := createComment("parent", nil)
+		replyID := createComment("reply", &parentID)
+
+		t.Run("編輯失敗 - 非相關使用者", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/comment/"+parentID.String(), otherLoginData.AccessToken, models.CommentUpdateRequest{Content: "hack"})
+			assert.Equal(t, 403, recorder.Code, "應該回傳 403 表示沒有權限")
+		})
+
+		t.Run("成功編輯 - 紀錄 editedAt 與編輯紀錄", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/comment/"+parentID.String(), commenterLoginData.AccessToken, models.CommentUpdateRequest{Content: "parent edited"})
+			assert.Equal(t, 200, recorder.Code, "應該回傳 200 表示編輯成功")
+			responseBody := &models.CommentUpdateResponse{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), responseBody))
+			assert.Equal(t, "parent edited", responseBody.Content)
+			assert.NotEmpty(t, responseBody.EditedAt)
+
+			comments := getFlatComments()
+			assert.NotNil(t, comments[parentID].EditedAt, "編輯後應該有 editedAt")
+			assert.Nil(t, comments[replyID].EditedAt, "未編輯的評論不應有 editedAt")
+
+			recorder = tests.SendTestRequest(server, "GET", "/api/comment/"+parentID.String()+"/history", commenterLoginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code)
+			histories := make([]models.CommentGetEditHistoriesResponseItem, 0)
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &histories))
+			if assert.Len(t, histories, 1) {
+				assert.Equal(t, "parent", histories[0].Content, "編輯紀錄應保存編輯前的內容")
+			}
+		})
+
+		t.Run("刪除有回覆的評論 - 保留墓碑", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "DELETE", "/api/comment/"+parentID.String(), otherLoginData.AccessToken, nil)
+			assert.Equal(t, 403, recorder.Code, "應該回傳 403 表示沒有權限")
+
+			// 貼文作者可刪除他人評論
+			recorder = tests.SendTestRequest(server, "DELETE", "/api/comment/"+parentID.String(), loginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code, "應該回傳 200 表示刪除成功")
+			responseBody := &models.CommentDeleteResponse{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), responseBody))
+			assert.True(t, responseBody.Tombstoned)
+
+			comments := getFlatComments()
+			assert.Len(t, comments, 2, "墓碑評論應保留在回覆串中")
+			assert.True(t, comments[parentID].Deleted)
+			assert.Equal(t, "[deleted]", comments[parentID].Content)
+			assert.Equal(t, "[deleted]", comments[parentID].UserName)
+			assert.Equal(t, []uuid.UUID{parentID, replyID}, comments[replyID].Path)
+
+			recorder = tests.SendTestRequest(server, "PUT", "/api/comment/"+parentID.String(), commenterLoginData.AccessToken, models.CommentUpdateRequest{Content: "edit tombstone"})
+			assert.Equal(t, 404, recorder.Code, "墓碑評論不可編輯")
+			recorder = tests.SendTestRequest(server, "POST", "/api/comment", commenterLoginData.AccessToken, models.CommentCreateRequest{PostID: editPostData.ID, Content: "reply tombstone", ParentID: &parentID})
+			assert.Equal(t, 404, recorder.Code, "墓碑評論不可回覆")
+		})
+
+		t.Run("刪除最後的回覆 - 一併清除墓碑", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "DELETE", "/api/comment/"+replyID.String(), commenterLoginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code, "應該回傳 200 表示刪除成功")
+			responseBody := &models.CommentDeleteResponse{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), responseBody))
+			assert.False(t, responseBody.Tombstoned)
+			assert.Empty(t, getFlatComments(), "沒有回覆的墓碑應該被清除")
 		})
 	})
 }
@@ -0,0 +1,378 @@
+package routers
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/services"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ConversationRouter struct {
+	ConversationService *services.ConversationService
+}
+
+var conversationRouterOnce sync.Once
+var conversationRouter *ConversationRouter
+
+func NewConversationRouter() *ConversationRouter {
+	conversationRouterOnce.Do(func() {
+		conversationRouter = &ConversationRouter{
+			ConversationService: services.NewConversationService(),
+		}
+	})
+	return conversationRouter
+}
+
+func (r *ConversationRouter) Bind(_router *gin.RouterGroup) {
+	router := _router.Group("/conversation")
+	// GET
+	{
+		router.GET("/list",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.GetConversations,
+		)
+		router.GET("/:conversationID",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.GetConversation,
+		)
+		router.GET("/:conversationID/messages",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.GetMessages,
+		)
+	}
+	// POST
+	{
+		router.POST("",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.CreateConversation,
+		)
+		router.POST("/:conversationID/message",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.SendMessage,
+		)
+	}
+	// PUT
+	{
+		router.PUT("/:conversationID/read",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.MarkRead,
+		)
+	}
+}
+
+// @title Conversation API
+// @Summary Create a conversation
+// @Description With one participant the conversation is a 1:1 conversation, the existing one is returned if the two users already have one. With more participants a group conversation is created
+// @Tags Conversation
+// @Security AccessToken
+// @Accept application/json
+// @Produce application/json
+// @Param request body models.ConversationCreateRequest true "Create conversation request"
+// @Success 200 {object} models.ConversationResponse
+// @Success 201 {object} models.ConversationResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/conversation [post]
+func (r *ConversationRouter) CreateConversation(ctx *gin.Context) {
+	reqBody := &models.ConversationCreateRequest{}
+	if err := ctx.ShouldBindJSON(reqBody); err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	conversation, created, err := r.ConversationService.Create(ctx, tokenData.UserID, reqBody.ParticipantIDs, reqBody.Title)
+	if err != nil {
+		r.handleError(ctx, err)
+		return
+	}
+	summary, err := r.ConversationService.GetSummary(ctx, tokenData.UserID, conversation.ID)
+	if err != nil {
+		r.handleError(ctx, err)
+		return
+	}
+
+	// 構建回應
+	status := 200
+	if created {
+		status = 201
+	}
+	ctx.JSON(status, toConversationResponse(summary))
+}
+
+// @title Conversation API
+// @Summary Get my conversations
+// @Description Sorted by last activity, newest first
+// @Tags Conversation
+// @Security AccessToken
+// @Produce application/json
+// @Param offset query string false "Offset"
+// @Param limit query string false "Limit"
+// @Success 200 {object} models.PaginationResponse[models.ConversationResponse]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/conversation/list [get]
+func (r *ConversationRouter) GetConversations(ctx *gin.Context) {
+	offset, err := strconv.ParseUint(ctx.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid offset"})
+		return
+	}
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
+	if err != nil || limit == 0 {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	pagination := &models.Pagination{
+		Offset: uint(offset),
+		Limit:  uint(limit),
+	}
+	summaries, totalCount, err := r.ConversationService.GetSummaries(ctx, tokenData.UserID, pagination)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	responseData := make([]models.ConversationResponse, len(summaries))
+	for i := range summaries {
+		responseData[i] = toConversationResponse(&summaries[i])
+	}
+	ctx.JSON(200, models.PaginationResponse[models.ConversationResponse]{
+		Data:       responseData,
+		TotalCount: totalCount,
+		Pagination: pagination,
+	})
+}
+
+// @title Conversation API
+// @Summary Get a conversation
+// @Tags Conversation
+// @Security AccessToken
+// @Produce application/json
+// @Param conversationID path string true "Conversation ID"
+// @Success 200 {object} models.ConversationResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/conversation/{conversationID} [get]
+func (r *ConversationRouter) GetConversation(ctx *gin.Context) {
+	conversationID, err := uuid.Parse(ctx.Param("conversationID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid conversation ID"})
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	summary, err := r.ConversationService.GetSummary(ctx, tokenData.UserID, conversationID)
+	if err != nil {
+		r.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, toConversationResponse(summary))
+}
+
+// @title Conversation API
+// @Summary Get messages of a conversation
+// @Description Cursor pagination, newest first. Pass an empty cursor for the first page
+// @Tags Conversation
+// @Security AccessToken
+// @Produce application/json
+// @Param conversationID path string true "Conversation ID"
+// @Param cursor query string false "Cursor of the next page"
+// @Param limit query string false "Limit"
+// @Param withTotalCount query bool false "Return totalCount"
+// @Success 200 {object} models.CursorPaginationResponse[models.ConversationMessageResponseItem]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/conversation/{conversationID}/messages [get]
+func (r *ConversationRouter) GetMessages(ctx *gin.Context) {
+	conversationID, err := uuid.Parse(ctx.Param("conversationID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid conversation ID"})
+		return
+	}
+	pagination, ok := parseCursorPagination(ctx)
+	if !ok {
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	messages, nextCursor, totalCount, err := r.ConversationService.GetMessagesByCursor(ctx, tokenData.UserID, conversationID, pagination)
+	if err != nil {
+		r.handleError(ctx, err)
+		return
+	}
+
+	// 構建回應
+	responseData := make([]models.ConversationMessageResponseItem, len(messages))
+	for i := range messages {
+		responseData[i] = toConversationMessageResponseItem(&messages[i])
+	}
+	ctx.JSON(200, newCursorPaginationResponse(responseData, nextCursor, totalCount))
+}
+
+// @title Conversation API
+// @Summary Send a message
+// @Description Fails with 403 if the sender and any participant have blocked each other
+// @Tags Conversation
+// @Security AccessToken
+// @Accept application/json
+// @Produce application/json
+// @Param conversationID path string true "Conversation ID"
+// @Param request body models.ConversationSendMessageRequest true "Send message request"
+// @Success 201 {object} models.ConversationMessageResponseItem
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/conversation/{conversationID}/message [post]
+func (r *ConversationRouter) SendMessage(ctx *gin.Context) {
+	conversationID, err := uuid.Parse(ctx.Param("conversationID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid conversation ID"})
+		return
+	}
+	reqBody := &models.ConversationSendMessageRequest{}
+	if err := ctx.ShouldBindJSON(reqBody); err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	if strings.TrimSpace(reqBody.Content) == "" || len([]rune(reqBody.Content)) > models.MESSAGE_MAX_LENGTH {
+		ctx.JSON(400, models.ErrorResponse{Error: "content characters must be between 0 and " + strconv.Itoa(models.MESSAGE_MAX_LENGTH)})
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	message, err := r.ConversationService.SendMessage(ctx, tokenData.UserID, conversationID, reqBody.Content)
+	if err != nil {
+		r.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(201, toConversationMessageResponseItem(message))
+}
+
+// @title Conversation API
+// @Summary Mark a conversation as read
+// @Description Moves my read receipt to the latest message, other participants see it as lastReadMessageID
+// @Tags Conversation
+// @Security AccessToken
+// @Produce application/json
+// @Param conversationID path string true "Conversation ID"
+// @Success 200 {object} models.ConversationMarkReadResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/conversation/{conversationID}/read [put]
+func (r *ConversationRouter) MarkRead(ctx *gin.Context) {
+	conversationID, err := uuid.Parse(ctx.Param("conversationID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid conversation ID"})
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	lastReadMessageID, err := r.ConversationService.MarkRead(ctx, tokenData.UserID, conversationID)
+	if err != nil {
+		r.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, models.ConversationMarkReadResponse{
+		ConversationID:    conversationID,
+		LastReadMessageID: lastReadMessageID,
+	})
+}
+
+// handleError 依錯誤類型回應 404 (對話或使用者不存在)、403 (封鎖)、400 或 500
+func (r *ConversationRouter) handleError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrConversationNotFound), errors.Is(err, services.ErrConversationUserNotFound):
+		ctx.JSON(404, models.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrConversationBlocked):
+		ctx.JSON(403, models.ErrorResponse{Error: err.Error()})
+	case r.ConversationService.ErrorUtils.IsServerInternalError(err.Error()):
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+	default:
+		ctx.JSON(400, models.ErrorResponse{Error: err.Error()})
+	}
+}
+
+func toConversationResponse(summary *models.ConversationSummary) models.ConversationResponse {
+	conversation := summary.Conversation
+	participants := make([]models.ConversationParticipantResponseItem, len(conversation.Participants))
+	for i, participant := range conversation.Participants {
+		participants[i] = models.ConversationParticipantResponseItem{
+			UserID:            participant.UserID,
+			LastReadMessageID: participant.LastReadMessageID,
+		}
+		if participant.User != nil {
+			participants[i].Username = participant.User.Username
+		}
+	}
+	response := models.ConversationResponse{
+		ID:            conversation.ID,
+		IsGroup:       conversation.IsGroup,
+		Title:         conversation.Title,
+		Participants:  participants,
+		UnreadCount:   summary.UnreadCount,
+		LastMessageAt: time.Unix(conversation.LastMessageAt, 0).Format(time.RFC3339),
+		CreatedAt:     time.Unix(conversation.CreatedAt, 0).Format(time.RFC3339),
+	}
+	if summary.LastMessage != nil {
+		lastMessage := toConversationMessageResponseItem(summary.LastMessage)
+		response.LastMessage = &lastMessage
+	}
+	return response
+}
+
+func toConversationMessageResponseItem(message *models.Message) models.ConversationMessageResponseItem {
+	return models.ConversationMessageResponseItem{
+		ID:             message.ID,
+		ConversationID: message.ConversationID,
+		SenderID:       message.SenderID,
+		Content:        message.Content,
+		CreatedAt:      time.Unix(message.CreatedAt, 0).Format(time.RFC3339),
+	}
+}
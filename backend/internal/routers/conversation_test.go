@@ -0,0 +1,190 @@
+package routers
+
+import (
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/tests"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationRouter(t *testing.T) {
+	server, apiRouter, _, db, cleanup := tests.SetupTestServer("test_conversation_router.db")
+	defer cleanup()
+
+	NewUserRouter().Bind(apiRouter)
+	NewConversationRouter().Bind(apiRouter)
+
+	aliceData, aliceLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+	bobData, bobLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+	carolData, carolLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+
+	createConversation := func(accessToken string, participantIDs ...uuid.UUID) (int, *models.ConversationResponse) {
+		recorder := tests.SendTestRequest(server, "POST", "/api/conversation", accessToken, &models.ConversationCreateRequest{ParticipantIDs: participantIDs})
+		respBody := &models.ConversationResponse{}
+		_ = json.Unmarshal(recorder.Body.Bytes(), respBody)
+		return recorder.Code, respBody
+	}
+	sendMessage := func(accessToken string, conversationID uuid.UUID, content string) *httptest.ResponseRecorder {
+		return tests.SendTestRequest(server, "POST", "/api/conversation/"+conversationID.String()+"/message", accessToken, &models.ConversationSendMessageRequest{Content: content})
+	}
+	getConversations := func(accessToken string) *models.PaginationResponse[models.ConversationResponse] {
+		recorder := tests.SendTestRequest(server, "GET", "/api/conversation/list?limit=10", accessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		respBody := &models.PaginationResponse[models.ConversationResponse]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		return respBody
+	}
+
+	var directConversationID uuid.UUID
+
+	t.Run("失敗 - 缺少 Authorization", func(t *testing.T) {
+		assert.Equal(t, 401, tests.SendTestRequest(server, "GET", "/api/conversation/list", "", nil).Code)
+		assert.Equal(t, 401, tests.SendTestRequest(server, "POST", "/api/conversation", "", &models.ConversationCreateRequest{ParticipantIDs: []uuid.UUID{bobData.ID}}).Code)
+	})
+
+	t.Run("創建一對一對話", func(t *testing.T) {
+		code, conversation := createConversation(aliceLoginData.AccessToken, bobData.ID)
+		require.Equal(t, 201, code)
+		assert.False(t, conversation.IsGroup)
+		assert.Len(t, conversation.Participants, 2)
+		directConversationID = conversation.ID
+
+		code, again := createConversation(bobLoginData.AccessToken, aliceData.ID)
+		assert.Equal(t, 200, code, "已存在的一對一對話不應重複建立")
+		assert.Equal(t, directConversationID, again.ID)
+
+		// 同時建立時由唯一索引保證兩人之間只有一個一對一對話
+		duplicated := &models.Conversation{
+			TableModel:       models.TableModel{ID: uuid.New()},
+			ConversationBase: models.ConversationBase{DirectKey: pkg.GetPointer(models.ConversationDirectKey(bobData.ID, aliceData.ID))},
+		}
+		assert.Error(t, db.Create(duplicated).Error)
+	})
+
+	t.Run("創建對話失敗", func(t *testing.T) {
+		code, _ := createConversation(aliceLoginData.AccessToken, aliceData.ID)
+		assert.Equal(t, 400, code, "不能只和自己對話")
+		code, _ = createConversation(aliceLoginData.AccessToken, uuid.New())
+		assert.Equal(t, 404, code)
+
+		participantIDs := make([]uuid.UUID, models.CONVERSATION_MAX_PARTICIPANTS)
+		for i := range participantIDs {
+			participantIDs[i] = uuid.New()
+		}
+		code, _ = createConversation(aliceLoginData.AccessToken, participantIDs...)
+		assert.Equal(t, 400, code, "超過參與者上限")
+	})
+
+	t.Run("傳送訊息與已讀回條", func(t *testing.T) {
+		recorder := sendMessage(aliceLoginData.AccessToken, directConversationID, "嗨 Bob")
+		require.Equal(t, 201, recorder.Code)
+		recorder = sendMessage(aliceLoginData.AccessToken, directConversationID, "在嗎？")
+		require.Equal(t, 201, recorder.Code)
+		lastMessage := &models.ConversationMessageResponseItem{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), lastMessage))
+
+		assert.Equal(t, 400, sendMessage(aliceLoginData.AccessToken, directConversationID, " ").Code)
+		assert.Equal(t, 404, sendMessage(carolLoginData.AccessToken, directConversationID, "我不在對話中").Code)
+
+		bobConversations := getConversations(bobLoginData.AccessToken)
+		if assert.Len(t, bobConversations.Data, 1) {
+			assert.Equal(t, uint(2), bobConversations.Data[0].UnreadCount)
+			if assert.NotNil(t, bobConversations.Data[0].LastMessage) {
+				assert.Equal(t, "在嗎？", bobConversations.Data[0].LastMessage.Content)
+			}
+		}
+		aliceConversations := getConversations(aliceLoginData.AccessToken)
+		if assert.Len(t, aliceConversations.Data, 1) {
+			assert.Zero(t, aliceConversations.Data[0].UnreadCount, "自己傳送的訊息不算未讀")
+		}
+
+		recorder = tests.SendTestRequest(server, "PUT", "/api/conversation/"+directConversationID.String()+"/read", bobLoginData.AccessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		readResp := &models.ConversationMarkReadResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), readResp))
+		if assert.NotNil(t, readResp.LastReadMessageID) {
+			assert.Equal(t, lastMessage.ID, *readResp.LastReadMessageID)
+		}
+
+		bobConversations = getConversations(bobLoginData.AccessToken)
+		if assert.Len(t, bobConversations.Data, 1) {
+			assert.Zero(t, bobConversations.Data[0].UnreadCount)
+		}
+		recorder = tests.SendTestRequest(server, "GET", "/api/conversation/"+directConversationID.String(), aliceLoginData.AccessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		conversation := &models.ConversationResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), conversation))
+		for _, participant := range conversation.Participants {
+			if assert.NotNil(t, participant.LastReadMessageID, "雙方皆已讀到最後一則訊息") {
+				assert.Equal(t, lastMessage.ID, *participant.LastReadMessageID)
+			}
+		}
+	})
+
+	t.Run("取得訊息 - cursor 分頁", func(t *testing.T) {
+		path := "/api/conversation/" + directConversationID.String() + "/messages?cursor=&limit=1&withTotalCount=true"
+		recorder := tests.SendTestRequest(server, "GET", path, bobLoginData.AccessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		firstPage := &models.CursorPaginationResponse[models.ConversationMessageResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), firstPage))
+		if assert.Len(t, firstPage.Data, 1) {
+			assert.Equal(t, "在嗎？", firstPage.Data[0].Content, "由新到舊排序")
+		}
+		if assert.NotNil(t, firstPage.TotalCount) {
+			assert.Equal(t, uint(2), *firstPage.TotalCount)
+		}
+		require.NotNil(t, firstPage.NextCursor)
+
+		recorder = tests.SendTestRequest(server, "GET", "/api/conversation/"+directConversationID.String()+"/messages?limit=1&cursor="+*firstPage.NextCursor, bobLoginData.AccessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		secondPage := &models.CursorPaginationResponse[models.ConversationMessageResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), secondPage))
+		if assert.Len(t, secondPage.Data, 1) {
+			assert.Equal(t, "嗨 Bob", secondPage.Data[0].Content)
+		}
+		assert.Nil(t, secondPage.NextCursor)
+
+		assert.Equal(t, 404, tests.SendTestRequest(server, "GET", "/api/conversation/"+directConversationID.String()+"/messages", carolLoginData.AccessToken, nil).Code)
+		assert.Equal(t, 400, tests.SendTestRequest(server, "GET", "/api/conversation/invalid/messages", bobLoginData.AccessToken, nil).Code)
+	})
+
+	t.Run("群組對話與依最後活動排序", func(t *testing.T) {
+		code, group := createConversation(carolLoginData.AccessToken, aliceData.ID, bobData.ID)
+		require.Equal(t, 201, code)
+		assert.True(t, group.IsGroup)
+		assert.Len(t, group.Participants, 3)
+
+		require.Equal(t, 201, sendMessage(bobLoginData.AccessToken, group.ID, "大家好").Code)
+		// 同一秒內的活動依 ID 排序不穩定，直接調整時間
+		require.NoError(t, db.Model(&models.Conversation{}).Where("id = ?", directConversationID).Update("last_message_at", 1).Error)
+
+		aliceConversations := getConversations(aliceLoginData.AccessToken)
+		assert.Equal(t, uint(2), aliceConversations.TotalCount)
+		if assert.Len(t, aliceConversations.Data, 2) {
+			assert.Equal(t, group.ID, aliceConversations.Data[0].ID)
+			assert.Equal(t, uint(1), aliceConversations.Data[0].UnreadCount)
+			assert.Equal(t, directConversationID, aliceConversations.Data[1].ID)
+		}
+	})
+
+	t.Run("封鎖後無法傳送訊息", func(t *testing.T) {
+		require.NoError(t, db.Create(&models.Block{
+			TableModel: models.TableModel{ID: uuid.New()},
+			BlockBase:  models.BlockBase{BlockerID: bobData.ID, BlockedID: aliceData.ID},
+		}).Error)
+
+		assert.Equal(t, 403, sendMessage(aliceLoginData.AccessToken, directConversationID, "還在嗎？").Code)
+		assert.Equal(t, 403, sendMessage(bobLoginData.AccessToken, directConversationID, "不論誰封鎖誰").Code)
+		code, _ := createConversation(aliceLoginData.AccessToken, bobData.ID, carolData.ID)
+		assert.Equal(t, 403, code)
+	})
+}
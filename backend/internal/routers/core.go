@@ -0,0 +1,79 @@
+package routers
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CURSOR_PAGINATION_MAX_LIMIT cursor 分頁每頁最多筆數
+const CURSOR_PAGINATION_MAX_LIMIT = 100
+
+// isCursorPagination 帶有 cursor 查詢參數時使用 cursor 分頁，第一頁傳入空字串 (?cursor=)
+func isCursorPagination(ctx *gin.Context) bool {
+	_, ok := ctx.GetQuery("cursor")
+	return ok
+}
+
+// parseCursorPagination 解析 cursor、limit 與 withTotalCount 查詢參數，失敗時回應 400
+func parseCursorPagination(ctx *gin.Context) (*models.CursorPagination, bool) {
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
+	if err != nil || limit == 0 || limit > CURSOR_PAGINATION_MAX_LIMIT {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		return nil, false
+	}
+	pagination := &models.CursorPagination{Limit: uint(limit)}
+	if queryCursor := ctx.Query("cursor"); queryCursor != "" {
+		if pagination.Cursor, err = models.ParseCursor(queryCursor); err != nil {
+			ctx.JSON(400, models.ErrorResponse{Error: "invalid cursor"})
+			return nil, false
+		}
+	}
+	if queryWithTotalCount := ctx.Query("withTotalCount"); queryWithTotalCount != "" {
+		if pagination.WithTotalCount, err = strconv.ParseBool(queryWithTotalCount); err != nil {
+			ctx.JSON(400, models.ErrorResponse{Error: "invalid withTotalCount"})
+			return nil, false
+		}
+	}
+	return pagination, true
+}
+
+func newCursorPaginationResponse[T any](data []T, nextCursor *models.Cursor, totalCount *uint) models.CursorPaginationResponse[T] {
+	response := models.CursorPaginationResponse[T]{
+		Data:       data,
+		TotalCount: totalCount,
+	}
+	if nextCursor != nil {
+		response.NextCursor = pkg.GetPointer(nextCursor.String())
+	}
+	return response
+}
+
+// getViewerID 搭配 OptionalAccessToken 使用，回傳登入的使用者 ID，未登入時為 nil
+func getViewerID(ctx *gin.Context) *uuid.UUID {
+	if tokenData := middlewares.GetOptionalContentAccessTokenData(ctx); tokenData != nil {
+		return &tokenData.UserID
+	}
+	return nil
+}
+
+// toMentionEntities 轉換提及為回應格式，被提及的使用者已刪除的提及會被略過
+func toMentionEntities(mentions []models.Mention) []models.MentionEntity {
+	entities := make([]models.MentionEntity, 0, len(mentions))
+	for _, mention := range mentions {
+		if mention.User == nil {
+			continue
+		}
+		entities = append(entities, models.MentionEntity{
+			UserID:   mention.UserID,
+			Username: mention.User.Username,
+			Start:    mention.StartOffset,
+			End:      mention.EndOffset,
+		})
+	}
+	return entities
+}
@@ -0,0 +1,193 @@
+package routers
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/services"
+	"encoding/json"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// EVENT_STREAM_MAX_POSTS 一個事件串流最多訂閱的貼文數
+const EVENT_STREAM_MAX_POSTS = 20
+
+// EVENT_STREAM_HEARTBEAT_INTERVAL 沒有事件時送出註解行的間隔，避免連線被 proxy 中斷，同時重新檢查 Token 是否仍有效
+const EVENT_STREAM_HEARTBEAT_INTERVAL = 30 * time.Second
+
+type EventRouter struct {
+	EventService *services.EventService
+	PostService  *services.PostService
+	BlockService *services.BlockService
+
+	HeartbeatInterval time.Duration
+
+	// closed 關閉伺服器時關閉，結束所有串流
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+var eventRouterOnce sync.Once
+var eventRouter *EventRouter
+
+func NewEventRouter() *EventRouter {
+	eventRouterOnce.Do(func() {
+		eventRouter = &EventRouter{
+			EventService: services.NewEventService(),
+			PostService:  services.NewPostService(),
+			BlockService: services.NewBlockService(),
+
+			HeartbeatInterval: EVENT_STREAM_HEARTBEAT_INTERVAL,
+
+			closed: make(chan struct{}),
+		}
+	})
+	return eventRouter
+}
+
+// Close 結束所有事件串流，關閉伺服器時呼叫 (http.Server.Shutdown 不會結束進行中的請求)
+func (r *EventRouter) Close() {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+	})
+}
+
+func (r *EventRouter) Bind(_router *gin.RouterGroup) {
+	router := _router.Group("/events")
+	// GET
+	{
+		router.GET("",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.Stream,
+		)
+	}
+}
+
+// @title Event API
+// @Summary Subscribe to real-time events
+// @Description Server-sent events, each message is "data: {json}" where json is models.Event: my new notifications (type notification), and new comments (type comment, excluding users with a block relationship) and like count changes (type likeCount) of the posts given by postID. Lines starting with ":" are heartbeats, the stream ends when the session is revoked or the account is suspended
+// @Tags Event
+// @Security AccessToken
+// @Produce text/event-stream
+// @Param postID query []string false "IDs of the posts being viewed (max 20)" collectionFormat(multi)
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/events [get]
+func (r *EventRouter) Stream(ctx *gin.Context) {
+	queryPostIDs := ctx.QueryArray("postID")
+	if len(queryPostIDs) > EVENT_STREAM_MAX_POSTS {
+		ctx.JSON(400, models.ErrorResponse{Error: "too many postID"})
+		return
+	}
+	postIDs := make([]uuid.UUID, len(queryPostIDs))
+	for i, queryPostID := range queryPostIDs {
+		postID, err := uuid.Parse(queryPostID)
+		if err != nil {
+			ctx.JSON(400, models.ErrorResponse{Error: "invalid postID"})
+			return
+		}
+		postIDs[i] = postID
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	// 被隱藏或與作者有封鎖關係的貼文視為不存在
+	for _, postID := range postIDs {
+		post, err := r.PostService.GetByID(ctx, postID)
+		if err != nil {
+			ctx.JSON(404, models.ErrorResponse{Error: "post not found"})
+			return
+		}
+		blocked, err := r.BlockService.IsBlockedBetween(ctx, tokenData.UserID, post.AuthorID)
+		if err != nil {
+			ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if blocked {
+			ctx.JSON(404, models.ErrorResponse{Error: "post not found"})
+			return
+		}
+	}
+
+	messages, err := r.EventService.Subscribe(ctx.Request.Context(), tokenData.UserID, postIDs)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 設定 Header 為流式傳輸
+	ctx.Writer.Header().Set("Content-Type", "text/event-stream")
+	ctx.Writer.Header().Set("Cache-Control", "no-cache")
+	ctx.Writer.Header().Set("Connection", "keep-alive")
+	// 訂閱完成的訊號
+	ctx.Writer.Write([]byte(": connected\n\n"))
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(r.HeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			if visible, err := r.isVisibleTo(ctx, tokenData.UserID, message); err != nil {
+				log.Printf("Failed to filter event from %s: %v\n", message.Topic, err)
+				continue
+			} else if !visible {
+				continue
+			}
+			if _, err := ctx.Writer.Write(slices.Concat([]byte("data: "), message.Payload, []byte("\n\n"))); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		case <-heartbeat.C:
+			// 登出、停權或 Token 失效後結束串流
+			if rejectReason, err := middlewares.CheckContentAccessTokenState(ctx); err != nil || rejectReason != "" {
+				return
+			}
+			if _, err := ctx.Writer.Write([]byte(": heartbeat\n\n")); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		case <-ctx.Request.Context().Done():
+			return
+		case <-r.closed:
+			return
+		}
+	}
+}
+
+// isVisibleTo 與訂閱者有封鎖關係的使用者的評論不推送
+func (r *EventRouter) isVisibleTo(ctx *gin.Context, viewerID uuid.UUID, message pkg.EventMessage) (bool, error) {
+	event := struct {
+		Type models.EventType `json:"type"`
+		Data json.RawMessage  `json:"data"`
+	}{}
+	if err := json.Unmarshal(message.Payload, &event); err != nil {
+		return false, err
+	}
+	if event.Type != models.EventTypeComment {
+		return true, nil
+	}
+	comment := models.EventCommentData{}
+	if err := json.Unmarshal(event.Data, &comment); err != nil {
+		return false, err
+	}
+	blocked, err := r.BlockService.IsBlockedBetween(ctx, viewerID, comment.UserID)
+	if err != nil {
+		return false, err
+	}
+	return !blocked, nil
+}
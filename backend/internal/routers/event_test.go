@@ -0,0 +1,166 @@
+package routers
+
+import (
+	"backend/internal/models"
+	"backend/internal/tests"
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventRouter(t *testing.T) {
+	server, apiRouter, _, _, cleanup := tests.SetupTestServer("test_event_router.db")
+	defer cleanup()
+
+	NewUserRouter().Bind(apiRouter)
+	NewPostRouter().Bind(apiRouter)
+	NewCommentRouter().Bind(apiRouter)
+	NewEventRouter().Bind(apiRouter)
+	NewBlockRouter().Bind(apiRouter)
+
+	// 串流需要真正的 HTTP 連線才能邊寫邊讀
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	_, authorLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+	_, viewerLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+	postData, err := tests.SetupTestPost(server, authorLoginData.AccessToken)
+	require.NoError(t, err)
+
+	// subscribe 開啟事件串流並等待訂閱完成，回傳讀取下一個事件的函式
+	subscribe := func(t *testing.T, ctx context.Context, query string, accessToken string) func() models.Event {
+		req, _ := http.NewRequestWithContext(ctx, "GET", httpServer.URL+"/api/events"+query, nil)
+		req.Header.Set("Authorization", accessToken)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		require.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		lines := make(chan string)
+		go func() {
+			defer close(lines)
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+		}()
+		require.Equal(t, ": connected", <-lines)
+
+		return func() models.Event {
+			for {
+				select {
+				case line, ok := <-lines:
+					require.True(t, ok, "串流不應結束")
+					data, ok := strings.CutPrefix(line, "data: ")
+					if !ok {
+						continue
+					}
+					event := models.Event{}
+					require.NoError(t, json.Unmarshal([]byte(data), &event))
+					return event
+				case <-time.After(3 * time.Second):
+					require.FailNow(t, "等待事件逾時")
+				}
+			}
+		}
+	}
+
+	t.Run("失敗 - 缺少 Authorization", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/events", nil)
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+		assert.Equal(t, 401, recorder.Code)
+	})
+
+	t.Run("失敗 - 無效的 postID", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/events?postID=invalid", nil)
+		req.Header.Set("Authorization", viewerLoginData.AccessToken)
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+		assert.Equal(t, 400, recorder.Code)
+	})
+
+	t.Run("推送貼文事件與通知", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		nextViewerEvent := subscribe(t, ctx, "?postID="+postData.ID.String(), viewerLoginData.AccessToken)
+		nextAuthorEvent := subscribe(t, ctx, "", authorLoginData.AccessToken)
+
+		require.Equal(t, 200, tests.SendTestRequest(server, "PUT", "/api/post/like/"+postData.ID.String(), viewerLoginData.AccessToken, nil).Code)
+		event := nextViewerEvent()
+		assert.Equal(t, models.EventTypeLikeCount, event.Type)
+		assert.Equal(t, map[string]any{"postID": postData.ID.String(), "likeCount": float64(1)}, event.Data)
+
+		event = nextAuthorEvent()
+		assert.Equal(t, models.EventTypeNotification, event.Type)
+		assert.Equal(t, string(models.NotificationTypeLike), event.Data.(map[string]any)["type"])
+
+		require.Equal(t, 200, tests.SendTestRequest(server, "POST", "/api/comment", authorLoginData.AccessToken, models.CommentCreateRequest{
+			PostID:  postData.ID,
+			Content: "live comment",
+		}).Code)
+		event = nextViewerEvent()
+		assert.Equal(t, models.EventTypeComment, event.Type)
+		assert.Equal(t, "live comment", event.Data.(map[string]any)["content"])
+	})
+
+	t.Run("封鎖 - 不推送有封鎖關係的使用者的評論", func(t *testing.T) {
+		_, blockedLoginData, err := tests.SetupTestUser(server)
+		require.NoError(t, err)
+		require.Equal(t, 200, tests.SendTestRequest(server, "PUT", "/api/block/"+blockedLoginData.ID.String(), viewerLoginData.AccessToken, nil).Code)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		nextViewerEvent := subscribe(t, ctx, "?postID="+postData.ID.String(), viewerLoginData.AccessToken)
+		require.Equal(t, 200, tests.SendTestRequest(server, "POST", "/api/comment", blockedLoginData.AccessToken, models.CommentCreateRequest{
+			PostID:  postData.ID,
+			Content: "blocked comment",
+		}).Code)
+		require.Equal(t, 200, tests.SendTestRequest(server, "POST", "/api/comment", authorLoginData.AccessToken, models.CommentCreateRequest{
+			PostID:  postData.ID,
+			Content: "visible comment",
+		}).Code)
+		event := nextViewerEvent()
+		assert.Equal(t, models.EventTypeComment, event.Type)
+		assert.Equal(t, "visible comment", event.Data.(map[string]any)["content"], "被封鎖的使用者的評論不推送")
+
+		// 作者封鎖訂閱者後無法訂閱其貼文
+		require.Equal(t, 200, tests.SendTestRequest(server, "PUT", "/api/block/"+blockedLoginData.ID.String(), authorLoginData.AccessToken, nil).Code)
+		assert.Equal(t, 404, tests.SendTestRequest(server, "GET", "/api/events?postID="+postData.ID.String(), blockedLoginData.AccessToken, nil).Code)
+		assert.Equal(t, 404, tests.SendTestRequest(server, "GET", "/api/events?postID="+uuid.New().String(), viewerLoginData.AccessToken, nil).Code)
+	})
+
+	t.Run("登出後結束串流", func(t *testing.T) {
+		eventRouter := NewEventRouter()
+		eventRouter.HeartbeatInterval = 50 * time.Millisecond
+		defer func() { eventRouter.HeartbeatInterval = EVENT_STREAM_HEARTBEAT_INTERVAL }()
+		_, userLoginData, err := tests.SetupTestUser(server)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, "GET", httpServer.URL+"/api/events", nil)
+		req.Header.Set("Authorization", userLoginData.AccessToken)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+
+		require.Equal(t, 200, tests.SendTestRequest(server, "POST", "/api/user/logout", userLoginData.AccessToken, nil).Code)
+		_, err = io.ReadAll(resp.Body)
+		assert.NoError(t, err, "串流應在逾時前結束")
+	})
+}
@@ -0,0 +1,209 @@
+package routers
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/services"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type FollowRouter struct {
+	FollowService *services.FollowService
+	UserService   *services.UserService
+}
+
+var followRouterOnce sync.Once
+var followRouter *FollowRouter
+
+func NewFollowRouter() *FollowRouter {
+	followRouterOnce.Do(func() {
+		followRouter = &FollowRouter{
+			FollowService: services.NewFollowService(),
+			UserService:   services.NewUserService(),
+		}
+	})
+	return followRouter
+}
+
+func (r *FollowRouter) Bind(_router *gin.RouterGroup) {
+	router := _router.Group("/follow")
+	// GET
+	{
+		router.GET("/:userID/followers", r.GetFollowers)
+		router.GET("/:userID/following", r.GetFollowing)
+	}
+	// PUT
+	{
+		router.PUT("/:userID",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.Follow,
+		)
+	}
+	// DELETE
+	{
+		router.DELETE("/:userID",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.Unfollow,
+		)
+	}
+}
+
+// @title Follow API
+// @Summary Follow a user
+// @Description Following a user more than once has no effect. Users with a block between them cannot follow each other
+// @Tags Follow
+// @Security AccessToken
+// @Produce application/json
+// @Param userID path string true "User ID"
+// @Success 200 {object} models.FollowResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/follow/{userID} [put]
+func (r *FollowRouter) Follow(ctx *gin.Context) {
+	r.setFollowing(ctx, true)
+}
+
+// @title Follow API
+// @Summary Unfollow a user
+// @Description Unfollowing a user that is not followed has no effect
+// @Tags Follow
+// @Security AccessToken
+// @Produce application/json
+// @Param userID path string true "User ID"
+// @Success 200 {object} models.FollowResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/follow/{userID} [delete]
+func (r *FollowRouter) Unfollow(ctx *gin.Context) {
+	r.setFollowing(ctx, false)
+}
+
+func (r *FollowRouter) setFollowing(ctx *gin.Context, following bool) {
+	user, ok := r.getUser(ctx)
+	if !ok {
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if following {
+		err = r.FollowService.Follow(ctx, tokenData.UserID, user.ID)
+	} else {
+		err = r.FollowService.Unfollow(ctx, tokenData.UserID, user.ID)
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrFollowBlocked) {
+			ctx.JSON(403, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if r.FollowService.ErrorUtils.IsServerInternalError(err.Error()) {
+			ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(400, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.FollowResponse{
+		UserID:    user.ID,
+		Following: following,
+	})
+}
+
+// @title Follow API
+// @Summary Get followers of a user
+// @Tags Follow
+// @Produce application/json
+// @Param userID path string true "User ID"
+// @Param offset query string false "Offset"
+// @Param limit query string false "Limit"
+// @Success 200 {object} models.PaginationResponse[models.FollowGetUsersResponseItem]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/follow/{userID}/followers [get]
+func (r *FollowRouter) GetFollowers(ctx *gin.Context) {
+	r.getUsers(ctx, r.FollowService.GetFollowers)
+}
+
+// @title Follow API
+// @Summary Get users followed by a user
+// @Tags Follow
+// @Produce application/json
+// @Param userID path string true "User ID"
+// @Param offset query string false "Offset"
+// @Param limit query string false "Limit"
+// @Success 200 {object} models.PaginationResponse[models.FollowGetUsersResponseItem]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/follow/{userID}/following [get]
+func (r *FollowRouter) GetFollowing(ctx *gin.Context) {
+	r.getUsers(ctx, r.FollowService.GetFollowing)
+}
+
+func (r *FollowRouter) getUsers(ctx *gin.Context, getList func(*gin.Context, uuid.UUID, *models.Pagination) ([]models.User, uint, error)) {
+	offset, err := strconv.ParseUint(ctx.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid offset"})
+		return
+	}
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
+	if err != nil || limit == 0 {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		return
+	}
+	user, ok := r.getUser(ctx)
+	if !ok {
+		return
+	}
+
+	pagination := &models.Pagination{
+		Offset: uint(offset),
+		Limit:  uint(limit),
+	}
+	users, totalCount, err := getList(ctx, user.ID, pagination)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	responseData := make([]models.FollowGetUsersResponseItem, len(users))
+	for i, user := range users {
+		responseData[i] = models.FollowGetUsersResponseItem{
+			ID:       user.ID,
+			Username: user.Username,
+		}
+	}
+	ctx.JSON(200, models.PaginationResponse[models.FollowGetUsersResponseItem]{
+		Data:       responseData,
+		TotalCount: totalCount,
+		Pagination: pagination,
+	})
+}
+
+// getUser 解析路由中的 userID 並檢查使用者存在
+func (r *FollowRouter) getUser(ctx *gin.Context) (*models.User, bool) {
+	userID, err := uuid.Parse(ctx.Param("userID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid user ID"})
+		return nil, false
+	}
+	user, err := r.UserService.GetByID(ctx, userID)
+	if err != nil {
+		ctx.JSON(404, models.ErrorResponse{Error: "user not found"})
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,84 @@
+package routers
+
+import (
+	"backend/internal/models"
+	"backend/internal/tests"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollowRouter(t *testing.T) {
+	server, apiRouter, _, _, cleanup := tests.SetupTestServer("test_follow_router.db")
+	defer cleanup()
+
+	NewUserRouter().Bind(apiRouter)
+	NewFollowRouter().Bind(apiRouter)
+
+	followerData, followerLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+	followeeData, _, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+
+	getUsers := func(path string) *models.PaginationResponse[models.FollowGetUsersResponseItem] {
+		recorder := tests.SendTestRequest(server, "GET", path, "", nil)
+		require.Equal(t, 200, recorder.Code)
+		response := &models.PaginationResponse[models.FollowGetUsersResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+		return response
+	}
+
+	t.Run("Follow", func(t *testing.T) {
+		t.Run("失敗 - 缺少 Authorization", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/follow/"+followeeData.ID.String(), "", nil)
+			assert.Equal(t, 401, recorder.Code)
+		})
+
+		t.Run("失敗 - 使用者不存在", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/follow/"+uuid.New().String(), followerLoginData.AccessToken, nil)
+			assert.Equal(t, 404, recorder.Code)
+		})
+
+		t.Run("失敗 - 追蹤自己", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/follow/"+followerData.ID.String(), followerLoginData.AccessToken, nil)
+			assert.Equal(t, 400, recorder.Code)
+		})
+
+		t.Run("成功追蹤 - 重複追蹤不重複計算", func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				recorder := tests.SendTestRequest(server, "PUT", "/api/follow/"+followeeData.ID.String(), followerLoginData.AccessToken, nil)
+				assert.Equal(t, 200, recorder.Code)
+				response := &models.FollowResponse{}
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+				assert.True(t, response.Following)
+			}
+
+			followers := getUsers("/api/follow/" + followeeData.ID.String() + "/followers")
+			assert.Equal(t, uint(1), followers.TotalCount)
+			if assert.Len(t, followers.Data, 1) {
+				assert.Equal(t, followerData.ID, followers.Data[0].ID)
+			}
+			following := getUsers("/api/follow/" + followerData.ID.String() + "/following")
+			assert.Equal(t, uint(1), following.TotalCount)
+			if assert.Len(t, following.Data, 1) {
+				assert.Equal(t, followeeData.ID, following.Data[0].ID)
+			}
+		})
+	})
+
+	t.Run("Unfollow", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			recorder := tests.SendTestRequest(server, "DELETE", "/api/follow/"+followeeData.ID.String(), followerLoginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code)
+			response := &models.FollowResponse{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+			assert.False(t, response.Following)
+		}
+		followers := getUsers("/api/follow/" + followeeData.ID.String() + "/followers")
+		assert.Equal(t, uint(0), followers.TotalCount)
+		assert.Empty(t, followers.Data)
+	})
+}
@@ -0,0 +1,177 @@
+package routers
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/services"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MuteRouter struct {
+	MuteService *services.MuteService
+	UserService *services.UserService
+}
+
+var muteRouterOnce sync.Once
+var muteRouter *MuteRouter
+
+func NewMuteRouter() *MuteRouter {
+	muteRouterOnce.Do(func() {
+		muteRouter = &MuteRouter{
+			MuteService: services.NewMuteService(),
+			UserService: services.NewUserService(),
+		}
+	})
+	return muteRouter
+}
+
+func (r *MuteRouter) Bind(_router *gin.RouterGroup) {
+	router := _router.Group("/mute")
+	// GET
+	{
+		router.GET("/list",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.GetMutedUsers,
+		)
+	}
+	// PUT
+	{
+		router.PUT("/:userID",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.Mute,
+		)
+	}
+	// DELETE
+	{
+		router.DELETE("/:userID",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.Unmute,
+		)
+	}
+}
+
+// @title Mute API
+// @Summary Mute a user
+// @Description Posts of muted users are hidden from the muter's feed and timeline only. Muting a user more than once has no effect
+// @Tags Mute
+// @Security AccessToken
+// @Produce application/json
+// @Param userID path string true "User ID"
+// @Success 200 {object} models.MuteResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/mute/{userID} [put]
+func (r *MuteRouter) Mute(ctx *gin.Context) {
+	r.setMuted(ctx, true)
+}
+
+// @title Mute API
+// @Summary Unmute a user
+// @Description Unmuting a user that is not muted has no effect
+// @Tags Mute
+// @Security AccessToken
+// @Produce application/json
+// @Param userID path string true "User ID"
+// @Success 200 {object} models.MuteResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/mute/{userID} [delete]
+func (r *MuteRouter) Unmute(ctx *gin.Context) {
+	r.setMuted(ctx, false)
+}
+
+func (r *MuteRouter) setMuted(ctx *gin.Context, muted bool) {
+	userID, err := uuid.Parse(ctx.Param("userID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid user ID"})
+		return
+	}
+	user, err := r.UserService.GetByID(ctx, userID)
+	if err != nil {
+		ctx.JSON(404, models.ErrorResponse{Error: "user not found"})
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if muted {
+		err = r.MuteService.Mute(ctx, tokenData.UserID, user.ID)
+	} else {
+		err = r.MuteService.Unmute(ctx, tokenData.UserID, user.ID)
+	}
+	if err != nil {
+		if r.MuteService.ErrorUtils.IsServerInternalError(err.Error()) {
+			ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(400, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.MuteResponse{
+		UserID: user.ID,
+		Muted:  muted,
+	})
+}
+
+// @title Mute API
+// @Summary Get users muted by the current user
+// @Description Ordered by mute time, newest first
+// @Tags Mute
+// @Security AccessToken
+// @Produce application/json
+// @Param offset query string false "Offset"
+// @Param limit query string false "Limit"
+// @Success 200 {object} models.PaginationResponse[models.MuteGetUsersResponseItem]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/mute/list [get]
+func (r *MuteRouter) GetMutedUsers(ctx *gin.Context) {
+	offset, err := strconv.ParseUint(ctx.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid offset"})
+		return
+	}
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
+	if err != nil || limit == 0 {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	pagination := &models.Pagination{
+		Offset: uint(offset),
+		Limit:  uint(limit),
+	}
+	users, totalCount, err := r.MuteService.GetMutedUsers(ctx, tokenData.UserID, pagination)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	responseData := make([]models.MuteGetUsersResponseItem, len(users))
+	for i, user := range users {
+		responseData[i] = models.MuteGetUsersResponseItem{
+			ID:       user.ID,
+			Username: user.Username,
+		}
+	}
+	ctx.JSON(200, models.PaginationResponse[models.MuteGetUsersResponseItem]{
+		Data:       responseData,
+		TotalCount: totalCount,
+		Pagination: pagination,
+	})
+}
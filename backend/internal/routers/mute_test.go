@@ -0,0 +1,90 @@
+package routers
+
+import (
+	"backend/internal/models"
+	"backend/internal/tests"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMuteRouter(t *testing.T) {
+	server, apiRouter, _, _, cleanup := tests.SetupTestServer("test_mute_router.db")
+	defer cleanup()
+
+	NewUserRouter().Bind(apiRouter)
+	NewPostRouter().Bind(apiRouter)
+	NewFollowRouter().Bind(apiRouter)
+	NewMuteRouter().Bind(apiRouter)
+
+	muterData, muterLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+	mutedData, mutedLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+
+	getPostIDs := func(path string, accessToken string) []uuid.UUID {
+		recorder := tests.SendTestRequest(server, "GET", path, accessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		respBody := &models.CursorPaginationResponse[models.PostGetTimelineResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		postIDs := make([]uuid.UUID, len(respBody.Data))
+		for i, post := range respBody.Data {
+			postIDs[i] = post.ID
+		}
+		return postIDs
+	}
+	getMutedUsers := func(accessToken string) *models.PaginationResponse[models.MuteGetUsersResponseItem] {
+		recorder := tests.SendTestRequest(server, "GET", "/api/mute/list?limit=10", accessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		respBody := &models.PaginationResponse[models.MuteGetUsersResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		return respBody
+	}
+
+	require.Equal(t, 200, tests.SendTestRequest(server, "PUT", "/api/follow/"+mutedData.ID.String(), muterLoginData.AccessToken, nil).Code)
+	mutedPostData, err := tests.SetupTestPost(server, mutedLoginData.AccessToken)
+	require.NoError(t, err)
+	muterPostData, err := tests.SetupTestPost(server, muterLoginData.AccessToken)
+	require.NoError(t, err)
+
+	t.Run("靜音失敗", func(t *testing.T) {
+		assert.Equal(t, 401, tests.SendTestRequest(server, "PUT", "/api/mute/"+mutedData.ID.String(), "", nil).Code)
+		assert.Equal(t, 401, tests.SendTestRequest(server, "GET", "/api/mute/list", "", nil).Code)
+		assert.Equal(t, 400, tests.SendTestRequest(server, "PUT", "/api/mute/invalid", muterLoginData.AccessToken, nil).Code)
+		assert.Equal(t, 404, tests.SendTestRequest(server, "PUT", "/api/mute/"+uuid.New().String(), muterLoginData.AccessToken, nil).Code)
+		assert.Equal(t, 400, tests.SendTestRequest(server, "PUT", "/api/mute/"+muterData.ID.String(), muterLoginData.AccessToken, nil).Code, "不能靜音自己")
+	})
+
+	t.Run("成功靜音 - 只過濾靜音者的動態", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/mute/"+mutedData.ID.String(), muterLoginData.AccessToken, nil)
+			require.Equal(t, 200, recorder.Code)
+			respBody := &models.MuteResponse{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			assert.True(t, respBody.Muted)
+		}
+		mutedUsers := getMutedUsers(muterLoginData.AccessToken)
+		assert.Equal(t, uint(1), mutedUsers.TotalCount)
+		if assert.Len(t, mutedUsers.Data, 1) {
+			assert.Equal(t, mutedData.ID, mutedUsers.Data[0].ID)
+		}
+
+		assert.Equal(t, []uuid.UUID{muterPostData.ID}, getPostIDs("/api/post/timeline?limit=10", muterLoginData.AccessToken))
+		assert.Equal(t, []uuid.UUID{mutedPostData.ID}, getPostIDs("/api/post/list/author/"+mutedData.ID.String()+"?limit=10", muterLoginData.AccessToken), "作者頁面不受靜音影響")
+		assert.Equal(t, []uuid.UUID{mutedPostData.ID}, getPostIDs("/api/post/timeline?limit=10", mutedLoginData.AccessToken), "被靜音者不受影響")
+	})
+
+	t.Run("解除靜音", func(t *testing.T) {
+		recorder := tests.SendTestRequest(server, "DELETE", "/api/mute/"+mutedData.ID.String(), muterLoginData.AccessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		respBody := &models.MuteResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		assert.False(t, respBody.Muted)
+
+		assert.Zero(t, getMutedUsers(muterLoginData.AccessToken).TotalCount)
+		assert.ElementsMatch(t, []uuid.UUID{muterPostData.ID, mutedPostData.ID}, getPostIDs("/api/post/timeline?limit=10", muterLoginData.AccessToken))
+	})
+}
@@ -0,0 +1,207 @@
+package routers
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/services"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NotificationRouter struct {
+	NotificationService *services.NotificationService
+}
+
+var notificationRouterOnce sync.Once
+var notificationRouter *NotificationRouter
+
+func NewNotificationRouter() *NotificationRouter {
+	notificationRouterOnce.Do(func() {
+		notificationRouter = &NotificationRouter{
+			NotificationService: services.NewNotificationService(),
+		}
+	})
+	return notificationRouter
+}
+
+func (r *NotificationRouter) Bind(_router *gin.RouterGroup) {
+	router := _router.Group("/notification")
+	// GET
+	{
+		router.GET("/list",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.GetNotifications,
+		)
+		router.GET("/unread/count",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.GetUnreadCount,
+		)
+	}
+	// PUT
+	{
+		router.PUT("/read/all",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.MarkAllRead,
+		)
+		router.PUT("/:notificationID/read",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.MarkRead,
+		)
+	}
+}
+
+// @title Notification API
+// @Summary Get my notifications
+// @Description Notifications of the same event are grouped, e.g. likes of a post ("alice and 5 others liked your post"), follows, comments of a post and replies to a comment. Newest first
+// @Tags Notification
+// @Security AccessToken
+// @Produce application/json
+// @Param offset query string false "Offset"
+// @Param limit query string false "Limit"
+// @Success 200 {object} models.PaginationResponse[models.NotificationGetListResponseItem]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/notification/list [get]
+func (r *NotificationRouter) GetNotifications(ctx *gin.Context) {
+	offset, err := strconv.ParseUint(ctx.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid offset"})
+		return
+	}
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
+	if err != nil || limit == 0 {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	pagination := &models.Pagination{
+		Offset: uint(offset),
+		Limit:  uint(limit),
+	}
+	groups, totalCount, err := r.NotificationService.GetGroups(ctx, tokenData.UserID, pagination)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	responseData := make([]models.NotificationGetListResponseItem, len(groups))
+	for i, group := range groups {
+		notification := group.Latest
+		actor := models.NotificationGetListResponseItemActor{ID: notification.ActorID}
+		if notification.Actor != nil {
+			actor.Username = notification.Actor.Username
+		}
+		responseData[i] = models.NotificationGetListResponseItem{
+			ID:         notification.ID,
+			Type:       notification.Type,
+			Actor:      actor,
+			PostID:     notification.PostID,
+			CommentID:  notification.CommentID,
+			ActorCount: group.ActorCount,
+			Message:    group.Message(),
+			Read:       group.Read,
+			CreatedAt:  time.Unix(notification.CreatedAt, 0).Format(time.RFC3339),
+		}
+	}
+	ctx.JSON(200, models.PaginationResponse[models.NotificationGetListResponseItem]{
+		Data:       responseData,
+		TotalCount: totalCount,
+		Pagination: pagination,
+	})
+}
+
+// @title Notification API
+// @Summary Get the number of unread notifications
+// @Description Grouped notifications are counted once, the same as the items of /api/notification/list
+// @Tags Notification
+// @Security AccessToken
+// @Produce application/json
+// @Success 200 {object} models.NotificationGetUnreadCountResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/notification/unread/count [get]
+func (r *NotificationRouter) GetUnreadCount(ctx *gin.Context) {
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	count, err := r.NotificationService.CountUnread(ctx, tokenData.UserID)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.NotificationGetUnreadCountResponse{Count: count})
+}
+
+// @title Notification API
+// @Summary Mark a notification as read
+// @Description The notifications grouped with it are marked as read as well
+// @Tags Notification
+// @Security AccessToken
+// @Produce application/json
+// @Param notificationID path string true "Notification ID"
+// @Success 200 {object} models.NotificationMarkReadResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/notification/{notificationID}/read [put]
+func (r *NotificationRouter) MarkRead(ctx *gin.Context) {
+	notificationID, err := uuid.Parse(ctx.Param("notificationID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid notification ID"})
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	count, err := r.NotificationService.MarkRead(ctx, tokenData.UserID, notificationID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotificationNotFound) {
+			ctx.JSON(404, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.NotificationMarkReadResponse{Count: count})
+}
+
+// @title Notification API
+// @Summary Mark all my notifications as read
+// @Tags Notification
+// @Security AccessToken
+// @Produce application/json
+// @Success 200 {object} models.NotificationMarkReadResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/notification/read/all [put]
+func (r *NotificationRouter) MarkAllRead(ctx *gin.Context) {
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	count, err := r.NotificationService.MarkAllRead(ctx, tokenData.UserID)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.NotificationMarkReadResponse{Count: count})
+}
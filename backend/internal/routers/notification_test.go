@@ -0,0 +1,170 @@
+package routers
+
+import (
+	"backend/internal/models"
+	"backend/internal/tests"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationRouter(t *testing.T) {
+	server, apiRouter, _, _, cleanup := tests.SetupTestServer("test_notification_router.db")
+	defer cleanup()
+
+	NewUserRouter().Bind(apiRouter)
+	NewPostRouter().Bind(apiRouter)
+	NewCommentRouter().Bind(apiRouter)
+	NewFollowRouter().Bind(apiRouter)
+	NewNotificationRouter().Bind(apiRouter)
+
+	_, authorLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+	bobData, bobLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+	_, carolLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+	postData, err := tests.SetupTestPost(server, authorLoginData.AccessToken)
+	require.NoError(t, err)
+
+	getNotifications := func(accessToken string) *models.PaginationResponse[models.NotificationGetListResponseItem] {
+		recorder := tests.SendTestRequest(server, "GET", "/api/notification/list?limit=10", accessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		respBody := &models.PaginationResponse[models.NotificationGetListResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		return respBody
+	}
+	getUnreadCount := func(accessToken string) uint {
+		recorder := tests.SendTestRequest(server, "GET", "/api/notification/unread/count", accessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		respBody := &models.NotificationGetUnreadCountResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		return respBody.Count
+	}
+	findByType := func(items []models.NotificationGetListResponseItem, notificationType models.NotificationType) *models.NotificationGetListResponseItem {
+		for i := range items {
+			if items[i].Type == notificationType {
+				return &items[i]
+			}
+		}
+		return nil
+	}
+
+	t.Run("失敗 - 缺少 Authorization", func(t *testing.T) {
+		assert.Equal(t, 401, tests.SendTestRequest(server, "GET", "/api/notification/list", "", nil).Code)
+		assert.Equal(t, 401, tests.SendTestRequest(server, "GET", "/api/notification/unread/count", "", nil).Code)
+		assert.Equal(t, 401, tests.SendTestRequest(server, "PUT", "/api/notification/read/all", "", nil).Code)
+	})
+
+	t.Run("事件通知與合併", func(t *testing.T) {
+		// 自己喜歡自己的貼文不通知
+		require.Equal(t, 200, tests.SendTestRequest(server, "PUT", "/api/post/like/"+postData.ID.String(), authorLoginData.AccessToken, nil).Code)
+		require.Equal(t, 200, tests.SendTestRequest(server, "PUT", "/api/post/like/"+postData.ID.String(), bobLoginData.AccessToken, nil).Code)
+		require.Equal(t, 200, tests.SendTestRequest(server, "PUT", "/api/post/like/"+postData.ID.String(), carolLoginData.AccessToken, nil).Code)
+		require.Equal(t, 200, tests.SendTestRequest(server, "PUT", "/api/follow/"+authorLoginData.ID.String(), bobLoginData.AccessToken, nil).Code)
+		require.Equal(t, 200, tests.SendTestRequest(server, "PUT", "/api/follow/"+authorLoginData.ID.String(), bobLoginData.AccessToken, nil).Code)
+		recorder := tests.SendTestRequest(server, "POST", "/api/comment", bobLoginData.AccessToken, models.CommentCreateRequest{PostID: postData.ID, Content: "nice"})
+		require.Equal(t, 200, recorder.Code)
+		comment := &models.CommentCreateResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), comment))
+
+		respBody := getNotifications(authorLoginData.AccessToken)
+		assert.Equal(t, uint(3), respBody.TotalCount, "兩個喜歡合併為一則")
+		assert.Equal(t, models.NotificationTypeComment, respBody.Data[0].Type, "最新的在前")
+		like := findByType(respBody.Data, models.NotificationTypeLike)
+		if assert.NotNil(t, like) {
+			assert.Equal(t, uint(2), like.ActorCount)
+			assert.Regexp(t, `^\S+ and 1 other liked your post$`, like.Message)
+			assert.Equal(t, postData.ID, *like.PostID)
+			assert.False(t, like.Read)
+		}
+		follow := findByType(respBody.Data, models.NotificationTypeFollow)
+		if assert.NotNil(t, follow) {
+			assert.Equal(t, bobData.ID, follow.Actor.ID)
+			assert.Equal(t, bobData.Username+" followed you", follow.Message, "重複追蹤不重複通知")
+		}
+		assert.Equal(t, uint(3), getUnreadCount(authorLoginData.AccessToken))
+
+		t.Run("回覆評論", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "POST", "/api/comment", carolLoginData.AccessToken, models.CommentCreateRequest{
+				PostID:   postData.ID,
+				Content:  "reply",
+				ParentID: &comment.ID,
+			})
+			require.Equal(t, 200, recorder.Code)
+			bobNotifications := getNotifications(bobLoginData.AccessToken)
+			if assert.Len(t, bobNotifications.Data, 1) {
+				assert.Equal(t, models.NotificationTypeReply, bobNotifications.Data[0].Type)
+				assert.Contains(t, bobNotifications.Data[0].Message, "replied to your comment")
+			}
+			commentNotification := findByType(getNotifications(authorLoginData.AccessToken).Data, models.NotificationTypeComment)
+			if assert.NotNil(t, commentNotification) {
+				assert.Equal(t, uint(2), commentNotification.ActorCount, "同一篇貼文的評論合併")
+			}
+		})
+
+		t.Run("取消喜歡移除通知", func(t *testing.T) {
+			require.Equal(t, 200, tests.SendTestRequest(server, "DELETE", "/api/post/like/"+postData.ID.String(), carolLoginData.AccessToken, nil).Code)
+			like := findByType(getNotifications(authorLoginData.AccessToken).Data, models.NotificationTypeLike)
+			if assert.NotNil(t, like) {
+				assert.Equal(t, uint(1), like.ActorCount)
+				assert.Equal(t, bobData.Username+" liked your post", like.Message)
+			}
+		})
+	})
+
+	t.Run("標記已讀", func(t *testing.T) {
+		like := findByType(getNotifications(authorLoginData.AccessToken).Data, models.NotificationTypeLike)
+		require.NotNil(t, like)
+
+		t.Run("失敗 - 通知不存在或不屬於自己", func(t *testing.T) {
+			assert.Equal(t, 400, tests.SendTestRequest(server, "PUT", "/api/notification/invalid/read", authorLoginData.AccessToken, nil).Code)
+			assert.Equal(t, 404, tests.SendTestRequest(server, "PUT", "/api/notification/"+uuid.New().String()+"/read", authorLoginData.AccessToken, nil).Code)
+			assert.Equal(t, 404, tests.SendTestRequest(server, "PUT", "/api/notification/"+like.ID.String()+"/read", bobLoginData.AccessToken, nil).Code)
+		})
+
+		t.Run("標記單則", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/notification/"+like.ID.String()+"/read", authorLoginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code)
+			respBody := &models.NotificationMarkReadResponse{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			assert.Equal(t, uint(1), respBody.Count)
+			assert.True(t, findByType(getNotifications(authorLoginData.AccessToken).Data, models.NotificationTypeLike).Read)
+			assert.Equal(t, uint(2), getUnreadCount(authorLoginData.AccessToken))
+		})
+
+		t.Run("全部標記", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/notification/read/all", authorLoginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code)
+			respBody := &models.NotificationMarkReadResponse{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			assert.Equal(t, uint(3), respBody.Count, "follow 與兩則評論")
+			assert.Equal(t, uint(0), getUnreadCount(authorLoginData.AccessToken))
+			assert.Equal(t, uint(1), getUnreadCount(bobLoginData.AccessToken), "不影響其他使用者")
+		})
+
+		t.Run("已讀的群組不再合併新的通知", func(t *testing.T) {
+			require.Equal(t, 200, tests.SendTestRequest(server, "PUT", "/api/follow/"+authorLoginData.ID.String(), carolLoginData.AccessToken, nil).Code)
+			respBody := getNotifications(authorLoginData.AccessToken)
+			assert.Equal(t, uint(4), respBody.TotalCount, "開啟新的追蹤通知群組")
+			if assert.NotEmpty(t, respBody.Data) {
+				assert.Equal(t, models.NotificationTypeFollow, respBody.Data[0].Type)
+				assert.Equal(t, uint(1), respBody.Data[0].ActorCount, "不計入已讀的通知")
+				assert.False(t, respBody.Data[0].Read)
+			}
+			assert.Equal(t, uint(1), getUnreadCount(authorLoginData.AccessToken))
+
+			_, daveLoginData, err := tests.SetupTestUser(server)
+			require.NoError(t, err)
+			require.Equal(t, 200, tests.SendTestRequest(server, "PUT", "/api/follow/"+authorLoginData.ID.String(), daveLoginData.AccessToken, nil).Code)
+			respBody = getNotifications(authorLoginData.AccessToken)
+			assert.Equal(t, uint(4), respBody.TotalCount, "未讀的群組繼續合併")
+			if assert.NotEmpty(t, respBody.Data) {
+				assert.Equal(t, uint(2), respBody.Data[0].ActorCount)
+			}
+		})
+	})
+}
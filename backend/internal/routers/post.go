@@ -5,6 +5,7 @@ import (
 	"backend/internal/models"
 	"backend/internal/pkg"
 	"backend/internal/services"
+	"errors"
 	"regexp"
 	"strconv"
 	"strings"
@@ -15,13 +16,17 @@ import (
 	"github.com/google/uuid"
 )
 
+// POST_TAG_REGEX 貼文內容中的 #標籤
+var POST_TAG_REGEX = regexp.MustCompile(`#([^\s#]+)`)
+
 type PostRouter struct {
 	JWTUtils  *pkg.JWTUtils
 	AuthUtils *pkg.AuthUtils
 
-	PostService *services.PostService
-	TagService  *services.TagService
-	UserService *services.UserService
+	PostService    *services.PostService
+	TagService     *services.TagService
+	UserService    *services.UserService
+	CommentService *services.CommentService
 }
 
 var postRouterOnce sync.Once
@@ -33,9 +38,10 @@ func NewPostRouter() *PostRouter {
 			JWTUtils:  pkg.NewJWTUtils(),
 			AuthUtils: pkg.NewAuthUtils(),
 
-			PostService: services.NewPostService(),
-			TagService:  services.NewTagService(),
-			UserService: services.NewUserService(),
+			PostService:    services.NewPostService(),
+			TagService:     services.NewTagService(),
+			UserService:    services.NewUserService(),
+			CommentService: services.NewCommentService(),
 		}
 	})
 	return postRouter
@@ -52,71 +58,183 @@ func (r *PostRouter) Bind(_router *gin.RouterGroup) {
 	}
 	// GET
 	{
-		router.GET("/list/author/:authorID/offset/:offset/limit/:limit", r.GetPostsByAuthorID)
-		router.GET("/list/search", r.GetPostsByKeyword)
+		router.GET("/list/author/:authorID",
+			middlewares.OptionalAccessToken(middlewares.ParseJWTAccessToken),
+			r.GetPostsByAuthorIDByCursor,
+		)
+		router.GET("/list/author/:authorID/offset/:offset/limit/:limit",
+			middlewares.OptionalAccessToken(middlewares.ParseJWTAccessToken),
+			r.GetPostsByAuthorID,
+		)
+		router.GET("/list/tag/:tagName",
+			middlewares.OptionalAccessToken(middlewares.ParseJWTAccessToken),
+			r.GetPostsByTag,
+		)
+		router.GET("/list/search",
+			middlewares.OptionalAccessToken(middlewares.ParseJWTAccessToken),
+			r.GetPostsByKeyword,
+		)
+		router.GET("/like/:postID/users", r.GetLikedUsers)
+		router.GET("/timeline",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.GetTimeline,
+		)
+		router.GET("/:postID",
+			middlewares.OptionalAccessToken(middlewares.ParseJWTAccessToken),
+			r.GetPostByID,
+		)
 	}
 	//PUT
 	{
-		// router.PUT("/like/:postID",
-		// 	middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
-		// 	r.LikedPostByUser,
-		// )
+		router.PUT("/:postID",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			middlewares.RequirePermission(models.PermissionPostEdit),
+			r.UpdatePost,
+		)
+		router.PUT("/like/:postID",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.LikedPostByUser,
+		)
+	}
+	// DELETE
+	{
+		router.DELETE("/:postID",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			middlewares.RequirePermission(models.PermissionPostDelete),
+			r.DeletePost,
+		)
+		router.DELETE("/like/:postID",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.UnlikedPostByUser,
+		)
 	}
 }
 
 // @Tags Post
-// @Summary Get posts by keyword
+// @Summary Search posts
+// @Description Search posts with a query language, whitespace separated conditions must all match:
+// @Description word (full-text, Chinese is matched by character bigrams), "exact phrase", #tag, author:username, from:YYYY-MM-DD, to:YYYY-MM-DD (UTC, inclusive), has:image.
+// @Description Prefix a word, phrase, tag or has:image with - to exclude it. userID restricts results to posts by that user. Syntax errors return 400 with the position of the error.
+// @Description sort=relevance (default) orders by rank, sort=recent orders by creation time. Results include rank and an HTML escaped snippet with matches wrapped in <mark></mark>.
+// @Description Posts of users with a block between them and the logged-in user are hidden
+// @Description Passing cursor (empty for the first page) switches to cursor pagination (sort=recent only), the response is then models.CursorPaginationResponse and totalCount is only returned with withTotalCount=true
 // @Accept text/plain
 // @Produce application/json
-// @Param keyword query string false "Search keyword"
+// @Param keyword query string false "Search query, e.g. #golang author:alice from:2026-01-01 has:image \"exact phrase\" -draft"
+// @Param sort query string false "Sort (relevance, recent)"
 // @Param offset query string false "Offset"
 // @Param limit query string false "Limit"
-// @Param userID query string false "User ID"
+// @Param cursor query string false "Cursor of the next page"
+// @Param withTotalCount query bool false "Return totalCount in cursor pagination"
+// @Param userID query string false "Author user ID"
 // @Success 200 {object} models.PaginationResponse[models.PostGetPostsByKeywordResponseItem]
+// @Success 200 {object} models.CursorPaginationResponse[models.PostGetPostsByKeywordResponseItem]
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /api/post/list/search [get]
 func (r *PostRouter) GetPostsByKeyword(ctx *gin.Context) {
-	keyword := ctx.Query("keyword")
-	offset, err := strconv.ParseUint(ctx.DefaultQuery("offset", "0"), 10, 64)
+	filter, err := models.ParsePostSearchQuery(ctx.Query("keyword"))
 	if err != nil {
-		ctx.JSON(400, models.ErrorResponse{Error: "invalid offset"})
-		return
-	}
-	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
-	if err != nil || limit == 0 {
-		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		ctx.JSON(400, models.ErrorResponse{Error: err.Error()})
 		return
 	}
-	var userID *uuid.UUID
 	queryUserID := ctx.Query("userID")
 	if queryUserID != "" {
-		parsedUserID, err := uuid.Parse(queryUserID)
+		userID, err := uuid.Parse(queryUserID)
 		if err != nil {
 			ctx.JSON(400, models.ErrorResponse{Error: "invalid user ID"})
 			return
 		}
-		userID = &parsedUserID
 		// 檢查用戶存在
-		if _, err := r.UserService.GetByID(ctx, *userID); err != nil {
+		if _, err := r.UserService.GetByID(ctx, userID); err != nil {
 			ctx.JSON(404, models.ErrorResponse{Error: "user not found"})
 			return
 		}
+		filter.AuthorID = &userID
+	}
+	filter.ViewerID = getViewerID(ctx)
+	var sort *models.PostSearchSort
+	if querySort := ctx.Query("sort"); querySort != "" {
+		parsedSort, ok := models.ParsePostSearchSort(querySort)
+		if !ok {
+			ctx.JSON(400, models.ErrorResponse{Error: "invalid sort"})
+			return
+		}
+		sort = &parsedSort
+	}
+
+	if isCursorPagination(ctx) {
+		// cursor 依 (created_at, id) 分頁，無法依相關度排序
+		if sort != nil && *sort != models.PostSearchSortRecent {
+			ctx.JSON(400, models.ErrorResponse{Error: "cursor pagination only supports sort=recent"})
+			return
+		}
+		pagination, ok := parseCursorPagination(ctx)
+		if !ok {
+			return
+		}
+		results, nextCursor, totalCount, err := r.PostService.SearchByCursor(ctx, filter, pagination)
+		if err != nil {
+			ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		responseData, ok := r.toPostGetPostsByKeywordResponseItems(ctx, results)
+		if !ok {
+			return
+		}
+		ctx.JSON(200, newCursorPaginationResponse(responseData, nextCursor, totalCount))
+		return
 	}
 
+	offset, err := strconv.ParseUint(ctx.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid offset"})
+		return
+	}
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
+	if err != nil || limit == 0 {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		return
+	}
+	if sort == nil {
+		sort = pkg.GetPointer(models.PostSearchSortRelevance)
+	}
 	pagination := &models.Pagination{
 		Offset: uint(offset),
 		Limit:  uint(limit),
 	}
-	posts, totalCount, err := r.PostService.GetListByKeywords(ctx, []string{keyword}, pagination)
+	results, totalCount, err := r.PostService.Search(ctx, filter, *sort, pagination)
 	if err != nil {
 		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
 		return
 	}
+	responseData, ok := r.toPostGetPostsByKeywordResponseItems(ctx, results)
+	if !ok {
+		return
+	}
+	ctx.JSON(200, models.PaginationResponse[models.PostGetPostsByKeywordResponseItem]{
+		Data:       responseData,
+		TotalCount: totalCount,
+		Pagination: pagination,
+	})
+}
+
+func (r *PostRouter) toPostGetPostsByKeywordResponseItems(ctx *gin.Context, results []models.PostSearchResult) ([]models.PostGetPostsByKeywordResponseItem, bool) {
+	posts := make([]models.Post, len(results))
+	for i, result := range results {
+		posts[i] = result.Post
+	}
+	likedPostIDs, err := r.getLikedPostIDs(ctx, posts)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
 
 	// 構建回應
-	responseData := make([]models.PostGetPostsByKeywordResponseItem, len(posts))
-	for i, post := range posts {
+	responseData := make([]models.PostGetPostsByKeywordResponseItem, len(results))
+	for i, result := range results {
+		post := result.Post
 		tags := make([]models.PostGetPostsByKeywordResponseItemTag, len(post.Tags))
 		for j, tag := range post.Tags {
 			tags[j] = models.PostGetPostsByKeywordResponseItemTag{ID: tag.ID, Name: tag.Name}
@@ -127,63 +245,167 @@ func (r *PostRouter) GetPostsByKeyword(ctx *gin.Context) {
 				ID:       post.Author.ID,
 				Username: post.Author.Username,
 			},
-			ImageURL:   post.ImageURL,
-			Content:    post.Content,
-			CreatedAt:  time.Unix(post.CreatedAt, 0).Format(time.RFC3339),
-			UpdatedAt:  time.Unix(post.UpdatedAt, 0).Format(time.RFC3339),
-			Tags:       tags,
-			LikedCount: uint(len(post.Likes)),
+			ImageURL:     post.ImageURL,
+			Content:      post.Content,
+			Mentions:     toMentionEntities(post.Mentions),
+			CreatedAt:    time.Unix(post.CreatedAt, 0).Format(time.RFC3339),
+			UpdatedAt:    time.Unix(post.UpdatedAt, 0).Format(time.RFC3339),
+			Tags:         tags,
+			LikedCount:   post.LikeCount,
+			CommentCount: post.CommentCount,
+			LikedByMe:    likedPostIDs[post.ID],
+			Rank:         result.Rank,
+			Snippet:      result.Snippet,
 		}
 	}
-	ctx.JSON(200, models.PaginationResponse[models.PostGetPostsByKeywordResponseItem]{
+	return responseData, true
+}
+
+// @title Post API
+// @Summary Like a post by user
+// @Description Liking a post more than once has no effect. Users with a block between them cannot like each other's posts
+// @Tags Post
+// @Security AccessToken
+// @Accept text/plain
+// @Produce application/json
+// @Param postID path string true "Post ID"
+// @Success 200 {object} models.PostLikeResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/post/like/{postID} [put]
+func (r *PostRouter) LikedPostByUser(ctx *gin.Context) {
+	r.setPostLiked(ctx, true)
+}
+
+// @title Post API
+// @Summary Unlike a post by user
+// @Description Unliking a post that is not liked has no effect
+// @Tags Post
+// @Security AccessToken
+// @Accept text/plain
+// @Produce application/json
+// @Param postID path string true "Post ID"
+// @Success 200 {object} models.PostLikeResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/post/like/{postID} [delete]
+func (r *PostRouter) UnlikedPostByUser(ctx *gin.Context) {
+	r.setPostLiked(ctx, false)
+}
+
+func (r *PostRouter) setPostLiked(ctx *gin.Context, liked bool) {
+	postID, err := uuid.Parse(ctx.Param("postID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid post ID"})
+		return
+	}
+	// 檢查貼文是否存在
+	if _, err := r.PostService.GetByID(ctx, postID); err != nil {
+		ctx.JSON(404, models.ErrorResponse{Error: "post not found"})
+		return
+	}
+
+	// 從 Token 中取得用戶 ID
+	claims, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: "failed to get user ID from token"})
+		return
+	}
+
+	// 添加或移除 用戶對貼文的喜歡
+	if liked {
+		_, err = r.PostService.LikedByUser(ctx, postID, claims.UserID)
+	} else {
+		_, err = r.PostService.UnlikedByUser(ctx, postID, claims.UserID)
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrPostLikeBlocked) {
+			ctx.JSON(403, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	post, err := r.PostService.GetByID(ctx, postID)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(200, models.PostLikeResponse{
+		PostID:     postID,
+		Liked:      liked,
+		LikedCount: post.LikeCount,
+	})
+}
+
+// @title Post API
+// @Summary Get users who liked a post
+// @Tags Post
+// @Accept text/plain
+// @Produce application/json
+// @Param postID path string true "Post ID"
+// @Param offset query string false "Offset"
+// @Param limit query string false "Limit"
+// @Success 200 {object} models.PaginationResponse[models.PostGetLikedUsersResponseItem]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/post/like/{postID}/users [get]
+func (r *PostRouter) GetLikedUsers(ctx *gin.Context) {
+	postID, err := uuid.Parse(ctx.Param("postID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid post ID"})
+		return
+	}
+	offset, err := strconv.ParseUint(ctx.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid offset"})
+		return
+	}
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
+	if err != nil || limit == 0 {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		return
+	}
+	// 檢查貼文是否存在
+	if _, err := r.PostService.GetByID(ctx, postID); err != nil {
+		ctx.JSON(404, models.ErrorResponse{Error: "post not found"})
+		return
+	}
+
+	pagination := &models.Pagination{
+		Offset: uint(offset),
+		Limit:  uint(limit),
+	}
+	users, totalCount, err := r.PostService.GetLikedUsers(ctx, postID, pagination)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	responseData := make([]models.PostGetLikedUsersResponseItem, len(users))
+	for i, user := range users {
+		responseData[i] = models.PostGetLikedUsersResponseItem{
+			ID:       user.ID,
+			Username: user.Username,
+		}
+	}
+	ctx.JSON(200, models.PaginationResponse[models.PostGetLikedUsersResponseItem]{
 		Data:       responseData,
 		TotalCount: totalCount,
 		Pagination: pagination,
 	})
 }
 
-// // @title Post API
-// // @Summary Like a post by user
-// // @Tags Post
-// // @Security AccessToken
-// // @Accept text/plain
-// // @Produce application/json
-// // @Param postID path string true "Post ID"
-// // @Success 200 {object} models.SuccessResponse
-// // @Failure 400 {object} models.ErrorResponse
-// // @Failure 404 {object} models.ErrorResponse
-// // @Failure 500 {object} models.ErrorResponse
-// // @Router /api/post/like/{postID} [put]
-// func (r *PostRouter) LikedPostByUser(ctx *gin.Context) {
-// 	postID, err := uuid.Parse(ctx.Param("postID"))
-// 	if err != nil {
-// 		ctx.JSON(400, models.ErrorResponse{Error: "invalid post ID"})
-// 		return
-// 	}
-// 	// 檢查貼文是否存在
-// 	if _, err := r.PostService.GetByID(ctx, postID); err != nil {
-// 		ctx.JSON(404, models.ErrorResponse{Error: "post not found"})
-// 		return
-// 	}
-
-// 	// 從 Token 中取得用戶 ID
-// 	claims, err := middlewares.GetContentAccessTokenData(ctx)
-// 	if err != nil {
-// 		ctx.JSON(500, models.ErrorResponse{Error: "failed to get user ID from token"})
-// 		return
-// 	}
-
-// 	// 添加 用戶對貼文的喜歡
-// 	if err := r.PostService.LikedByUser(ctx, postID, claims.UserID); err != nil {
-// 		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
-// 		return
-// 	}
-
-// 	ctx.JSON(200, models.SuccessResponse{Success: true})
-// }
-
 // @title Post API
 // @Summary Get posts by author ID
+// @Description Deprecated: use /api/post/list/author/{authorID} with cursor pagination
+// @Description Posts of users with a block between them and the logged-in user are hidden
 // @Tags Post
 // @Accept text/plain
 // @Produce application/json
@@ -193,7 +415,8 @@ func (r *PostRouter) GetPostsByKeyword(ctx *gin.Context) {
 // @Success 200 {object} models.PaginationResponse[models.PostGetPostsByAuthorIDResponseItem]
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
-// @Router /api/post/author/{authorID}/offset/{offset}/limit/{limit} [get]
+// @Deprecated
+// @Router /api/post/list/author/{authorID}/offset/{offset}/limit/{limit} [get]
 func (r *PostRouter) GetPostsByAuthorID(ctx *gin.Context) {
 	// 解析路由參數
 	authorID, err := uuid.Parse(ctx.Param("authorID"))
@@ -223,12 +446,143 @@ func (r *PostRouter) GetPostsByAuthorID(ctx *gin.Context) {
 		Offset: uint(offset),
 		Limit:  uint(limit),
 	}
-	posts, totalCount, err := r.PostService.GetPostsByAuthorID(ctx, user.ID, pagination)
+	posts, totalCount, err := r.PostService.GetPostsByAuthorID(ctx, user.ID, getViewerID(ctx), pagination)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	responseData, ok := r.toPostGetPostsByAuthorIDResponseItems(ctx, posts)
+	if !ok {
+		return
+	}
+	ctx.JSON(200, models.PaginationResponse[models.PostGetPostsByAuthorIDResponseItem]{
+		Data:       responseData,
+		TotalCount: totalCount,
+		Pagination: pagination,
+	})
+}
+
+// @title Post API
+// @Summary Get posts of a tag with cursor pagination
+// @Description Newest first. Pass nextCursor of the previous page as cursor to get the next page
+// @Description Posts of users with a block between them and the logged-in user are hidden
+// @Tags Post
+// @Accept text/plain
+// @Produce application/json
+// @Param tagName path string true "Tag name (without #)"
+// @Param cursor query string false "Cursor of the next page"
+// @Param limit query string false "Limit (default 10, max 100)"
+// @Param withTotalCount query bool false "Return totalCount"
+// @Success 200 {object} models.CursorPaginationResponse[models.PostGetPostsByTagResponseItem]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/post/list/tag/{tagName} [get]
+func (r *PostRouter) GetPostsByTag(ctx *gin.Context) {
+	pagination, ok := parseCursorPagination(ctx)
+	if !ok {
+		return
+	}
+
+	tag, err := r.TagService.GetByName(ctx, ctx.Param("tagName"))
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if tag == nil {
+		ctx.JSON(404, models.ErrorResponse{Error: "tag not found"})
+		return
+	}
+	posts, nextCursor, totalCount, err := r.PostService.GetPostsByTagIDByCursor(ctx, tag.ID, getViewerID(ctx), pagination)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	likedPostIDs, err := r.getLikedPostIDs(ctx, posts)
 	if err != nil {
 		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
 		return
 	}
 
+	// 構建回應
+	responseData := make([]models.PostGetPostsByTagResponseItem, len(posts))
+	for i, post := range posts {
+		tags := make([]models.PostGetPostsByTagResponseItemTag, len(post.Tags))
+		for j, tag := range post.Tags {
+			tags[j] = models.PostGetPostsByTagResponseItemTag{ID: tag.ID, Name: tag.Name}
+		}
+		responseData[i] = models.PostGetPostsByTagResponseItem{
+			ID: post.ID,
+			Author: models.PostGetPostsByTagResponseItemAuthor{
+				ID:       post.Author.ID,
+				Username: post.Author.Username,
+			},
+			ImageURL:     post.ImageURL,
+			Content:      post.Content,
+			Mentions:     toMentionEntities(post.Mentions),
+			CreatedAt:    time.Unix(post.CreatedAt, 0).Format(time.RFC3339),
+			UpdatedAt:    time.Unix(post.UpdatedAt, 0).Format(time.RFC3339),
+			Tags:         tags,
+			LikedCount:   post.LikeCount,
+			CommentCount: post.CommentCount,
+			LikedByMe:    likedPostIDs[post.ID],
+		}
+	}
+	ctx.JSON(200, newCursorPaginationResponse(responseData, nextCursor, totalCount))
+}
+
+// @title Post API
+// @Summary Get posts by author ID with cursor pagination
+// @Description Newest first. Pass nextCursor of the previous page as cursor to get the next page
+// @Description Posts of users with a block between them and the logged-in user are hidden
+// @Tags Post
+// @Accept text/plain
+// @Produce application/json
+// @Param authorID path string true "Author ID"
+// @Param cursor query string false "Cursor of the next page"
+// @Param limit query string false "Limit (default 10, max 100)"
+// @Param withTotalCount query bool false "Return totalCount"
+// @Success 200 {object} models.CursorPaginationResponse[models.PostGetPostsByAuthorIDResponseItem]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/post/list/author/{authorID} [get]
+func (r *PostRouter) GetPostsByAuthorIDByCursor(ctx *gin.Context) {
+	authorID, err := uuid.Parse(ctx.Param("authorID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid author ID"})
+		return
+	}
+	pagination, ok := parseCursorPagination(ctx)
+	if !ok {
+		return
+	}
+
+	// 檢查用戶是否存在
+	user, err := r.UserService.GetByID(ctx, authorID)
+	if err != nil {
+		ctx.JSON(404, models.ErrorResponse{Error: "author not found"})
+		return
+	}
+	posts, nextCursor, totalCount, err := r.PostService.GetPostsByAuthorIDByCursor(ctx, user.ID, getViewerID(ctx), pagination)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	responseData, ok := r.toPostGetPostsByAuthorIDResponseItems(ctx, posts)
+	if !ok {
+		return
+	}
+	ctx.JSON(200, newCursorPaginationResponse(responseData, nextCursor, totalCount))
+}
+
+func (r *PostRouter) toPostGetPostsByAuthorIDResponseItems(ctx *gin.Context, posts []models.Post) ([]models.PostGetPostsByAuthorIDResponseItem, bool) {
+	likedPostIDs, err := r.getLikedPostIDs(ctx, posts)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+
 	// 構建回應
 	responseData := make([]models.PostGetPostsByAuthorIDResponseItem, len(posts))
 	for i, post := range posts {
@@ -242,30 +596,178 @@ func (r *PostRouter) GetPostsByAuthorID(ctx *gin.Context) {
 				ID:       post.Author.ID,
 				Username: post.Author.Username,
 			},
-			ImageURL:   post.ImageURL,
-			Content:    post.Content,
-			CreatedAt:  time.Unix(post.CreatedAt, 0).Format(time.RFC3339),
-			UpdatedAt:  time.Unix(post.UpdatedAt, 0).Format(time.RFC3339),
-			Tags:       tags,
-			LikedCount: uint(len(post.Likes)),
+			ImageURL:     post.ImageURL,
+			Content:      post.Content,
+			Mentions:     toMentionEntities(post.Mentions),
+			CreatedAt:    time.Unix(post.CreatedAt, 0).Format(time.RFC3339),
+			UpdatedAt:    time.Unix(post.UpdatedAt, 0).Format(time.RFC3339),
+			Tags:         tags,
+			LikedCount:   post.LikeCount,
+			CommentCount: post.CommentCount,
+			LikedByMe:    likedPostIDs[post.ID],
 		}
 	}
-	ctx.JSON(200, models.PaginationResponse[models.PostGetPostsByAuthorIDResponseItem]{
-		Data:       responseData,
-		TotalCount: totalCount,
-		Pagination: pagination,
-	})
+	return responseData, true
+}
+
+// @title Post API
+// @Summary Get home timeline
+// @Description Posts of the user and the users they follow (excluding blocked and muted users), newest first. Pass nextCursor of the previous page as cursor to get the next page
+// @Tags Post
+// @Security AccessToken
+// @Accept text/plain
+// @Produce application/json
+// @Param cursor query string false "Cursor of the next page"
+// @Param limit query string false "Limit (default 10, max 100)"
+// @Param withTotalCount query bool false "Return totalCount"
+// @Success 200 {object} models.CursorPaginationResponse[models.PostGetTimelineResponseItem]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/post/timeline [get]
+func (r *PostRouter) GetTimeline(ctx *gin.Context) {
+	pagination, ok := parseCursorPagination(ctx)
+	if !ok {
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	posts, nextCursor, totalCount, err := r.PostService.GetTimeline(ctx, tokenData.UserID, pagination)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	likedPostIDs, err := r.getLikedPostIDs(ctx, posts)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	responseData := make([]models.PostGetTimelineResponseItem, len(posts))
+	for i, post := range posts {
+		tags := make([]models.PostGetTimelineResponseItemTag, len(post.Tags))
+		for j, tag := range post.Tags {
+			tags[j] = models.PostGetTimelineResponseItemTag{
+				ID:   tag.ID,
+				Name: tag.Name,
+			}
+		}
+		responseData[i] = models.PostGetTimelineResponseItem{
+			ID: post.ID,
+			Author: models.PostGetTimelineResponseItemAuthor{
+				ID:       post.Author.ID,
+				Username: post.Author.Username,
+			},
+			ImageURL:     post.ImageURL,
+			Content:      post.Content,
+			Mentions:     toMentionEntities(post.Mentions),
+			CreatedAt:    time.Unix(post.CreatedAt, 0).Format(time.RFC3339),
+			UpdatedAt:    time.Unix(post.UpdatedAt, 0).Format(time.RFC3339),
+			Tags:         tags,
+			LikedCount:   post.LikeCount,
+			CommentCount: post.CommentCount,
+			LikedByMe:    likedPostIDs[post.ID],
+		}
+	}
+	ctx.JSON(200, newCursorPaginationResponse(responseData, nextCursor, totalCount))
+}
+
+// @title Post API
+// @Summary Get a post by ID
+// @Description includeComments=true embeds the first page of the comment tree (paginated by root comments), the next pages are available from /api/comment/list/post/{postID} with the returned nextCursor
+// @Tags Post
+// @Accept text/plain
+// @Produce application/json
+// @Param postID path string true "Post ID"
+// @Param includeComments query bool false "Embed comment tree"
+// @Param commentLimit query string false "Root comments per page (default 10)"
+// @Success 200 {object} models.PostGetPostByIDResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/post/{postID} [get]
+func (r *PostRouter) GetPostByID(ctx *gin.Context) {
+	postID, err := uuid.Parse(ctx.Param("postID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid post ID"})
+		return
+	}
+	includeComments, err := strconv.ParseBool(ctx.DefaultQuery("includeComments", "false"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid includeComments"})
+		return
+	}
+	commentLimit, err := strconv.ParseUint(ctx.DefaultQuery("commentLimit", "10"), 10, 64)
+	if err != nil || commentLimit == 0 || commentLimit > CURSOR_PAGINATION_MAX_LIMIT {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid commentLimit"})
+		return
+	}
+
+	post, err := r.PostService.GetByID(ctx, postID)
+	if err != nil {
+		ctx.JSON(404, models.ErrorResponse{Error: "post not found"})
+		return
+	}
+	likedPostIDs, err := r.getLikedPostIDs(ctx, []models.Post{*post})
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	tags := make([]models.PostGetPostByIDResponseTag, len(post.Tags))
+	for i, tag := range post.Tags {
+		tags[i] = models.PostGetPostByIDResponseTag{
+			ID:   tag.ID,
+			Name: tag.Name,
+		}
+	}
+	respBody := models.PostGetPostByIDResponse{
+		ID: post.ID,
+		Author: models.PostGetPostByIDResponseAuthor{
+			ID:       post.Author.ID,
+			Username: post.Author.Username,
+		},
+		ImageURL:     post.ImageURL,
+		Content:      post.Content,
+		Mentions:     toMentionEntities(post.Mentions),
+		CreatedAt:    time.Unix(post.CreatedAt, 0).Format(time.RFC3339),
+		UpdatedAt:    time.Unix(post.UpdatedAt, 0).Format(time.RFC3339),
+		Tags:         tags,
+		LikedCount:   post.LikeCount,
+		CommentCount: post.CommentCount,
+		LikedByMe:    likedPostIDs[post.ID],
+	}
+
+	// 嵌入第一頁評論樹
+	if includeComments {
+		pagination := &models.CursorPagination{Limit: uint(commentLimit), WithTotalCount: true}
+		roots, nextCursor, totalCount, err := r.CommentService.GetTreePageByPostID(ctx, post.ID, getViewerID(ctx), pagination)
+		if err != nil {
+			ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		comments := newCursorPaginationResponse(toCommentTreeResponseItems(roots), nextCursor, totalCount)
+		respBody.Comments = &comments
+	}
+	ctx.JSON(200, respBody)
 }
 
 // @title Post API
 // @Summary Create a post
+// @Description The content policy may mask parts of the content, hold the post for review (pendingReview) or reject it
 // @Tags Post
 // @Security AccessToken
 // @Accept application/json
 // @Produce application/json
 // @Param post body models.PostCreateRequest true "Post create request"
 // @Success 200 {object} models.PostCreateResponse
-// @Failure 400 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid request body or content rejected by the content policy"
 // @Router /api/post [post]
 func (r *PostRouter) CreatePost(ctx *gin.Context) {
 	// 解析請求體
@@ -287,22 +789,18 @@ func (r *PostRouter) CreatePost(ctx *gin.Context) {
 		return
 	}
 
-	// 解析標籤
-	tagBases := make([]models.TagBase, 0)
-	tagRegex := regexp.MustCompile(`#([^\s#]+)`)
-	matchedTags := tagRegex.FindAllStringSubmatch(reqBody.Content, -1)
-	for _, match := range matchedTags {
-		tagName := match[1]
-		tagBases = append(tagBases, models.TagBase{Name: strings.Trim(tagName, " ")})
-	}
 	// 創建 Post
 	postBase := models.PostBase{
 		AuthorID: tokenData.UserID,
 		ImageURL: reqBody.ImageURL,
 		Content:  reqBody.Content,
 	}
-	post, err := r.PostService.CreatePostWithTags(ctx, postBase, tagBases)
+	post, err := r.PostService.CreatePostWithTags(ctx, postBase, parseTagBases(reqBody.Content))
 	if err != nil {
+		if errors.Is(err, services.ErrContentRejected) {
+			ctx.JSON(400, models.ErrorResponse{Error: err.Error()})
+			return
+		}
 		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -317,9 +815,143 @@ func (r *PostRouter) CreatePost(ctx *gin.Context) {
 		AuthorID:  post.AuthorID,
 		ImageURL:  post.ImageURL,
 		Content:   post.Content,
+		Mentions:  toMentionEntities(post.Mentions),
 		TagIDs:    tagIDs,
 		CreatedAt: time.Unix(post.CreatedAt, 0).Format(time.RFC3339),
 		UpdatedAt: time.Unix(post.UpdatedAt, 0).Format(time.RFC3339),
+
+		PendingReview: post.HiddenAt != nil,
 	}
 	ctx.JSON(200, respBody)
 }
+
+// @title Post API
+// @Summary Update a post
+// @Description Only the author or an admin can update the post, tags are re-parsed from the content, the content policy may mask parts of the content, hold the post for review (pendingReview) or reject it
+// @Tags Post
+// @Security AccessToken
+// @Accept application/json
+// @Produce application/json
+// @Param postID path string true "Post ID"
+// @Param post body models.PostUpdateRequest true "Post update request"
+// @Success 200 {object} models.PostUpdateResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid request body or content rejected by the content policy"
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/post/{postID} [put]
+func (r *PostRouter) UpdatePost(ctx *gin.Context) {
+	// 解析請求體
+	reqBody := &models.PostUpdateRequest{}
+	if err := ctx.ShouldBindJSON(reqBody); err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	if reqBody.Content == "" || len([]rune(reqBody.Content)) > 500 {
+		ctx.JSON(400, models.ErrorResponse{Error: "content characters must be between 0 and 500"})
+		return
+	}
+	post, ok := r.getOwnedPost(ctx, models.PermissionPostEdit)
+	if !ok {
+		return
+	}
+
+	// 更新 Post 並重新同步標籤
+	post, err := r.PostService.UpdatePostWithTags(ctx, post.ID, reqBody.ImageURL, reqBody.Content, parseTagBases(reqBody.Content))
+	if err != nil {
+		if errors.Is(err, services.ErrContentRejected) {
+			ctx.JSON(400, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	tagIDs := make([]uuid.UUID, len(post.Tags))
+	for i, tag := range post.Tags {
+		tagIDs[i] = tag.ID
+	}
+	ctx.JSON(200, models.PostUpdateResponse{
+		ID:        post.ID,
+		AuthorID:  post.AuthorID,
+		ImageURL:  post.ImageURL,
+		Content:   post.Content,
+		Mentions:  toMentionEntities(post.Mentions),
+		TagIDs:    tagIDs,
+		CreatedAt: time.Unix(post.CreatedAt, 0).Format(time.RFC3339),
+		UpdatedAt: time.Unix(post.UpdatedAt, 0).Format(time.RFC3339),
+
+		PendingReview: post.HiddenAt != nil,
+	})
+}
+
+// @title Post API
+// @Summary Delete a post
+// @Description Only the author or an admin can delete the post, comments, likes and tag links are removed together
+// @Tags Post
+// @Security AccessToken
+// @Produce application/json
+// @Param postID path string true "Post ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/post/{postID} [delete]
+func (r *PostRouter) DeletePost(ctx *gin.Context) {
+	post, ok := r.getOwnedPost(ctx, models.PermissionPostDelete)
+	if !ok {
+		return
+	}
+
+	if err := r.PostService.DeleteWithContent(ctx, post.ID); err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.SuccessResponse{Success: true})
+}
+
+// getOwnedPost 解析路由中的 postID，並檢查使用者對貼文具備 permission
+func (r *PostRouter) getOwnedPost(ctx *gin.Context, permission models.Permission) (*models.Post, bool) {
+	postID, err := uuid.Parse(ctx.Param("postID"))
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid post ID"})
+		return nil, false
+	}
+	post, err := r.PostService.GetByID(ctx, postID)
+	if err != nil {
+		ctx.JSON(404, models.ErrorResponse{Error: "post not found"})
+		return nil, false
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+	if !tokenData.Can(permission, post.AuthorID) {
+		ctx.JSON(403, models.ErrorResponse{Error: "permission denied"})
+		return nil, false
+	}
+	return post, true
+}
+
+// parseTagBases 解析內容中的 #標籤，重複的標籤只保留一個
+func parseTagBases(content string) []models.TagBase {
+	tagBases := make([]models.TagBase, 0)
+	existed := make(map[string]bool)
+	for _, match := range POST_TAG_REGEX.FindAllStringSubmatch(content, -1) {
+		tagName := strings.Trim(match[1], " ")
+		if existed[tagName] {
+			continue
+		}
+		existed[tagName] = true
+		tagBases = append(tagBases, models.TagBase{Name: tagName})
+	}
+	return tagBases
+}
+
+// getLikedPostIDs 登入時回傳使用者喜歡的貼文，未登入時為空集合
+func (r *PostRouter) getLikedPostIDs(ctx *gin.Context, posts []models.Post) (map[uuid.UUID]bool, error) {
+	return r.PostService.GetLikedPostIDs(ctx, getViewerID(ctx), posts)
+}
@@ -7,6 +7,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 
 	"github.com/google/uuid"
@@ -16,11 +18,13 @@ import (
 func TestPostRouter(t *testing.T) {
 	httpUtils := pkg.NewHTTPUtils()
 
-	server, apiRouter, _, _, cleanup := tests.SetupTestServer("test_post_router.db")
+	server, apiRouter, _, db, cleanup := tests.SetupTestServer("test_post_router.db")
 	defer cleanup()
 
 	NewUserRouter().Bind(apiRouter)
 	NewPostRouter().Bind(apiRouter)
+	NewCommentRouter().Bind(apiRouter)
+	NewFollowRouter().Bind(apiRouter)
 
 	// 1. 創建一個新用戶
 	userData, loginData, err := tests.SetupTestUser(server)
@@ -161,6 +165,416 @@ func TestPostRouter(t *testing.T) {
 			assert.NotEmpty(t, respCreatePostBody.UpdatedAt, "Post UpdatedAt should not be empty")
 		})
 
+		t.Run("成功創建 Post - 包含提及", func(t *testing.T) {
+			mentionedData, _, err := tests.SetupTestUser(server)
+			assert.NoError(t, err)
+
+			reqCreatePostBody := &models.PostCreateRequest{
+				Content: "哈囉 @" + mentionedData.Username + " 與 @not_a_user",
+			}
+			bufReqCreatePostBody, _ := httpUtils.ToJSONBuffer(reqCreatePostBody)
+			reqCreatePost, _ := http.NewRequest("POST", "/api/post", bufReqCreatePostBody)
+			reqCreatePost.Header.Set("Content-Type", "application/json")
+			reqCreatePost.Header.Set("Authorization", loginData.AccessToken)
+			recorderCreatePost := httptest.NewRecorder()
+			server.ServeHTTP(recorderCreatePost, reqCreatePost)
+			assert.Equal(t, 200, recorderCreatePost.Code, "應該回傳 200 表示創建 Post 成功")
+			respCreatePostBody := &models.PostCreateResponse{}
+			assert.NoError(t, json.Unmarshal(recorderCreatePost.Body.Bytes(), respCreatePostBody))
+			expected := []models.MentionEntity{{
+				UserID:   mentionedData.ID,
+				Username: mentionedData.Username,
+				Start:    3,
+				End:      4 + len([]rune(mentionedData.Username)),
+			}}
+			assert.Equal(t, expected, respCreatePostBody.Mentions, "不存在的使用者不是提及")
+
+			reqGetPost, _ := http.NewRequest("GET", "/api/post/"+respCreatePostBody.ID.String(), nil)
+			recorderGetPost := httptest.NewRecorder()
+			server.ServeHTTP(recorderGetPost, reqGetPost)
+			respGetPostBody := &models.PostGetPostByIDResponse{}
+			assert.NoError(t, json.Unmarshal(recorderGetPost.Body.Bytes(), respGetPostBody))
+			assert.Equal(t, expected, respGetPostBody.Mentions)
+
+			count := int64(0)
+			assert.NoError(t, db.Model(&models.Notification{}).
+				Where("user_id = ? AND post_id = ?", mentionedData.ID, respCreatePostBody.ID).
+				Count(&count).Error)
+			assert.Equal(t, int64(1), count, "被提及的使用者應收到通知")
+		})
+
+	})
+
+	t.Run("喜歡 Post", func(t *testing.T) {
+		postData, err := tests.SetupTestPost(server, loginData.AccessToken)
+		assert.NoError(t, err)
+		likeRequest := func(method string, path string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest(method, path, nil)
+			req.Header.Set("Authorization", loginData.AccessToken)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		t.Run("失敗 - 缺少 Authorization", func(t *testing.T) {
+			req, _ := http.NewRequest("PUT", "/api/post/like/"+postData.ID.String(), nil)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			assert.Equal(t, 401, recorder.Code, "應該回傳 401 表示未授權")
+		})
+
+		t.Run("失敗 - Post 不存在", func(t *testing.T) {
+			recorder := likeRequest("PUT", "/api/post/like/"+uuid.New().String())
+			assert.Equal(t, 404, recorder.Code, "應該回傳 404 表示 Post 不存在")
+		})
+
+		t.Run("重複喜歡不重複計數", func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				recorder := likeRequest("PUT", "/api/post/like/"+postData.ID.String())
+				assert.Equal(t, 200, recorder.Code, "應該回傳 200 表示喜歡成功")
+				respBody := &models.PostLikeResponse{}
+				err := json.Unmarshal(recorder.Body.Bytes(), respBody)
+				assert.NoError(t, err, "Response should be valid JSON")
+				assert.True(t, respBody.Liked)
+				assert.Equal(t, uint(1), respBody.LikedCount, "重複喜歡不應增加計數")
+			}
+		})
+
+		t.Run("喜歡的使用者列表與 likedByMe", func(t *testing.T) {
+			recorder := likeRequest("GET", "/api/post/like/"+postData.ID.String()+"/users?offset=0&limit=10")
+			assert.Equal(t, 200, recorder.Code)
+			respUsers := &models.PaginationResponse[models.PostGetLikedUsersResponseItem]{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respUsers))
+			assert.Equal(t, uint(1), respUsers.TotalCount)
+			if assert.Len(t, respUsers.Data, 1) {
+				assert.Equal(t, userData.Username, respUsers.Data[0].Username)
+			}
+
+			recorder = likeRequest("GET", "/api/post/list/author/"+loginData.ID.String()+"/offset/0/limit/10")
+			assert.Equal(t, 200, recorder.Code)
+			respPosts := &models.PaginationResponse[models.PostGetPostsByAuthorIDResponseItem]{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respPosts))
+			for _, post := range respPosts.Data {
+				assert.Equal(t, post.ID == postData.ID, post.LikedByMe, "只有喜歡的 Post 應標記 likedByMe")
+			}
+
+			// 未登入時 likedByMe 為 false
+			req, _ := http.NewRequest("GET", "/api/post/list/author/"+loginData.ID.String()+"/offset/0/limit/10", nil)
+			recorder = httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			assert.Equal(t, 200, recorder.Code)
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respPosts))
+			for _, post := range respPosts.Data {
+				assert.False(t, post.LikedByMe)
+			}
+		})
+
+		t.Run("取消喜歡", func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				recorder := likeRequest("DELETE", "/api/post/like/"+postData.ID.String())
+				assert.Equal(t, 200, recorder.Code, "應該回傳 200 表示取消喜歡成功")
+				respBody := &models.PostLikeResponse{}
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+				assert.False(t, respBody.Liked)
+				assert.Equal(t, uint(0), respBody.LikedCount)
+			}
+		})
+	})
+
+	t.Run("編輯與刪除 Post", func(t *testing.T) {
+		_, otherLoginData, err := tests.SetupTestUser(server)
+		assert.NoError(t, err)
+		_, adminLoginData, err := tests.SetupTestAdminUser(server, db)
+		assert.NoError(t, err)
+		countTags := func(name string) int64 {
+			count := int64(0)
+			db.Model(&models.Tag{}).Where("name = ?", name).Count(&count)
+			return count
+		}
+
+		recorder := tests.SendTestRequest(server, "POST", "/api/post", loginData.AccessToken, models.PostCreateRequest{Content: "編輯前 #編輯前標籤 #共用"})
+		assert.Equal(t, 200, recorder.Code)
+		postData := &models.PostCreateResponse{}
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), postData))
+
+		t.Run("編輯失敗 - 非作者", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/post/"+postData.ID.String(), otherLoginData.AccessToken, models.PostUpdateRequest{Content: "hack"})
+			assert.Equal(t, 403, recorder.Code, "應該回傳 403 表示沒有權限")
+		})
+
+		t.Run("編輯失敗 - Post 不存在", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/post/"+uuid.New().String(), loginData.AccessToken, models.PostUpdateRequest{Content: "content"})
+			assert.Equal(t, 404, recorder.Code, "應該回傳 404 表示 Post 不存在")
+		})
+
+		t.Run("成功編輯 - 重新同步標籤", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/post/"+postData.ID.String(), loginData.AccessToken, models.PostUpdateRequest{Content: "編輯後 #編輯後標籤 #共用 #共用"})
+			assert.Equal(t, 200, recorder.Code, "應該回傳 200 表示編輯成功")
+			respBody := &models.PostUpdateResponse{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			assert.Equal(t, "編輯後 #編輯後標籤 #共用 #共用", respBody.Content)
+			assert.Len(t, respBody.TagIDs, 2, "重複的標籤只保留一個")
+			assert.Equal(t, int64(0), countTags("編輯前標籤"), "不再使用的標籤應該被刪除")
+			assert.Equal(t, int64(1), countTags("編輯後標籤"))
+		})
+
+		t.Run("管理員可編輯", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/post/"+postData.ID.String(), adminLoginData.AccessToken, models.PostUpdateRequest{Content: "管理員編輯 #共用"})
+			assert.Equal(t, 200, recorder.Code, "管理員應該可以編輯任何 Post")
+		})
+
+		t.Run("刪除失敗 - 非作者", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "DELETE", "/api/post/"+postData.ID.String(), otherLoginData.AccessToken, nil)
+			assert.Equal(t, 403, recorder.Code, "應該回傳 403 表示沒有權限")
+		})
+
+		t.Run("成功刪除 - 移除評論、喜歡與標籤", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "POST", "/api/comment", otherLoginData.AccessToken, models.CommentCreateRequest{PostID: postData.ID, Content: "comment"})
+			assert.Equal(t, 200, recorder.Code)
+			recorder = tests.SendTestRequest(server, "PUT", "/api/post/like/"+postData.ID.String(), otherLoginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code)
+
+			recorder = tests.SendTestRequest(server, "DELETE", "/api/post/"+postData.ID.String(), loginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code, "應該回傳 200 表示刪除成功")
+
+			count := int64(0)
+			db.Model(&models.Comment{}).Where("post_id = ?", postData.ID).Count(&count)
+			assert.Equal(t, int64(0), count, "評論應該已刪除")
+			db.Table("post_to_user").Where("post_id = ?", postData.ID).Count(&count)
+			assert.Equal(t, int64(0), count, "喜歡應該已刪除")
+			assert.Equal(t, int64(0), countTags("共用"), "不再使用的標籤應該被刪除")
+
+			recorder = tests.SendTestRequest(server, "DELETE", "/api/post/"+postData.ID.String(), loginData.AccessToken, nil)
+			assert.Equal(t, 404, recorder.Code, "應該回傳 404 表示 Post 不存在")
+		})
+	})
+
+	t.Run("獲取單一 Post", func(t *testing.T) {
+		postData, err := tests.SetupTestPost(server, loginData.AccessToken)
+		assert.NoError(t, err)
+
+		t.Run("失敗 - Post 不存在", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "GET", "/api/post/"+uuid.New().String(), "", nil)
+			assert.Equal(t, 404, recorder.Code, "應該回傳 404 表示 Post 不存在")
+			recorder = tests.SendTestRequest(server, "GET", "/api/post/invalid", "", nil)
+			assert.Equal(t, 400, recorder.Code, "應該回傳 400 表示 ID 格式錯誤")
+		})
+
+		t.Run("成功獲取 Post 與評論", func(t *testing.T) {
+			req, _ := http.NewRequest("PUT", "/api/post/like/"+postData.ID.String(), nil)
+			req.Header.Set("Authorization", loginData.AccessToken)
+			server.ServeHTTP(httptest.NewRecorder(), req)
+			for _, content := range []string{"comment 1", "comment 2"} {
+				buf, _ := httpUtils.ToJSONBuffer(models.CommentCreateRequest{PostID: postData.ID, Content: content})
+				req, _ := http.NewRequest("POST", "/api/comment", buf)
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", loginData.AccessToken)
+				recorder := httptest.NewRecorder()
+				server.ServeHTTP(recorder, req)
+				assert.Equal(t, 200, recorder.Code)
+			}
+
+			recorder := tests.SendTestRequest(server, "GET", "/api/post/"+postData.ID.String(), loginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code, "應該回傳 200 表示獲取 Post 成功")
+			respBody := &models.PostGetPostByIDResponse{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			assert.Equal(t, postData.ID, respBody.ID)
+			assert.Equal(t, userData.Username, respBody.Author.Username)
+			assert.Len(t, respBody.Tags, len(postData.TagIDs))
+			assert.Equal(t, uint(1), respBody.LikedCount)
+			assert.Equal(t, uint(2), respBody.CommentCount)
+			assert.True(t, respBody.LikedByMe)
+			assert.Nil(t, respBody.Comments, "預設不包含評論")
+
+			recorder = tests.SendTestRequest(server, "GET", "/api/post/"+postData.ID.String()+"?includeComments=true&commentLimit=1", "", nil)
+			assert.Equal(t, 200, recorder.Code)
+			respBody = &models.PostGetPostByIDResponse{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			assert.False(t, respBody.LikedByMe, "未登入時 likedByMe 為 false")
+			if assert.NotNil(t, respBody.Comments) {
+				assert.Len(t, respBody.Comments.Data, 1, "只包含第一頁評論")
+				if assert.NotNil(t, respBody.Comments.TotalCount) {
+					assert.Equal(t, uint(2), *respBody.Comments.TotalCount)
+				}
+				assert.NotNil(t, respBody.Comments.NextCursor, "應該有下一頁評論")
+			}
+		})
+	})
+
+	t.Run("時間軸", func(t *testing.T) {
+		followeeData, followeeLoginData, err := tests.SetupTestUser(server)
+		assert.NoError(t, err)
+		_, strangerLoginData, err := tests.SetupTestUser(server)
+		assert.NoError(t, err)
+		_, readerLoginData, err := tests.SetupTestUser(server)
+		assert.NoError(t, err)
+		getTimeline := func(query string) *models.CursorPaginationResponse[models.PostGetTimelineResponseItem] {
+			recorder := tests.SendTestRequest(server, "GET", "/api/post/timeline"+query, readerLoginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code)
+			respBody := &models.CursorPaginationResponse[models.PostGetTimelineResponseItem]{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			return respBody
+		}
+
+		recorder := tests.SendTestRequest(server, "PUT", "/api/follow/"+followeeData.ID.String(), readerLoginData.AccessToken, nil)
+		assert.Equal(t, 200, recorder.Code)
+		expectedPostIDs := map[uuid.UUID]bool{}
+		for _, accessToken := range []string{followeeLoginData.AccessToken, followeeLoginData.AccessToken, readerLoginData.AccessToken} {
+			postData, err := tests.SetupTestPost(server, accessToken)
+			assert.NoError(t, err)
+			expectedPostIDs[postData.ID] = true
+		}
+		strangerPostData, err := tests.SetupTestPost(server, strangerLoginData.AccessToken)
+		assert.NoError(t, err)
+
+		t.Run("失敗 - 缺少 Authorization", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "GET", "/api/post/timeline", "", nil)
+			assert.Equal(t, 401, recorder.Code)
+		})
+
+		t.Run("失敗 - 無效的 cursor", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "GET", "/api/post/timeline?cursor=invalid", readerLoginData.AccessToken, nil)
+			assert.Equal(t, 400, recorder.Code)
+		})
+
+		t.Run("成功獲取 - keyset 分頁", func(t *testing.T) {
+			firstPage := getTimeline("?limit=2")
+			assert.Len(t, firstPage.Data, 2)
+			if !assert.NotNil(t, firstPage.NextCursor) {
+				return
+			}
+
+			// 第一頁之後新增的貼文不影響下一頁
+			newPostData, err := tests.SetupTestPost(server, followeeLoginData.AccessToken)
+			assert.NoError(t, err)
+
+			secondPage := getTimeline("?limit=2&cursor=" + *firstPage.NextCursor)
+			assert.Len(t, secondPage.Data, 1)
+			assert.Nil(t, secondPage.NextCursor, "沒有下一頁")
+
+			seen := map[uuid.UUID]bool{}
+			for _, post := range append(firstPage.Data, secondPage.Data...) {
+				assert.False(t, seen[post.ID], "分頁之間不應重複")
+				seen[post.ID] = true
+				assert.NotEqual(t, strangerPostData.ID, post.ID, "不應包含未追蹤使用者的貼文")
+				assert.NotEqual(t, newPostData.ID, post.ID, "不應包含第一頁之後新增的貼文")
+			}
+			assert.Equal(t, expectedPostIDs, seen, "應包含追蹤對象與自己的貼文")
+
+			latest := getTimeline("?limit=10")
+			if assert.NotEmpty(t, latest.Data) {
+				assert.Equal(t, newPostData.ID, latest.Data[0].ID, "重新整理後最新貼文在最前面")
+			}
+			assert.Nil(t, latest.TotalCount, "預設不回傳總筆數")
+
+			withTotalCount := getTimeline("?limit=1&withTotalCount=true")
+			if assert.NotNil(t, withTotalCount.TotalCount) {
+				assert.Equal(t, uint(len(expectedPostIDs)+1), *withTotalCount.TotalCount)
+			}
+		})
+	})
+
+	t.Run("搜尋 Post", func(t *testing.T) {
+		recorder := tests.SendTestRequest(server, "POST", "/api/post", loginData.AccessToken, models.PostCreateRequest{Content: "<b>全文檢索</b> 好用 #搜尋引擎"})
+		assert.Equal(t, 200, recorder.Code)
+		postData := &models.PostCreateResponse{}
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), postData))
+
+		t.Run("失敗 - 無效的排序", func(t *testing.T) {
+			assert.Equal(t, 400, tests.SendTestRequest(server, "GET", "/api/post/list/search?keyword=全文&sort=unknown", loginData.AccessToken, nil).Code)
+			assert.Equal(t, 400, tests.SendTestRequest(server, "GET", "/api/post/list/search?keyword=全文&cursor=&sort=relevance", loginData.AccessToken, nil).Code, "cursor 分頁只支援 sort=recent")
+		})
+
+		t.Run("失敗 - 搜尋語法錯誤", func(t *testing.T) {
+			for query, message := range map[string]string{
+				`全文 "未結束`:                       "invalid search query at position 4: unterminated quote",
+				"from:2026-13-01":               `invalid search query at position 1: invalid date "2026-13-01" for from:, expected YYYY-MM-DD`,
+				"has:video":                     `invalid search query at position 1: unknown has: value "video", expected image`,
+				"全文 -author:alice":              "invalid search query at position 4: author: cannot be excluded",
+				"from:2026-02-01 to:2026-01-01": "invalid search query: from: must not be after to:",
+			} {
+				recorder := tests.SendTestRequest(server, "GET", "/api/post/list/search?keyword="+url.QueryEscape(query), loginData.AccessToken, nil)
+				assert.Equal(t, 400, recorder.Code, query)
+				assert.JSONEq(t, `{"error":`+strconv.Quote(message)+`}`, recorder.Body.String(), query)
+			}
+		})
+
+		t.Run("成功搜尋 - 搜尋語法與作者", func(t *testing.T) {
+			query := url.QueryEscape(`"全文檢索" #搜尋引擎 -廣告 from:2000-01-01`)
+			recorder := tests.SendTestRequest(server, "GET", "/api/post/list/search?keyword="+query+"&userID="+postData.AuthorID.String(), loginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code)
+			respBody := &models.PaginationResponse[models.PostGetPostsByKeywordResponseItem]{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			if assert.Len(t, respBody.Data, 1) {
+				assert.Equal(t, postData.ID, respBody.Data[0].ID)
+			}
+
+			recorder = tests.SendTestRequest(server, "GET", "/api/post/list/search?keyword="+query+"&userID="+uuid.NewString(), loginData.AccessToken, nil)
+			assert.Equal(t, 404, recorder.Code, "作者不存在")
+		})
+
+		t.Run("成功搜尋 - 包含摘要", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "GET", "/api/post/list/search?keyword=全文檢索", loginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code)
+			respBody := &models.PaginationResponse[models.PostGetPostsByKeywordResponseItem]{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			if assert.Len(t, respBody.Data, 1) {
+				assert.Equal(t, postData.ID, respBody.Data[0].ID)
+				assert.Equal(t, "&lt;b&gt;<mark>全文檢索</mark>&lt;/b&gt; 好用 #搜尋引擎", respBody.Data[0].Snippet)
+			}
+			assert.Equal(t, uint(1), respBody.TotalCount)
+
+			recorder = tests.SendTestRequest(server, "GET", "/api/post/list/search?keyword=搜尋引擎&cursor=", loginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code)
+			cursorRespBody := &models.CursorPaginationResponse[models.PostGetPostsByKeywordResponseItem]{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), cursorRespBody))
+			if assert.Len(t, cursorRespBody.Data, 1) {
+				assert.Equal(t, postData.ID, cursorRespBody.Data[0].ID, "標籤也可被搜尋")
+			}
+			assert.Nil(t, cursorRespBody.NextCursor)
+		})
+	})
+
+	t.Run("依作者 cursor 分頁", func(t *testing.T) {
+		_, loginData, err := tests.SetupTestUser(server)
+		assert.NoError(t, err)
+		postIDs := []uuid.UUID{}
+		for range 3 {
+			postData, err := tests.SetupTestPost(server, loginData.AccessToken)
+			assert.NoError(t, err)
+			postIDs = append(postIDs, postData.ID)
+		}
+
+		t.Run("失敗 - 無效的分頁參數", func(t *testing.T) {
+			for _, query := range []string{"?limit=0", "?limit=101", "?cursor=invalid", "?withTotalCount=maybe"} {
+				assert.Equal(t, 400, tests.SendTestRequest(server, "GET", "/api/post/list/author/"+loginData.ID.String()+query, "", nil).Code, query)
+			}
+		})
+
+		t.Run("成功獲取", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "GET", "/api/post/list/author/"+loginData.ID.String()+"?limit=2&withTotalCount=true", "", nil)
+			assert.Equal(t, 200, recorder.Code)
+			firstPage := &models.CursorPaginationResponse[models.PostGetPostsByAuthorIDResponseItem]{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), firstPage))
+			if !assert.Len(t, firstPage.Data, 2) || !assert.NotNil(t, firstPage.NextCursor) {
+				return
+			}
+			assert.Equal(t, postIDs[2], firstPage.Data[0].ID, "由新到舊排序")
+			assert.Equal(t, postIDs[1], firstPage.Data[1].ID)
+			if assert.NotNil(t, firstPage.TotalCount) {
+				assert.Equal(t, uint(3), *firstPage.TotalCount)
+			}
+
+			recorder = tests.SendTestRequest(server, "GET", "/api/post/list/author/"+loginData.ID.String()+"?limit=2&cursor="+*firstPage.NextCursor, "", nil)
+			assert.Equal(t, 200, recorder.Code)
+			secondPage := &models.CursorPaginationResponse[models.PostGetPostsByAuthorIDResponseItem]{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), secondPage))
+			if assert.Len(t, secondPage.Data, 1) {
+				assert.Equal(t, postIDs[0], secondPage.Data[0].ID)
+			}
+			assert.Nil(t, secondPage.NextCursor, "沒有下一頁")
+			assert.Nil(t, secondPage.TotalCount, "未要求時不回傳總筆數")
+		})
 	})
 
 }
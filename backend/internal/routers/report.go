@@ -0,0 +1,112 @@
+package routers
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/services"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportRouter struct {
+	ReportService *services.ReportService
+}
+
+var reportRouterOnce sync.Once
+var reportRouter *ReportRouter
+
+func NewReportRouter() *ReportRouter {
+	reportRouterOnce.Do(func() {
+		reportRouter = &ReportRouter{
+			ReportService: services.NewReportService(),
+		}
+	})
+	return reportRouter
+}
+
+func (r *ReportRouter) Bind(_router *gin.RouterGroup) {
+	router := _router.Group("/report")
+	// POST
+	{
+		router.POST("",
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.CreateReport,
+		)
+	}
+}
+
+// @title Report API
+// @Summary Report a post or comment
+// @Description targetType is post or comment, reason is one of spam, harassment, hate_speech, violence, sexual_content, misinformation, other.
+// @Description Reporting the same content more than once returns the existing report with 200
+// @Tags Report
+// @Security AccessToken
+// @Accept application/json
+// @Produce application/json
+// @Param request body models.ReportCreateRequest true "Report data"
+// @Success 201 {object} models.ReportResponse
+// @Success 200 {object} models.ReportResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/report [post]
+func (r *ReportRouter) CreateReport(ctx *gin.Context) {
+	reqBody := &models.ReportCreateRequest{}
+	if err := ctx.ShouldBindJSON(reqBody); err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	targetType, ok := models.ParseReportTargetType(reqBody.TargetType)
+	if !ok {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid target type"})
+		return
+	}
+	reason, ok := models.ParseReportReason(reqBody.Reason)
+	if !ok {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid reason"})
+		return
+	}
+	detail := strings.TrimSpace(reqBody.Detail)
+	if len([]rune(detail)) > models.REPORT_DETAIL_MAX_LENGTH {
+		ctx.JSON(400, models.ErrorResponse{Error: "detail is too long"})
+		return
+	}
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	report, created, err := r.ReportService.Create(ctx, tokenData.UserID, targetType, reqBody.TargetID, reason, detail)
+	if err != nil {
+		if errors.Is(err, services.ErrReportTargetNotFound) {
+			ctx.JSON(404, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if r.ReportService.ErrorUtils.IsServerInternalError(err.Error()) {
+			ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(400, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	code := 200
+	if created {
+		code = 201
+	}
+	ctx.JSON(code, models.ReportResponse{
+		ID:         report.ID,
+		TargetType: string(report.TargetType),
+		TargetID:   report.TargetID,
+		Reason:     string(report.Reason),
+		Detail:     report.Detail,
+		Status:     string(report.Status),
+		CreatedAt:  time.Unix(report.CreatedAt, 0).Format(time.RFC3339),
+	})
+}
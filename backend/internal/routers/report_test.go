@@ -0,0 +1,473 @@
+package routers
+
+import (
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/services"
+	"backend/internal/tests"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportRouter(t *testing.T) {
+	server, apiRouter, _, db, cleanup := tests.SetupTestServer("test_report_router.db")
+	defer cleanup()
+
+	NewUserRouter().Bind(apiRouter)
+	NewPostRouter().Bind(apiRouter)
+	NewCommentRouter().Bind(apiRouter)
+	NewAdminRouter().Bind(apiRouter)
+	NewReportRouter().Bind(apiRouter)
+
+	adminData, adminLoginData, err := tests.SetupTestAdminUser(server, db)
+	require.NoError(t, err)
+	authorData, authorLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+	_, aliceLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+	_, bobLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+
+	report := func(accessToken string, targetType models.ReportTargetType, targetID uuid.UUID) (int, *models.ReportResponse) {
+		recorder := tests.SendTestRequest(server, "POST", "/api/report", accessToken, &models.ReportCreateRequest{
+			TargetType: string(targetType),
+			TargetID:   targetID,
+			Reason:     string(models.ReportReasonSpam),
+			Detail:     "廣告",
+		})
+		respBody := &models.ReportResponse{}
+		_ = json.Unmarshal(recorder.Body.Bytes(), respBody)
+		return recorder.Code, respBody
+	}
+	resolve := func(reportID uuid.UUID, action models.ModerationAction) (int, *models.AdminResolveReportResponse) {
+		recorder := tests.SendTestRequest(server, "PUT", "/api/admin/report/"+reportID.String()+"/resolve", adminLoginData.AccessToken, &models.AdminResolveReportRequest{
+			Action: string(action),
+			Note:   "處理檢舉",
+		})
+		respBody := &models.AdminResolveReportResponse{}
+		_ = json.Unmarshal(recorder.Body.Bytes(), respBody)
+		return recorder.Code, respBody
+	}
+	getReports := func(query string) *models.PaginationResponse[models.AdminGetReportsResponseItem] {
+		recorder := tests.SendTestRequest(server, "GET", "/api/admin/report/list"+query, adminLoginData.AccessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		respBody := &models.PaginationResponse[models.AdminGetReportsResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		return respBody
+	}
+	createComment := func(accessToken string, postID uuid.UUID, content string) *models.CommentCreateResponse {
+		recorder := tests.SendTestRequest(server, "POST", "/api/comment", accessToken, &models.CommentCreateRequest{PostID: postID, Content: content})
+		require.Equal(t, 200, recorder.Code)
+		respBody := &models.CommentCreateResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		return respBody
+	}
+	getPost := func(postID uuid.UUID) *httptest.ResponseRecorder {
+		return tests.SendTestRequest(server, "GET", "/api/post/"+postID.String(), "", nil)
+	}
+
+	postData, err := tests.SetupTestPost(server, authorLoginData.AccessToken)
+	require.NoError(t, err)
+	otherPostData, err := tests.SetupTestPost(server, authorLoginData.AccessToken)
+	require.NoError(t, err)
+
+	var postReport *models.ReportResponse
+
+	t.Run("檢舉失敗", func(t *testing.T) {
+		code, _ := report("", models.ReportTargetTypePost, postData.ID)
+		assert.Equal(t, 401, code)
+		code, _ = report(aliceLoginData.AccessToken, "user", postData.ID)
+		assert.Equal(t, 400, code, "無效的內容類型")
+		code, _ = report(aliceLoginData.AccessToken, models.ReportTargetTypePost, uuid.New())
+		assert.Equal(t, 404, code)
+		code, _ = report(aliceLoginData.AccessToken, models.ReportTargetTypeComment, postData.ID)
+		assert.Equal(t, 404, code, "類型與 ID 不符")
+		code, _ = report(authorLoginData.AccessToken, models.ReportTargetTypePost, postData.ID)
+		assert.Equal(t, 400, code, "不能檢舉自己的內容")
+
+		recorder := tests.SendTestRequest(server, "POST", "/api/report", aliceLoginData.AccessToken, &models.ReportCreateRequest{
+			TargetType: string(models.ReportTargetTypePost),
+			TargetID:   postData.ID,
+			Reason:     "boring",
+		})
+		assert.Equal(t, 400, recorder.Code, "無效的原因")
+		recorder = tests.SendTestRequest(server, "POST", "/api/report", aliceLoginData.AccessToken, &models.ReportCreateRequest{
+			TargetType: string(models.ReportTargetTypePost),
+			TargetID:   postData.ID,
+			Reason:     string(models.ReportReasonOther),
+			Detail:     strings.Repeat("長", models.REPORT_DETAIL_MAX_LENGTH+1),
+		})
+		assert.Equal(t, 400, recorder.Code, "說明過長")
+	})
+
+	t.Run("成功檢舉 - 重複檢舉回傳既有的檢舉", func(t *testing.T) {
+		code, created := report(aliceLoginData.AccessToken, models.ReportTargetTypePost, postData.ID)
+		require.Equal(t, 201, code)
+		assert.Equal(t, string(models.ReportStatusPending), created.Status)
+		postReport = created
+
+		code, again := report(aliceLoginData.AccessToken, models.ReportTargetTypePost, postData.ID)
+		assert.Equal(t, 200, code)
+		assert.Equal(t, created.ID, again.ID)
+
+		code, _ = report(bobLoginData.AccessToken, models.ReportTargetTypePost, postData.ID)
+		assert.Equal(t, 201, code)
+	})
+
+	t.Run("檢舉佇列", func(t *testing.T) {
+		assert.Equal(t, 403, tests.SendTestRequest(server, "GET", "/api/admin/report/list", aliceLoginData.AccessToken, nil).Code)
+		assert.Equal(t, 400, tests.SendTestRequest(server, "GET", "/api/admin/report/list?status=unknown", adminLoginData.AccessToken, nil).Code)
+
+		reports := getReports("")
+		assert.Equal(t, uint(2), reports.TotalCount)
+		if assert.Len(t, reports.Data, 2) {
+			assert.Equal(t, postReport.ID, reports.Data[0].ID, "先檢舉的在前")
+			assert.Equal(t, authorData.ID, reports.Data[0].TargetAuthorID)
+			assert.Equal(t, postData.Content, reports.Data[0].TargetContent)
+		}
+	})
+
+	t.Run("隱藏貼文 - 同一內容的檢舉一併結案", func(t *testing.T) {
+		assert.Equal(t, 403, tests.SendTestRequest(server, "PUT", "/api/admin/report/"+postReport.ID.String()+"/resolve", aliceLoginData.AccessToken, &models.AdminResolveReportRequest{Action: "hide"}).Code)
+		code, _ := resolve(postReport.ID, "ban")
+		assert.Equal(t, 400, code, "無效的處置")
+		code, _ = resolve(uuid.New(), models.ModerationActionHide)
+		assert.Equal(t, 404, code)
+
+		code, resolved := resolve(postReport.ID, models.ModerationActionHide)
+		require.Equal(t, 200, code)
+		assert.Equal(t, uint(2), resolved.ResolvedCount)
+		code, _ = resolve(postReport.ID, models.ModerationActionDismiss)
+		assert.Equal(t, 409, code, "已結案的檢舉")
+
+		assert.Zero(t, getReports("").TotalCount)
+		actioned := getReports("?status=actioned")
+		assert.Equal(t, uint(2), actioned.TotalCount)
+		for _, item := range actioned.Data {
+			if assert.NotNil(t, item.ResolvedByID) {
+				assert.Equal(t, adminData.ID, *item.ResolvedByID)
+			}
+		}
+
+		assert.Equal(t, 404, getPost(postData.ID).Code, "隱藏的貼文視為不存在")
+		assert.Equal(t, 200, getPost(otherPostData.ID).Code)
+		recorder := tests.SendTestRequest(server, "GET", "/api/post/list/author/"+authorData.ID.String()+"?limit=10", "", nil)
+		require.Equal(t, 200, recorder.Code)
+		authorPosts := &models.CursorPaginationResponse[models.PostGetPostsByAuthorIDResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), authorPosts))
+		if assert.Len(t, authorPosts.Data, 1) {
+			assert.Equal(t, otherPostData.ID, authorPosts.Data[0].ID)
+		}
+		assert.Equal(t, 404, tests.SendTestRequest(server, "POST", "/api/comment", aliceLoginData.AccessToken, &models.CommentCreateRequest{PostID: postData.ID, Content: "評論"}).Code)
+		code, _ = report(bobLoginData.AccessToken, models.ReportTargetTypePost, postData.ID)
+		assert.Equal(t, 404, code, "隱藏的內容無法再檢舉")
+	})
+
+	t.Run("隱藏評論 - 回覆一併隱藏", func(t *testing.T) {
+		comment := createComment(aliceLoginData.AccessToken, otherPostData.ID, "垃圾訊息")
+		recorder := tests.SendTestRequest(server, "POST", "/api/comment", bobLoginData.AccessToken, &models.CommentCreateRequest{PostID: otherPostData.ID, Content: "回覆", ParentID: &comment.ID})
+		require.Equal(t, 200, recorder.Code)
+		createComment(bobLoginData.AccessToken, otherPostData.ID, "正常評論")
+
+		code, commentReport := report(authorLoginData.AccessToken, models.ReportTargetTypeComment, comment.ID)
+		require.Equal(t, 201, code)
+		code, _ = resolve(commentReport.ID, models.ModerationActionHide)
+		require.Equal(t, 200, code)
+
+		recorder = tests.SendTestRequest(server, "GET", "/api/comment/list/post/"+otherPostData.ID.String()+"?mode=flat", "", nil)
+		require.Equal(t, 200, recorder.Code)
+		comments := []models.CommentGetFlatListByPostIDResponseItem{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &comments))
+		if assert.Len(t, comments, 1) {
+			assert.Equal(t, "正常評論", comments[0].Content)
+		}
+
+		recorder = getPost(otherPostData.ID)
+		require.Equal(t, 200, recorder.Code)
+		post := &models.PostGetPostByIDResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), post))
+		assert.Equal(t, uint(1), post.CommentCount, "隱藏的評論連同其回覆不列入評論計數")
+	})
+
+	t.Run("刪除評論", func(t *testing.T) {
+		comment := createComment(aliceLoginData.AccessToken, otherPostData.ID, "要被刪除的評論")
+		code, commentReport := report(bobLoginData.AccessToken, models.ReportTargetTypeComment, comment.ID)
+		require.Equal(t, 201, code)
+		code, _ = resolve(commentReport.ID, models.ModerationActionDelete)
+		require.Equal(t, 200, code)
+		assert.Equal(t, 404, tests.SendTestRequest(server, "GET", "/api/comment/"+comment.ID.String()+"/history", adminLoginData.AccessToken, nil).Code)
+	})
+
+	t.Run("駁回與停權", func(t *testing.T) {
+		code, dismissedReport := report(aliceLoginData.AccessToken, models.ReportTargetTypePost, otherPostData.ID)
+		require.Equal(t, 201, code)
+		code, _ = resolve(dismissedReport.ID, models.ModerationActionDismiss)
+		require.Equal(t, 200, code)
+		assert.Equal(t, uint(1), getReports("?status=dismissed").TotalCount)
+		assert.Equal(t, 200, getPost(otherPostData.ID).Code, "駁回不影響內容")
+
+		code, suspendReport := report(bobLoginData.AccessToken, models.ReportTargetTypePost, otherPostData.ID)
+		require.Equal(t, 201, code)
+		code, _ = resolve(suspendReport.ID, models.ModerationActionSuspend)
+		require.Equal(t, 200, code)
+		author := &models.User{}
+		require.NoError(t, db.First(author, "id = ?", authorData.ID).Error)
+		assert.NotNil(t, author.SuspendedAt)
+		if assert.NotNil(t, author.SuspendedReason) {
+			assert.Equal(t, "處理檢舉", *author.SuspendedReason)
+		}
+	})
+
+	t.Run("稽核紀錄", func(t *testing.T) {
+		assert.Equal(t, 403, tests.SendTestRequest(server, "GET", "/api/admin/audit-log/list", aliceLoginData.AccessToken, nil).Code)
+
+		recorder := tests.SendTestRequest(server, "GET", "/api/admin/audit-log/list?limit=10", adminLoginData.AccessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		auditLogs := &models.PaginationResponse[models.AdminGetAuditLogsResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), auditLogs))
+		assert.Equal(t, uint(5), auditLogs.TotalCount)
+		actions := make([]string, len(auditLogs.Data))
+		for i, auditLog := range auditLogs.Data {
+			actions[i] = auditLog.Action
+			assert.Equal(t, adminData.ID, auditLog.ActorID)
+			assert.Equal(t, "處理檢舉", auditLog.Note)
+		}
+		assert.Equal(t, []string{"suspend", "dismiss", "delete", "hide", "hide"}, actions, "由新到舊排序")
+
+		recorder = tests.SendTestRequest(server, "GET", "/api/admin/audit-log/list?targetID="+postData.ID.String(), adminLoginData.AccessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), auditLogs))
+		if assert.Len(t, auditLogs.Data, 1) {
+			assert.Equal(t, postReport.ID, *auditLogs.Data[0].ReportID)
+		}
+	})
+
+	t.Run("駁回 - 不發布被管理員隱藏的內容", func(t *testing.T) {
+		// 隱藏前開始的背景審核在隱藏後才送交的檢舉
+		lateReport := &models.Report{
+			TableModel: models.TableModel{ID: uuid.New()},
+			ReportBase: models.ReportBase{
+				TargetType:     models.ReportTargetTypePost,
+				TargetID:       postData.ID,
+				TargetAuthorID: authorData.ID,
+				Reason:         models.ReportReasonSpam,
+				Status:         models.ReportStatusPending,
+			},
+		}
+		require.NoError(t, db.Omit("Reporter", "TargetAuthor").Create(lateReport).Error)
+		code, _ := resolve(lateReport.ID, models.ModerationActionDismiss)
+		require.Equal(t, 200, code)
+		assert.Equal(t, 404, getPost(postData.ID).Code, "駁回不應發布被管理員隱藏的內容")
+	})
+}
+
+func TestReportRouterContentPolicy(t *testing.T) {
+	server, apiRouter, _, db, cleanup := tests.SetupTestServer("test_report_router_content_policy.db")
+	defer cleanup()
+
+	NewUserRouter().Bind(apiRouter)
+	NewPostRouter().Bind(apiRouter)
+	NewCommentRouter().Bind(apiRouter)
+	NewAdminRouter().Bind(apiRouter)
+	NewReportRouter().Bind(apiRouter)
+
+	contentPolicyService := services.NewContentPolicyService()
+	contentPolicyService.SetFilter(&pkg.ContentFilter{Policies: []pkg.ContentFilterPolicy{
+		{Rule: pkg.NewBannedWordsRule([]string{"垃圾"}), Action: pkg.ContentFilterActionMask},
+		{Rule: pkg.NewURLRule(nil, []string{"bad.com"}), Action: pkg.ContentFilterActionReject},
+		{Rule: &pkg.MaxLinksRule{Max: 1}, Action: pkg.ContentFilterActionReview},
+	}})
+	defer contentPolicyService.SetFilter(&pkg.ContentFilter{})
+
+	_, adminLoginData, err := tests.SetupTestAdminUser(server, db)
+	require.NoError(t, err)
+	authorData, authorLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+	_, aliceLoginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+
+	createPost := func(content string) (int, *models.PostCreateResponse) {
+		recorder := tests.SendTestRequest(server, "POST", "/api/post", authorLoginData.AccessToken, &models.PostCreateRequest{Content: content})
+		respBody := &models.PostCreateResponse{}
+		_ = json.Unmarshal(recorder.Body.Bytes(), respBody)
+		return recorder.Code, respBody
+	}
+	createComment := func(postID uuid.UUID, content string) (int, *models.CommentCreateResponse) {
+		recorder := tests.SendTestRequest(server, "POST", "/api/comment", aliceLoginData.AccessToken, &models.CommentCreateRequest{PostID: postID, Content: content})
+		respBody := &models.CommentCreateResponse{}
+		_ = json.Unmarshal(recorder.Body.Bytes(), respBody)
+		return recorder.Code, respBody
+	}
+	getPost := func(postID uuid.UUID) (int, *models.PostGetPostByIDResponse) {
+		recorder := tests.SendTestRequest(server, "GET", "/api/post/"+postID.String(), "", nil)
+		respBody := &models.PostGetPostByIDResponse{}
+		_ = json.Unmarshal(recorder.Body.Bytes(), respBody)
+		return recorder.Code, respBody
+	}
+	getComments := func(postID uuid.UUID) []models.CommentGetFlatListByPostIDResponseItem {
+		recorder := tests.SendTestRequest(server, "GET", "/api/comment/list/post/"+postID.String()+"?mode=flat", "", nil)
+		require.Equal(t, 200, recorder.Code)
+		comments := []models.CommentGetFlatListByPostIDResponseItem{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &comments))
+		return comments
+	}
+	// heldReport 回傳系統送交審核的待處理檢舉
+	heldReport := func(targetID uuid.UUID) *models.AdminGetReportsResponseItem {
+		recorder := tests.SendTestRequest(server, "GET", "/api/admin/report/list", adminLoginData.AccessToken, nil)
+		require.Equal(t, 200, recorder.Code)
+		reports := &models.PaginationResponse[models.AdminGetReportsResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), reports))
+		for _, report := range reports.Data {
+			if report.TargetID == targetID {
+				return &report
+			}
+		}
+		return nil
+	}
+	resolve := func(reportID uuid.UUID, action models.ModerationAction) int {
+		return tests.SendTestRequest(server, "PUT", "/api/admin/report/"+reportID.String()+"/resolve", adminLoginData.AccessToken, &models.AdminResolveReportRequest{Action: string(action)}).Code
+	}
+
+	var post *models.PostCreateResponse
+	var comment *models.CommentCreateResponse
+
+	t.Run("遮蔽 - 被遮蔽的標籤不建立", func(t *testing.T) {
+		code, respBody := createPost("這是垃圾 #垃圾 #正常")
+		require.Equal(t, 200, code)
+		assert.Equal(t, "這是** #** #正常", respBody.Content)
+		assert.Len(t, respBody.TagIDs, 1)
+		assert.False(t, respBody.PendingReview)
+		post = respBody
+
+		code, comment = createComment(post.ID, "垃圾評論")
+		require.Equal(t, 200, code)
+		assert.Equal(t, "**評論", comment.Content)
+	})
+
+	t.Run("拒絕", func(t *testing.T) {
+		code, _ := createPost("看 https://www.bad.com")
+		assert.Equal(t, 400, code)
+		code, _ = createComment(post.ID, "https://bad.com/x")
+		assert.Equal(t, 400, code)
+	})
+
+	t.Run("送交審核 - 審核通過後發布貼文", func(t *testing.T) {
+		code, held := createPost("https://a.com https://b.com")
+		require.Equal(t, 200, code)
+		assert.True(t, held.PendingReview)
+		code, _ = getPost(held.ID)
+		assert.Equal(t, 404, code, "審核通過前不公開")
+
+		report := heldReport(held.ID)
+		require.NotNil(t, report)
+		assert.Nil(t, report.ReporterID, "由系統送交審核")
+		assert.Equal(t, authorData.ID, report.TargetAuthorID)
+		assert.Equal(t, string(models.ReportReasonContentPolicy), report.Reason)
+		assert.Equal(t, "max_links", report.Detail)
+
+		require.Equal(t, 200, resolve(report.ID, models.ModerationActionDismiss))
+		code, _ = getPost(held.ID)
+		assert.Equal(t, 200, code)
+	})
+
+	t.Run("送交審核 - 評論審核通過前不列入評論計數", func(t *testing.T) {
+		code, held := createComment(post.ID, "https://a.com https://b.com")
+		require.Equal(t, 200, code)
+		assert.True(t, held.PendingReview)
+		assert.Len(t, getComments(post.ID), 1)
+		_, postData := getPost(post.ID)
+		assert.Equal(t, uint(1), postData.CommentCount)
+
+		report := heldReport(held.ID)
+		require.NotNil(t, report)
+		require.Equal(t, 200, resolve(report.ID, models.ModerationActionDismiss))
+		assert.Len(t, getComments(post.ID), 2)
+		_, postData = getPost(post.ID)
+		assert.Equal(t, uint(2), postData.CommentCount)
+	})
+
+	t.Run("送交審核 - 刪除等待審核的評論", func(t *testing.T) {
+		code, held := createComment(post.ID, "https://c.com https://d.com")
+		require.Equal(t, 200, code)
+		report := heldReport(held.ID)
+		require.NotNil(t, report)
+		require.Equal(t, 200, resolve(report.ID, models.ModerationActionDelete))
+
+		assert.Len(t, getComments(post.ID), 2)
+		_, postData := getPost(post.ID)
+		assert.Equal(t, uint(2), postData.CommentCount)
+		assert.Error(t, db.First(&models.Comment{}, "id = ?", held.ID).Error)
+	})
+
+	t.Run("編輯貼文 - 套用內容政策", func(t *testing.T) {
+		updatePost := func(content string) (int, *models.PostUpdateResponse) {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/post/"+post.ID.String(), authorLoginData.AccessToken, &models.PostUpdateRequest{Content: content})
+			respBody := &models.PostUpdateResponse{}
+			_ = json.Unmarshal(recorder.Body.Bytes(), respBody)
+			return recorder.Code, respBody
+		}
+
+		code, _ := updatePost("https://bad.com")
+		assert.Equal(t, 400, code)
+		code, updated := updatePost("又是垃圾 #垃圾")
+		require.Equal(t, 200, code)
+		assert.Equal(t, "又是** #**", updated.Content)
+		assert.Empty(t, updated.TagIDs, "被遮蔽的標籤不建立")
+		assert.False(t, updated.PendingReview)
+
+		code, updated = updatePost("https://e.com https://f.com")
+		require.Equal(t, 200, code)
+		assert.True(t, updated.PendingReview)
+		code, _ = getPost(post.ID)
+		assert.Equal(t, 404, code, "審核通過前不公開")
+
+		report := heldReport(post.ID)
+		require.NotNil(t, report)
+		assert.Equal(t, "max_links", report.Detail)
+		require.Equal(t, 200, resolve(report.ID, models.ModerationActionDismiss))
+		code, postData := getPost(post.ID)
+		require.Equal(t, 200, code)
+		assert.Equal(t, "https://e.com https://f.com", postData.Content)
+	})
+
+	t.Run("編輯評論 - 套用內容政策", func(t *testing.T) {
+		updateComment := func(content string) (int, *models.CommentUpdateResponse) {
+			recorder := tests.SendTestRequest(server, "PUT", "/api/comment/"+comment.ID.String(), aliceLoginData.AccessToken, &models.CommentUpdateRequest{Content: content})
+			respBody := &models.CommentUpdateResponse{}
+			_ = json.Unmarshal(recorder.Body.Bytes(), respBody)
+			return recorder.Code, respBody
+		}
+
+		code, _ := updateComment("https://bad.com/x")
+		assert.Equal(t, 400, code)
+		code, updated := updateComment("又是垃圾")
+		require.Equal(t, 200, code)
+		assert.Equal(t, "又是**", updated.Content)
+		assert.False(t, updated.PendingReview)
+
+		recorder := tests.SendTestRequest(server, "POST", "/api/comment", authorLoginData.AccessToken, &models.CommentCreateRequest{PostID: post.ID, Content: "回覆", ParentID: &comment.ID})
+		require.Equal(t, 200, recorder.Code)
+		_, postData := getPost(post.ID)
+		commentCount := postData.CommentCount
+
+		code, updated = updateComment("https://g.com https://h.com")
+		require.Equal(t, 200, code)
+		assert.True(t, updated.PendingReview)
+		_, postData = getPost(post.ID)
+		assert.Equal(t, commentCount-2, postData.CommentCount, "評論連同其回覆不列入評論計數")
+		assert.Len(t, getComments(post.ID), int(commentCount-2))
+
+		report := heldReport(comment.ID)
+		require.NotNil(t, report)
+		require.Equal(t, 200, resolve(report.ID, models.ModerationActionDismiss))
+		_, postData = getPost(post.ID)
+		assert.Equal(t, commentCount, postData.CommentCount)
+		assert.Len(t, getComments(post.ID), int(commentCount))
+	})
+}
@@ -0,0 +1,226 @@
+package routers
+
+import (
+	"backend/internal/models"
+	"backend/internal/services"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TAG_AUTOCOMPLETE_MAX_LIMIT 自動完成最多回傳的標籤數
+const TAG_AUTOCOMPLETE_MAX_LIMIT = 50
+
+type TagRouter struct {
+	TagService *services.TagService
+}
+
+var tagRouterOnce sync.Once
+var tagRouter *TagRouter
+
+func NewTagRouter() *TagRouter {
+	tagRouterOnce.Do(func() {
+		tagRouter = &TagRouter{
+			TagService: services.NewTagService(),
+		}
+	})
+	return tagRouter
+}
+
+func (r *TagRouter) Bind(_router *gin.RouterGroup) {
+	router := _router.Group("/tag")
+	// GET
+	{
+		router.GET("/list", r.GetTags)
+		router.GET("/autocomplete", r.Autocomplete)
+		router.GET("/trending", r.GetTrendingTags)
+		router.GET("/:tagName", r.GetTagByName)
+	}
+}
+
+// @title Tag API
+// @Summary Get tags with post counts
+// @Description sort=popular (default) orders by post count, sort=name orders by name
+// @Tags Tag
+// @Produce application/json
+// @Param sort query string false "Sort (popular, name)"
+// @Param offset query string false "Offset"
+// @Param limit query string false "Limit"
+// @Success 200 {object} models.PaginationResponse[models.TagGetListResponseItem]
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/tag/list [get]
+func (r *TagRouter) GetTags(ctx *gin.Context) {
+	sort, ok := models.ParseTagSort(ctx.DefaultQuery("sort", string(models.TagSortPopular)))
+	if !ok {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid sort"})
+		return
+	}
+	offset, err := strconv.ParseUint(ctx.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid offset"})
+		return
+	}
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
+	if err != nil || limit == 0 {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		return
+	}
+
+	pagination := &models.Pagination{
+		Offset: uint(offset),
+		Limit:  uint(limit),
+	}
+	summaries, totalCount, err := r.TagService.GetSummaries(ctx, sort, pagination)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	responseData := make([]models.TagGetListResponseItem, len(summaries))
+	for i, summary := range summaries {
+		responseData[i] = models.TagGetListResponseItem{
+			ID:        summary.ID,
+			Name:      summary.Name,
+			PostCount: summary.PostCount,
+		}
+	}
+	ctx.JSON(200, models.PaginationResponse[models.TagGetListResponseItem]{
+		Data:       responseData,
+		TotalCount: totalCount,
+		Pagination: pagination,
+	})
+}
+
+// @title Tag API
+// @Summary Autocomplete tags by prefix
+// @Description Case-insensitive prefix match (a leading # is ignored), tags with more posts first
+// @Tags Tag
+// @Produce application/json
+// @Param prefix query string true "Tag name prefix"
+// @Param limit query string false "Limit (default 10, max 50)"
+// @Success 200 {array} models.TagAutocompleteResponseItem
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/tag/autocomplete [get]
+func (r *TagRouter) Autocomplete(ctx *gin.Context) {
+	prefix := strings.TrimPrefix(strings.TrimSpace(ctx.Query("prefix")), "#")
+	if prefix == "" {
+		ctx.JSON(400, models.ErrorResponse{Error: "prefix is required"})
+		return
+	}
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
+	if err != nil || limit == 0 || limit > TAG_AUTOCOMPLETE_MAX_LIMIT {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		return
+	}
+
+	summaries, err := r.TagService.Autocomplete(ctx, prefix, uint(limit))
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	responseData := make([]models.TagAutocompleteResponseItem, len(summaries))
+	for i, summary := range summaries {
+		responseData[i] = models.TagAutocompleteResponseItem{
+			ID:        summary.ID,
+			Name:      summary.Name,
+			PostCount: summary.PostCount,
+		}
+	}
+	ctx.JSON(200, responseData)
+}
+
+// @title Tag API
+// @Summary Get trending tags
+// @Description Tags ordered by the number of posts created within a sliding time window (windowSeconds). The result is cached and refreshed in the background, refreshedAt is the time of the last refresh
+// @Tags Tag
+// @Produce application/json
+// @Param limit query string false "Limit (default 10, max 50)"
+// @Success 200 {object} models.TagGetTrendingResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/tag/trending [get]
+func (r *TagRouter) GetTrendingTags(ctx *gin.Context) {
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "10"), 10, 64)
+	if err != nil || limit == 0 || limit > services.TRENDING_TAGS_CACHE_SIZE {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid limit"})
+		return
+	}
+
+	trendingTags, err := r.TagService.GetTrendingTags(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 構建回應
+	summaries := trendingTags.Tags[:min(len(trendingTags.Tags), int(limit))]
+	responseData := make([]models.TagGetTrendingResponseItem, len(summaries))
+	for i, summary := range summaries {
+		responseData[i] = models.TagGetTrendingResponseItem{
+			ID:        summary.ID,
+			Name:      summary.Name,
+			PostCount: summary.PostCount,
+		}
+	}
+	ctx.JSON(200, models.TagGetTrendingResponse{
+		Data:          responseData,
+		WindowSeconds: int64(trendingTags.Window / time.Second),
+		RefreshedAt:   trendingTags.RefreshedAt.Format(time.RFC3339),
+	})
+}
+
+// @title Tag API
+// @Summary Get a tag by name
+// @Description The name is normalized (case-insensitive, punctuation ignored) and aliases of merged tags resolve to their tag. Posts of the tag are listed by /api/post/list/tag/{tagName}
+// @Tags Tag
+// @Produce application/json
+// @Param tagName path string true "Tag name (without #)"
+// @Success 200 {object} models.TagGetByNameResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/tag/{tagName} [get]
+func (r *TagRouter) GetTagByName(ctx *gin.Context) {
+	tag, err := r.TagService.GetByName(ctx, ctx.Param("tagName"))
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if tag == nil {
+		ctx.JSON(404, models.ErrorResponse{Error: "tag not found"})
+		return
+	}
+	aliases, postCount, ok := getTagAliasesAndPostCount(ctx, r.TagService, tag)
+	if !ok {
+		return
+	}
+	ctx.JSON(200, models.TagGetByNameResponse{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		Aliases:   aliases,
+		PostCount: postCount,
+		CreatedAt: time.Unix(tag.CreatedAt, 0).Format(time.RFC3339),
+	})
+}
+
+// getTagAliasesAndPostCount 查詢失敗時回傳 500 並回傳 false
+func getTagAliasesAndPostCount(ctx *gin.Context, tagService *services.TagService, tag *models.Tag) ([]string, uint, bool) {
+	aliases, err := tagService.GetAliasNamesByID(ctx, tag.ID)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return nil, 0, false
+	}
+	postCount, err := tagService.CountPostsByID(ctx, tag.ID)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return nil, 0, false
+	}
+	return aliases, postCount, true
+}
@@ -0,0 +1,242 @@
+package routers
+
+import (
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/services"
+	"backend/internal/tests"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagRouter(t *testing.T) {
+	httpUtils := pkg.NewHTTPUtils()
+
+	server, apiRouter, ctx, db, cleanup := tests.SetupTestServer("test_tag_router.db")
+	defer cleanup()
+
+	NewUserRouter().Bind(apiRouter)
+	NewPostRouter().Bind(apiRouter)
+	NewTagRouter().Bind(apiRouter)
+	NewAdminRouter().Bind(apiRouter)
+
+	_, loginData, err := tests.SetupTestUser(server)
+	require.NoError(t, err)
+
+	createPost := func(content string) uuid.UUID {
+		recorder := tests.SendTestRequest(server, "POST", "/api/post", loginData.AccessToken, models.PostCreateRequest{Content: content})
+		require.Equal(t, 200, recorder.Code)
+		postData := &models.PostCreateResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), postData))
+		return postData.ID
+	}
+	golangPostIDs := []uuid.UUID{
+		createPost("first #golang #gin"),
+		createPost("second #golang"),
+		createPost("third #golang #go_100%"),
+	}
+	oldPostID := createPost("old #gorm #gin")
+	// 舊貼文不在趨勢標籤的統計區間內
+	require.NoError(t, db.Model(&models.Post{}).Where("id = ?", oldPostID).
+		UpdateColumn("created_at", time.Now().Add(-2*services.TRENDING_TAGS_WINDOW).Unix()).Error)
+
+	t.Run("GetTags", func(t *testing.T) {
+		t.Run("失敗 - 無效的排序", func(t *testing.T) {
+			assert.Equal(t, 400, tests.SendTestRequest(server, "GET", "/api/tag/list?sort=unknown", loginData.AccessToken, nil).Code)
+		})
+
+		t.Run("成功 - 依貼文數排序", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "GET", "/api/tag/list?limit=2", loginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code)
+			respBody := &models.PaginationResponse[models.TagGetListResponseItem]{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			assert.Equal(t, uint(4), respBody.TotalCount)
+			if assert.Len(t, respBody.Data, 2) {
+				assert.Equal(t, "golang", respBody.Data[0].Name)
+				assert.Equal(t, uint(3), respBody.Data[0].PostCount)
+				assert.Equal(t, "gin", respBody.Data[1].Name)
+				assert.Equal(t, uint(2), respBody.Data[1].PostCount)
+			}
+		})
+
+		t.Run("成功 - 依名稱排序", func(t *testing.T) {
+			recorder := tests.SendTestRequest(server, "GET", "/api/tag/list?sort=name", loginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code)
+			respBody := &models.PaginationResponse[models.TagGetListResponseItem]{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			names := []string{}
+			for _, item := range respBody.Data {
+				names = append(names, item.Name)
+			}
+			assert.Equal(t, []string{"gin", "go_100", "golang", "gorm"}, names)
+		})
+	})
+
+	t.Run("Autocomplete", func(t *testing.T) {
+		autocomplete := func(prefix string) []string {
+			recorder := tests.SendTestRequest(server, "GET", "/api/tag/autocomplete?prefix="+url.QueryEscape(prefix), loginData.AccessToken, nil)
+			require.Equal(t, 200, recorder.Code)
+			respBody := []models.TagAutocompleteResponseItem{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &respBody))
+			names := []string{}
+			for _, item := range respBody {
+				names = append(names, item.Name)
+			}
+			return names
+		}
+
+		t.Run("失敗 - 缺少 prefix", func(t *testing.T) {
+			assert.Equal(t, 400, tests.SendTestRequest(server, "GET", "/api/tag/autocomplete?prefix=%23", loginData.AccessToken, nil).Code)
+			assert.Equal(t, 400, tests.SendTestRequest(server, "GET", "/api/tag/autocomplete?prefix=go&limit=51", loginData.AccessToken, nil).Code)
+		})
+
+		t.Run("成功 - 前綴比對", func(t *testing.T) {
+			assert.Equal(t, []string{"golang", "go_100", "gorm"}, autocomplete("GO"), "不分大小寫，貼文數多的在前")
+			assert.Equal(t, []string{"golang"}, autocomplete("#gol"))
+			assert.Equal(t, []string{"go_100"}, autocomplete("go_"), "萬用字元應以字面比對")
+			assert.Empty(t, autocomplete("%"))
+		})
+	})
+
+	t.Run("GetTrendingTags", func(t *testing.T) {
+		_, err := services.NewTagService().RefreshTrendingTags(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 400, tests.SendTestRequest(server, "GET", "/api/tag/trending?limit=0", loginData.AccessToken, nil).Code)
+
+		recorder := tests.SendTestRequest(server, "GET", "/api/tag/trending?limit=2", loginData.AccessToken, nil)
+		assert.Equal(t, 200, recorder.Code)
+		respBody := &models.TagGetTrendingResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		assert.Equal(t, int64(services.TRENDING_TAGS_WINDOW/time.Second), respBody.WindowSeconds)
+		assert.NotEmpty(t, respBody.RefreshedAt)
+		if assert.Len(t, respBody.Data, 2) {
+			assert.Equal(t, "golang", respBody.Data[0].Name)
+			assert.Equal(t, uint(3), respBody.Data[0].PostCount)
+			assert.Equal(t, "gin", respBody.Data[1].Name)
+			assert.Equal(t, uint(1), respBody.Data[1].PostCount, "只統計區間內的貼文")
+		}
+
+		recorder = tests.SendTestRequest(server, "GET", "/api/tag/trending?limit=50", loginData.AccessToken, nil)
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		for _, item := range respBody.Data {
+			assert.NotEqual(t, "gorm", item.Name, "區間內沒有貼文的標籤不應出現")
+		}
+	})
+
+	t.Run("GetTagByName", func(t *testing.T) {
+		assert.Equal(t, 404, tests.SendTestRequest(server, "GET", "/api/tag/unknown", loginData.AccessToken, nil).Code)
+
+		recorder := tests.SendTestRequest(server, "GET", "/api/tag/golang", loginData.AccessToken, nil)
+		assert.Equal(t, 200, recorder.Code)
+		respBody := &models.TagGetByNameResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		assert.Equal(t, "golang", respBody.Name)
+		assert.Equal(t, uint(3), respBody.PostCount)
+	})
+
+	t.Run("標籤正規化", func(t *testing.T) {
+		recorder := tests.SendTestRequest(server, "POST", "/api/post", loginData.AccessToken, models.PostCreateRequest{Content: "fourth #GoLang, #ＧＯＬＡＮＧ #!!!"})
+		require.Equal(t, 200, recorder.Code)
+		postData := &models.PostCreateResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), postData))
+		assert.Len(t, postData.TagIDs, 1, "正規化後相同的標籤只保留一個，無效的標籤忽略")
+
+		recorder = tests.SendTestRequest(server, "GET", "/api/tag/GOLANG", loginData.AccessToken, nil)
+		assert.Equal(t, 200, recorder.Code)
+		respBody := &models.TagGetByNameResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		assert.Equal(t, "golang", respBody.Name)
+		assert.Equal(t, uint(4), respBody.PostCount)
+		golangPostIDs = append(golangPostIDs, postData.ID)
+	})
+
+	t.Run("依標籤取得貼文", func(t *testing.T) {
+		assert.Equal(t, 404, tests.SendTestRequest(server, "GET", "/api/post/list/tag/unknown", loginData.AccessToken, nil).Code)
+
+		recorder := tests.SendTestRequest(server, "GET", "/api/post/list/tag/golang?limit=3&withTotalCount=true", loginData.AccessToken, nil)
+		assert.Equal(t, 200, recorder.Code)
+		respBody := &models.CursorPaginationResponse[models.PostGetPostsByTagResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		if assert.Len(t, respBody.Data, 3) {
+			assert.Equal(t, golangPostIDs[3], respBody.Data[0].ID, "由新到舊")
+			assert.Equal(t, golangPostIDs[2], respBody.Data[1].ID)
+			assert.Equal(t, golangPostIDs[1], respBody.Data[2].ID)
+		}
+		if assert.NotNil(t, respBody.TotalCount) {
+			assert.Equal(t, uint(4), *respBody.TotalCount)
+		}
+		require.NotNil(t, respBody.NextCursor)
+
+		recorder = tests.SendTestRequest(server, "GET", "/api/post/list/tag/golang?limit=3&cursor="+*respBody.NextCursor, loginData.AccessToken, nil)
+		assert.Equal(t, 200, recorder.Code)
+		respBody = &models.CursorPaginationResponse[models.PostGetPostsByTagResponseItem]{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+		if assert.Len(t, respBody.Data, 1) {
+			assert.Equal(t, golangPostIDs[0], respBody.Data[0].ID)
+		}
+		assert.Nil(t, respBody.NextCursor)
+	})
+
+	t.Run("MergeTags", func(t *testing.T) {
+		_, adminLoginData, err := tests.SetupTestAdminUser(server, db)
+		require.NoError(t, err)
+		merge := func(accessToken string, body models.AdminMergeTagsRequest) *httptest.ResponseRecorder {
+			buf, _ := httpUtils.ToJSONBuffer(body)
+			req, _ := http.NewRequest("POST", "/api/admin/tag/merge", buf)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", accessToken)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		t.Run("失敗 - 沒有權限", func(t *testing.T) {
+			recorder := merge(loginData.AccessToken, models.AdminMergeTagsRequest{SourceNames: []string{"gin"}, TargetName: "golang"})
+			assert.Equal(t, 403, recorder.Code)
+		})
+
+		t.Run("失敗 - 標籤不存在或合併到自己", func(t *testing.T) {
+			assert.Equal(t, 404, merge(adminLoginData.AccessToken, models.AdminMergeTagsRequest{SourceNames: []string{"unknown"}, TargetName: "golang"}).Code)
+			assert.Equal(t, 404, merge(adminLoginData.AccessToken, models.AdminMergeTagsRequest{SourceNames: []string{"gin"}, TargetName: "unknown"}).Code)
+			assert.Equal(t, 400, merge(adminLoginData.AccessToken, models.AdminMergeTagsRequest{SourceNames: []string{"GoLang"}, TargetName: "golang"}).Code)
+			assert.Equal(t, 400, merge(adminLoginData.AccessToken, models.AdminMergeTagsRequest{TargetName: "golang"}).Code)
+		})
+
+		t.Run("成功 - 合併後成為別名", func(t *testing.T) {
+			recorder := merge(adminLoginData.AccessToken, models.AdminMergeTagsRequest{SourceNames: []string{"#Gin", "gorm"}, TargetName: "golang"})
+			assert.Equal(t, 200, recorder.Code)
+			respBody := &models.AdminMergeTagsResponse{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), respBody))
+			assert.Equal(t, "golang", respBody.Name)
+			assert.Equal(t, []string{"gin", "gorm"}, respBody.Aliases)
+			assert.Equal(t, uint(5), respBody.PostCount, "已使用目標標籤的貼文不重複計算")
+
+			recorder = tests.SendTestRequest(server, "GET", "/api/tag/gin", loginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code)
+			tagRespBody := &models.TagGetByNameResponse{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), tagRespBody))
+			assert.Equal(t, "golang", tagRespBody.Name, "別名應解析為合併後的標籤")
+
+			recorder = tests.SendTestRequest(server, "POST", "/api/post", loginData.AccessToken, models.PostCreateRequest{Content: "fifth #gin #golang"})
+			require.Equal(t, 200, recorder.Code)
+			postData := &models.PostCreateResponse{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), postData))
+			assert.Equal(t, []uuid.UUID{respBody.ID}, postData.TagIDs, "使用別名時應關聯到合併後的標籤")
+
+			recorder = tests.SendTestRequest(server, "GET", "/api/post/list/search?keyword="+url.QueryEscape("#GIN"), loginData.AccessToken, nil)
+			assert.Equal(t, 200, recorder.Code)
+			searchRespBody := &models.PaginationResponse[models.PostGetPostsByKeywordResponseItem]{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), searchRespBody))
+			assert.Equal(t, uint(6), searchRespBody.TotalCount, "搜尋別名應找到合併後標籤的貼文")
+		})
+	})
+}
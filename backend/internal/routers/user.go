@@ -1,6 +1,7 @@
 package routers
 
 import (
+	"backend/internal/middlewares"
 	"backend/internal/models"
 	"backend/internal/pkg"
 	"backend/internal/services"
@@ -12,6 +13,7 @@ import (
 )
 
 type UserRouter struct {
+	AuthService    *services.AuthService
 	UserService    *services.UserService
 	CityService    *services.CityService
 	AddressService *services.AddressService
@@ -26,6 +28,7 @@ var userRouter *UserRouter
 func NewUserRouter() *UserRouter {
 	userOnce.Do(func() {
 		userRouter = &UserRouter{
+			AuthService:    services.NewAuthService(),
 			UserService:    services.NewUserService(),
 			CityService:    services.NewCityService(),
 			AddressService: services.NewAddressService(),
@@ -43,6 +46,25 @@ func (r *UserRouter) Bind(_router *gin.RouterGroup) {
 	{
 		router.POST("/register", r.Register)
 		router.POST("/login", r.Login)
+		router.POST("/token/refresh", r.RefreshToken)
+		router.POST("/logout",
+			middlewares.AllowPasswordResetRequired,
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.Logout,
+		)
+		router.POST("/logout/all",
+			middlewares.AllowPasswordResetRequired,
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.LogoutAll,
+		)
+	}
+	// PUT
+	{
+		router.PUT("/password",
+			middlewares.AllowPasswordResetRequired,
+			middlewares.VerifyAccessToken(middlewares.ParseJWTAccessToken),
+			r.ChangePassword,
+		)
 	}
 }
 
@@ -71,18 +93,33 @@ func (r *UserRouter) Login(ctx *gin.Context) {
 	}
 
 	// 驗證密碼
-	cryptoUtils := pkg.NewCryptoUtils()
-	isValid := cryptoUtils.VerifyPasswordHash(user.HashedPassword, &pkg.CryptoUtilsPasswordHashInput{
+	passwordInput := &pkg.CryptoUtilsPasswordHashInput{
 		Email:    user.Email,
 		Password: body.Password,
-	})
+	}
+	isValid := r.CryptoUtils.VerifyPasswordHash(user.HashedPassword, passwordInput)
 	if !isValid {
 		ctx.JSON(400, models.ErrorResponse{Error: "incorrect email or password"})
 		return
 	}
 
-	// 生成 JWT Token
-	accessToken, err := r.JWTUtils.GenerateToken(&models.JWTClaimsData{UserID: user.ID}, nil)
+	// 停權帳號不可登入
+	if user.SuspendedAt != nil {
+		ctx.JSON(403, models.ErrorResponse{Error: "account suspended"})
+		return
+	}
+
+	// 舊版或參數過時的密碼雜湊，登入成功後升級
+	if r.CryptoUtils.NeedsRehash(user.HashedPassword) {
+		if hashedPassword, err := r.CryptoUtils.GeneratePasswordHash(passwordInput); err != nil {
+			log.Printf("failed to rehash password of user %s: %v\n", user.ID, err)
+		} else if err := r.UserService.UpdateHashedPassword(ctx, user.ID, hashedPassword); err != nil {
+			log.Printf("failed to update password hash of user %s: %v\n", user.ID, err)
+		}
+	}
+
+	// 建立 session 並生成 Token
+	tokens, err := r.AuthService.IssueTokens(ctx, user)
 	if err != nil {
 		ctx.JSON(500, models.ErrorResponse{Error: "failed to generate access token"})
 		log.Panic(err)
@@ -91,15 +128,151 @@ func (r *UserRouter) Login(ctx *gin.Context) {
 
 	// 構建回應
 	response := &models.UserLoginResponse{
-		ID:          user.ID,
-		Username:    user.Username,
-		Email:       user.Email,
-		AccessToken: accessToken,
+		ID:           user.ID,
+		Username:     user.Username,
+		Email:        user.Email,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+
+		PasswordResetRequired: user.PasswordResetRequired,
 	}
 	ctx.IndentedJSON(200, response)
 	// ctx.JSON(200, response)
 }
 
+// @title User API
+// @Summary Rotate refresh token and issue a new access token
+// @Tags User
+// @Accept application/json
+// @Produce application/json
+// @Param request body models.UserTokenRefreshRequest true "Refresh token request"
+// @Success 200 {object} models.UserTokenRefreshResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/user/token/refresh [post]
+func (r *UserRouter) RefreshToken(ctx *gin.Context) {
+	reqBody := &models.UserTokenRefreshRequest{}
+	if err := ctx.ShouldBindJSON(reqBody); err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	tokens, err := r.AuthService.RefreshTokens(ctx, reqBody.RefreshToken)
+	if err != nil {
+		if r.AuthService.ErrorUtils.IsServerInternalError(err.Error()) {
+			ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(401, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(200, models.UserTokenRefreshResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	})
+}
+
+// @title User API
+// @Summary Logout current session
+// @Tags User
+// @Security AccessToken
+// @Produce application/json
+// @Success 200 {object} models.SuccessResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/user/logout [post]
+func (r *UserRouter) Logout(ctx *gin.Context) {
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := r.AuthService.Logout(ctx, tokenData.SessionID); err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.SuccessResponse{Success: true})
+}
+
+// @title User API
+// @Summary Logout all sessions of current user
+// @Tags User
+// @Security AccessToken
+// @Produce application/json
+// @Success 200 {object} models.SuccessResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/user/logout/all [post]
+func (r *UserRouter) LogoutAll(ctx *gin.Context) {
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := r.AuthService.LogoutAll(ctx, tokenData.UserID); err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.SuccessResponse{Success: true})
+}
+
+// @title User API
+// @Summary Change password of current user
+// @Tags User
+// @Security AccessToken
+// @Accept application/json
+// @Produce application/json
+// @Param request body models.UserChangePasswordRequest true "Change password request"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /api/user/password [put]
+func (r *UserRouter) ChangePassword(ctx *gin.Context) {
+	reqBody := &models.UserChangePasswordRequest{}
+	if err := ctx.ShouldBindJSON(reqBody); err != nil {
+		ctx.JSON(400, models.ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	// Validate password length
+	passwordLen := len(reqBody.NewPassword)
+	if passwordLen < 6 || passwordLen > 12 {
+		ctx.JSON(400, models.ErrorResponse{Error: "password length must be between 6 and 12 characters"})
+		return
+	}
+
+	tokenData, err := middlewares.GetContentAccessTokenData(ctx)
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	user, err := r.UserService.GetByID(ctx, tokenData.UserID)
+	if err != nil {
+		ctx.JSON(404, models.ErrorResponse{Error: "user not found"})
+		return
+	}
+
+	// 驗證舊密碼
+	if !r.CryptoUtils.VerifyPasswordHash(user.HashedPassword, &pkg.CryptoUtilsPasswordHashInput{
+		Email:    user.Email,
+		Password: reqBody.OldPassword,
+	}) {
+		ctx.JSON(400, models.ErrorResponse{Error: "incorrect password"})
+		return
+	}
+
+	if err := r.UserService.ChangePassword(ctx, user, reqBody.NewPassword); err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(200, models.SuccessResponse{Success: true})
+}
+
 // @title User API
 // @Summary Register a new user
 // @Tags User
@@ -145,15 +318,21 @@ func (r *UserRouter) Register(ctx *gin.Context) {
 	}
 
 	// 創建用戶與地址資料
-	userBase := &models.UserBase{
-		Username: username,
+	hashedPassword, err := r.CryptoUtils.GeneratePasswordHash(&pkg.CryptoUtilsPasswordHashInput{
 		Email:    reqBody.Email,
-		Age:      reqBody.Age,
-		HashedPassword: r.CryptoUtils.GeneratePasswordHash(&pkg.CryptoUtilsPasswordHashInput{
-			Email:    reqBody.Email,
-			Password: reqBody.Password,
-		}),
-		Role: models.RoleNormalCustomer,
+		Password: reqBody.Password,
+	})
+	if err != nil {
+		ctx.JSON(500, models.ErrorResponse{Error: "server internal error"})
+		log.Panic(err)
+		return
+	}
+	userBase := &models.UserBase{
+		Username:       username,
+		Email:          reqBody.Email,
+		Age:            reqBody.Age,
+		HashedPassword: hashedPassword,
+		Role:           models.RoleNormalCustomer,
 	}
 	var addressBase *models.AddressBase
 	if reqBody.Address != nil {
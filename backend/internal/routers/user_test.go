@@ -4,19 +4,24 @@ import (
 	"backend/internal/models"
 	"backend/internal/pkg"
 	"backend/internal/tests"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func TestUserRouter(t *testing.T) {
 	httpUtils := pkg.NewHTTPUtils()
-	server, apiRouter, ctx, _, cleanup := tests.SetupTestServer("test_user_router.db")
+	server, apiRouter, ctx, db, cleanup := tests.SetupTestServer("test_user_router.db")
 	defer cleanup()
 	userRouter := NewUserRouter()
 	userRouter.Bind(apiRouter)
@@ -109,6 +114,43 @@ func TestUserRouter(t *testing.T) {
 			assert.NotEmpty(t, response.ID, "User ID should not be empty")
 		})
 
+		t.Run("成功登入 - 舊版密碼雜湊自動升級", func(t *testing.T) {
+			// 1. 直接建立使用舊版 SHA-256 雜湊的用戶
+			email := pkg.GetRandomString(5) + "@example.com"
+			password := "password123"
+			legacyHash := sha256.Sum256([]byte(email + "|" + password))
+			user := &models.User{
+				TableModel: models.TableModel{ID: uuid.New()},
+				UserBase: models.UserBase{
+					Username:       "legacy",
+					Email:          email,
+					HashedPassword: hex.EncodeToString(legacyHash[:]),
+					Role:           models.RoleNormalCustomer,
+				},
+			}
+			require.NoError(t, db.Create(user).Error)
+
+			// 2. 登入成功
+			loginBuf, _ := httpUtils.ToJSONBuffer(models.UserLoginRequest{Email: email, Password: password})
+			loginReq, _ := http.NewRequest("POST", "/api/user/login", loginBuf)
+			loginReq.Header.Set("Content-Type", "application/json")
+			loginRecorder := httptest.NewRecorder()
+			server.ServeHTTP(loginRecorder, loginReq)
+			assert.Equal(t, 200, loginRecorder.Code, "應該回傳 200 表示登入成功")
+
+			// 3. 密碼雜湊已升級為新格式，且仍可登入
+			upgraded := &models.User{}
+			require.NoError(t, db.First(upgraded, "id = ?", user.ID).Error)
+			assert.True(t, strings.HasPrefix(upgraded.HashedPassword, "$argon2id$"), "密碼雜湊應該已升級")
+
+			loginBuf, _ = httpUtils.ToJSONBuffer(models.UserLoginRequest{Email: email, Password: password})
+			loginReq, _ = http.NewRequest("POST", "/api/user/login", loginBuf)
+			loginReq.Header.Set("Content-Type", "application/json")
+			loginRecorder = httptest.NewRecorder()
+			server.ServeHTTP(loginRecorder, loginReq)
+			assert.Equal(t, 200, loginRecorder.Code, "升級後應該仍可登入")
+		})
+
 		t.Run("登入失敗 - 錯誤的電子郵件或密碼", func(t *testing.T) {
 			// 嘗試登入不存在的用戶
 			loginPayload := models.UserLoginRequest{
@@ -126,6 +168,121 @@ func TestUserRouter(t *testing.T) {
 
 	})
 
+	t.Run("Token", func(t *testing.T) {
+		postJSON := func(path string, body any, accessToken string) *httptest.ResponseRecorder {
+			buf, _ := httpUtils.ToJSONBuffer(body)
+			req, _ := http.NewRequest("POST", path, buf)
+			req.Header.Set("Content-Type", "application/json")
+			if accessToken != "" {
+				req.Header.Set("Authorization", accessToken)
+			}
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			return recorder
+		}
+		login := func(email string, password string) *models.UserLoginResponse {
+			recorder := postJSON("/api/user/login", models.UserLoginRequest{Email: email, Password: password}, "")
+			require.Equal(t, 200, recorder.Code)
+			response := &models.UserLoginResponse{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+			return response
+		}
+		register := func() (string, string) {
+			email := pkg.GetRandomString(5) + "@example.com"
+			recorder := postJSON("/api/user/register", models.UserRegisterRequest{Email: email, Password: "password123"}, "")
+			require.Equal(t, 200, recorder.Code)
+			return email, "password123"
+		}
+
+		t.Run("成功輪替 Refresh Token", func(t *testing.T) {
+			loginData := login(register())
+			assert.NotEmpty(t, loginData.RefreshToken, "Refresh token should not be empty")
+
+			recorder := postJSON("/api/user/token/refresh", models.UserTokenRefreshRequest{RefreshToken: loginData.RefreshToken}, "")
+			assert.Equal(t, 200, recorder.Code, "應該回傳 200 表示輪替成功")
+			response := &models.UserTokenRefreshResponse{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+			assert.NotEmpty(t, response.AccessToken)
+			assert.NotEqual(t, loginData.RefreshToken, response.RefreshToken, "應該簽發新的 Refresh Token")
+
+			// 新的 Access Token 可正常使用
+			recorder = postJSON("/api/user/logout", nil, response.AccessToken)
+			assert.Equal(t, 200, recorder.Code)
+		})
+
+		t.Run("失敗 - 無效的 Refresh Token", func(t *testing.T) {
+			recorder := postJSON("/api/user/token/refresh", models.UserTokenRefreshRequest{RefreshToken: "invalid"}, "")
+			assert.Equal(t, 401, recorder.Code, "應該回傳 401 表示 Token 無效")
+		})
+
+		t.Run("失敗 - 重複使用 Refresh Token 撤銷 session", func(t *testing.T) {
+			loginData := login(register())
+
+			recorder := postJSON("/api/user/token/refresh", models.UserTokenRefreshRequest{RefreshToken: loginData.RefreshToken}, "")
+			require.Equal(t, 200, recorder.Code)
+			rotated := &models.UserTokenRefreshResponse{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), rotated))
+
+			// 重複使用舊的 Refresh Token
+			recorder = postJSON("/api/user/token/refresh", models.UserTokenRefreshRequest{RefreshToken: loginData.RefreshToken}, "")
+			assert.Equal(t, 401, recorder.Code, "應該回傳 401 表示偵測到重複使用")
+
+			// 整個 session 已被撤銷
+			recorder = postJSON("/api/user/token/refresh", models.UserTokenRefreshRequest{RefreshToken: rotated.RefreshToken}, "")
+			assert.Equal(t, 401, recorder.Code, "輪替後的 Refresh Token 也應該失效")
+			recorder = postJSON("/api/user/logout", nil, rotated.AccessToken)
+			assert.Equal(t, 401, recorder.Code, "Access Token 應該失效")
+		})
+
+		t.Run("登出目前 session", func(t *testing.T) {
+			email, password := register()
+			session1 := login(email, password)
+			session2 := login(email, password)
+
+			recorder := postJSON("/api/user/logout", nil, session1.AccessToken)
+			assert.Equal(t, 200, recorder.Code)
+
+			recorder = postJSON("/api/user/logout", nil, session1.AccessToken)
+			assert.Equal(t, 401, recorder.Code, "已登出的 Access Token 應該失效")
+			recorder = postJSON("/api/user/token/refresh", models.UserTokenRefreshRequest{RefreshToken: session1.RefreshToken}, "")
+			assert.Equal(t, 401, recorder.Code, "已登出的 Refresh Token 應該失效")
+
+			recorder = postJSON("/api/user/token/refresh", models.UserTokenRefreshRequest{RefreshToken: session2.RefreshToken}, "")
+			assert.Equal(t, 200, recorder.Code, "其他 session 不受影響")
+		})
+
+		t.Run("失敗 - Token 版本過期", func(t *testing.T) {
+			loginData := login(register())
+			require.NoError(t, db.Model(&models.User{}).Where("id = ?", loginData.ID).
+				Update("token_version", gorm.Expr("token_version + 1")).Error)
+
+			recorder := postJSON("/api/user/logout", nil, loginData.AccessToken)
+			assert.Equal(t, 401, recorder.Code, "舊版本的 Access Token 應該失效")
+
+			// Refresh 後取得新版本的 Access Token
+			recorder = postJSON("/api/user/token/refresh", models.UserTokenRefreshRequest{RefreshToken: loginData.RefreshToken}, "")
+			require.Equal(t, 200, recorder.Code)
+			response := &models.UserTokenRefreshResponse{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+			recorder = postJSON("/api/user/logout", nil, response.AccessToken)
+			assert.Equal(t, 200, recorder.Code)
+		})
+
+		t.Run("登出所有 session", func(t *testing.T) {
+			email, password := register()
+			session1 := login(email, password)
+			session2 := login(email, password)
+
+			recorder := postJSON("/api/user/logout/all", nil, session1.AccessToken)
+			assert.Equal(t, 200, recorder.Code)
+
+			recorder = postJSON("/api/user/logout", nil, session2.AccessToken)
+			assert.Equal(t, 401, recorder.Code, "所有 session 的 Access Token 應該失效")
+			recorder = postJSON("/api/user/token/refresh", models.UserTokenRefreshRequest{RefreshToken: session2.RefreshToken}, "")
+			assert.Equal(t, 401, recorder.Code, "所有 session 的 Refresh Token 應該失效")
+		})
+	})
+
 	t.Run("Register", func(t *testing.T) {
 		t.Run("成功註冊", func(t *testing.T) {
 			username := pkg.GetRandomString(5)
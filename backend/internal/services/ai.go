@@ -8,7 +8,6 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/tmc/langchaingo/llms"
-	"github.com/tmc/langchaingo/llms/openai"
 	"github.com/tmc/langchaingo/prompts"
 )
 
@@ -28,7 +27,7 @@ func NewAIService() *AIService {
 	return aiService
 }
 
-func (s *AIService) CreatePostContent(ctx *gin.Context, model *openai.LLM, topic string, style string, options ...models.AITextStreamingCallback) (string, error) {
+func (s *AIService) CreatePostContent(ctx *gin.Context, model llms.Model, topic string, style string, options ...models.AITextStreamingCallback) (string, error) {
 	// 使用 LangChain 的 PromptTemplate 來格式化指令
 	prompt := prompts.NewChatPromptTemplate([]prompts.MessageFormatter{
 		prompts.NewHumanMessagePromptTemplate(
@@ -72,7 +71,7 @@ func (s *AIService) CreatePostContent(ctx *gin.Context, model *openai.LLM, topic
 	return output.Choices[0].Content, nil
 }
 
-func (s *AIService) ContentOptimization(ctx *gin.Context, model *openai.LLM, content string, style string, options ...models.AITextStreamingCallback) (string, error) {
+func (s *AIService) ContentOptimization(ctx *gin.Context, model llms.Model, content string, style string, options ...models.AITextStreamingCallback) (string, error) {
 
 	// 使用 LangChain 的 PromptTemplate 來格式化指令
 	prompt := prompts.NewChatPromptTemplate([]prompts.MessageFormatter{
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/prompts"
+	"gorm.io/gorm"
+)
+
+const (
+	// AI_MODERATION_DEFAULT_THRESHOLD 任一類別的信心程度達到門檻時送交審核
+	AI_MODERATION_DEFAULT_THRESHOLD = 0.8
+	// AI_MODERATION_TIMEOUT 單次分類的逾時時間
+	AI_MODERATION_TIMEOUT = 30 * time.Second
+	// AI_MODERATION_MAX_CONCURRENCY 同時進行分類的最大數量 (背景工作的數量)
+	AI_MODERATION_MAX_CONCURRENCY = 4
+	// AI_MODERATION_QUEUE_SIZE 等待分類的內容上限，佇列已滿時不分類並記錄
+	AI_MODERATION_QUEUE_SIZE = 256
+)
+
+// AIModerationService 在貼文與評論發布後於背景以模型分類內容，信心程度達到門檻的內容以系統的名義送入檢舉佇列 (內容不會被隱藏)
+type AIModerationService struct {
+	ReportRepository  *repositories.ReportRepository
+	PostRepository    *repositories.PostRepository
+	CommentRepository *repositories.CommentRepository
+
+	mutex     sync.RWMutex
+	model     llms.Model
+	threshold float64
+	// stopped 關閉伺服器後不再加入佇列
+	stopped bool
+
+	// waitGroup 佇列中與分類中的內容數
+	waitGroup sync.WaitGroup
+	queue     chan aiModerationJob
+}
+
+// aiModerationJob 等待背景分類的內容
+type aiModerationJob struct {
+	db         *gorm.DB
+	model      llms.Model
+	threshold  float64
+	targetType models.ReportTargetType
+	targetID   uuid.UUID
+	authorID   uuid.UUID
+	content    string
+}
+
+var aiModerationServiceOnce sync.Once
+var aiModerationService *AIModerationService
+
+func NewAIModerationService() *AIModerationService {
+	aiModerationServiceOnce.Do(func() {
+		aiModerationService = &AIModerationService{
+			ReportRepository:  repositories.NewReportRepository(),
+			PostRepository:    repositories.NewPostRepository(),
+			CommentRepository: repositories.NewCommentRepository(),
+
+			threshold: AI_MODERATION_DEFAULT_THRESHOLD,
+			queue:     make(chan aiModerationJob, AI_MODERATION_QUEUE_SIZE),
+		}
+		for range AI_MODERATION_MAX_CONCURRENCY {
+			go aiModerationService.work()
+		}
+	})
+	return aiModerationService
+}
+
+// SetModel 設定分類使用的模型與送交審核的門檻，model 為 nil 時停用 AI 審核 (預設停用)
+func (s *AIModerationService) SetModel(model llms.Model, threshold float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.model = model
+	s.threshold = threshold
+}
+
+func (s *AIModerationService) getModel() (llms.Model, float64) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.model, s.threshold
+}
+
+// Wait 等待佇列中與分類中的內容完成
+func (s *AIModerationService) Wait() {
+	s.waitGroup.Wait()
+}
+
+// Shutdown 在關閉伺服器時停止加入佇列並等待佇列中與分類中的內容完成，ctx 結束時不再等待並回傳錯誤 (包含未分類的內容數)
+func (s *AIModerationService) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	s.stopped = true
+	s.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.waitGroup.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w, %d queued reviews dropped", ctx.Err(), len(s.queue))
+	}
+}
+
+// ReviewAsync 在目前交易提交後將內容加入背景分類的佇列 (交易回滾時不分類)，
+// 佇列已滿或分類失敗時只記錄錯誤，停用 AI 審核時不做任何事
+func (s *AIModerationService) ReviewAsync(ctx *gin.Context, targetType models.ReportTargetType, targetID uuid.UUID, authorID uuid.UUID, content string) {
+	model, threshold := s.getModel()
+	if model == nil {
+		return
+	}
+	middlewares.AfterCommit(ctx, func() {
+		// 交易提交後 ctx 的 GORM DB 已還原為交易外的連線
+		db, err := middlewares.GetContentGORMDB(ctx)
+		if err != nil {
+			log.Printf("Failed to moderate %s %s: %v\n", targetType, targetID, err)
+			return
+		}
+		// 在讀取鎖內加入佇列，Shutdown 開始等待後不再增加 waitGroup
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+		if s.stopped {
+			log.Printf("Failed to moderate %s %s: server is shutting down\n", targetType, targetID)
+			return
+		}
+		s.waitGroup.Add(1)
+		select {
+		case s.queue <- aiModerationJob{db, model, threshold, targetType, targetID, authorID, content}:
+		default:
+			s.waitGroup.Done()
+			log.Printf("Failed to moderate %s %s: moderation queue is full\n", targetType, targetID)
+		}
+	})
+}
+
+// work 背景工作，依序分類佇列中的內容
+func (s *AIModerationService) work() {
+	for job := range s.queue {
+		// 請求結束後 gin.Context 會被重複使用，背景工作使用獨立的 context
+		backgroundCtx := &gin.Context{}
+		middlewares.SetContentGORMDB(backgroundCtx, job.db)
+		if _, err := s.Review(backgroundCtx, job.model, job.threshold, job.targetType, job.targetID, job.authorID, job.content); err != nil {
+			log.Printf("Failed to moderate %s %s: %v\n", job.targetType, job.targetID, err)
+		}
+		s.waitGroup.Done()
+	}
+}
+
+// Review 分類內容，信心程度最高的類別達到門檻時送入檢舉佇列，回傳是否送交審核，
+// 內容已被刪除或隱藏時不送交審核
+func (s *AIModerationService) Review(ctx *gin.Context, model llms.Model, threshold float64, targetType models.ReportTargetType, targetID uuid.UUID, authorID uuid.UUID, content string) (bool, error) {
+	if visible, err := s.isVisible(ctx, targetType, targetID); err != nil || !visible {
+		return false, err
+	}
+	runCtx, cancel := context.WithTimeout(context.Background(), AI_MODERATION_TIMEOUT)
+	defer cancel()
+	verdict, err := s.Classify(runCtx, model, content)
+	if err != nil {
+		return false, err
+	}
+	if len(verdict.Categories) == 0 || verdict.Categories[0].Confidence < threshold {
+		return false, nil
+	}
+	// 分類期間內容可能已被管理員隱藏或刪除
+	if visible, err := s.isVisible(ctx, targetType, targetID); err != nil || !visible {
+		return false, err
+	}
+
+	details := make([]string, len(verdict.Categories))
+	for i, category := range verdict.Categories {
+		details[i] = fmt.Sprintf("%s %.2f", category.Category, category.Confidence)
+	}
+	if _, _, err := s.ReportRepository.Create(ctx, models.ReportBase{
+		TargetType:     targetType,
+		TargetID:       targetID,
+		TargetAuthorID: authorID,
+		TargetContent:  content,
+		Reason:         verdict.Categories[0].Category,
+		Detail:         "ai moderation: " + strings.Join(details, ", "),
+		Status:         models.ReportStatusPending,
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// isVisible 回傳內容是否仍存在且未被隱藏 (墓碑評論視為不存在)
+func (s *AIModerationService) isVisible(ctx *gin.Context, targetType models.ReportTargetType, targetID uuid.UUID) (bool, error) {
+	var err error
+	switch targetType {
+	case models.ReportTargetTypePost:
+		_, err = s.PostRepository.GetByID(ctx, targetID)
+	case models.ReportTargetTypeComment:
+		var comment *models.Comment
+		comment, err = s.CommentRepository.GetByID(ctx, targetID)
+		if err == nil && comment.IsDeleted() {
+			return false, nil
+		}
+	default:
+		return false, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Classify 以模型分類內容，回傳的類別依信心程度由高到低排序
+func (s *AIModerationService) Classify(ctx context.Context, model llms.Model, content string) (*models.AIModerationVerdict, error) {
+	categories := make([]string, len(models.AI_MODERATION_CATEGORIES))
+	for i, category := range models.AI_MODERATION_CATEGORIES {
+		categories[i] = string(category)
+	}
+
+	// 使用 LangChain 的 PromptTemplate 來格式化指令
+	prompt := prompts.NewChatPromptTemplate([]prompts.MessageFormatter{
+		prompts.NewSystemMessagePromptTemplate(
+			"你是社群平台的內容審核員，請判斷使用者的內容是否屬於以下類別: {{.categories}}。"+
+				`只回覆 JSON，格式為 {"categories": [{"category": "<類別>", "confidence": <0 到 1 的信心程度>}]}，`+
+				"只列出信心程度大於 0 的類別，內容沒有問題時回覆 {\"categories\": []}。不要說任何多餘的話。",
+			[]string{"categories"},
+		),
+		prompts.NewHumanMessagePromptTemplate(
+			`{{.content}}`,
+			[]string{"content"},
+		),
+	})
+	instruction, err := prompt.Format(map[string]any{
+		"categories": strings.Join(categories, ", "),
+		"content":    content,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, instruction),
+	}
+	output, err := model.GenerateContent(ctx, messages, llms.WithTemperature(0))
+	if err != nil {
+		return nil, err
+	}
+	if len(output.Choices) == 0 {
+		return nil, errors.New("empty response from model")
+	}
+	return parseAIModerationVerdict(output.Choices[0].Content)
+}
+
+// parseAIModerationVerdict 模型可能以 markdown 程式碼區塊包住 JSON，取第一個 { 到最後一個 } 之間的內容，
+// 未知的類別會被忽略，信心程度限制在 0 到 1 之間
+func parseAIModerationVerdict(output string) (*models.AIModerationVerdict, error) {
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("invalid moderation verdict: %q", output)
+	}
+	verdict := &models.AIModerationVerdict{}
+	if err := json.Unmarshal([]byte(output[start:end+1]), verdict); err != nil {
+		return nil, fmt.Errorf("invalid moderation verdict: %w", err)
+	}
+
+	categories := []models.AIModerationCategory{}
+	for _, category := range verdict.Categories {
+		if !slices.Contains(models.AI_MODERATION_CATEGORIES, category.Category) {
+			continue
+		}
+		category.Confidence = min(max(category.Confidence, 0), 1)
+		categories = append(categories, category)
+	}
+	slices.SortStableFunc(categories, func(a, b models.AIModerationCategory) int {
+		return cmp.Compare(b.Confidence, a.Confidence)
+	})
+	verdict.Categories = categories
+	return verdict, nil
+}
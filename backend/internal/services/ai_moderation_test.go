@@ -0,0 +1,143 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/tests"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/fake"
+)
+
+// failingLLM 永遠回傳錯誤的模型
+type failingLLM struct{}
+
+func (m *failingLLM) GenerateContent(_ context.Context, _ []llms.MessageContent, _ ...llms.CallOption) (*llms.ContentResponse, error) {
+	return nil, errors.New("model unavailable")
+}
+
+func (m *failingLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func TestAIModerationService(t *testing.T) {
+	service := NewAIModerationService()
+	postService := NewPostService()
+	commentService := NewCommentService()
+	ctx, db, cleanup := tests.SetupTestContext("test_ai_moderation_service.db")
+	defer cleanup()
+	defer service.SetModel(nil, AI_MODERATION_DEFAULT_THRESHOLD)
+
+	author := &models.User{
+		TableModel: models.TableModel{ID: uuid.New()},
+		UserBase: models.UserBase{
+			Username:       "author",
+			Email:          "author@example.com",
+			HashedPassword: "hashed",
+			Role:           models.RoleNormalCustomer,
+		},
+	}
+	require.NoError(t, db.Create(author).Error)
+
+	getPendingReports := func() []models.Report {
+		status := models.ReportStatusPending
+		reports, _, err := NewReportService().GetList(ctx, &status, nil)
+		require.NoError(t, err)
+		return reports
+	}
+
+	t.Run("單例模式測試", func(t *testing.T) {
+		assert.Same(t, service, NewAIModerationService(), "應該返回相同的實例")
+	})
+
+	t.Run("parseAIModerationVerdict", func(t *testing.T) {
+		verdict, err := parseAIModerationVerdict("```json\n{\"categories\": [{\"category\": \"spam\", \"confidence\": 0.4}, {\"category\": \"violence\", \"confidence\": 1.5}, {\"category\": \"unknown\", \"confidence\": 0.9}]}\n```")
+		require.NoError(t, err)
+		assert.Equal(t, []models.AIModerationCategory{
+			{Category: models.ReportReasonViolence, Confidence: 1},
+			{Category: models.ReportReasonSpam, Confidence: 0.4},
+		}, verdict.Categories, "依信心程度排序，忽略未知的類別")
+
+		_, err = parseAIModerationVerdict("這則內容沒有問題")
+		assert.Error(t, err)
+	})
+
+	t.Run("停用時不分類", func(t *testing.T) {
+		_, err := postService.CreatePostWithTags(ctx, models.PostBase{AuthorID: author.ID, Content: "停用"}, nil)
+		require.NoError(t, err)
+		service.Wait()
+		assert.Empty(t, getPendingReports())
+	})
+
+	t.Run("信心程度達到門檻時送交審核", func(t *testing.T) {
+		model := fake.NewFakeLLM([]string{
+			`{"categories": [{"category": "spam", "confidence": 0.95}, {"category": "harassment", "confidence": 0.3}]}`,
+			`{"categories": [{"category": "spam", "confidence": 0.5}]}`,
+		})
+		service.SetModel(model, 0.8)
+
+		post, err := postService.CreatePostWithTags(ctx, models.PostBase{AuthorID: author.ID, Content: "便宜代購"}, nil)
+		require.NoError(t, err)
+		service.Wait()
+		reports := getPendingReports()
+		if assert.Len(t, reports, 1) {
+			assert.Nil(t, reports[0].ReporterID, "由系統送交審核")
+			assert.Equal(t, models.ReportTargetTypePost, reports[0].TargetType)
+			assert.Equal(t, post.ID, reports[0].TargetID)
+			assert.Equal(t, author.ID, reports[0].TargetAuthorID)
+			assert.Equal(t, models.ReportReasonSpam, reports[0].Reason)
+			assert.Equal(t, "ai moderation: spam 0.95, harassment 0.30", reports[0].Detail)
+		}
+		_, err = postService.GetByID(ctx, post.ID)
+		assert.NoError(t, err, "內容不會被隱藏")
+
+		_, err = commentService.Create(ctx, []models.CommentBase{{PostID: post.ID, UserID: author.ID, Content: "低於門檻"}})
+		require.NoError(t, err)
+		service.Wait()
+		assert.Len(t, getPendingReports(), 1)
+	})
+
+	t.Run("內容已被隱藏時不送交審核", func(t *testing.T) {
+		post, err := postService.CreatePostWithTags(ctx, models.PostBase{AuthorID: author.ID, Content: "已隱藏"}, nil)
+		require.NoError(t, err)
+		require.NoError(t, db.Model(&models.Post{}).Where("id = ?", post.ID).Update("hidden_at", time.Now().Unix()).Error)
+
+		model := fake.NewFakeLLM([]string{`{"categories": [{"category": "spam", "confidence": 0.95}]}`})
+		reviewed, err := service.Review(ctx, model, 0.8, models.ReportTargetTypePost, post.ID, author.ID, post.Content)
+		require.NoError(t, err)
+		assert.False(t, reviewed)
+		assert.Len(t, getPendingReports(), 1)
+	})
+
+	t.Run("分類失敗不影響發布", func(t *testing.T) {
+		service.SetModel(&failingLLM{}, 0.8)
+		post, err := postService.CreatePostWithTags(ctx, models.PostBase{AuthorID: author.ID, Content: "模型錯誤"}, nil)
+		require.NoError(t, err)
+		service.Wait()
+		_, err = postService.GetByID(ctx, post.ID)
+		assert.NoError(t, err)
+
+		service.SetModel(fake.NewFakeLLM([]string{"not json"}), 0.8)
+		_, err = commentService.Create(ctx, []models.CommentBase{{PostID: post.ID, UserID: author.ID, Content: "格式錯誤"}})
+		require.NoError(t, err)
+		service.Wait()
+		assert.Len(t, getPendingReports(), 1)
+	})
+
+	t.Run("關閉後不再加入佇列", func(t *testing.T) {
+		defer func() { service.stopped = false }()
+		service.SetModel(fake.NewFakeLLM([]string{`{"categories": [{"category": "spam", "confidence": 0.95}]}`}), 0.8)
+		require.NoError(t, service.Shutdown(context.Background()))
+
+		_, err := postService.CreatePostWithTags(ctx, models.PostBase{AuthorID: author.ID, Content: "關閉後發布"}, nil)
+		require.NoError(t, err)
+		service.Wait()
+		assert.Len(t, getPendingReports(), 1)
+	})
+}
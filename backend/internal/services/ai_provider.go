@@ -0,0 +1,124 @@
+package services
+
+import (
+	"backend/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/ollama"
+	"github.com/tmc/langchaingo/llms/openai"
+)
+
+const (
+	// AI_PROVIDER_OPENAI OpenAI 與相容 OpenAI API 的服務 (以 BaseURL 指定)
+	AI_PROVIDER_OPENAI = "openai"
+	// AI_PROVIDER_OLLAMA 以 BaseURL 指定 Ollama 服務的位址 (預設為本機)
+	AI_PROVIDER_OLLAMA = "ollama"
+	// AI_PROVIDER_FAKE 不連線的固定輸出模型，供測試與開發使用
+	AI_PROVIDER_FAKE = "fake"
+)
+
+// AIProviderFactory 依設定建立模型
+type AIProviderFactory func(cfg *models.AIModelConfig) (llms.Model, error)
+
+var aiProvidersMutex sync.RWMutex
+var aiProviders = map[string]AIProviderFactory{
+	AI_PROVIDER_OPENAI: newOpenAIModel,
+	AI_PROVIDER_OLLAMA: newOllamaModel,
+	AI_PROVIDER_FAKE: func(_ *models.AIModelConfig) (llms.Model, error) {
+		return &FakeAIModel{}, nil
+	},
+}
+
+// RegisterAIProvider 註冊或取代模型供應者，需在 NewAIModel 前呼叫
+func RegisterAIProvider(name string, factory AIProviderFactory) {
+	aiProvidersMutex.Lock()
+	defer aiProvidersMutex.Unlock()
+	aiProviders[strings.ToLower(name)] = factory
+}
+
+// NewAIModel 依 cfg.Provider 建立模型，Provider 為空字串時回傳 nil (停用 AI)，未註冊的供應者回傳錯誤
+func NewAIModel(cfg *models.AIModelConfig) (llms.Model, error) {
+	if cfg.Provider == "" {
+		return nil, nil
+	}
+	aiProvidersMutex.RLock()
+	factory, ok := aiProviders[strings.ToLower(cfg.Provider)]
+	names := slices.Sorted(maps.Keys(aiProviders))
+	aiProvidersMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported AI provider %q, available providers: %s", cfg.Provider, strings.Join(names, ", "))
+	}
+	return factory(cfg)
+}
+
+func newOpenAIModel(cfg *models.AIModelConfig) (llms.Model, error) {
+	options := []openai.Option{openai.WithToken(cfg.APIKey)}
+	if cfg.BaseURL != "" {
+		options = append(options, openai.WithBaseURL(cfg.BaseURL))
+	}
+	if cfg.ModelName != "" {
+		options = append(options, openai.WithModel(cfg.ModelName))
+	}
+	// 建立失敗時回傳的 *openai.LLM 為 nil，直接回傳會成為不等於 nil 的 llms.Model
+	model, err := openai.New(options...)
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+func newOllamaModel(cfg *models.AIModelConfig) (llms.Model, error) {
+	if cfg.ModelName == "" {
+		return nil, errors.New("ollama requires a model name")
+	}
+	options := []ollama.Option{ollama.WithModel(cfg.ModelName)}
+	if cfg.BaseURL != "" {
+		options = append(options, ollama.WithServerURL(cfg.BaseURL))
+	}
+	model, err := ollama.New(options...)
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// FakeAIModel 固定輸出的模型，Response 為空字串時回傳最後一則訊息的文字，
+// 有設定串流時整段輸出為一個 chunk
+type FakeAIModel struct {
+	Response string
+}
+
+func (m *FakeAIModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	response := m.Response
+	if response == "" && len(messages) > 0 {
+		for _, part := range messages[len(messages)-1].Parts {
+			if text, ok := part.(llms.TextContent); ok {
+				response += text.Text
+			}
+		}
+	}
+
+	callOptions := llms.CallOptions{}
+	for _, option := range options {
+		option(&callOptions)
+	}
+	if callOptions.StreamingFunc != nil {
+		if err := callOptions.StreamingFunc(ctx, []byte(response)); err != nil {
+			return nil, err
+		}
+	}
+	return &llms.ContentResponse{
+		Choices: []*llms.ContentChoice{{Content: response}},
+	}, nil
+}
+
+func (m *FakeAIModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
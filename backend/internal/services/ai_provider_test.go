@@ -0,0 +1,71 @@
+package services
+
+import (
+	"backend/internal/models"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestAIProvider(t *testing.T) {
+	t.Run("未設定供應者時停用 AI", func(t *testing.T) {
+		model, err := NewAIModel(&models.AIModelConfig{})
+		require.NoError(t, err)
+		assert.Nil(t, model)
+	})
+
+	t.Run("未註冊的供應者", func(t *testing.T) {
+		_, err := NewAIModel(&models.AIModelConfig{Provider: "unknown"})
+		assert.ErrorContains(t, err, "fake, ollama, openai")
+	})
+
+	t.Run("建立模型", func(t *testing.T) {
+		model, err := NewAIModel(&models.AIModelConfig{Provider: "OpenAI", APIKey: "test-key", BaseURL: "http://localhost:1/v1", ModelName: "gpt"})
+		require.NoError(t, err)
+		assert.NotNil(t, model, "供應者名稱不分大小寫")
+
+		model, err = NewAIModel(&models.AIModelConfig{Provider: AI_PROVIDER_OLLAMA, ModelName: "llama3"})
+		require.NoError(t, err)
+		assert.NotNil(t, model)
+		_, err = NewAIModel(&models.AIModelConfig{Provider: AI_PROVIDER_OLLAMA})
+		assert.Error(t, err, "ollama 需要模型名稱")
+	})
+
+	t.Run("建立失敗時回傳 nil", func(t *testing.T) {
+		t.Setenv("OPENAI_API_KEY", "")
+		model, err := NewAIModel(&models.AIModelConfig{Provider: AI_PROVIDER_OPENAI})
+		assert.Error(t, err, "openai 需要 API key")
+		assert.True(t, model == nil, "不可為包含 nil 指標的 llms.Model")
+	})
+
+	t.Run("固定輸出的模型", func(t *testing.T) {
+		model, err := NewAIModel(&models.AIModelConfig{Provider: AI_PROVIDER_FAKE})
+		require.NoError(t, err)
+
+		output, err := llms.GenerateFromSinglePrompt(context.Background(), model, "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "hello", output, "未設定 Response 時回傳最後一則訊息")
+
+		chunks := []string{}
+		output, err = (&FakeAIModel{Response: "固定輸出"}).Call(context.Background(), "hello", llms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+			chunks = append(chunks, string(chunk))
+			return nil
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "固定輸出", output)
+		assert.Equal(t, []string{"固定輸出"}, chunks)
+	})
+
+	t.Run("RegisterAIProvider", func(t *testing.T) {
+		fakeModel := &FakeAIModel{Response: "custom"}
+		RegisterAIProvider("Custom", func(_ *models.AIModelConfig) (llms.Model, error) {
+			return fakeModel, nil
+		})
+		model, err := NewAIModel(&models.AIModelConfig{Provider: "custom"})
+		require.NoError(t, err)
+		assert.Same(t, fakeModel, model)
+	})
+}
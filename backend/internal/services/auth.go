@@ -0,0 +1,157 @@
+package services
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/repositories"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const REFRESH_TOKEN_BYTE_LENGTH = 32
+
+var (
+	ErrRefreshTokenInvalid = errors.New("invalid refresh token")
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+	ErrRefreshTokenRevoked = errors.New("refresh token revoked")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+	ErrAccountSuspended    = errors.New("account suspended")
+)
+
+type AuthService struct {
+	ErrorUtils  *pkg.ErrorUtils
+	CryptoUtils *pkg.CryptoUtils
+	JWTUtils    *pkg.JWTUtils
+
+	RefreshTokenRepository *repositories.RefreshTokenRepository
+	UserRepository         *repositories.UserRepository
+}
+
+var authServiceOnce sync.Once
+var authService *AuthService
+
+func NewAuthService() *AuthService {
+	authServiceOnce.Do(func() {
+		authService = &AuthService{
+			ErrorUtils:  pkg.NewErrorUtils(),
+			CryptoUtils: pkg.NewCryptoUtils(),
+			JWTUtils:    pkg.NewJWTUtils(),
+
+			RefreshTokenRepository: repositories.NewRefreshTokenRepository(),
+			UserRepository:         repositories.NewUserRepository(),
+		}
+	})
+	return authService
+}
+
+// IssueTokens 登入時建立新的 session，並簽發 access token 與 refresh token
+func (s *AuthService) IssueTokens(ctx *gin.Context, user *models.User) (*models.AuthTokens, error) {
+	return s.issueTokens(ctx, user, uuid.New())
+}
+
+func (s *AuthService) issueTokens(ctx *gin.Context, user *models.User, sessionID uuid.UUID) (*models.AuthTokens, error) {
+	refreshToken, err := s.CryptoUtils.GenerateRandomToken(REFRESH_TOKEN_BYTE_LENGTH)
+	if err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	if _, err := s.RefreshTokenRepository.Create(ctx, []models.RefreshTokenBase{{
+		UserID:    user.ID,
+		SessionID: sessionID,
+		TokenHash: s.CryptoUtils.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.JWTUtils.RefreshTokenTTL).Unix(),
+	}}); err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	accessToken, err := s.JWTUtils.GenerateToken(&models.JWTClaimsData{
+		UserID:       user.ID,
+		SessionID:    sessionID,
+		Role:         user.Role,
+		TokenVersion: user.TokenVersion,
+	}, nil)
+	if err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return &models.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		SessionID:    sessionID,
+	}, nil
+}
+
+// RefreshTokens 輪替 refresh token，已使用過的 token 再次出現時撤銷整個 session
+func (s *AuthService) RefreshTokens(ctx *gin.Context, refreshToken string) (*models.AuthTokens, error) {
+	stored, err := s.RefreshTokenRepository.GetByTokenHash(ctx, s.CryptoUtils.HashToken(refreshToken))
+	if err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	if stored == nil {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if stored.RevokedAt != nil {
+		return nil, ErrRefreshTokenRevoked
+	}
+	if stored.UsedAt != nil {
+		if err := s.RefreshTokenRepository.RevokeBySessionID(ctx, stored.SessionID); err != nil {
+			return nil, s.ErrorUtils.ServerInternalError(err.Error())
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if stored.ExpiresAt <= time.Now().Unix() {
+		return nil, ErrRefreshTokenExpired
+	}
+
+	// 重新讀取使用者，讓新的 access token 帶有最新的角色與版本
+	user, err := s.UserRepository.GetByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if user.SuspendedAt != nil {
+		return nil, ErrAccountSuspended
+	}
+
+	var tokens *models.AuthTokens
+	var reused bool
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		marked, err := s.RefreshTokenRepository.MarkUsed(ctx, stored.ID)
+		if err != nil {
+			return err
+		}
+		if !marked {
+			// 併發請求已先輪替此 token
+			reused = true
+			return nil
+		}
+		tokens, err = s.issueTokens(ctx, user, stored.SessionID)
+		return err
+	}); err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	if reused {
+		if err := s.RefreshTokenRepository.RevokeBySessionID(ctx, stored.SessionID); err != nil {
+			return nil, s.ErrorUtils.ServerInternalError(err.Error())
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	return tokens, nil
+}
+
+// Logout 撤銷目前的 session
+func (s *AuthService) Logout(ctx *gin.Context, sessionID uuid.UUID) error {
+	if err := s.RefreshTokenRepository.RevokeBySessionID(ctx, sessionID); err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return nil
+}
+
+// LogoutAll 撤銷使用者所有的 session
+func (s *AuthService) LogoutAll(ctx *gin.Context, userID uuid.UUID) error {
+	if err := s.RefreshTokenRepository.RevokeByUserID(ctx, userID); err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return nil
+}
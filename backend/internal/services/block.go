@@ -0,0 +1,91 @@
+package services
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/repositories"
+	"errors"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var ErrBlockSelf = errors.New("cannot block yourself")
+
+type BlockService struct {
+	ErrorUtils *pkg.ErrorUtils
+
+	BlockRepository  *repositories.BlockRepository
+	FollowRepository *repositories.FollowRepository
+
+	NotificationService *NotificationService
+}
+
+var blockServiceOnce sync.Once
+var blockService *BlockService
+
+func NewBlockService() *BlockService {
+	blockServiceOnce.Do(func() {
+		blockService = &BlockService{
+			ErrorUtils: pkg.NewErrorUtils(),
+
+			BlockRepository:  repositories.NewBlockRepository(),
+			FollowRepository: repositories.NewFollowRepository(),
+
+			NotificationService: NewNotificationService(),
+		}
+	})
+	return blockService
+}
+
+// Block 封鎖使用者並移除雙方之間的追蹤 (含追蹤通知)，重複封鎖不會產生錯誤
+func (s *BlockService) Block(ctx *gin.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	if blockerID == blockedID {
+		return ErrBlockSelf
+	}
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		blocked, err := s.BlockRepository.Block(ctx, blockerID, blockedID)
+		if err != nil || !blocked {
+			return err
+		}
+		for _, pair := range [][2]uuid.UUID{{blockerID, blockedID}, {blockedID, blockerID}} {
+			unfollowed, err := s.FollowRepository.Unfollow(ctx, pair[0], pair[1])
+			if err != nil {
+				return err
+			}
+			if !unfollowed {
+				continue
+			}
+			if err := s.NotificationService.RemoveFollow(ctx, pair[1], pair[0]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return nil
+}
+
+// Unblock 解除封鎖，未封鎖時不會產生錯誤，解除封鎖不會恢復原本的追蹤
+func (s *BlockService) Unblock(ctx *gin.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	if _, err := s.BlockRepository.Unblock(ctx, blockerID, blockedID); err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return nil
+}
+
+// IsBlockedBetween userID 與 otherUserIDs 中任一使用者之間有封鎖關係 (不論是誰封鎖誰)
+func (s *BlockService) IsBlockedBetween(ctx *gin.Context, userID uuid.UUID, otherUserIDs ...uuid.UUID) (bool, error) {
+	blocked, err := s.BlockRepository.ExistsBetween(ctx, userID, otherUserIDs)
+	if err != nil {
+		return false, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return blocked, nil
+}
+
+func (s *BlockService) GetBlockedUsers(ctx *gin.Context, userID uuid.UUID, pagination *models.Pagination) ([]models.User, uint, error) {
+	return s.BlockRepository.GetBlockedUsers(ctx, userID, pagination)
+}
@@ -1,16 +1,35 @@
 package services
 
 import (
+	"backend/internal/middlewares"
 	"backend/internal/models"
+	"backend/internal/pkg"
 	"backend/internal/repositories"
+	"errors"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+var ErrCommentBlocked = errors.New("cannot comment on content of a user you have blocked or who has blocked you")
+
 type CommentService struct {
-	CommentRepository *repositories.CommentRepository
+	ErrorUtils *pkg.ErrorUtils
+
+	CommentRepository            *repositories.CommentRepository
+	CommentEditHistoryRepository *repositories.CommentEditHistoryRepository
+	PostRepository               *repositories.PostRepository
+	BlockRepository              *repositories.BlockRepository
+
+	MentionService       *MentionService
+	NotificationService  *NotificationService
+	EventService         *EventService
+	ContentPolicyService *ContentPolicyService
+	AIModerationService  *AIModerationService
 }
 
 var commentServiceOnce sync.Once
@@ -19,20 +38,383 @@ var commentService *CommentService
 func NewCommentService() *CommentService {
 	commentServiceOnce.Do(func() {
 		commentService = &CommentService{
-			CommentRepository: repositories.NewCommentRepository(),
+			ErrorUtils: pkg.NewErrorUtils(),
+
+			CommentRepository:            repositories.NewCommentRepository(),
+			CommentEditHistoryRepository: repositories.NewCommentEditHistoryRepository(),
+			PostRepository:               repositories.NewPostRepository(),
+			BlockRepository:              repositories.NewBlockRepository(),
+
+			MentionService:       NewMentionService(),
+			NotificationService:  NewNotificationService(),
+			EventService:         NewEventService(),
+			ContentPolicyService: NewContentPolicyService(),
+			AIModerationService:  NewAIModerationService(),
 		}
 	})
 	return commentService
 }
 
+// Create 建立評論並同步更新貼文的評論計數，通知貼文作者、被回覆評論的作者與被提及的使用者，並推送給正在瀏覽貼文的使用者，
+// 評論者與貼文作者或被回覆評論的作者之間有封鎖關係時回傳 ErrCommentBlocked。
+// 保存前套用內容政策，違反處置為 reject 的規則時回傳 ErrContentRejected，
+// 違反處置為 review 的規則時評論保存為隱藏並送交審核，審核通過前不列入評論計數也不同步提及、通知與推送，
+// 其他評論發布後於背景進行 AI 審核
 func (s *CommentService) Create(ctx *gin.Context, commentBases []models.CommentBase) ([]models.Comment, error) {
-	return s.CommentRepository.Create(ctx, commentBases)
+	commentBases = slices.Clone(commentBases)
+	violations := make([][]string, len(commentBases))
+	for i, commentBase := range commentBases {
+		blocked, err := s.isBlocked(ctx, commentBase)
+		if err != nil {
+			return nil, s.ErrorUtils.ServerInternalError(err.Error())
+		}
+		if blocked {
+			return nil, ErrCommentBlocked
+		}
+
+		result, err := s.ContentPolicyService.Check(commentBase.Content)
+		if err != nil {
+			return nil, err
+		}
+		commentBases[i].Content = result.Content
+		if result.Action == pkg.ContentFilterActionReview {
+			commentBases[i].HiddenAt = pkg.GetPointer(time.Now().Unix())
+			violations[i] = result.Violations
+		}
+	}
+
+	var comments []models.Comment
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		var err error
+		comments, err = s.CommentRepository.Create(ctx, commentBases)
+		if err != nil {
+			return err
+		}
+		published := []models.Comment{}
+		for i, comment := range comments {
+			if comment.HiddenAt != nil {
+				if err := s.ContentPolicyService.HoldForReview(ctx, models.ReportTargetTypeComment, comment.ID, comment.UserID, comment.Content, violations[i]); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := s.PostRepository.IncrementCommentCount(ctx, comment.PostID, 1); err != nil {
+				return err
+			}
+			comments[i].Mentions, err = s.MentionService.SyncCommentMentions(ctx, comment.UserID, comment.PostID, comment.ID, comment.Content)
+			if err != nil {
+				return err
+			}
+			if err := s.NotificationService.NotifyComment(ctx, &comments[i]); err != nil {
+				return err
+			}
+			s.AIModerationService.ReviewAsync(ctx, models.ReportTargetTypeComment, comment.ID, comment.UserID, comment.Content)
+			published = append(published, comments[i])
+		}
+		s.EventService.PublishComments(ctx, published)
+		return nil
+	}); err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return comments, nil
+}
+
+// isBlocked 評論者與貼文作者或被回覆評論的作者之間有封鎖關係
+func (s *CommentService) isBlocked(ctx *gin.Context, commentBase models.CommentBase) (bool, error) {
+	post, err := s.PostRepository.GetByID(ctx, commentBase.PostID)
+	if err != nil {
+		return false, err
+	}
+	userIDs := []uuid.UUID{post.AuthorID}
+	if commentBase.ParentID != nil {
+		parent, err := s.CommentRepository.GetByID(ctx, *commentBase.ParentID)
+		if err != nil {
+			return false, err
+		}
+		userIDs = append(userIDs, parent.UserID)
+	}
+	return s.BlockRepository.ExistsBetween(ctx, commentBase.UserID, userIDs)
 }
 
 func (s *CommentService) GetByID(ctx *gin.Context, commentID uuid.UUID) (*models.Comment, error) {
 	return s.CommentRepository.GetByID(ctx, commentID)
 }
 
-func (s *CommentService) GetListByPostID(ctx *gin.Context, postID uuid.UUID) ([]models.Comment, error) {
-	return s.CommentRepository.GetListByPostID(ctx, postID)
+// Update 套用內容政策後編輯評論並重新同步提及，編輯前的內容保存於編輯紀錄，內容政策的處置同 Create，
+// 送交審核的評論連同其回覆被隱藏 (不列入評論計數) 並清除提及，審核通過後才重新同步
+func (s *CommentService) Update(ctx *gin.Context, comment *models.Comment, editorID uuid.UUID, content string) (*models.Comment, error) {
+	result, err := s.ContentPolicyService.Check(content)
+	if err != nil {
+		return nil, err
+	}
+	content = result.Content
+	held := result.Action == pkg.ContentFilterActionReview
+
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		if _, err := s.CommentEditHistoryRepository.Create(ctx, []models.CommentEditHistoryBase{{
+			CommentID: comment.ID,
+			EditorID:  editorID,
+			Content:   comment.Content,
+		}}); err != nil {
+			return err
+		}
+		updates := map[string]any{
+			"content":   content,
+			"edited_at": time.Now().Unix(),
+		}
+		if held {
+			count, err := s.countVisibleSubtree(ctx, comment.ID)
+			if err != nil {
+				return err
+			}
+			if err := s.PostRepository.IncrementCommentCount(ctx, comment.PostID, -count); err != nil {
+				return err
+			}
+			updates["hidden_at"] = time.Now().Unix()
+		}
+		if err := s.CommentRepository.UpdateByID(ctx, comment.ID, updates); err != nil {
+			return err
+		}
+		if held {
+			if err := s.ContentPolicyService.HoldForReview(ctx, models.ReportTargetTypeComment, comment.ID, comment.UserID, content, result.Violations); err != nil {
+				return err
+			}
+			_, err := s.MentionService.SyncCommentMentions(ctx, comment.UserID, comment.PostID, comment.ID, "")
+			return err
+		}
+		// 提及的通知由評論作者發出
+		if _, err := s.MentionService.SyncCommentMentions(ctx, comment.UserID, comment.PostID, comment.ID, content); err != nil {
+			return err
+		}
+		s.AIModerationService.ReviewAsync(ctx, models.ReportTargetTypeComment, comment.ID, comment.UserID, content)
+		return nil
+	}); err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+
+	getByID := s.CommentRepository.GetByID
+	if held {
+		getByID = s.CommentRepository.GetHiddenByID
+	}
+	updated, err := getByID(ctx, comment.ID)
+	if err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return updated, nil
+}
+
+// Delete 有回覆的評論保留為墓碑 (刪除前的內容保存於編輯紀錄)，沒有回覆的評論直接刪除，
+// 並一併清除因此不再有回覆的墓碑父評論，回傳是否保留為墓碑
+func (s *CommentService) Delete(ctx *gin.Context, comment *models.Comment, editorID uuid.UUID) (bool, error) {
+	var tombstoned bool
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		replyCount, err := s.CommentRepository.CountReplies(ctx, comment.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.PostRepository.IncrementCommentCount(ctx, comment.PostID, -1); err != nil {
+			return err
+		}
+
+		if replyCount > 0 {
+			tombstoned = true
+			if _, err := s.CommentEditHistoryRepository.Create(ctx, []models.CommentEditHistoryBase{{
+				CommentID: comment.ID,
+				EditorID:  editorID,
+				Content:   comment.Content,
+			}}); err != nil {
+				return err
+			}
+			// 墓碑評論不保留提及
+			if _, err := s.MentionService.SyncCommentMentions(ctx, comment.UserID, comment.PostID, comment.ID, ""); err != nil {
+				return err
+			}
+			return s.CommentRepository.UpdateByID(ctx, comment.ID, map[string]any{
+				"content":    models.COMMENT_DELETED_CONTENT,
+				"deleted_at": time.Now().Unix(),
+			})
+		}
+
+		if err := s.CommentRepository.DeleteByIDs(ctx, []uuid.UUID{comment.ID}); err != nil {
+			return err
+		}
+		// 往上清除沒有回覆的墓碑父評論 (墓碑不列入評論計數，不需再更新)
+		for parentID := comment.ParentID; parentID != nil; {
+			parent, err := s.CommentRepository.GetByID(ctx, *parentID)
+			// 被隱藏的父評論不是墓碑，不需清除
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if !parent.IsDeleted() {
+				break
+			}
+			replyCount, err := s.CommentRepository.CountReplies(ctx, parent.ID)
+			if err != nil {
+				return err
+			}
+			if replyCount > 0 {
+				break
+			}
+			if err := s.CommentRepository.DeleteByIDs(ctx, []uuid.UUID{parent.ID}); err != nil {
+				return err
+			}
+			parentID = parent.ParentID
+		}
+		return nil
+	}); err != nil {
+		return false, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return tombstoned, nil
+}
+
+func (s *CommentService) GetEditHistories(ctx *gin.Context, commentID uuid.UUID) ([]models.CommentEditHistory, error) {
+	return s.CommentEditHistoryRepository.GetListByCommentID(ctx, commentID)
+}
+
+// countVisibleSubtree 回傳評論本身加上其下列入評論計數的回覆數 (不含墓碑，被隱藏的回覆連同其回覆不計)，
+// 隱藏或發布評論時評論樹中一併消失或出現的評論數
+func (s *CommentService) countVisibleSubtree(ctx *gin.Context, commentID uuid.UUID) (int, error) {
+	descendants, err := s.CommentRepository.GetDescendantsByIDs(ctx, []uuid.UUID{commentID}, nil)
+	if err != nil {
+		return 0, err
+	}
+	count := 1
+	for _, descendant := range descendants {
+		if !descendant.IsDeleted() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// GetListByPostID viewerID 為登入的使用者 (未登入時為 nil)，排除與其有封鎖關係的使用者的評論
+func (s *CommentService) GetListByPostID(ctx *gin.Context, postID uuid.UUID, viewerID *uuid.UUID) ([]models.Comment, error) {
+	return s.CommentRepository.GetListByPostID(ctx, postID, viewerID)
+}
+
+// GetTreeByPostID 回傳貼文的評論樹 (根評論列表)，巢狀深度不限，
+// 被隱藏或與 viewerID 有封鎖關係的使用者的評論連同其回覆一併隱藏
+func (s *CommentService) GetTreeByPostID(ctx *gin.Context, postID uuid.UUID, viewerID *uuid.UUID) ([]*models.CommentTreeNode, error) {
+	comments, err := s.CommentRepository.GetListByPostID(ctx, postID, viewerID)
+	if err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return s.BuildTree(dropOrphanComments(comments)), nil
+}
+
+// dropOrphanComments 移除父評論不在 comments 中的回覆 (包含其下所有回覆)
+func dropOrphanComments(comments []models.Comment) []models.Comment {
+	parentIDs := make(map[uuid.UUID]*uuid.UUID, len(comments))
+	for _, comment := range comments {
+		parentIDs[comment.ID] = comment.ParentID
+	}
+	kept := make(map[uuid.UUID]bool, len(comments))
+	var isKept func(commentID uuid.UUID, depth int) bool
+	isKept = func(commentID uuid.UUID, depth int) bool {
+		if result, ok := kept[commentID]; ok {
+			return result
+		}
+		parentID, ok := parentIDs[commentID]
+		// 深度超過評論數表示資料中有循環，視為根評論交由 BuildTree 處理
+		result := ok && (parentID == nil || depth > len(comments) || isKept(*parentID, depth+1))
+		kept[commentID] = result
+		return result
+	}
+	result := make([]models.Comment, 0, len(comments))
+	for _, comment := range comments {
+		if isKept(comment.ID, 0) {
+			result = append(result, comment)
+		}
+	}
+	return result
+}
+
+// GetTreePageByPostID 依根評論 keyset 分頁回傳評論樹，每個根評論包含所有回覆，回傳下一頁的位置與根評論總數 (未要求時為 nil)，
+// 過濾方式同 GetTreeByPostID
+func (s *CommentService) GetTreePageByPostID(ctx *gin.Context, postID uuid.UUID, viewerID *uuid.UUID, pagination *models.CursorPagination) ([]*models.CommentTreeNode, *models.Cursor, *uint, error) {
+	roots, nextCursor, totalCount, err := s.CommentRepository.GetRootListByPostIDByCursor(ctx, postID, viewerID, pagination)
+	if err != nil {
+		return nil, nil, nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	rootIDs := make([]uuid.UUID, len(roots))
+	for i, root := range roots {
+		rootIDs[i] = root.ID
+	}
+	descendants, err := s.CommentRepository.GetDescendantsByIDs(ctx, rootIDs, viewerID)
+	if err != nil {
+		return nil, nil, nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return s.BuildTree(append(roots, descendants...)), nextCursor, totalCount, nil
+}
+
+// GetFlatListByPostID 以深度優先順序回傳貼文的評論，每個節點帶有 Depth 與 Path
+func (s *CommentService) GetFlatListByPostID(ctx *gin.Context, postID uuid.UUID, viewerID *uuid.UUID) ([]*models.CommentTreeNode, error) {
+	roots, err := s.GetTreeByPostID(ctx, postID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Flatten(roots), nil
+}
+
+// BuildTree 依 ParentID 組成評論樹，comments 的順序即為同層的順序
+// 父評論不在 comments 中的評論視為根評論
+func (s *CommentService) BuildTree(comments []models.Comment) []*models.CommentTreeNode {
+	nodes := make(map[uuid.UUID]*models.CommentTreeNode, len(comments))
+	for _, comment := range comments {
+		nodes[comment.ID] = &models.CommentTreeNode{
+			Comment:  comment,
+			Children: []*models.CommentTreeNode{},
+		}
+	}
+
+	roots := make([]*models.CommentTreeNode, 0)
+	for _, comment := range comments {
+		node := nodes[comment.ID]
+		if comment.ParentID != nil {
+			if parent, ok := nodes[*comment.ParentID]; ok && parent != node {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	// 由根評論往下設定深度與路徑 (已走訪的節點不再處理，避免資料錯誤造成循環)
+	visited := make(map[uuid.UUID]bool, len(nodes))
+	var walk func(node *models.CommentTreeNode, depth uint, parentPath []uuid.UUID)
+	walk = func(node *models.CommentTreeNode, depth uint, parentPath []uuid.UUID) {
+		visited[node.Comment.ID] = true
+		node.Depth = depth
+		node.Path = append(append(make([]uuid.UUID, 0, len(parentPath)+1), parentPath...), node.Comment.ID)
+		children := make([]*models.CommentTreeNode, 0, len(node.Children))
+		for _, child := range node.Children {
+			if visited[child.Comment.ID] {
+				continue
+			}
+			children = append(children, child)
+			walk(child, depth+1, node.Path)
+		}
+		node.Children = children
+	}
+	for _, root := range roots {
+		walk(root, 0, nil)
+	}
+	return roots
+}
+
+// Flatten 以深度優先順序展開評論樹
+func (s *CommentService) Flatten(roots []*models.CommentTreeNode) []*models.CommentTreeNode {
+	result := make([]*models.CommentTreeNode, 0)
+	var walk func(nodes []*models.CommentTreeNode)
+	walk = func(nodes []*models.CommentTreeNode) {
+		for _, node := range nodes {
+			result = append(result, node)
+			walk(node.Children)
+		}
+	}
+	walk(roots)
+	return result
 }
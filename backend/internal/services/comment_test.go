@@ -0,0 +1,67 @@
+package services
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentService(t *testing.T) {
+	service := NewCommentService()
+
+	t.Run("單例模式測試", func(t *testing.T) {
+		assert.Same(t, service, NewCommentService(), "應該返回相同的實例")
+	})
+
+	t.Run("BuildTree", func(t *testing.T) {
+		newComment := func(parentID *uuid.UUID) models.Comment {
+			return models.Comment{
+				TableModel:  models.TableModel{ID: uuid.New()},
+				CommentBase: models.CommentBase{ParentID: parentID},
+			}
+		}
+		root := newComment(nil)
+		reply := newComment(&root.ID)
+		replyOfReply := newComment(&reply.ID)
+		missingParentID := uuid.New()
+		orphan := newComment(&missingParentID)
+
+		t.Run("任意深度", func(t *testing.T) {
+			roots := service.BuildTree([]models.Comment{root, reply, replyOfReply})
+			if assert.Len(t, roots, 1) {
+				assert.Equal(t, root.ID, roots[0].Comment.ID)
+				assert.Equal(t, reply.ID, roots[0].Children[0].Comment.ID)
+				node := roots[0].Children[0].Children[0]
+				assert.Equal(t, replyOfReply.ID, node.Comment.ID)
+				assert.Equal(t, uint(2), node.Depth)
+				assert.Equal(t, []uuid.UUID{root.ID, reply.ID, replyOfReply.ID}, node.Path)
+			}
+		})
+
+		t.Run("子評論先於父評論", func(t *testing.T) {
+			roots := service.BuildTree([]models.Comment{replyOfReply, reply, root})
+			assert.Len(t, roots, 1)
+			assert.Len(t, service.Flatten(roots), 3)
+		})
+
+		t.Run("父評論不存在視為根評論", func(t *testing.T) {
+			roots := service.BuildTree([]models.Comment{root, orphan})
+			if assert.Len(t, roots, 2) {
+				assert.Equal(t, orphan.ID, roots[1].Comment.ID)
+				assert.Equal(t, uint(0), roots[1].Depth)
+			}
+		})
+
+		t.Run("Flatten 深度優先", func(t *testing.T) {
+			root2 := newComment(nil)
+			nodes := service.Flatten(service.BuildTree([]models.Comment{root, root2, reply, replyOfReply}))
+			ids := make([]uuid.UUID, len(nodes))
+			for i, node := range nodes {
+				ids[i] = node.Comment.ID
+			}
+			assert.Equal(t, []uuid.UUID{root.ID, reply.ID, replyOfReply.ID, root2.ID}, ids)
+		})
+	})
+}
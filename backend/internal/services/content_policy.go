@@ -0,0 +1,74 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/repositories"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var ErrContentRejected = errors.New("content violates the content policy")
+
+// ContentPolicyService 在貼文與評論保存前套用內容政策，違反處置為 review 的規則的內容保存為隱藏並送入檢舉佇列等待審核
+type ContentPolicyService struct {
+	ReportRepository *repositories.ReportRepository
+
+	mutex  sync.RWMutex
+	filter *pkg.ContentFilter
+}
+
+var contentPolicyServiceOnce sync.Once
+var contentPolicyService *ContentPolicyService
+
+func NewContentPolicyService() *ContentPolicyService {
+	contentPolicyServiceOnce.Do(func() {
+		contentPolicyService = &ContentPolicyService{
+			ReportRepository: repositories.NewReportRepository(),
+
+			filter: &pkg.ContentFilter{},
+		}
+	})
+	return contentPolicyService
+}
+
+// SetFilter 替換內容政策 (預設不套用任何規則)
+func (s *ContentPolicyService) SetFilter(filter *pkg.ContentFilter) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.filter = filter
+}
+
+func (s *ContentPolicyService) getFilter() *pkg.ContentFilter {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.filter
+}
+
+// Check 回傳套用內容政策的結果，違反處置為 reject 的規則時回傳包含規則名稱的 ErrContentRejected
+func (s *ContentPolicyService) Check(content string) (*pkg.ContentFilterResult, error) {
+	result := s.getFilter().Apply(content)
+	if result.Action == pkg.ContentFilterActionReject {
+		return nil, fmt.Errorf("%w: %s", ErrContentRejected, strings.Join(result.Violations, ", "))
+	}
+	return result, nil
+}
+
+// HoldForReview 以系統的名義檢舉等待審核的內容，需在交易中呼叫
+func (s *ContentPolicyService) HoldForReview(ctx *gin.Context, targetType models.ReportTargetType, targetID uuid.UUID, authorID uuid.UUID, content string, violations []string) error {
+	_, _, err := s.ReportRepository.Create(ctx, models.ReportBase{
+		TargetType:     targetType,
+		TargetID:       targetID,
+		TargetAuthorID: authorID,
+		TargetContent:  content,
+		Reason:         models.ReportReasonContentPolicy,
+		Detail:         strings.Join(violations, ", "),
+		Status:         models.ReportStatusPending,
+	})
+	return err
+}
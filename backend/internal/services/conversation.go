@@ -0,0 +1,276 @@
+package services
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/repositories"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrConversationNotFound            = errors.New("conversation not found")
+	ErrConversationUserNotFound        = errors.New("user not found")
+	ErrConversationNoParticipants      = errors.New("conversation requires at least one other participant")
+	ErrConversationTooManyParticipants = errors.New("too many participants")
+	ErrConversationBlocked             = errors.New("cannot message a blocked user")
+)
+
+type ConversationService struct {
+	ErrorUtils *pkg.ErrorUtils
+
+	ConversationRepository *repositories.ConversationRepository
+	MessageRepository      *repositories.MessageRepository
+	UserRepository         *repositories.UserRepository
+	BlockRepository        *repositories.BlockRepository
+
+	EventService *EventService
+}
+
+var conversationServiceOnce sync.Once
+var conversationService *ConversationService
+
+func NewConversationService() *ConversationService {
+	conversationServiceOnce.Do(func() {
+		conversationService = &ConversationService{
+			ErrorUtils: pkg.NewErrorUtils(),
+
+			ConversationRepository: repositories.NewConversationRepository(),
+			MessageRepository:      repositories.NewMessageRepository(),
+			UserRepository:         repositories.NewUserRepository(),
+			BlockRepository:        repositories.NewBlockRepository(),
+
+			EventService: NewEventService(),
+		}
+	})
+	return conversationService
+}
+
+// Create 建立 creatorID 與 participantIDs 的對話，participantIDs 只有一位時為一對一對話，
+// 兩人之間已有一對一對話時回傳既有的對話 (created 為 false)
+func (s *ConversationService) Create(ctx *gin.Context, creatorID uuid.UUID, participantIDs []uuid.UUID, title string) (conversation *models.Conversation, created bool, err error) {
+	otherUserIDs := []uuid.UUID{}
+	for _, participantID := range participantIDs {
+		if participantID != creatorID && !slices.Contains(otherUserIDs, participantID) {
+			otherUserIDs = append(otherUserIDs, participantID)
+		}
+	}
+	if len(otherUserIDs) == 0 {
+		return nil, false, ErrConversationNoParticipants
+	}
+	if len(otherUserIDs)+1 > models.CONVERSATION_MAX_PARTICIPANTS {
+		return nil, false, ErrConversationTooManyParticipants
+	}
+	for _, userID := range otherUserIDs {
+		if _, err := s.UserRepository.GetByID(ctx, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, false, ErrConversationUserNotFound
+			}
+			return nil, false, s.ErrorUtils.ServerInternalError(err.Error())
+		}
+	}
+	blocked, err := s.BlockRepository.ExistsBetween(ctx, creatorID, otherUserIDs)
+	if err != nil {
+		return nil, false, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	if blocked {
+		return nil, false, ErrConversationBlocked
+	}
+
+	isGroup := len(otherUserIDs) > 1
+	conversationBase := models.ConversationBase{
+		IsGroup: isGroup,
+		Title:   strings.TrimSpace(title),
+	}
+	if !isGroup {
+		// 一對一對話沒有標題
+		conversationBase.Title = ""
+		conversationBase.DirectKey = pkg.GetPointer(models.ConversationDirectKey(creatorID, otherUserIDs[0]))
+	}
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		newConversation, isNew, err := s.ConversationRepository.Create(ctx, conversationBase, append([]uuid.UUID{creatorID}, otherUserIDs...))
+		if err != nil {
+			return err
+		}
+		// 重新查詢以取得參與者的使用者資料
+		conversation, err = s.ConversationRepository.GetByID(ctx, newConversation.ID)
+		created = isNew
+		return err
+	}); err != nil {
+		return nil, false, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return conversation, created, nil
+}
+
+// getAsParticipant 取得 userID 參與的對話，不存在或未參與時回傳 ErrConversationNotFound
+func (s *ConversationService) getAsParticipant(ctx *gin.Context, userID uuid.UUID, conversationID uuid.UUID) (*models.Conversation, error) {
+	conversation, err := s.ConversationRepository.GetByID(ctx, conversationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConversationNotFound
+		}
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	if !slices.ContainsFunc(conversation.Participants, func(participant models.ConversationParticipant) bool {
+		return participant.UserID == userID
+	}) {
+		return nil, ErrConversationNotFound
+	}
+	return conversation, nil
+}
+
+// getOtherUserIDs 回傳對話中 userID 以外的參與者
+func getOtherUserIDs(conversation *models.Conversation, userID uuid.UUID) []uuid.UUID {
+	otherUserIDs := make([]uuid.UUID, 0, len(conversation.Participants))
+	for _, participant := range conversation.Participants {
+		if participant.UserID != userID {
+			otherUserIDs = append(otherUserIDs, participant.UserID)
+		}
+	}
+	return otherUserIDs
+}
+
+// getParticipantUserIDs 回傳對話所有參與者
+func getParticipantUserIDs(conversation *models.Conversation) []uuid.UUID {
+	userIDs := make([]uuid.UUID, len(conversation.Participants))
+	for i, participant := range conversation.Participants {
+		userIDs[i] = participant.UserID
+	}
+	return userIDs
+}
+
+// GetSummaries 回傳使用者的對話列表 (包含最後一則訊息與未讀數)，依最後活動時間由新到舊排序
+func (s *ConversationService) GetSummaries(ctx *gin.Context, userID uuid.UUID, pagination *models.Pagination) ([]models.ConversationSummary, uint, error) {
+	conversations, totalCount, err := s.ConversationRepository.GetListByUserID(ctx, userID, pagination)
+	if err != nil {
+		return nil, 0, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	summaries, err := s.toSummaries(ctx, userID, conversations)
+	if err != nil {
+		return nil, 0, err
+	}
+	return summaries, totalCount, nil
+}
+
+// GetSummary 回傳使用者參與的對話，不存在或未參與時回傳 ErrConversationNotFound
+func (s *ConversationService) GetSummary(ctx *gin.Context, userID uuid.UUID, conversationID uuid.UUID) (*models.ConversationSummary, error) {
+	conversation, err := s.getAsParticipant(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := s.toSummaries(ctx, userID, []models.Conversation{*conversation})
+	if err != nil {
+		return nil, err
+	}
+	return &summaries[0], nil
+}
+
+func (s *ConversationService) toSummaries(ctx *gin.Context, userID uuid.UUID, conversations []models.Conversation) ([]models.ConversationSummary, error) {
+	conversationIDs := make([]uuid.UUID, len(conversations))
+	for i, conversation := range conversations {
+		conversationIDs[i] = conversation.ID
+	}
+	latestMessages, err := s.MessageRepository.GetLatestByConversationIDs(ctx, conversationIDs)
+	if err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	unreadCounts, err := s.MessageRepository.CountUnreadByConversationIDs(ctx, userID, conversationIDs)
+	if err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+
+	summaries := make([]models.ConversationSummary, len(conversations))
+	for i, conversation := range conversations {
+		summaries[i] = models.ConversationSummary{
+			Conversation: conversation,
+			UnreadCount:  unreadCounts[conversation.ID],
+		}
+		if latestMessage, ok := latestMessages[conversation.ID]; ok {
+			summaries[i].LastMessage = &latestMessage
+		}
+	}
+	return summaries, nil
+}
+
+// GetMessagesByCursor 依 (created_at, id) 由新到舊分頁對話的訊息，未參與對話時回傳 ErrConversationNotFound
+func (s *ConversationService) GetMessagesByCursor(ctx *gin.Context, userID uuid.UUID, conversationID uuid.UUID, pagination *models.CursorPagination) ([]models.Message, *models.Cursor, *uint, error) {
+	if _, err := s.getAsParticipant(ctx, userID, conversationID); err != nil {
+		return nil, nil, nil, err
+	}
+	messages, nextCursor, totalCount, err := s.MessageRepository.GetListByConversationIDByCursor(ctx, conversationID, pagination)
+	if err != nil {
+		return nil, nil, nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return messages, nextCursor, totalCount, nil
+}
+
+// SendMessage 傳送訊息並推送給對話的參與者，傳送者的已讀位置移到此訊息，
+// 傳送者與任一參與者之間有封鎖關係時回傳 ErrConversationBlocked
+func (s *ConversationService) SendMessage(ctx *gin.Context, senderID uuid.UUID, conversationID uuid.UUID, content string) (*models.Message, error) {
+	conversation, err := s.getAsParticipant(ctx, senderID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.BlockRepository.ExistsBetween(ctx, senderID, getOtherUserIDs(conversation, senderID))
+	if err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	if blocked {
+		return nil, ErrConversationBlocked
+	}
+
+	var message *models.Message
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		var err error
+		message, err = s.MessageRepository.Create(ctx, models.MessageBase{
+			ConversationID: conversation.ID,
+			SenderID:       senderID,
+			Content:        content,
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.ConversationRepository.UpdateLastMessageAt(ctx, conversation.ID, message.CreatedAt); err != nil {
+			return err
+		}
+		if _, err := s.ConversationRepository.UpdateLastRead(ctx, conversation.ID, senderID, message); err != nil {
+			return err
+		}
+		s.EventService.PublishMessage(ctx, getParticipantUserIDs(conversation), message)
+		return nil
+	}); err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return message, nil
+}
+
+// MarkRead 將使用者的已讀位置移到對話最新的訊息並推送已讀回條給其他參與者，
+// 回傳已讀的最後一則訊息 (對話沒有訊息時為 nil)
+func (s *ConversationService) MarkRead(ctx *gin.Context, userID uuid.UUID, conversationID uuid.UUID) (*uuid.UUID, error) {
+	conversation, err := s.getAsParticipant(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	latestMessage, err := s.MessageRepository.GetLatestByConversationID(ctx, conversation.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	updated, err := s.ConversationRepository.UpdateLastRead(ctx, conversation.ID, userID, latestMessage)
+	if err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	if updated {
+		s.EventService.PublishMessageRead(ctx, getOtherUserIDs(conversation, userID), userID, latestMessage)
+	}
+	return &latestMessage.ID, nil
+}
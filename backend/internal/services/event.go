@@ -0,0 +1,150 @@
+package services
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type EventService struct {
+	mutex  sync.RWMutex
+	broker pkg.EventBroker
+}
+
+var eventServiceOnce sync.Once
+var eventService *EventService
+
+func NewEventService() *EventService {
+	eventServiceOnce.Do(func() {
+		eventService = &EventService{
+			broker: pkg.NewMemoryEventBroker(),
+		}
+	})
+	return eventService
+}
+
+// SetBroker 替換發布/訂閱的後端 (例如多個實例時使用跨實例的實作)，需在開始訂閱前呼叫
+func (s *EventService) SetBroker(broker pkg.EventBroker) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.broker = broker
+}
+
+func (s *EventService) getBroker() pkg.EventBroker {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.broker
+}
+
+// UserEventTopic 使用者的通知與私訊
+func UserEventTopic(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
+
+// PostEventTopic 貼文的評論與喜歡數
+func PostEventTopic(postID uuid.UUID) string {
+	return "post:" + postID.String()
+}
+
+// Publish 在目前的交易提交後發布事件 (不在交易中時立即發布)，發布失敗只記錄錯誤，不影響請求
+func (s *EventService) Publish(ctx *gin.Context, topic string, event models.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to marshal event %s: %v\n", event.Type, err)
+		return
+	}
+	middlewares.AfterCommit(ctx, func() {
+		if err := s.getBroker().Publish(topic, payload); err != nil {
+			log.Printf("Failed to publish event %s to %s: %v\n", event.Type, topic, err)
+		}
+	})
+}
+
+func (s *EventService) PublishNotifications(ctx *gin.Context, notifications []models.Notification) {
+	for _, notification := range notifications {
+		s.Publish(ctx, UserEventTopic(notification.UserID), models.Event{
+			Type: models.EventTypeNotification,
+			Data: models.EventNotificationData{
+				ID:        notification.ID,
+				Type:      notification.Type,
+				ActorID:   notification.ActorID,
+				PostID:    notification.PostID,
+				CommentID: notification.CommentID,
+				CreatedAt: time.Unix(notification.CreatedAt, 0).Format(time.RFC3339),
+			},
+		})
+	}
+}
+
+func (s *EventService) PublishComments(ctx *gin.Context, comments []models.Comment) {
+	for _, comment := range comments {
+		s.Publish(ctx, PostEventTopic(comment.PostID), models.Event{
+			Type: models.EventTypeComment,
+			Data: models.EventCommentData{
+				ID:        comment.ID,
+				PostID:    comment.PostID,
+				ParentID:  comment.ParentID,
+				UserID:    comment.UserID,
+				Content:   comment.Content,
+				CreatedAt: time.Unix(comment.CreatedAt, 0).Format(time.RFC3339),
+			},
+		})
+	}
+}
+
+func (s *EventService) PublishLikeCount(ctx *gin.Context, postID uuid.UUID, likeCount uint) {
+	s.Publish(ctx, PostEventTopic(postID), models.Event{
+		Type: models.EventTypeLikeCount,
+		Data: models.EventLikeCountData{
+			PostID:    postID,
+			LikeCount: likeCount,
+		},
+	})
+}
+
+// PublishMessage 推送新的訊息給對話的參與者 (包含傳送者，讓其他裝置同步)
+func (s *EventService) PublishMessage(ctx *gin.Context, userIDs []uuid.UUID, message *models.Message) {
+	for _, userID := range userIDs {
+		s.Publish(ctx, UserEventTopic(userID), models.Event{
+			Type: models.EventTypeMessage,
+			Data: models.EventMessageData{
+				ID:             message.ID,
+				ConversationID: message.ConversationID,
+				SenderID:       message.SenderID,
+				Content:        message.Content,
+				CreatedAt:      time.Unix(message.CreatedAt, 0).Format(time.RFC3339),
+			},
+		})
+	}
+}
+
+// PublishMessageRead 推送 readerID 的已讀位置給對話的參與者
+func (s *EventService) PublishMessageRead(ctx *gin.Context, userIDs []uuid.UUID, readerID uuid.UUID, message *models.Message) {
+	for _, userID := range userIDs {
+		s.Publish(ctx, UserEventTopic(userID), models.Event{
+			Type: models.EventTypeMessageRead,
+			Data: models.EventMessageReadData{
+				ConversationID:    message.ConversationID,
+				UserID:            readerID,
+				LastReadMessageID: message.ID,
+			},
+		})
+	}
+}
+
+// Subscribe 訂閱使用者的通知、私訊與 postIDs 的評論與喜歡數，回傳的 channel 在 ctx 結束後關閉
+func (s *EventService) Subscribe(ctx context.Context, userID uuid.UUID, postIDs []uuid.UUID) (<-chan pkg.EventMessage, error) {
+	topics := []string{UserEventTopic(userID)}
+	for _, postID := range postIDs {
+		topics = append(topics, PostEventTopic(postID))
+	}
+	return s.getBroker().Subscribe(ctx, topics)
+}
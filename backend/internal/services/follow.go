@@ -0,0 +1,93 @@
+package services
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/repositories"
+	"errors"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var ErrFollowSelf = errors.New("cannot follow yourself")
+var ErrFollowBlocked = errors.New("cannot follow a user you have blocked or who has blocked you")
+
+type FollowService struct {
+	ErrorUtils *pkg.ErrorUtils
+
+	FollowRepository *repositories.FollowRepository
+	BlockRepository  *repositories.BlockRepository
+
+	NotificationService *NotificationService
+}
+
+var followServiceOnce sync.Once
+var followService *FollowService
+
+func NewFollowService() *FollowService {
+	followServiceOnce.Do(func() {
+		followService = &FollowService{
+			ErrorUtils: pkg.NewErrorUtils(),
+
+			FollowRepository: repositories.NewFollowRepository(),
+			BlockRepository:  repositories.NewBlockRepository(),
+
+			NotificationService: NewNotificationService(),
+		}
+	})
+	return followService
+}
+
+// Follow 追蹤使用者並通知被追蹤的使用者，重複追蹤不會產生錯誤也不會重複通知，
+// 雙方之間有封鎖關係時回傳 ErrFollowBlocked
+func (s *FollowService) Follow(ctx *gin.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	if followerID == followeeID {
+		return ErrFollowSelf
+	}
+	blocked, err := s.BlockRepository.ExistsBetween(ctx, followerID, []uuid.UUID{followeeID})
+	if err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	if blocked {
+		return ErrFollowBlocked
+	}
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		followed, err := s.FollowRepository.Follow(ctx, followerID, followeeID)
+		if err != nil || !followed {
+			return err
+		}
+		return s.NotificationService.NotifyFollow(ctx, followeeID, followerID)
+	}); err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return nil
+}
+
+// Unfollow 取消追蹤使用者並移除追蹤的通知，未追蹤時不會產生錯誤
+func (s *FollowService) Unfollow(ctx *gin.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		unfollowed, err := s.FollowRepository.Unfollow(ctx, followerID, followeeID)
+		if err != nil || !unfollowed {
+			return err
+		}
+		return s.NotificationService.RemoveFollow(ctx, followeeID, followerID)
+	}); err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return nil
+}
+
+func (s *FollowService) IsFollowing(ctx *gin.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	return s.FollowRepository.IsFollowing(ctx, followerID, followeeID)
+}
+
+func (s *FollowService) GetFollowers(ctx *gin.Context, userID uuid.UUID, pagination *models.Pagination) ([]models.User, uint, error) {
+	return s.FollowRepository.GetFollowers(ctx, userID, pagination)
+}
+
+func (s *FollowService) GetFollowing(ctx *gin.Context, userID uuid.UUID, pagination *models.Pagination) ([]models.User, uint, error) {
+	return s.FollowRepository.GetFollowing(ctx, userID, pagination)
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/repositories"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MentionService struct {
+	MentionUtils *pkg.MentionUtils
+
+	MentionRepository      *repositories.MentionRepository
+	NotificationRepository *repositories.NotificationRepository
+	UserRepository         *repositories.UserRepository
+	BlockRepository        *repositories.BlockRepository
+
+	NotificationService *NotificationService
+}
+
+var mentionServiceOnce sync.Once
+var mentionService *MentionService
+
+func NewMentionService() *MentionService {
+	mentionServiceOnce.Do(func() {
+		mentionService = &MentionService{
+			MentionUtils: pkg.NewMentionUtils(),
+
+			MentionRepository:      repositories.NewMentionRepository(),
+			NotificationRepository: repositories.NewNotificationRepository(),
+			UserRepository:         repositories.NewUserRepository(),
+			BlockRepository:        repositories.NewBlockRepository(),
+
+			NotificationService: NewNotificationService(),
+		}
+	})
+	return mentionService
+}
+
+// SyncPostMentions 依貼文內容重新建立提及，通知新被提及的使用者並移除不再被提及的通知，需在交易中呼叫
+func (s *MentionService) SyncPostMentions(ctx *gin.Context, actorID uuid.UUID, postID uuid.UUID, content string) ([]models.Mention, error) {
+	oldUserIDs, err := s.MentionRepository.GetUserIDsByPostID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.MentionRepository.DeleteByPostID(ctx, postID); err != nil {
+		return nil, err
+	}
+	return s.sync(ctx, actorID, postID, nil, content, oldUserIDs)
+}
+
+// SyncCommentMentions 依評論內容重新建立提及 (content 為空時清除)，通知的 PostID 為評論所屬的貼文，需在交易中呼叫
+func (s *MentionService) SyncCommentMentions(ctx *gin.Context, actorID uuid.UUID, postID uuid.UUID, commentID uuid.UUID, content string) ([]models.Mention, error) {
+	oldUserIDs, err := s.MentionRepository.GetUserIDsByCommentID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.MentionRepository.DeleteByCommentID(ctx, commentID); err != nil {
+		return nil, err
+	}
+	return s.sync(ctx, actorID, postID, &commentID, content, oldUserIDs)
+}
+
+func (s *MentionService) sync(ctx *gin.Context, actorID uuid.UUID, postID uuid.UUID, commentID *uuid.UUID, content string, oldUserIDs []uuid.UUID) ([]models.Mention, error) {
+	matches := s.MentionUtils.Parse(content)
+	matches = matches[:min(len(matches), models.MENTION_MAX_PER_CONTENT)]
+
+	// 使用者名稱不存在或與 actorID 之間有封鎖關係時視為一般文字
+	users := map[string]*models.User{}
+	mentionBases := []models.MentionBase{}
+	for _, match := range matches {
+		user, resolved := users[match.Username]
+		if !resolved {
+			var err error
+			user, err = s.UserRepository.GetByUsername(ctx, match.Username)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+			if user != nil {
+				blocked, err := s.BlockRepository.ExistsBetween(ctx, actorID, []uuid.UUID{user.ID})
+				if err != nil {
+					return nil, err
+				}
+				if blocked {
+					user = nil
+				}
+			}
+			users[match.Username] = user
+		}
+		if user == nil {
+			continue
+		}
+		mentionBase := models.MentionBase{
+			UserID:      user.ID,
+			User:        user,
+			StartOffset: match.Start,
+			EndOffset:   match.End,
+		}
+		if commentID == nil {
+			mentionBase.PostID = &postID
+		} else {
+			mentionBase.CommentID = commentID
+		}
+		mentionBases = append(mentionBases, mentionBase)
+	}
+	mentions, err := s.MentionRepository.Create(ctx, mentionBases)
+	if err != nil {
+		return nil, err
+	}
+
+	newUserIDs := []uuid.UUID{}
+	notificationBases := []models.NotificationBase{}
+	for _, mention := range mentions {
+		if slices.Contains(newUserIDs, mention.UserID) {
+			continue
+		}
+		newUserIDs = append(newUserIDs, mention.UserID)
+		if mention.UserID == actorID || slices.Contains(oldUserIDs, mention.UserID) {
+			continue
+		}
+		notificationBases = append(notificationBases, models.NotificationBase{
+			UserID:    mention.UserID,
+			ActorID:   actorID,
+			Type:      models.NotificationTypeMention,
+			PostID:    &postID,
+			CommentID: commentID,
+		})
+	}
+	if _, err := s.NotificationService.Create(ctx, notificationBases); err != nil {
+		return nil, err
+	}
+
+	removedUserIDs := []uuid.UUID{}
+	for _, userID := range oldUserIDs {
+		if !slices.Contains(newUserIDs, userID) {
+			removedUserIDs = append(removedUserIDs, userID)
+		}
+	}
+	if err := s.NotificationRepository.DeleteMentions(ctx, postID, commentID, removedUserIDs); err != nil {
+		return nil, err
+	}
+	return mentions, nil
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/tests"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMentionService(t *testing.T) {
+	service := NewMentionService()
+	postService := NewPostService()
+	commentService := NewCommentService()
+	ctx, db, cleanup := tests.SetupTestContext("test_mention_service.db")
+	defer cleanup()
+
+	createUser := func(username string) *models.User {
+		user := &models.User{
+			TableModel: models.TableModel{ID: uuid.New()},
+			UserBase: models.UserBase{
+				Username:       username,
+				Email:          username + "@example.com",
+				HashedPassword: "hashed",
+				Role:           models.RoleNormalCustomer,
+			},
+		}
+		require.NoError(t, db.Create(user).Error)
+		return user
+	}
+	author := createUser("author")
+	alice := createUser("alice")
+	bob := createUser("bob")
+
+	countNotifications := func(userID uuid.UUID, postID uuid.UUID) int64 {
+		count := int64(0)
+		require.NoError(t, db.Model(&models.Notification{}).
+			Where("user_id = ? AND post_id = ? AND type = ?", userID, postID, models.NotificationTypeMention).
+			Count(&count).Error)
+		return count
+	}
+
+	t.Run("單例模式測試", func(t *testing.T) {
+		assert.Same(t, service, NewMentionService(), "應該返回相同的實例")
+	})
+
+	t.Run("貼文提及", func(t *testing.T) {
+		post, err := postService.CreatePostWithTags(ctx, models.PostBase{
+			AuthorID: author.ID,
+			Content:  "嗨 @alice 與 @bob，@alice 還有 @nobody 和 @author",
+		}, nil)
+		require.NoError(t, err)
+
+		if assert.Len(t, post.Mentions, 4, "不存在的使用者不是提及") {
+			assert.Equal(t, alice.ID, post.Mentions[0].UserID)
+			assert.Equal(t, 2, post.Mentions[0].StartOffset)
+			assert.Equal(t, 8, post.Mentions[0].EndOffset)
+			assert.Equal(t, "bob", post.Mentions[1].User.Username)
+			assert.Equal(t, author.ID, post.Mentions[3].UserID)
+		}
+		assert.Equal(t, int64(1), countNotifications(alice.ID, post.ID), "重複提及只通知一次")
+		assert.Equal(t, int64(1), countNotifications(bob.ID, post.ID))
+		assert.Equal(t, int64(0), countNotifications(author.ID, post.ID), "提及自己不通知")
+
+		t.Run("編輯後重新同步", func(t *testing.T) {
+			updated, err := postService.UpdatePostWithTags(ctx, post.ID, nil, "只剩 @alice", nil)
+			require.NoError(t, err)
+			if assert.Len(t, updated.Mentions, 1) {
+				assert.Equal(t, alice.ID, updated.Mentions[0].UserID)
+				assert.Equal(t, 3, updated.Mentions[0].StartOffset)
+			}
+			assert.Equal(t, int64(1), countNotifications(alice.ID, post.ID), "已被提及的使用者不重複通知")
+			assert.Equal(t, int64(0), countNotifications(bob.ID, post.ID), "不再被提及時移除通知")
+		})
+
+		t.Run("刪除貼文", func(t *testing.T) {
+			require.NoError(t, postService.DeleteWithContent(ctx, post.ID))
+			count := int64(0)
+			require.NoError(t, db.Model(&models.Mention{}).Where("post_id = ?", post.ID).Count(&count).Error)
+			assert.Equal(t, int64(0), count)
+			assert.Equal(t, int64(0), countNotifications(alice.ID, post.ID))
+		})
+	})
+
+	t.Run("評論提及", func(t *testing.T) {
+		post, err := postService.CreatePostWithTags(ctx, models.PostBase{AuthorID: author.ID, Content: "comment mentions"}, nil)
+		require.NoError(t, err)
+		comments, err := commentService.Create(ctx, []models.CommentBase{{PostID: post.ID, UserID: alice.ID, Content: "@bob 看這裡"}})
+		require.NoError(t, err)
+		comment := comments[0]
+		if assert.Len(t, comment.Mentions, 1) {
+			assert.Equal(t, bob.ID, comment.Mentions[0].UserID)
+			assert.Equal(t, 0, comment.Mentions[0].StartOffset)
+			assert.Equal(t, 4, comment.Mentions[0].EndOffset)
+		}
+
+		notification := &models.Notification{}
+		require.NoError(t, db.Where("user_id = ? AND post_id = ?", bob.ID, post.ID).First(notification).Error)
+		assert.Equal(t, alice.ID, notification.ActorID)
+		if assert.NotNil(t, notification.CommentID) {
+			assert.Equal(t, comment.ID, *notification.CommentID)
+		}
+
+		t.Run("墓碑評論清除提及", func(t *testing.T) {
+			_, err := commentService.Create(ctx, []models.CommentBase{{PostID: post.ID, UserID: author.ID, Content: "reply", ParentID: &comment.ID}})
+			require.NoError(t, err)
+			tombstoned, err := commentService.Delete(ctx, &comment, alice.ID)
+			require.NoError(t, err)
+			assert.True(t, tombstoned)
+
+			deleted, err := commentService.GetByID(ctx, comment.ID)
+			require.NoError(t, err)
+			assert.Empty(t, deleted.Mentions)
+			assert.Equal(t, int64(0), countNotifications(bob.ID, post.ID))
+		})
+	})
+}
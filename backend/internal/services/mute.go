@@ -0,0 +1,57 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/repositories"
+	"errors"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var ErrMuteSelf = errors.New("cannot mute yourself")
+
+type MuteService struct {
+	ErrorUtils *pkg.ErrorUtils
+
+	MuteRepository *repositories.MuteRepository
+}
+
+var muteServiceOnce sync.Once
+var muteService *MuteService
+
+func NewMuteService() *MuteService {
+	muteServiceOnce.Do(func() {
+		muteService = &MuteService{
+			ErrorUtils: pkg.NewErrorUtils(),
+
+			MuteRepository: repositories.NewMuteRepository(),
+		}
+	})
+	return muteService
+}
+
+// Mute 靜音使用者，重複靜音不會產生錯誤
+func (s *MuteService) Mute(ctx *gin.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	if muterID == mutedID {
+		return ErrMuteSelf
+	}
+	if _, err := s.MuteRepository.Mute(ctx, muterID, mutedID); err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return nil
+}
+
+// Unmute 解除靜音，未靜音時不會產生錯誤
+func (s *MuteService) Unmute(ctx *gin.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	if _, err := s.MuteRepository.Unmute(ctx, muterID, mutedID); err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return nil
+}
+
+func (s *MuteService) GetMutedUsers(ctx *gin.Context, userID uuid.UUID, pagination *models.Pagination) ([]models.User, uint, error) {
+	return s.MuteRepository.GetMutedUsers(ctx, userID, pagination)
+}
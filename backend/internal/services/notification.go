@@ -0,0 +1,192 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/repositories"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NOTIFICATION_GROUP_KEY_FOLLOW 未讀的追蹤通知合併為一則
+const NOTIFICATION_GROUP_KEY_FOLLOW = "follow"
+
+type NotificationService struct {
+	ErrorUtils *pkg.ErrorUtils
+
+	NotificationRepository *repositories.NotificationRepository
+	PostRepository         *repositories.PostRepository
+	CommentRepository      *repositories.CommentRepository
+
+	EventService *EventService
+}
+
+var notificationServiceOnce sync.Once
+var notificationService *NotificationService
+
+func NewNotificationService() *NotificationService {
+	notificationServiceOnce.Do(func() {
+		notificationService = &NotificationService{
+			ErrorUtils: pkg.NewErrorUtils(),
+
+			NotificationRepository: repositories.NewNotificationRepository(),
+			PostRepository:         repositories.NewPostRepository(),
+			CommentRepository:      repositories.NewCommentRepository(),
+
+			EventService: NewEventService(),
+		}
+	})
+	return notificationService
+}
+
+// Create 建立通知並在交易提交後推送給收到通知的使用者
+func (s *NotificationService) Create(ctx *gin.Context, notificationBases []models.NotificationBase) ([]models.Notification, error) {
+	notifications, err := s.NotificationRepository.Create(ctx, notificationBases)
+	if err != nil {
+		return nil, err
+	}
+	s.EventService.PublishNotifications(ctx, notifications)
+	return notifications, nil
+}
+
+// NotifyComment 通知被回覆評論的作者 (reply) 與貼文作者 (comment)，同一使用者只通知一次，
+// 已在評論中被提及的使用者由提及通知，需在交易中呼叫
+func (s *NotificationService) NotifyComment(ctx *gin.Context, comment *models.Comment) error {
+	notified := []uuid.UUID{comment.UserID}
+	for _, mention := range comment.Mentions {
+		notified = append(notified, mention.UserID)
+	}
+
+	notificationBases := []models.NotificationBase{}
+	if comment.ParentID != nil {
+		parent, err := s.CommentRepository.GetByID(ctx, *comment.ParentID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(notified, parent.UserID) {
+			notified = append(notified, parent.UserID)
+			notificationBases = append(notificationBases, models.NotificationBase{
+				UserID:    parent.UserID,
+				ActorID:   comment.UserID,
+				Type:      models.NotificationTypeReply,
+				PostID:    &comment.PostID,
+				CommentID: &comment.ID,
+				GroupKey:  string(models.NotificationTypeReply) + ":" + parent.ID.String(),
+			})
+		}
+	}
+	post, err := s.PostRepository.GetByID(ctx, comment.PostID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(notified, post.AuthorID) {
+		notificationBases = append(notificationBases, models.NotificationBase{
+			UserID:    post.AuthorID,
+			ActorID:   comment.UserID,
+			Type:      models.NotificationTypeComment,
+			PostID:    &comment.PostID,
+			CommentID: &comment.ID,
+			GroupKey:  string(models.NotificationTypeComment) + ":" + post.ID.String(),
+		})
+	}
+	_, err = s.Create(ctx, notificationBases)
+	return err
+}
+
+// NotifyLike 通知貼文作者，同一篇貼文未讀的喜歡合併為一則，需在交易中呼叫
+func (s *NotificationService) NotifyLike(ctx *gin.Context, postID uuid.UUID, actorID uuid.UUID) error {
+	post, err := s.PostRepository.GetByID(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if post.AuthorID == actorID {
+		return nil
+	}
+	_, err = s.Create(ctx, []models.NotificationBase{{
+		UserID:   post.AuthorID,
+		ActorID:  actorID,
+		Type:     models.NotificationTypeLike,
+		PostID:   &postID,
+		GroupKey: string(models.NotificationTypeLike) + ":" + postID.String(),
+	}})
+	return err
+}
+
+// RemoveLike 取消喜歡時移除對應的通知，需在交易中呼叫
+func (s *NotificationService) RemoveLike(ctx *gin.Context, postID uuid.UUID, actorID uuid.UUID) error {
+	post, err := s.PostRepository.GetByID(ctx, postID)
+	if err != nil {
+		return err
+	}
+	return s.NotificationRepository.DeleteByActor(ctx, post.AuthorID, actorID, models.NotificationTypeLike, &postID)
+}
+
+// NotifyFollow 通知被追蹤的使用者，需在交易中呼叫
+func (s *NotificationService) NotifyFollow(ctx *gin.Context, followeeID uuid.UUID, followerID uuid.UUID) error {
+	_, err := s.Create(ctx, []models.NotificationBase{{
+		UserID:   followeeID,
+		ActorID:  followerID,
+		Type:     models.NotificationTypeFollow,
+		GroupKey: NOTIFICATION_GROUP_KEY_FOLLOW,
+	}})
+	return err
+}
+
+// RemoveFollow 取消追蹤時移除對應的通知，需在交易中呼叫
+func (s *NotificationService) RemoveFollow(ctx *gin.Context, followeeID uuid.UUID, followerID uuid.UUID) error {
+	return s.NotificationRepository.DeleteByActor(ctx, followeeID, followerID, models.NotificationTypeFollow, nil)
+}
+
+// GetGroups 回傳合併後的通知列表，由新到舊排序
+func (s *NotificationService) GetGroups(ctx *gin.Context, userID uuid.UUID, pagination *models.Pagination) ([]models.NotificationGroup, uint, error) {
+	groups, totalCount, err := s.NotificationRepository.GetGroupsByUserID(ctx, userID, pagination)
+	if err != nil {
+		return nil, 0, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return groups, totalCount, nil
+}
+
+// CountUnread 回傳未讀的通知數 (合併後)
+func (s *NotificationService) CountUnread(ctx *gin.Context, userID uuid.UUID) (uint, error) {
+	count, err := s.NotificationRepository.CountUnreadGroupsByUserID(ctx, userID)
+	if err != nil {
+		return 0, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return count, nil
+}
+
+// MarkRead 將通知與其合併的通知標記為已讀，回傳標記的通知數
+func (s *NotificationService) MarkRead(ctx *gin.Context, userID uuid.UUID, notificationID uuid.UUID) (uint, error) {
+	notification, err := s.NotificationRepository.GetByID(ctx, notificationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrNotificationNotFound
+		}
+		return 0, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	// 不透露其他使用者的通知是否存在
+	if notification.UserID != userID {
+		return 0, ErrNotificationNotFound
+	}
+	count, err := s.NotificationRepository.MarkReadByGroupKey(ctx, userID, notification.GroupKey)
+	if err != nil {
+		return 0, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return count, nil
+}
+
+// MarkAllRead 將使用者所有通知標記為已讀，回傳標記的通知數
+func (s *NotificationService) MarkAllRead(ctx *gin.Context, userID uuid.UUID) (uint, error) {
+	count, err := s.NotificationRepository.MarkAllReadByUserID(ctx, userID)
+	if err != nil {
+		return 0, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return count, nil
+}
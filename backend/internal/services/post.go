@@ -5,18 +5,34 @@ import (
 	"backend/internal/models"
 	"backend/internal/pkg"
 	"backend/internal/repositories"
+	"errors"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+// POST_SEARCH_SNIPPET_LENGTH 搜尋結果摘要的最大字數
+const POST_SEARCH_SNIPPET_LENGTH = 100
+
+var ErrPostLikeBlocked = errors.New("cannot like a post of a user you have blocked or who has blocked you")
+
 type PostService struct {
-	ErrorUtils *pkg.ErrorUtils
+	ErrorUtils  *pkg.ErrorUtils
+	SearchUtils *pkg.SearchUtils
 
-	PostRepository *repositories.PostRepository
+	PostRepository  *repositories.PostRepository
+	BlockRepository *repositories.BlockRepository
 
-	TagService *TagService
+	TagService           *TagService
+	MentionService       *MentionService
+	NotificationService  *NotificationService
+	EventService         *EventService
+	ContentPolicyService *ContentPolicyService
+	AIModerationService  *AIModerationService
 }
 
 var postServiceOnce sync.Once
@@ -25,11 +41,18 @@ var postService *PostService
 func NewPostService() *PostService {
 	postServiceOnce.Do(func() {
 		postService = &PostService{
-			ErrorUtils: pkg.NewErrorUtils(),
+			ErrorUtils:  pkg.NewErrorUtils(),
+			SearchUtils: pkg.NewSearchUtils(),
 
-			PostRepository: repositories.NewPostRepository(),
+			PostRepository:  repositories.NewPostRepository(),
+			BlockRepository: repositories.NewBlockRepository(),
 
-			TagService: NewTagService(),
+			TagService:           NewTagService(),
+			MentionService:       NewMentionService(),
+			NotificationService:  NewNotificationService(),
+			EventService:         NewEventService(),
+			ContentPolicyService: NewContentPolicyService(),
+			AIModerationService:  NewAIModerationService(),
 		}
 	})
 	return postService
@@ -43,7 +66,20 @@ func (s *PostService) Create(ctx *gin.Context, postBases []models.PostBase, tags
 	return s.PostRepository.Create(ctx, postBases, tags)
 }
 
+// CreatePostWithTags 套用內容政策後建立貼文，違反處置為 reject 的規則時回傳 ErrContentRejected，
+// 違反處置為 review 的規則時貼文保存為隱藏並送交審核 (審核通過後才同步提及)，其他貼文發布後於背景進行 AI 審核
 func (s *PostService) CreatePostWithTags(ctx *gin.Context, postBase models.PostBase, tagBases []models.TagBase) (*models.Post, error) {
+	result, err := s.ContentPolicyService.Check(postBase.Content)
+	if err != nil {
+		return nil, err
+	}
+	if result.Content != postBase.Content {
+		postBase.Content, tagBases = result.Content, dropMaskedTags(result.Content, tagBases)
+	}
+	held := result.Action == pkg.ContentFilterActionReview
+	if held {
+		postBase.HiddenAt = pkg.GetPointer(time.Now().Unix())
+	}
 
 	var postID uuid.UUID
 	if err := middlewares.TransactionGORMDB(ctx, func() error {
@@ -57,30 +93,272 @@ func (s *PostService) CreatePostWithTags(ctx *gin.Context, postBase models.PostB
 			return err
 		}
 		postID = posts[0].ID
+		if held {
+			return s.ContentPolicyService.HoldForReview(ctx, models.ReportTargetTypePost, postID, postBase.AuthorID, postBase.Content, result.Violations)
+		}
+		if _, err := s.MentionService.SyncPostMentions(ctx, postBase.AuthorID, postID, postBase.Content); err != nil {
+			return err
+		}
+		s.AIModerationService.ReviewAsync(ctx, models.ReportTargetTypePost, postID, postBase.AuthorID, postBase.Content)
 		return nil
 	}); err != nil {
 		return nil, s.ErrorUtils.ServerInternalError(err.Error())
 	}
 
-	post, err := s.PostRepository.GetByID(ctx, postID)
+	getByID := s.PostRepository.GetByID
+	if held {
+		getByID = s.PostRepository.GetHiddenByID
+	}
+	post, err := getByID(ctx, postID)
+	if err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return post, nil
+}
+
+// UpdatePostWithTags 套用內容政策後更新貼文內容並重新同步標籤與提及，不再使用的標籤會被刪除，
+// 內容政策的處置同 CreatePostWithTags，送交審核的貼文被隱藏並清除提及 (審核通過後才重新同步)
+func (s *PostService) UpdatePostWithTags(ctx *gin.Context, postID uuid.UUID, imageURL *string, content string, tagBases []models.TagBase) (*models.Post, error) {
+	result, err := s.ContentPolicyService.Check(content)
+	if err != nil {
+		return nil, err
+	}
+	if result.Content != content {
+		content, tagBases = result.Content, dropMaskedTags(result.Content, tagBases)
+	}
+	held := result.Action == pkg.ContentFilterActionReview
+
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		post, err := s.PostRepository.GetByID(ctx, postID)
+		if err != nil {
+			return err
+		}
+		oldTagIDs, err := s.PostRepository.GetTagIDsByPostIDs(ctx, []uuid.UUID{postID})
+		if err != nil {
+			return err
+		}
+		tags, err := s.TagService.CreateIfNotExist(ctx, tagBases)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]any{
+			"image_url": imageURL,
+			"content":   content,
+		}
+		if held {
+			updates["hidden_at"] = time.Now().Unix()
+		}
+		if err := s.PostRepository.UpdateByID(ctx, postID, updates); err != nil {
+			return err
+		}
+		if err := s.PostRepository.ReplaceTags(ctx, postID, tags); err != nil {
+			return err
+		}
+		if held {
+			if err := s.ContentPolicyService.HoldForReview(ctx, models.ReportTargetTypePost, postID, post.AuthorID, content, result.Violations); err != nil {
+				return err
+			}
+			s.TagService.InvalidateTrendingTags(ctx)
+			if _, err := s.MentionService.SyncPostMentions(ctx, post.AuthorID, postID, ""); err != nil {
+				return err
+			}
+		} else {
+			// 提及的通知由貼文作者發出
+			if _, err := s.MentionService.SyncPostMentions(ctx, post.AuthorID, postID, content); err != nil {
+				return err
+			}
+			s.AIModerationService.ReviewAsync(ctx, models.ReportTargetTypePost, postID, post.AuthorID, content)
+		}
+		return s.TagService.DeleteOrphansByIDs(ctx, oldTagIDs)
+	}); err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+
+	getByID := s.PostRepository.GetByID
+	if held {
+		getByID = s.PostRepository.GetHiddenByID
+	}
+	post, err := getByID(ctx, postID)
 	if err != nil {
 		return nil, s.ErrorUtils.ServerInternalError(err.Error())
 	}
 	return post, nil
 }
 
-func (s *PostService) GetPostsByAuthorID(ctx *gin.Context, AuthorID uuid.UUID, pagination *models.Pagination) ([]models.Post, uint, error) {
-	return s.PostRepository.GetPostsByAuthorID(ctx, AuthorID, pagination)
+// dropMaskedTags 移除內容被遮蔽後不再出現的標籤 (被遮蔽的標籤不建立)
+func dropMaskedTags(content string, tagBases []models.TagBase) []models.TagBase {
+	return slices.DeleteFunc(slices.Clone(tagBases), func(tagBase models.TagBase) bool {
+		return !strings.Contains(content, "#"+tagBase.Name)
+	})
 }
 
-func (s *PostService) LikedByUser(ctx *gin.Context, postID uuid.UUID, userID uuid.UUID) error {
-	return s.PostRepository.LikedByUser(ctx, postID, userID)
+// DeleteWithContent 刪除貼文以及其評論、喜歡與標籤關聯，不再使用的標籤會被刪除
+func (s *PostService) DeleteWithContent(ctx *gin.Context, postID uuid.UUID) error {
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		tagIDs, err := s.PostRepository.GetTagIDsByPostIDs(ctx, []uuid.UUID{postID})
+		if err != nil {
+			return err
+		}
+		if err := s.PostRepository.DeleteByIDs(ctx, []uuid.UUID{postID}); err != nil {
+			return err
+		}
+		return s.TagService.DeleteOrphansByIDs(ctx, tagIDs)
+	}); err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return nil
+}
+
+// GetPostsByAuthorID viewerID 為登入的使用者 (未登入時為 nil)，與作者之間有封鎖關係時回傳空列表
+func (s *PostService) GetPostsByAuthorID(ctx *gin.Context, AuthorID uuid.UUID, viewerID *uuid.UUID, pagination *models.Pagination) ([]models.Post, uint, error) {
+	return s.PostRepository.GetPostsByAuthorID(ctx, AuthorID, viewerID, pagination)
 }
 
-func (s *PostService) GetList(ctx *gin.Context, pagination *models.Pagination) ([]models.Post, uint, error) {
-	return s.PostRepository.GetList(ctx, pagination)
+func (s *PostService) GetPostsByAuthorIDByCursor(ctx *gin.Context, authorID uuid.UUID, viewerID *uuid.UUID, pagination *models.CursorPagination) ([]models.Post, *models.Cursor, *uint, error) {
+	return s.PostRepository.GetPostsByAuthorIDByCursor(ctx, authorID, viewerID, pagination)
 }
 
-func (s *PostService) GetListByKeywords(ctx *gin.Context, keywords []string, pagination *models.Pagination) ([]models.Post, uint, error) {
-	return s.PostRepository.GetListByKeywords(ctx, keywords, pagination)
+func (s *PostService) GetPostsByTagIDByCursor(ctx *gin.Context, tagID uuid.UUID, viewerID *uuid.UUID, pagination *models.CursorPagination) ([]models.Post, *models.Cursor, *uint, error) {
+	return s.PostRepository.GetPostsByTagIDByCursor(ctx, tagID, viewerID, pagination)
+}
+
+// LikedByUser 新增喜歡並同步更新貼文的喜歡計數與通知貼文作者，回傳是否為新增的喜歡，
+// 使用者與貼文作者之間有封鎖關係時回傳 ErrPostLikeBlocked
+func (s *PostService) LikedByUser(ctx *gin.Context, postID uuid.UUID, userID uuid.UUID) (bool, error) {
+	post, err := s.PostRepository.GetByID(ctx, postID)
+	if err != nil {
+		return false, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	blocked, err := s.BlockRepository.ExistsBetween(ctx, userID, []uuid.UUID{post.AuthorID})
+	if err != nil {
+		return false, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	if blocked {
+		return false, ErrPostLikeBlocked
+	}
+
+	var liked bool
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		var err error
+		liked, err = s.PostRepository.LikedByUser(ctx, postID, userID)
+		if err != nil || !liked {
+			return err
+		}
+		if err := s.PostRepository.IncrementLikeCount(ctx, postID, 1); err != nil {
+			return err
+		}
+		if err := s.publishLikeCount(ctx, postID); err != nil {
+			return err
+		}
+		return s.NotificationService.NotifyLike(ctx, postID, userID)
+	}); err != nil {
+		return false, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return liked, nil
+}
+
+// UnlikedByUser 移除喜歡並同步更新貼文的喜歡計數與移除喜歡的通知，回傳是否有移除喜歡
+func (s *PostService) UnlikedByUser(ctx *gin.Context, postID uuid.UUID, userID uuid.UUID) (bool, error) {
+	var unliked bool
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		var err error
+		unliked, err = s.PostRepository.UnlikedByUser(ctx, postID, userID)
+		if err != nil || !unliked {
+			return err
+		}
+		if err := s.PostRepository.IncrementLikeCount(ctx, postID, -1); err != nil {
+			return err
+		}
+		if err := s.publishLikeCount(ctx, postID); err != nil {
+			return err
+		}
+		return s.NotificationService.RemoveLike(ctx, postID, userID)
+	}); err != nil {
+		return false, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return unliked, nil
+}
+
+// publishLikeCount 在交易提交後推送貼文目前的喜歡數
+func (s *PostService) publishLikeCount(ctx *gin.Context, postID uuid.UUID) error {
+	likeCount, err := s.PostRepository.GetLikeCountByID(ctx, postID)
+	if err != nil {
+		return err
+	}
+	s.EventService.PublishLikeCount(ctx, postID, likeCount)
+	return nil
+}
+
+// ReconcileCounters 重新計算所有貼文的喜歡與評論計數
+func (s *PostService) ReconcileCounters(ctx *gin.Context) (uint, error) {
+	count, err := s.PostRepository.ReconcileCounters(ctx, nil)
+	if err != nil {
+		return 0, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return count, nil
+}
+
+func (s *PostService) GetLikedUsers(ctx *gin.Context, postID uuid.UUID, pagination *models.Pagination) ([]models.User, uint, error) {
+	return s.PostRepository.GetLikedUsers(ctx, postID, pagination)
+}
+
+// GetLikedPostIDs userID 為 nil (未登入) 時回傳空集合
+func (s *PostService) GetLikedPostIDs(ctx *gin.Context, userID *uuid.UUID, posts []models.Post) (map[uuid.UUID]bool, error) {
+	if userID == nil {
+		return map[uuid.UUID]bool{}, nil
+	}
+	postIDs := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+	return s.PostRepository.GetLikedPostIDs(ctx, *userID, postIDs)
+}
+
+// GetTimeline 回傳時間軸的一頁貼文，以及下一頁的位置 (沒有下一頁時為 nil)
+func (s *PostService) GetTimeline(ctx *gin.Context, userID uuid.UUID, pagination *models.CursorPagination) ([]models.Post, *models.Cursor, *uint, error) {
+	posts, nextCursor, totalCount, err := s.PostRepository.GetTimeline(ctx, userID, pagination)
+	if err != nil {
+		return nil, nil, nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return posts, nextCursor, totalCount, nil
+}
+
+// GetList viewerID 為登入的使用者 (未登入時為 nil)，排除與其有封鎖關係或被其靜音的作者的貼文
+func (s *PostService) GetList(ctx *gin.Context, viewerID *uuid.UUID, pagination *models.Pagination) ([]models.Post, uint, error) {
+	return s.PostRepository.GetList(ctx, viewerID, pagination)
+}
+
+func (s *PostService) GetListByCursor(ctx *gin.Context, viewerID *uuid.UUID, pagination *models.CursorPagination) ([]models.Post, *models.Cursor, *uint, error) {
+	return s.PostRepository.GetListByCursor(ctx, viewerID, pagination)
+}
+
+// Search 依搜尋條件搜尋貼文，結果附帶標示符合片段的摘要
+func (s *PostService) Search(ctx *gin.Context, filter *models.PostSearchFilter, sort models.PostSearchSort, pagination *models.Pagination) ([]models.PostSearchResult, uint, error) {
+	results, totalCount, err := s.PostRepository.Search(ctx, filter, sort, pagination)
+	if err != nil {
+		return nil, 0, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	s.setSearchSnippets(filter, results)
+	return results, totalCount, nil
+}
+
+// SearchByCursor 依搜尋條件搜尋貼文 (由新到舊)，結果附帶標示符合片段的摘要
+func (s *PostService) SearchByCursor(ctx *gin.Context, filter *models.PostSearchFilter, pagination *models.CursorPagination) ([]models.PostSearchResult, *models.Cursor, *uint, error) {
+	results, nextCursor, totalCount, err := s.PostRepository.SearchByCursor(ctx, filter, pagination)
+	if err != nil {
+		return nil, nil, nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	s.setSearchSnippets(filter, results)
+	return results, nextCursor, totalCount, nil
+}
+
+func (s *PostService) setSearchSnippets(filter *models.PostSearchFilter, results []models.PostSearchResult) {
+	segments := []string{}
+	for _, text := range filter.HighlightTexts() {
+		segments = append(segments, s.SearchUtils.Segments(text)...)
+	}
+	for i := range results {
+		results[i].Snippet = s.SearchUtils.Highlight(results[i].Post.Content, segments, POST_SEARCH_SNIPPET_LENGTH)
+	}
 }
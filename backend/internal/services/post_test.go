@@ -0,0 +1,221 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/tests"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostService(t *testing.T) {
+	service := NewPostService()
+	commentService := NewCommentService()
+	ctx, db, cleanup := tests.SetupTestContext("test_post_service.db")
+	defer cleanup()
+
+	createUser := func(username string) *models.User {
+		user := &models.User{
+			TableModel: models.TableModel{ID: uuid.New()},
+			UserBase: models.UserBase{
+				Username:       username,
+				Email:          username + "@example.com",
+				HashedPassword: "hashed",
+				Role:           models.RoleNormalCustomer,
+			},
+		}
+		require.NoError(t, db.Create(user).Error)
+		return user
+	}
+	author := createUser("author")
+	liker := createUser("liker")
+
+	getPost := func(postID uuid.UUID) *models.Post {
+		post, err := service.GetByID(ctx, postID)
+		require.NoError(t, err)
+		return post
+	}
+
+	t.Run("單例模式測試", func(t *testing.T) {
+		assert.Same(t, service, NewPostService(), "應該返回相同的實例")
+	})
+
+	t.Run("喜歡計數", func(t *testing.T) {
+		post, err := service.CreatePostWithTags(ctx, models.PostBase{AuthorID: author.ID, Content: "like count"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint(0), post.LikeCount)
+
+		liked, err := service.LikedByUser(ctx, post.ID, liker.ID)
+		assert.NoError(t, err)
+		assert.True(t, liked)
+		liked, err = service.LikedByUser(ctx, post.ID, liker.ID)
+		assert.NoError(t, err)
+		assert.False(t, liked, "重複喜歡不應新增")
+		assert.Equal(t, uint(1), getPost(post.ID).LikeCount)
+
+		unliked, err := service.UnlikedByUser(ctx, post.ID, liker.ID)
+		assert.NoError(t, err)
+		assert.True(t, unliked)
+		unliked, err = service.UnlikedByUser(ctx, post.ID, liker.ID)
+		assert.NoError(t, err)
+		assert.False(t, unliked, "未喜歡時不應移除")
+		assert.Equal(t, uint(0), getPost(post.ID).LikeCount)
+	})
+
+	t.Run("評論計數", func(t *testing.T) {
+		post, err := service.CreatePostWithTags(ctx, models.PostBase{AuthorID: author.ID, Content: "comment count"}, nil)
+		require.NoError(t, err)
+
+		_, err = commentService.Create(ctx, []models.CommentBase{
+			{PostID: post.ID, UserID: liker.ID, Content: "first"},
+			{PostID: post.ID, UserID: author.ID, Content: "second"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), getPost(post.ID).CommentCount)
+	})
+
+	t.Run("ReconcileCounters", func(t *testing.T) {
+		post, err := service.CreatePostWithTags(ctx, models.PostBase{AuthorID: author.ID, Content: "reconcile"}, nil)
+		require.NoError(t, err)
+		_, err = service.LikedByUser(ctx, post.ID, liker.ID)
+		require.NoError(t, err)
+		comments, err := commentService.Create(ctx, []models.CommentBase{{PostID: post.ID, UserID: liker.ID, Content: "comment"}})
+		require.NoError(t, err)
+		hidden, err := commentService.Create(ctx, []models.CommentBase{{PostID: post.ID, UserID: liker.ID, Content: "hidden"}})
+		require.NoError(t, err)
+		_, err = commentService.Create(ctx, []models.CommentBase{
+			{PostID: post.ID, UserID: author.ID, Content: "reply", ParentID: &comments[0].ID},
+			{PostID: post.ID, UserID: author.ID, Content: "reply of hidden", ParentID: &hidden[0].ID},
+		})
+		require.NoError(t, err)
+		require.NoError(t, db.Model(&models.Comment{}).Where("id = ?", hidden[0].ID).Update("hidden_at", time.Now().Unix()).Error)
+
+		// 模擬計數與實際資料不一致
+		require.NoError(t, db.Model(&models.Post{}).Where("id = ?", post.ID).
+			UpdateColumns(map[string]any{"like_count": 10, "comment_count": 10}).Error)
+
+		count, err := service.ReconcileCounters(ctx)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, count, uint(1))
+		reconciled := getPost(post.ID)
+		assert.Equal(t, uint(1), reconciled.LikeCount)
+		assert.Equal(t, uint(2), reconciled.CommentCount, "被隱藏的評論連同其回覆不列入評論計數")
+	})
+
+	t.Run("全文搜尋", func(t *testing.T) {
+		createPost := func(content string) uuid.UUID {
+			post, err := service.CreatePostWithTags(ctx, models.PostBase{AuthorID: author.ID, Content: content}, nil)
+			require.NoError(t, err)
+			return post.ID
+		}
+		taipeiCityID := createPost("我住在台北市，喜歡 Golang")
+		taipeiID := createPost("台北 天氣很好")
+		kaohsiungID := createPost("高雄市 Golang meetup")
+		parseFilter := func(query string) *models.PostSearchFilter {
+			filter, err := models.ParsePostSearchQuery(query)
+			require.NoError(t, err)
+			return filter
+		}
+		search := func(query string) []models.PostSearchResult {
+			results, totalCount, err := service.Search(ctx, parseFilter(query), models.PostSearchSortRelevance, &models.Pagination{Limit: 10})
+			require.NoError(t, err)
+			assert.Equal(t, uint(len(results)), totalCount)
+			return results
+		}
+		resultIDs := func(results []models.PostSearchResult) []uuid.UUID {
+			postIDs := make([]uuid.UUID, len(results))
+			for i, result := range results {
+				postIDs[i] = result.Post.ID
+			}
+			return postIDs
+		}
+
+		t.Run("中文需相鄰出現", func(t *testing.T) {
+			results := search("台北市")
+			assert.Equal(t, []uuid.UUID{taipeiCityID}, resultIDs(results))
+			if assert.Len(t, results, 1) {
+				assert.Contains(t, results[0].Snippet, "<mark>台北市</mark>")
+				assert.Equal(t, "我住在台北市，喜歡 Golang", results[0].Post.Content)
+			}
+			assert.ElementsMatch(t, []uuid.UUID{taipeiCityID, taipeiID}, resultIDs(search("台北")))
+			assert.ElementsMatch(t, []uuid.UUID{taipeiCityID, taipeiID}, resultIDs(search("台")), "單一中文字以前綴比對")
+		})
+
+		t.Run("多個查詢詞皆需符合", func(t *testing.T) {
+			assert.Equal(t, []uuid.UUID{taipeiCityID}, resultIDs(search("GOLANG 台北")))
+			assert.ElementsMatch(t, []uuid.UUID{taipeiCityID, kaohsiungID}, resultIDs(search("golang")))
+			assert.Empty(t, search("golang 台中"))
+		})
+
+		t.Run("依相關度排序", func(t *testing.T) {
+			results := search("golang")
+			for i := 1; i < len(results); i++ {
+				assert.GreaterOrEqual(t, results[i-1].Rank, results[i].Rank)
+			}
+		})
+
+		t.Run("編輯與刪除後更新索引", func(t *testing.T) {
+			_, err := service.UpdatePostWithTags(ctx, taipeiID, nil, "台北市 天氣很好", nil)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []uuid.UUID{taipeiCityID, taipeiID}, resultIDs(search("台北市")))
+
+			require.NoError(t, service.DeleteWithContent(ctx, taipeiCityID))
+			assert.Equal(t, []uuid.UUID{taipeiID}, resultIDs(search("台北市")))
+		})
+
+		t.Run("cursor 分頁", func(t *testing.T) {
+			tutorialID := createPost("Golang 教學")
+			results, nextCursor, totalCount, err := service.SearchByCursor(ctx, parseFilter("golang"), &models.CursorPagination{Limit: 1, WithTotalCount: true})
+			require.NoError(t, err)
+			assert.Equal(t, []uuid.UUID{tutorialID}, resultIDs(results), "由新到舊")
+			if assert.NotNil(t, totalCount) {
+				assert.Equal(t, uint(2), *totalCount, "已刪除的貼文不應計入")
+			}
+			require.NotNil(t, nextCursor)
+			results, nextCursor, totalCount, err = service.SearchByCursor(ctx, parseFilter("golang"), &models.CursorPagination{Cursor: nextCursor, Limit: 1})
+			require.NoError(t, err)
+			assert.Equal(t, []uuid.UUID{kaohsiungID}, resultIDs(results))
+			assert.Nil(t, nextCursor)
+			assert.Nil(t, totalCount)
+		})
+
+		t.Run("搜尋語法", func(t *testing.T) {
+			tagged, err := service.CreatePostWithTags(ctx, models.PostBase{
+				AuthorID: liker.ID,
+				Content:  "Hello world #語法測試",
+				ImageURL: pkg.GetPointer("https://example.com/a.png"),
+			}, []models.TagBase{{Name: "語法測試"}})
+			require.NoError(t, err)
+			plain, err := service.CreatePostWithTags(ctx, models.PostBase{AuthorID: author.ID, Content: "world hello 語法"}, nil)
+			require.NoError(t, err)
+			require.NoError(t, db.Model(&models.Post{}).Where("id = ?", plain.ID).
+				UpdateColumn("created_at", time.Date(2026, 1, 31, 23, 0, 0, 0, time.UTC).Unix()).Error)
+
+			assert.ElementsMatch(t, []uuid.UUID{tagged.ID, plain.ID}, resultIDs(search("hello world")))
+			assert.Equal(t, []uuid.UUID{tagged.ID}, resultIDs(search(`"Hello, world"`)), "片語需依序相鄰出現")
+			assert.Equal(t, []uuid.UUID{plain.ID}, resultIDs(search(`hello -"hello world"`)))
+			assert.Equal(t, []uuid.UUID{tagged.ID}, resultIDs(search("#語法測試")))
+			assert.Equal(t, []uuid.UUID{plain.ID}, resultIDs(search("hello -#語法測試")))
+			assert.Equal(t, []uuid.UUID{tagged.ID}, resultIDs(search("hello author:liker")))
+			assert.Equal(t, []uuid.UUID{tagged.ID}, resultIDs(search("hello has:image")))
+			assert.Equal(t, []uuid.UUID{plain.ID}, resultIDs(search("hello -has:image")))
+			assert.Equal(t, []uuid.UUID{plain.ID}, resultIDs(search("hello from:2026-01-31 to:2026-01-31")), "日期包含當天")
+			assert.Empty(t, search("hello to:2026-01-30"))
+
+			filter := parseFilter("hello")
+			filter.AuthorID = &author.ID
+			results, _, err := service.Search(ctx, filter, models.PostSearchSortRecent, &models.Pagination{Limit: 10})
+			require.NoError(t, err)
+			assert.Equal(t, []uuid.UUID{plain.ID}, resultIDs(results))
+
+			results = search("#語法測試")
+			if assert.Len(t, results, 1) {
+				assert.Equal(t, "Hello world #<mark>語法測試</mark>", results[0].Snippet, "標籤也應標示於摘要")
+			}
+		})
+	})
+}
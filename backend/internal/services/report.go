@@ -0,0 +1,307 @@
+package services
+
+import (
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/pkg"
+	"backend/internal/repositories"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReportTargetNotFound = errors.New("content not found")
+	ErrReportOwnContent     = errors.New("cannot report your own content")
+	ErrReportNotFound       = errors.New("report not found")
+	ErrReportResolved       = errors.New("report already resolved")
+	ErrReportSuspendSelf    = errors.New("cannot suspend your own account")
+)
+
+type ReportService struct {
+	ErrorUtils *pkg.ErrorUtils
+
+	ReportRepository   *repositories.ReportRepository
+	AuditLogRepository *repositories.AuditLogRepository
+	PostRepository     *repositories.PostRepository
+	CommentRepository  *repositories.CommentRepository
+
+	PostService         *PostService
+	CommentService      *CommentService
+	TagService          *TagService
+	UserService         *UserService
+	MentionService      *MentionService
+	NotificationService *NotificationService
+	EventService        *EventService
+}
+
+var reportServiceOnce sync.Once
+var reportService *ReportService
+
+func NewReportService() *ReportService {
+	reportServiceOnce.Do(func() {
+		reportService = &ReportService{
+			ErrorUtils: pkg.NewErrorUtils(),
+
+			ReportRepository:   repositories.NewReportRepository(),
+			AuditLogRepository: repositories.NewAuditLogRepository(),
+			PostRepository:     repositories.NewPostRepository(),
+			CommentRepository:  repositories.NewCommentRepository(),
+
+			PostService:         NewPostService(),
+			CommentService:      NewCommentService(),
+			TagService:          NewTagService(),
+			UserService:         NewUserService(),
+			MentionService:      NewMentionService(),
+			NotificationService: NewNotificationService(),
+			EventService:        NewEventService(),
+		}
+	})
+	return reportService
+}
+
+// reportTarget 被檢舉的貼文或評論，兩者只有一個不為 nil，Hidden 為被隱藏 (被管理員隱藏或等待審核) 的內容，
+// HeldForReview 為因內容政策等待審核的內容 (被隱藏且有待處理的內容政策檢舉)
+type reportTarget struct {
+	Post          *models.Post
+	Comment       *models.Comment
+	Hidden        bool
+	HeldForReview bool
+}
+
+func (t *reportTarget) AuthorID() uuid.UUID {
+	if t.Post != nil {
+		return t.Post.AuthorID
+	}
+	return t.Comment.UserID
+}
+
+func (t *reportTarget) Content() string {
+	if t.Post != nil {
+		return t.Post.Content
+	}
+	return t.Comment.Content
+}
+
+// getTarget 不存在或已刪除 (墓碑評論) 的內容回傳 ErrReportTargetNotFound，includeHidden 為 false 時已隱藏的內容也視為不存在
+func (s *ReportService) getTarget(ctx *gin.Context, targetType models.ReportTargetType, targetID uuid.UUID, includeHidden bool) (*reportTarget, error) {
+	target := &reportTarget{}
+	var err error
+	switch targetType {
+	case models.ReportTargetTypePost:
+		target.Post, err = s.PostRepository.GetByID(ctx, targetID)
+		if errors.Is(err, gorm.ErrRecordNotFound) && includeHidden {
+			target.Post, err = s.PostRepository.GetHiddenByID(ctx, targetID)
+			target.Hidden = err == nil
+		}
+	case models.ReportTargetTypeComment:
+		target.Comment, err = s.CommentRepository.GetByID(ctx, targetID)
+		if errors.Is(err, gorm.ErrRecordNotFound) && includeHidden {
+			target.Comment, err = s.CommentRepository.GetHiddenByID(ctx, targetID)
+			target.Hidden = err == nil
+		}
+		if err == nil && target.Comment.IsDeleted() {
+			err = gorm.ErrRecordNotFound
+		}
+	default:
+		return nil, ErrReportTargetNotFound
+	}
+	if err == nil && target.Hidden {
+		target.HeldForReview, err = s.ReportRepository.HasPendingByTarget(ctx, targetType, targetID, models.ReportReasonContentPolicy)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportTargetNotFound
+		}
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return target, nil
+}
+
+// Create 檢舉貼文或評論，保存檢舉當下的作者與內容，已檢舉過同一內容時回傳既有的檢舉 (created 為 false)
+func (s *ReportService) Create(ctx *gin.Context, reporterID uuid.UUID, targetType models.ReportTargetType, targetID uuid.UUID, reason models.ReportReason, detail string) (report *models.Report, created bool, err error) {
+	target, err := s.getTarget(ctx, targetType, targetID, false)
+	if err != nil {
+		return nil, false, err
+	}
+	if target.AuthorID() == reporterID {
+		return nil, false, ErrReportOwnContent
+	}
+
+	report, created, err = s.ReportRepository.Create(ctx, models.ReportBase{
+		ReporterID:     &reporterID,
+		TargetType:     targetType,
+		TargetID:       targetID,
+		TargetAuthorID: target.AuthorID(),
+		TargetContent:  target.Content(),
+		Reason:         reason,
+		Detail:         detail,
+		Status:         models.ReportStatusPending,
+	})
+	if err != nil {
+		return nil, false, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return report, created, nil
+}
+
+// GetList 管理員的檢舉佇列，依建立時間由舊到新排序，status 為 nil 時不過濾狀態
+func (s *ReportService) GetList(ctx *gin.Context, status *models.ReportStatus, pagination *models.Pagination) ([]models.Report, uint, error) {
+	return s.ReportRepository.GetList(ctx, status, pagination)
+}
+
+// Resolve 依 action 處置檢舉的內容或作者並記錄於稽核紀錄，同一內容所有待處理的檢舉一併結案，回傳結案的檢舉數。
+// 隱藏或刪除時內容已不存在回傳 ErrReportTargetNotFound (仍可駁回或停權作者)，
+// 駁回因內容政策等待審核的內容時內容審核通過並發布，被管理員隱藏的內容維持隱藏
+func (s *ReportService) Resolve(ctx *gin.Context, moderatorID uuid.UUID, reportID uuid.UUID, action models.ModerationAction, note string) (uint, error) {
+	report, err := s.ReportRepository.GetByID(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrReportNotFound
+		}
+		return 0, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	if report.Status != models.ReportStatusPending {
+		return 0, ErrReportResolved
+	}
+	if action == models.ModerationActionSuspend && report.TargetAuthorID == moderatorID {
+		return 0, ErrReportSuspendSelf
+	}
+	var target *reportTarget
+	if action != models.ModerationActionSuspend {
+		target, err = s.getTarget(ctx, report.TargetType, report.TargetID, true)
+		if errors.Is(err, ErrReportTargetNotFound) && action == models.ModerationActionDismiss {
+			target, err = nil, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var resolvedCount uint
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		status := models.ReportStatusActioned
+		switch action {
+		case models.ModerationActionDismiss:
+			status = models.ReportStatusDismissed
+			if target != nil && target.HeldForReview {
+				if err := s.publish(ctx, target); err != nil {
+					return err
+				}
+			}
+		case models.ModerationActionHide:
+			if !target.Hidden {
+				if err := s.hide(ctx, target); err != nil {
+					return err
+				}
+			}
+		case models.ModerationActionDelete:
+			if target.Post != nil {
+				if err := s.PostService.DeleteWithContent(ctx, target.Post.ID); err != nil {
+					return err
+				}
+			} else {
+				if target.Hidden {
+					// 等待審核的評論 (編輯後送交審核時可能有回覆) 先恢復評論計數，再依一般評論刪除，有回覆時保留為墓碑
+					if err := s.unhideComment(ctx, target.Comment); err != nil {
+						return err
+					}
+				}
+				if _, err := s.CommentService.Delete(ctx, target.Comment, moderatorID); err != nil {
+					return err
+				}
+			}
+		case models.ModerationActionSuspend:
+			reason := "reported for " + string(report.Reason)
+			if note != "" {
+				reason = note
+			}
+			if err := s.UserService.Suspend(ctx, report.TargetAuthorID, &reason); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		resolvedCount, err = s.ReportRepository.ResolvePendingByTarget(ctx, report.TargetType, report.TargetID, status, moderatorID)
+		if err != nil {
+			return err
+		}
+		_, err = s.AuditLogRepository.Create(ctx, models.AuditLogBase{
+			ActorID:        moderatorID,
+			Action:         action,
+			TargetType:     report.TargetType,
+			TargetID:       report.TargetID,
+			TargetAuthorID: report.TargetAuthorID,
+			ReportID:       &report.ID,
+			Note:           note,
+		})
+		return err
+	}); err != nil {
+		return 0, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return resolvedCount, nil
+}
+
+// hide 隱藏貼文或評論，被隱藏的評論連同其回覆不列入貼文的評論計數
+func (s *ReportService) hide(ctx *gin.Context, target *reportTarget) error {
+	hiddenAt := time.Now().Unix()
+	if target.Post != nil {
+		s.TagService.InvalidateTrendingTags(ctx)
+		return s.PostRepository.UpdateByID(ctx, target.Post.ID, map[string]any{"hidden_at": hiddenAt})
+	}
+	count, err := s.CommentService.countVisibleSubtree(ctx, target.Comment.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.CommentRepository.UpdateByID(ctx, target.Comment.ID, map[string]any{"hidden_at": hiddenAt}); err != nil {
+		return err
+	}
+	return s.PostRepository.IncrementCommentCount(ctx, target.Comment.PostID, -count)
+}
+
+// publish 發布審核通過的內容，同步提及、評論計數 (包含其下重新出現的回覆) 與通知並推送評論
+func (s *ReportService) publish(ctx *gin.Context, target *reportTarget) error {
+	if target.Post != nil {
+		if err := s.PostRepository.UpdateByID(ctx, target.Post.ID, map[string]any{"hidden_at": nil}); err != nil {
+			return err
+		}
+		s.TagService.InvalidateTrendingTags(ctx)
+		_, err := s.MentionService.SyncPostMentions(ctx, target.Post.AuthorID, target.Post.ID, target.Post.Content)
+		return err
+	}
+
+	comment := target.Comment
+	if err := s.unhideComment(ctx, comment); err != nil {
+		return err
+	}
+	var err error
+	comment.Mentions, err = s.MentionService.SyncCommentMentions(ctx, comment.UserID, comment.PostID, comment.ID, comment.Content)
+	if err != nil {
+		return err
+	}
+	if err := s.NotificationService.NotifyComment(ctx, comment); err != nil {
+		return err
+	}
+	s.EventService.PublishComments(ctx, []models.Comment{*comment})
+	return nil
+}
+
+// unhideComment 取消隱藏評論，評論連同其回覆重新列入評論計數
+func (s *ReportService) unhideComment(ctx *gin.Context, comment *models.Comment) error {
+	count, err := s.CommentService.countVisibleSubtree(ctx, comment.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.CommentRepository.UpdateByID(ctx, comment.ID, map[string]any{"hidden_at": nil}); err != nil {
+		return err
+	}
+	return s.PostRepository.IncrementCommentCount(ctx, comment.PostID, count)
+}
+
+// GetAuditLogs 依建立時間由新到舊排序，targetID 不為 nil 時只回傳對該內容的處置
+func (s *ReportService) GetAuditLogs(ctx *gin.Context, targetID *uuid.UUID, pagination *models.Pagination) ([]models.AuditLog, uint, error) {
+	return s.AuditLogRepository.GetList(ctx, targetID, pagination)
+}
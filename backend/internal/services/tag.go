@@ -1,16 +1,42 @@
 package services
 
 import (
+	"backend/internal/middlewares"
 	"backend/internal/models"
+	"backend/internal/pkg"
 	"backend/internal/repositories"
+	"context"
+	"errors"
+	"log"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// TRENDING_TAGS_WINDOW 趨勢標籤統計的時間區間
+const TRENDING_TAGS_WINDOW = 24 * time.Hour
+
+// TRENDING_TAGS_REFRESH_INTERVAL 背景重新計算趨勢標籤的間隔
+const TRENDING_TAGS_REFRESH_INTERVAL = 5 * time.Minute
+
+// TRENDING_TAGS_CACHE_SIZE 快取的趨勢標籤數量
+const TRENDING_TAGS_CACHE_SIZE = 50
+
+var ErrTagNotFound = errors.New("tag not found")
+var ErrTagMergeSelf = errors.New("cannot merge a tag into itself")
+
 type TagService struct {
+	ErrorUtils *pkg.ErrorUtils
+	TagUtils   *pkg.TagUtils
+
 	TagRepository *repositories.TagRepository
+
+	// trendingTags 最近一次計算的趨勢標籤，尚未計算時為 nil
+	trendingTagsMutex sync.RWMutex
+	trendingTags      *models.TrendingTags
 }
 
 var tagService *TagService
@@ -19,6 +45,9 @@ var tagServiceOnce sync.Once
 func NewTagService() *TagService {
 	tagServiceOnce.Do(func() {
 		tagService = &TagService{
+			ErrorUtils: pkg.NewErrorUtils(),
+			TagUtils:   pkg.NewTagUtils(),
+
 			TagRepository: repositories.NewTagRepository(),
 		}
 	})
@@ -29,19 +58,29 @@ func (s *TagService) Create(ctx *gin.Context, tagBases []models.TagBase) ([]mode
 	return s.TagRepository.Create(ctx, tagBases)
 }
 
+// CreateIfNotExist 正規化標籤名稱後取得或建立標籤，別名會解析為其所指的標籤
+// 無效的名稱會被忽略，正規化或解析後相同的標籤只保留一個
 func (s *TagService) CreateIfNotExist(ctx *gin.Context, tagBases []models.TagBase) ([]models.Tag, error) {
 	// 檢查是否有已存在的 Tag
 	result := make([]models.Tag, 0, len(tagBases))
 	mustCreate := make([]models.TagBase, 0, len(tagBases))
+	existed := make(map[string]bool)
+	existedIDs := make(map[uuid.UUID]bool)
 	for _, tagBase := range tagBases {
-		existingTag, err := s.TagRepository.GetByName(ctx, tagBase.Name)
+		name, ok := s.TagUtils.Normalize(tagBase.Name)
+		if !ok || existed[name] {
+			continue
+		}
+		existed[name] = true
+		existingTag, err := s.resolve(ctx, name)
 		if err != nil {
 			return nil, err
 		}
-		if existingTag != nil {
+		if existingTag == nil {
+			mustCreate = append(mustCreate, models.TagBase{Name: name})
+		} else if !existedIDs[existingTag.ID] {
+			existedIDs[existingTag.ID] = true
 			result = append(result, *existingTag)
-		} else {
-			mustCreate = append(mustCreate, tagBase)
 		}
 	}
 	if len(mustCreate) == 0 {
@@ -58,6 +97,169 @@ func (s *TagService) CreateIfNotExist(ctx *gin.Context, tagBases []models.TagBas
 	return result, nil
 }
 
+// GetByName 正規化名稱並解析別名，標籤不存在或名稱無效時回傳 nil
+func (s *TagService) GetByName(ctx *gin.Context, name string) (*models.Tag, error) {
+	normalized, ok := s.TagUtils.Normalize(name)
+	if !ok {
+		return nil, nil
+	}
+	tag, err := s.resolve(ctx, normalized)
+	if err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return tag, nil
+}
+
+func (s *TagService) GetAliasNamesByID(ctx *gin.Context, tagID uuid.UUID) ([]string, error) {
+	names, err := s.TagRepository.GetAliasNamesByID(ctx, tagID)
+	if err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return names, nil
+}
+
+// Merge 將 sourceNames 的標籤合併到 targetName 的標籤，來源標籤的貼文移到目標標籤，名稱成為目標標籤的別名
+func (s *TagService) Merge(ctx *gin.Context, sourceNames []string, targetName string) (*models.Tag, error) {
+	var target *models.Tag
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		var err error
+		if target, err = s.GetByName(ctx, targetName); err != nil {
+			return err
+		}
+		if target == nil {
+			return ErrTagNotFound
+		}
+		sources := make([]models.Tag, 0, len(sourceNames))
+		existedIDs := make(map[uuid.UUID]bool)
+		for _, sourceName := range sourceNames {
+			source, err := s.GetByName(ctx, sourceName)
+			if err != nil {
+				return err
+			}
+			if source == nil {
+				return ErrTagNotFound
+			}
+			if source.ID == target.ID {
+				return ErrTagMergeSelf
+			}
+			if !existedIDs[source.ID] {
+				existedIDs[source.ID] = true
+				sources = append(sources, *source)
+			}
+		}
+		if err := s.TagRepository.Merge(ctx, sources, target.ID); err != nil {
+			return s.ErrorUtils.ServerInternalError(err.Error())
+		}
+		s.InvalidateTrendingTags(ctx)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// resolve 以正規化的名稱取得標籤，找不到時查詢別名
+func (s *TagService) resolve(ctx *gin.Context, name string) (*models.Tag, error) {
+	tag, err := s.TagRepository.GetByName(ctx, name)
+	if err != nil || tag != nil {
+		return tag, err
+	}
+	return s.TagRepository.GetByAlias(ctx, name)
+}
+
 func (s *TagService) GetByIDs(ctx *gin.Context, ids []uuid.UUID) ([]models.Tag, error) {
 	return s.TagRepository.GetByIDs(ctx, ids)
 }
+
+// DeleteOrphansByIDs 刪除 tagIDs 中已無任何貼文關聯的標籤
+func (s *TagService) DeleteOrphansByIDs(ctx *gin.Context, tagIDs []uuid.UUID) error {
+	return s.TagRepository.DeleteOrphansByIDs(ctx, tagIDs)
+}
+
+func (s *TagService) CountPostsByID(ctx *gin.Context, tagID uuid.UUID) (uint, error) {
+	postCount, err := s.TagRepository.CountPostsByID(ctx, tagID)
+	if err != nil {
+		return 0, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return postCount, nil
+}
+
+func (s *TagService) GetSummaries(ctx *gin.Context, sort models.TagSort, pagination *models.Pagination) ([]models.TagSummary, uint, error) {
+	summaries, totalCount, err := s.TagRepository.GetSummaries(ctx, sort, pagination)
+	if err != nil {
+		return nil, 0, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return summaries, totalCount, nil
+}
+
+// Autocomplete 回傳名稱以 prefix 開頭的標籤，貼文數多的在前
+func (s *TagService) Autocomplete(ctx *gin.Context, prefix string, limit uint) ([]models.TagSummary, error) {
+	summaries, err := s.TagRepository.GetSummariesByPrefix(ctx, prefix, limit)
+	if err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return summaries, nil
+}
+
+// GetTrendingTags 回傳快取的趨勢標籤，尚未計算時 (例如未啟動背景更新) 立即計算
+func (s *TagService) GetTrendingTags(ctx *gin.Context) (*models.TrendingTags, error) {
+	s.trendingTagsMutex.RLock()
+	trendingTags := s.trendingTags
+	s.trendingTagsMutex.RUnlock()
+	if trendingTags != nil {
+		return trendingTags, nil
+	}
+	return s.RefreshTrendingTags(ctx)
+}
+
+// RefreshTrendingTags 重新計算 TRENDING_TAGS_WINDOW 內的趨勢標籤並更新快取
+func (s *TagService) RefreshTrendingTags(ctx *gin.Context) (*models.TrendingTags, error) {
+	now := time.Now()
+	summaries, err := s.TagRepository.GetTrendingSummaries(ctx, now.Add(-TRENDING_TAGS_WINDOW).Unix(), TRENDING_TAGS_CACHE_SIZE)
+	if err != nil {
+		return nil, s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	trendingTags := &models.TrendingTags{
+		Tags:        summaries,
+		Window:      TRENDING_TAGS_WINDOW,
+		RefreshedAt: now,
+	}
+	s.trendingTagsMutex.Lock()
+	s.trendingTags = trendingTags
+	s.trendingTagsMutex.Unlock()
+	return trendingTags, nil
+}
+
+// InvalidateTrendingTags 在目前交易提交後清除趨勢標籤的快取，下次取得時重新計算，
+// 標籤合併或貼文被隱藏、發布時呼叫，避免在下次背景更新前回傳過期或被隱藏的資料
+func (s *TagService) InvalidateTrendingTags(ctx *gin.Context) {
+	middlewares.AfterCommit(ctx, func() {
+		s.trendingTagsMutex.Lock()
+		s.trendingTags = nil
+		s.trendingTagsMutex.Unlock()
+	})
+}
+
+// StartTrendingTagsRefresher 立即計算趨勢標籤，之後每隔 interval 在背景重新計算，直到 runCtx 結束
+func (s *TagService) StartTrendingTagsRefresher(runCtx context.Context, db *gorm.DB, interval time.Duration) {
+	ctx := &gin.Context{}
+	middlewares.SetContentGORMDB(ctx, db)
+	refresh := func() {
+		if _, err := s.RefreshTrendingTags(ctx); err != nil {
+			log.Printf("Failed to refresh trending tags: %v\n", err)
+		}
+	}
+	refresh()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+				refresh()
+			}
+		}
+	}()
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/tests"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagService(t *testing.T) {
+	service := NewTagService()
+	postService := NewPostService()
+	ctx, db, cleanup := tests.SetupTestContext("test_tag_service.db")
+	defer cleanup()
+
+	author := &models.User{
+		TableModel: models.TableModel{ID: uuid.New()},
+		UserBase: models.UserBase{
+			Username:       "author",
+			Email:          "author@example.com",
+			HashedPassword: "hashed",
+			Role:           models.RoleNormalCustomer,
+		},
+	}
+	require.NoError(t, db.Create(author).Error)
+
+	t.Run("單例模式測試", func(t *testing.T) {
+		assert.Same(t, service, NewTagService(), "應該返回相同的實例")
+	})
+
+	t.Run("CreateIfNotExist - 正規化名稱", func(t *testing.T) {
+		tags, err := service.CreateIfNotExist(ctx, []models.TagBase{{Name: "Rust"}, {Name: "rust,"}, {Name: "!!!"}, {Name: "ＲＵＳＴ"}})
+		require.NoError(t, err)
+		if assert.Len(t, tags, 1) {
+			assert.Equal(t, "rust", tags[0].Name)
+		}
+
+		again, err := service.CreateIfNotExist(ctx, []models.TagBase{{Name: "RUST"}})
+		require.NoError(t, err)
+		if assert.Len(t, again, 1) {
+			assert.Equal(t, tags[0].ID, again[0].ID, "不分大小寫應取得相同的標籤")
+		}
+	})
+
+	t.Run("遷移既有的重複標籤", func(t *testing.T) {
+		// 模擬正規化前建立的標籤
+		createTag := func(name string, createdAt int64) models.Tag {
+			tag := models.Tag{
+				TableModel: models.TableModel{ID: uuid.New(), CreatedAt: createdAt},
+				TagBase:    models.TagBase{Name: name},
+			}
+			require.NoError(t, db.Create(&tag).Error)
+			return tag
+		}
+		createPost := func(tags ...models.Tag) uuid.UUID {
+			post, err := postService.CreatePostWithTags(ctx, models.PostBase{AuthorID: author.ID, Content: "legacy"}, nil)
+			require.NoError(t, err)
+			for _, tag := range tags {
+				require.NoError(t, db.Exec("INSERT INTO post_to_tag (post_id, tag_id) VALUES (?, ?)", post.ID, tag.ID).Error)
+			}
+			return post.ID
+		}
+		upperTag := createTag("Go", 1)
+		commaTag := createTag("go,", 2)
+		fullWidthTag := createTag("ＧＯ", 3)
+		invalidTag := createTag("!!!", 4)
+		bothPostID := createPost(upperTag, commaTag)
+		fullWidthPostID := createPost(fullWidthTag, invalidTag)
+
+		require.NoError(t, database.Migrate(db))
+
+		tags := []models.Tag{}
+		require.NoError(t, db.Where("id IN ?", []uuid.UUID{upperTag.ID, commaTag.ID, fullWidthTag.ID, invalidTag.ID}).Find(&tags).Error)
+		if assert.Len(t, tags, 1, "重複與無效的標籤應被刪除") {
+			assert.Equal(t, upperTag.ID, tags[0].ID, "保留最早建立的標籤")
+			assert.Equal(t, "go", tags[0].Name)
+		}
+		postIDs := []uuid.UUID{}
+		require.NoError(t, db.Table("post_to_tag").Where("tag_id = ?", upperTag.ID).Order("post_id").Pluck("post_id", &postIDs).Error)
+		assert.ElementsMatch(t, []uuid.UUID{bothPostID, fullWidthPostID}, postIDs, "貼文關聯應合併且不重複")
+		invalidCount := int64(0)
+		require.NoError(t, db.Table("post_to_tag").Where("tag_id = ?", invalidTag.ID).Count(&invalidCount).Error)
+		assert.Zero(t, invalidCount)
+
+		require.NoError(t, database.Migrate(db), "重複執行遷移不應失敗")
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		_, err := service.Merge(ctx, []string{"rust"}, "unknown")
+		assert.ErrorIs(t, err, ErrTagNotFound)
+		_, err = service.Merge(ctx, []string{"Go"}, "go")
+		assert.ErrorIs(t, err, ErrTagMergeSelf)
+
+		_, err = service.CreateIfNotExist(ctx, []models.TagBase{{Name: "zig"}})
+		require.NoError(t, err)
+		target, err := service.Merge(ctx, []string{"rust"}, "zig")
+		require.NoError(t, err)
+		assert.Equal(t, "zig", target.Name)
+		aliases, err := service.GetAliasNamesByID(ctx, target.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"rust"}, aliases)
+
+		resolved, err := service.GetByName(ctx, "#Rust")
+		require.NoError(t, err)
+		if assert.NotNil(t, resolved) {
+			assert.Equal(t, target.ID, resolved.ID)
+		}
+
+		// 有別名的標籤沒有貼文時不應被當作孤兒刪除
+		require.NoError(t, service.DeleteOrphansByIDs(ctx, []uuid.UUID{target.ID}))
+		resolved, err = service.GetByName(ctx, "zig")
+		require.NoError(t, err)
+		assert.NotNil(t, resolved)
+	})
+
+	t.Run("貼文數不含被隱藏的貼文", func(t *testing.T) {
+		createPost := func() uuid.UUID {
+			post, err := postService.CreatePostWithTags(ctx, models.PostBase{AuthorID: author.ID, Content: "#hidden"}, []models.TagBase{{Name: "hidden"}})
+			require.NoError(t, err)
+			return post.ID
+		}
+		createPost()
+		hiddenPostID := createPost()
+		require.NoError(t, db.Model(&models.Post{}).Where("id = ?", hiddenPostID).Update("hidden_at", time.Now().Unix()).Error)
+
+		tag, err := service.GetByName(ctx, "hidden")
+		require.NoError(t, err)
+		require.NotNil(t, tag)
+		postCount, err := service.CountPostsByID(ctx, tag.ID)
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), postCount)
+
+		summaries, err := service.Autocomplete(ctx, "hid", 10)
+		require.NoError(t, err)
+		if assert.Len(t, summaries, 1) {
+			assert.Equal(t, uint(1), summaries[0].PostCount)
+		}
+		summaries, _, err = service.GetSummaries(ctx, models.TagSortPopular, &models.Pagination{Limit: 100})
+		require.NoError(t, err)
+		for _, summary := range summaries {
+			if summary.ID == tag.ID {
+				assert.Equal(t, uint(1), summary.PostCount)
+			}
+		}
+	})
+
+	t.Run("合併標籤後清除趨勢標籤的快取", func(t *testing.T) {
+		_, err := postService.CreatePostWithTags(ctx, models.PostBase{AuthorID: author.ID, Content: "#trendy"}, []models.TagBase{{Name: "trendy"}})
+		require.NoError(t, err)
+		_, err = service.CreateIfNotExist(ctx, []models.TagBase{{Name: "popular"}})
+		require.NoError(t, err)
+		getTrendingNames := func() []string {
+			trendingTags, err := service.GetTrendingTags(ctx)
+			require.NoError(t, err)
+			names := []string{}
+			for _, tag := range trendingTags.Tags {
+				names = append(names, tag.Name)
+			}
+			return names
+		}
+		_, err = service.RefreshTrendingTags(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, getTrendingNames(), "trendy")
+
+		_, err = service.Merge(ctx, []string{"trendy"}, "popular")
+		require.NoError(t, err)
+		names := getTrendingNames()
+		assert.NotContains(t, names, "trendy")
+		assert.Contains(t, names, "popular")
+	})
+}
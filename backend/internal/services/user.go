@@ -6,16 +6,30 @@ import (
 	"backend/internal/pkg"
 	"backend/internal/repositories"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type UserService struct {
-	UserRepository    *repositories.UserRepository
-	AddressRepository *repositories.AddressRepository
+	UserRepository         *repositories.UserRepository
+	AddressRepository      *repositories.AddressRepository
+	RefreshTokenRepository *repositories.RefreshTokenRepository
+	PostRepository         *repositories.PostRepository
+	CommentRepository      *repositories.CommentRepository
+	TagRepository          *repositories.TagRepository
+	FollowRepository       *repositories.FollowRepository
+	MentionRepository      *repositories.MentionRepository
+	NotificationRepository *repositories.NotificationRepository
+	BlockRepository        *repositories.BlockRepository
+	MuteRepository         *repositories.MuteRepository
+	ReportRepository       *repositories.ReportRepository
+	ConversationRepository *repositories.ConversationRepository
+	MessageRepository      *repositories.MessageRepository
 
-	ErrorUtils *pkg.ErrorUtils
+	ErrorUtils  *pkg.ErrorUtils
+	CryptoUtils *pkg.CryptoUtils
 }
 
 var userOnce sync.Once
@@ -24,10 +38,23 @@ var userService *UserService
 func NewUserService() *UserService {
 	userOnce.Do(func() {
 		userService = &UserService{
-			UserRepository:    repositories.NewUserRepository(),
-			AddressRepository: repositories.NewAddressRepository(),
+			UserRepository:         repositories.NewUserRepository(),
+			AddressRepository:      repositories.NewAddressRepository(),
+			RefreshTokenRepository: repositories.NewRefreshTokenRepository(),
+			PostRepository:         repositories.NewPostRepository(),
+			CommentRepository:      repositories.NewCommentRepository(),
+			TagRepository:          repositories.NewTagRepository(),
+			FollowRepository:       repositories.NewFollowRepository(),
+			MentionRepository:      repositories.NewMentionRepository(),
+			NotificationRepository: repositories.NewNotificationRepository(),
+			BlockRepository:        repositories.NewBlockRepository(),
+			MuteRepository:         repositories.NewMuteRepository(),
+			ReportRepository:       repositories.NewReportRepository(),
+			ConversationRepository: repositories.NewConversationRepository(),
+			MessageRepository:      repositories.NewMessageRepository(),
 
-			ErrorUtils: pkg.NewErrorUtils(),
+			ErrorUtils:  pkg.NewErrorUtils(),
+			CryptoUtils: pkg.NewCryptoUtils(),
 		}
 	})
 	return userService
@@ -49,10 +76,200 @@ func (s *UserService) Create(ctx *gin.Context, userBaseSlice []models.UserBase)
 	return s.UserRepository.Create(ctx, userBaseSlice)
 }
 
+func (s *UserService) GetList(ctx *gin.Context, filter *models.UserListFilter, pagination *models.Pagination) ([]models.User, uint, error) {
+	return s.UserRepository.GetList(ctx, filter, pagination)
+}
+
+func (s *UserService) GetListByCursor(ctx *gin.Context, filter *models.UserListFilter, pagination *models.CursorPagination) ([]models.User, *models.Cursor, *uint, error) {
+	return s.UserRepository.GetListByCursor(ctx, filter, pagination)
+}
+
+func (s *UserService) UpdateHashedPassword(ctx *gin.Context, userID uuid.UUID, hashedPassword string) error {
+	return s.UserRepository.UpdateByID(ctx, userID, map[string]any{"hashed_password": hashedPassword})
+}
+
 func (s *UserService) DeleteByID(ctx *gin.Context, userID uuid.UUID) error {
 	return s.UserRepository.DeleteByID(ctx, userID)
 }
 
+// UpdateRole 變更角色後，舊的 access token 隨即失效
+func (s *UserService) UpdateRole(ctx *gin.Context, userID uuid.UUID, role models.Role) error {
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		if err := s.UserRepository.UpdateByID(ctx, userID, map[string]any{"role": role}); err != nil {
+			return err
+		}
+		return s.UserRepository.IncrementTokenVersion(ctx, userID)
+	}); err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return nil
+}
+
+// Suspend 停權並撤銷所有 session
+func (s *UserService) Suspend(ctx *gin.Context, userID uuid.UUID, reason *string) error {
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		if err := s.UserRepository.UpdateByID(ctx, userID, map[string]any{
+			"suspended_at":     time.Now().Unix(),
+			"suspended_reason": reason,
+		}); err != nil {
+			return err
+		}
+		if err := s.UserRepository.IncrementTokenVersion(ctx, userID); err != nil {
+			return err
+		}
+		return s.RefreshTokenRepository.RevokeByUserID(ctx, userID)
+	}); err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return nil
+}
+
+func (s *UserService) Unsuspend(ctx *gin.Context, userID uuid.UUID) error {
+	if err := s.UserRepository.UpdateByID(ctx, userID, map[string]any{
+		"suspended_at":     nil,
+		"suspended_reason": nil,
+	}); err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return nil
+}
+
+// ForcePasswordReset 產生一次性臨時密碼並撤銷所有 session，使用者登入後需變更密碼
+func (s *UserService) ForcePasswordReset(ctx *gin.Context, user *models.User) (string, error) {
+	// 9 bytes 經 base64 編碼後為 12 個字元，符合密碼長度限制
+	temporaryPassword, err := s.CryptoUtils.GenerateRandomToken(9)
+	if err != nil {
+		return "", s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	hashedPassword, err := s.CryptoUtils.GeneratePasswordHash(&pkg.CryptoUtilsPasswordHashInput{
+		Email:    user.Email,
+		Password: temporaryPassword,
+	})
+	if err != nil {
+		return "", s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		if err := s.UserRepository.UpdateByID(ctx, user.ID, map[string]any{
+			"hashed_password":         hashedPassword,
+			"password_reset_required": true,
+		}); err != nil {
+			return err
+		}
+		if err := s.UserRepository.IncrementTokenVersion(ctx, user.ID); err != nil {
+			return err
+		}
+		return s.RefreshTokenRepository.RevokeByUserID(ctx, user.ID)
+	}); err != nil {
+		return "", s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return temporaryPassword, nil
+}
+
+// ChangePassword 變更密碼並撤銷所有 session (包含目前的 session)，需重新登入
+func (s *UserService) ChangePassword(ctx *gin.Context, user *models.User, newPassword string) error {
+	hashedPassword, err := s.CryptoUtils.GeneratePasswordHash(&pkg.CryptoUtilsPasswordHashInput{
+		Email:    user.Email,
+		Password: newPassword,
+	})
+	if err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		if err := s.UserRepository.UpdateByID(ctx, user.ID, map[string]any{
+			"hashed_password":         hashedPassword,
+			"password_reset_required": false,
+		}); err != nil {
+			return err
+		}
+		if err := s.UserRepository.IncrementTokenVersion(ctx, user.ID); err != nil {
+			return err
+		}
+		return s.RefreshTokenRepository.RevokeByUserID(ctx, user.ID)
+	}); err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return nil
+}
+
+// DeleteWithContent 永久刪除使用者以及其貼文、評論、喜歡、追蹤、提及、通知、封鎖、靜音、私訊、檢舉與 session (保留稽核紀錄)
+func (s *UserService) DeleteWithContent(ctx *gin.Context, user *models.User) error {
+	if err := middlewares.TransactionGORMDB(ctx, func() error {
+		// 記錄使用者評論或喜歡過的貼文，刪除後重新計算這些貼文的計數
+		commentedPostIDs, err := s.CommentRepository.GetPostIDsByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		likedPostIDs, err := s.PostRepository.GetIDsLikedByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		authoredPostIDs, err := s.PostRepository.GetIDsByAuthorID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		tagIDs, err := s.PostRepository.GetTagIDsByPostIDs(ctx, authoredPostIDs)
+		if err != nil {
+			return err
+		}
+
+		if err := s.PostRepository.DeleteByIDs(ctx, authoredPostIDs); err != nil {
+			return err
+		}
+		if err := s.TagRepository.DeleteOrphansByIDs(ctx, tagIDs); err != nil {
+			return err
+		}
+		if err := s.CommentRepository.DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := s.UserRepository.ClearLikes(ctx, user.ID); err != nil {
+			return err
+		}
+		if affectedPostIDs := append(commentedPostIDs, likedPostIDs...); len(affectedPostIDs) > 0 {
+			if _, err := s.PostRepository.ReconcileCounters(ctx, affectedPostIDs); err != nil {
+				return err
+			}
+		}
+		if err := s.RefreshTokenRepository.DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := s.FollowRepository.DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := s.MentionRepository.DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := s.NotificationRepository.DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := s.BlockRepository.DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := s.MuteRepository.DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := s.ReportRepository.DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := s.MessageRepository.DeleteBySenderID(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := s.ConversationRepository.DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := s.UserRepository.DeleteByID(ctx, user.ID); err != nil {
+			return err
+		}
+		if user.AddressID != nil {
+			return s.AddressRepository.DeleteByID(ctx, *user.AddressID)
+		}
+		return nil
+	}); err != nil {
+		return s.ErrorUtils.ServerInternalError(err.Error())
+	}
+	return nil
+}
+
 func (s *UserService) CreateUserWithAddress(ctx *gin.Context, userBase *models.UserBase, addressBase *models.AddressBase) (*models.User, error) {
 
 	var user *models.User
@@ -50,6 +50,24 @@ func SetupTestServer(dbFile string) (*gin.Engine, *gin.RouterGroup, *gin.Context
 	return server, apiRouter, ctx, db, cleanup
 }
 
+// SendTestRequest 對測試伺服器發送請求，body 不為 nil 時以 JSON 送出，accessToken 為空字串時不帶 Authorization
+func SendTestRequest(server *gin.Engine, method string, path string, accessToken string, body any) *httptest.ResponseRecorder {
+	var req *http.Request
+	if body != nil {
+		buf, _ := pkg.NewHTTPUtils().ToJSONBuffer(body)
+		req, _ = http.NewRequest(method, path, buf)
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req, _ = http.NewRequest(method, path, nil)
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", accessToken)
+	}
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+	return recorder
+}
+
 // Required PostRouter.Bind
 func SetupTestPost(server *gin.Engine, accessToken string) (*models.PostCreateResponse, error) {
 	httpUtils := pkg.NewHTTPUtils()
@@ -108,3 +126,36 @@ func SetupTestUser(server *gin.Engine) (*models.UserRegisterResponse, *models.Us
 
 	return registerRespBody, loginRespBody, nil
 }
+
+// Required UserRouter.Bind
+func SetupTestAdminUser(server *gin.Engine, db *gorm.DB) (*models.UserRegisterResponse, *models.UserLoginResponse, error) {
+	httpUtils := pkg.NewHTTPUtils()
+
+	// 1. 創建一個新用戶並設為管理員
+	password := "password123"
+	registerRespBody, _, err := SetupTestUser(server)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := db.Model(&models.User{}).
+		Where("id = ?", registerRespBody.ID).
+		Update("role", models.RoleAdmin).Error; err != nil {
+		return nil, nil, err
+	}
+
+	// 2. 重新登入以取得管理員 token
+	loginReqBuf, _ := httpUtils.ToJSONBuffer(&models.UserLoginRequest{
+		Email:    registerRespBody.Email,
+		Password: password,
+	})
+	reqLogin, _ := http.NewRequest("POST", "/api/user/login", loginReqBuf)
+	reqLogin.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, reqLogin)
+	loginRespBody := &models.UserLoginResponse{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), loginRespBody); err != nil {
+		return nil, nil, err
+	}
+
+	return registerRespBody, loginRespBody, nil
+}
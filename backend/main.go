@@ -1,19 +1,29 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
 	_ "backend/docs"
 	"backend/internal/database"
+	"backend/internal/middlewares"
 	"backend/internal/models"
+	"backend/internal/pkg"
 	"backend/internal/routers"
 	"backend/internal/servers"
+	"backend/internal/services"
 )
 
 // @title Social APP API
@@ -23,6 +33,13 @@ import (
 // @in header
 // @name Authorization
 // @basePath /api
+
+// SERVER_SHUTDOWN_TIMEOUT how long to wait for in-flight requests when shutting down
+const SERVER_SHUTDOWN_TIMEOUT = 10 * time.Second
+
+// AI_MODERATION_SHUTDOWN_TIMEOUT how long to wait for queued AI moderation after the server has shut down
+const AI_MODERATION_SHUTDOWN_TIMEOUT = 30 * time.Second
+
 func main() {
 	// Load environment variables from .env file
 	if _, err := os.Stat(".env"); err == nil {
@@ -52,6 +69,8 @@ func main() {
 	flag.StringVar(&host, "host", host, "Host for the server")
 	flag.StringVar(&port, "port", port, "Port for the server")
 	flag.BoolVar(&debug, "debug", debug, "Enable debug mode")
+	reconcileCounters := false
+	flag.BoolVar(&reconcileCounters, "reconcile-counters", false, "Recompute like and comment counters of all posts, then exit")
 	flag.Parse()
 
 	// Connect to database
@@ -65,6 +84,29 @@ func main() {
 		EnableLog: debug,
 	})
 
+	// Recompute denormalized counters
+	if reconcileCounters {
+		ctx := &gin.Context{}
+		middlewares.SetContentGORMDB(ctx, db)
+		count, err := services.NewPostService().ReconcileCounters(ctx)
+		if err != nil {
+			log.Fatal("Failed to reconcile counters:", err)
+		}
+		log.Printf("Reconciled counters of %d posts\n", count)
+		return
+	}
+
+	// Load content policy
+	contentFilterConfig, err := pkg.ContentFilterConfigFromEnv()
+	if err != nil {
+		log.Fatal("Failed to load content filter config:", err)
+	}
+	contentFilter, err := pkg.NewContentFilter(contentFilterConfig)
+	if err != nil {
+		log.Fatal("Failed to load content filter:", err)
+	}
+	services.NewContentPolicyService().SetFilter(contentFilter)
+
 	// Setup Gin server
 	server, apiRouter := servers.SetupGin(&servers.GinConfig{
 		DB:    db,
@@ -74,24 +116,90 @@ func main() {
 	routers.NewUserRouter().Bind(apiRouter)
 	routers.NewPostRouter().Bind(apiRouter)
 	routers.NewCommentRouter().Bind(apiRouter)
+	routers.NewAdminRouter().Bind(apiRouter)
+	routers.NewFollowRouter().Bind(apiRouter)
+	routers.NewTagRouter().Bind(apiRouter)
+	routers.NewNotificationRouter().Bind(apiRouter)
+	routers.NewEventRouter().Bind(apiRouter)
+	routers.NewConversationRouter().Bind(apiRouter)
+	routers.NewBlockRouter().Bind(apiRouter)
+	routers.NewMuteRouter().Bind(apiRouter)
+	routers.NewReportRouter().Bind(apiRouter)
+
+	// Background jobs stop when the server shuts down on SIGINT or SIGTERM
+	runCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Refresh trending tags in the background
+	services.NewTagService().StartTrendingTagsRefresher(runCtx, db, services.TRENDING_TAGS_REFRESH_INTERVAL)
 
 	server.Static("/public", "./public")
 	server.GET("/", func(ctx *gin.Context) {
 		ctx.File("./public/index.html")
 	})
 
-	// Setup AI Router
-	routers.NewAIRouter(&models.AIModelConfigs{
-		ChatModel: models.AIModelConfig{
-			APIKey:    os.Getenv("OPENAI_API_KEY"),
-			BaseURL:   os.Getenv("OPENAI_BASE_URL"),
-			ModelName: os.Getenv("OPENAI_CHAT_MODEL"),
-		},
-	}).Bind(apiRouter)
+	// Setup AI Router, AI is disabled when no provider is configured
+	chatModelConfig := models.AIModelConfig{
+		Provider:  strings.ToLower(os.Getenv("AI_PROVIDER")),
+		APIKey:    os.Getenv("OPENAI_API_KEY"),
+		BaseURL:   os.Getenv("OPENAI_BASE_URL"),
+		ModelName: os.Getenv("OPENAI_CHAT_MODEL"),
+	}
+	switch chatModelConfig.Provider {
+	case "":
+		// Fall back to OpenAI when only the OpenAI settings are present
+		if chatModelConfig.APIKey != "" {
+			chatModelConfig.Provider = services.AI_PROVIDER_OPENAI
+		}
+	case services.AI_PROVIDER_OLLAMA:
+		chatModelConfig.BaseURL = os.Getenv("OLLAMA_SERVER_URL")
+		chatModelConfig.ModelName = os.Getenv("OLLAMA_CHAT_MODEL")
+	}
+	aiRouter := routers.NewAIRouter(&models.AIModelConfigs{ChatModel: chatModelConfig})
+	aiRouter.Bind(apiRouter)
+
+	// AI moderation reuses the chat model
+	if enabled := strings.ToLower(os.Getenv("AI_MODERATION_ENABLED")); enabled == "1" || enabled == "true" {
+		threshold := services.AI_MODERATION_DEFAULT_THRESHOLD
+		if value := os.Getenv("AI_MODERATION_THRESHOLD"); value != "" {
+			if threshold, err = strconv.ParseFloat(value, 64); err != nil {
+				log.Fatal("Invalid AI_MODERATION_THRESHOLD:", err)
+			}
+		}
+		if aiRouter.ChatModel == nil {
+			log.Println("AI moderation is disabled because AI is disabled")
+		} else {
+			services.NewAIModerationService().SetModel(aiRouter.ChatModel, threshold)
+		}
+	}
 
 	// Start the server
 	log.Printf("Swagger docs available at http://%s:%s/swagger/index.html\n", host, port)
-	if err := server.Run(host + ":" + port); err != nil {
-		log.Fatal(err)
+	httpServer := &http.Server{
+		Addr:    host + ":" + port,
+		Handler: server,
+	}
+	// Shutdown does not cancel in-flight requests, end the long-lived event streams explicitly
+	httpServer.RegisterOnShutdown(routers.NewEventRouter().Close)
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// Shut down gracefully
+	<-runCtx.Done()
+	stop()
+	log.Println("Shutting down server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), SERVER_SHUTDOWN_TIMEOUT)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to shut down server: %v\n", err)
+	}
+	// Finish queued AI moderation before exiting
+	moderationCtx, cancelModeration := context.WithTimeout(context.Background(), AI_MODERATION_SHUTDOWN_TIMEOUT)
+	defer cancelModeration()
+	if err := services.NewAIModerationService().Shutdown(moderationCtx); err != nil {
+		log.Printf("Failed to finish AI moderation: %v\n", err)
 	}
 }